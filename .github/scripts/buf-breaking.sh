@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Fails when the protos in proto/ break the wire or JSON schema of the
+# version on the base branch, such as by removing or renumbering a field,
+# so a client built against one version keeps working with the next.
+# Requires buf. Compare against another ref with BUF_BREAKING_AGAINST.
+BASE="${BUF_BREAKING_AGAINST:-origin/main}"
+
+ROOT_DIR="$(cd "$(dirname "$0")/../.." && pwd)"
+cd "$ROOT_DIR"
+
+if ! command -v buf >/dev/null 2>&1; then
+  echo "buf is required. Please install buf and re-run." >&2
+  exit 1
+fi
+
+if ! git cat-file -e "$BASE:proto/buf.yaml" 2>/dev/null; then
+  echo "No proto module at $BASE; nothing to compare against."
+  exit 0
+fi
+
+buf breaking proto --against ".git#ref=$BASE,subdir=proto"
+echo "No breaking changes against $BASE."
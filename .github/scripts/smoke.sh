@@ -6,6 +6,8 @@ API_HOST="localhost"
 API_PORT="8000"
 RACING_GRPC="localhost:9000"
 SPORTS_GRPC="localhost:9001"
+BETTING_GRPC="localhost:9002"
+ACCOUNTS_GRPC="localhost:9003"
 
 if ! command -v jq >/dev/null 2>&1; then
   echo "jq is required. Please install jq and re-run." >&2
@@ -16,56 +18,204 @@ ROOT_DIR="$(cd "$(dirname "$0")/../.." && pwd)"
 DIST_DIR="$ROOT_DIR/dist"
 
 mkdir -p "$DIST_DIR"
-if [[ ! -x "$DIST_DIR/racing" || ! -x "$DIST_DIR/sports" || ! -x "$DIST_DIR/api" ]]; then
+if [[ ! -x "$DIST_DIR/racing" || ! -x "$DIST_DIR/sports" || ! -x "$DIST_DIR/betting" || ! -x "$DIST_DIR/accounts" || ! -x "$DIST_DIR/api" ]]; then
   echo "Building services into dist/ ..."
   (cd "$ROOT_DIR/racing" && go build -buildvcs=false -o "$DIST_DIR/racing" .)
   (cd "$ROOT_DIR/sports" && go build -buildvcs=false -o "$DIST_DIR/sports" .)
+  (cd "$ROOT_DIR/betting" && go build -buildvcs=false -o "$DIST_DIR/betting" .)
+  (cd "$ROOT_DIR/accounts" && go build -buildvcs=false -o "$DIST_DIR/accounts" .)
   (cd "$ROOT_DIR/api" && go build -buildvcs=false -o "$DIST_DIR/api" .)
 fi
 
+# A trading API key for the checks that need authentication.
+API_KEY="smoke-trading-key"
+API_KEYS_FILE="$DIST_DIR/api-keys.json"
+printf '{"keys":[{"name":"smoke","sha256":"%s","roles":["trading"]}]}' "$(printf '%s' "$API_KEY" | sha256sum | cut -d' ' -f1)" > "$API_KEYS_FILE"
+
+# Every service gets a certificate from a throwaway development CA.
+TLS_DIR="$DIST_DIR/tls"
+rm -rf "$TLS_DIR"
+TLS_FLAGS=(--tls-dev --tls-dev-dir "$TLS_DIR")
+CURL=(curl --cacert "$TLS_DIR/ca/ca.pem")
+
+# The gateway and racing write their trace spans to files.
+rm -f "$DIST_DIR"/*-traces.jsonl
+trace_flags() { echo --trace-exporter file --trace-file "$DIST_DIR/$1-traces.jsonl"; }
+
+# Settings come from flags, the environment and config files alike.
+ACCOUNTS_CONFIG="$DIST_DIR/accounts.yaml"
+printf 'grpc-endpoint: %s\ntls:\n  dev: true\n  dev-dir: %s\n' "$ACCOUNTS_GRPC" "$TLS_DIR" > "$ACCOUNTS_CONFIG"
+
+echo "Checking configuration..."
+config=$(SPORTS_DB_DSN=/tmp/smoke.db "$DIST_DIR/sports" --print-config --tls-dev --grpc-endpoint "$SPORTS_GRPC")
+grep -qx "db-dsn: /tmp/smoke.db # env \$SPORTS_DB_DSN" <<< "$config" || { echo "print-config missed the environment: $config" >&2; exit 1; }
+grep -qx "grpc-endpoint: $SPORTS_GRPC # flag" <<< "$config" || { echo "print-config missed the flag: $config" >&2; exit 1; }
+set +e
+"$DIST_DIR/racing" --tls-dev --grpc-endpoint nope > /dev/null 2>&1
+code=$?
+set -e
+[[ "$code" == "2" ]] || { echo "Expected an invalid endpoint to exit 2, got $code" >&2; exit 1; }
+
 echo "Starting services..."
 chmod +x "$DIST_DIR"/*
 (
-  cd "$ROOT_DIR/racing"; nohup "$DIST_DIR/racing" --grpc-endpoint "$RACING_GRPC" > "$ROOT_DIR/racing.out" 2>&1 & echo $! > "$ROOT_DIR/racing.pid"
+  cd "$ROOT_DIR/racing"; nohup "$DIST_DIR/racing" "${TLS_FLAGS[@]}" $(trace_flags racing) --grpc-endpoint "$RACING_GRPC" > "$ROOT_DIR/racing.out" 2>&1 & echo $! > "$ROOT_DIR/racing.pid"
+)
+(
+  cd "$ROOT_DIR/sports"; SPORTS_GRPC_ENDPOINT="$SPORTS_GRPC" nohup "$DIST_DIR/sports" "${TLS_FLAGS[@]}" > "$ROOT_DIR/sports.out" 2>&1 & echo $! > "$ROOT_DIR/sports.pid"
+)
+(
+  cd "$ROOT_DIR/accounts"; nohup "$DIST_DIR/accounts" --config "$ACCOUNTS_CONFIG" > "$ROOT_DIR/accounts.out" 2>&1 & echo $! > "$ROOT_DIR/accounts.pid"
 )
 (
-  cd "$ROOT_DIR/sports"; nohup "$DIST_DIR/sports" --grpc-endpoint "$SPORTS_GRPC" > "$ROOT_DIR/sports.out" 2>&1 & echo $! > "$ROOT_DIR/sports.pid"
+  cd "$ROOT_DIR/betting"; nohup "$DIST_DIR/betting" "${TLS_FLAGS[@]}" --grpc-endpoint "$BETTING_GRPC" --racing-grpc-endpoint "$RACING_GRPC" --accounts-grpc-endpoint "$ACCOUNTS_GRPC" > "$ROOT_DIR/betting.out" 2>&1 & echo $! > "$ROOT_DIR/betting.pid"
 )
 (
-  cd "$ROOT_DIR/api"; nohup "$DIST_DIR/api" --api-endpoint "$API_HOST:$API_PORT" --racing-grpc-endpoint "$RACING_GRPC" --sports-grpc-endpoint "$SPORTS_GRPC" > "$ROOT_DIR/api.out" 2>&1 & echo $! > "$ROOT_DIR/api.pid"
+  cd "$ROOT_DIR/api"; nohup "$DIST_DIR/api" "${TLS_FLAGS[@]}" $(trace_flags api) --grpc-tls-dev --grpc-tls-dev-dir "$TLS_DIR" --api-endpoint "$API_HOST:$API_PORT" --racing-grpc-endpoint "$RACING_GRPC" --sports-grpc-endpoint "$SPORTS_GRPC" --betting-grpc-endpoint "$BETTING_GRPC" --accounts-grpc-endpoint "$ACCOUNTS_GRPC" --api-keys-file "$API_KEYS_FILE" > "$ROOT_DIR/api.out" 2>&1 & echo $! > "$ROOT_DIR/api.pid"
 )
 
-cleanup() { for svc in api sports racing; do [[ -f "$ROOT_DIR/$svc.pid" ]] && kill "$(cat "$ROOT_DIR/$svc.pid")" 2>/dev/null || true; rm -f "$ROOT_DIR/$svc.pid"; done; }
+cleanup() { for svc in api betting accounts sports racing; do [[ -f "$ROOT_DIR/$svc.pid" ]] && kill "$(cat "$ROOT_DIR/$svc.pid")" 2>/dev/null || true; rm -f "$ROOT_DIR/$svc.pid"; done; }
 trap cleanup EXIT
 
-echo "Waiting for API at http://$API_HOST:$API_PORT ..."
+# The first service to start creates the CA.
+for i in {1..30}; do [[ -f "$TLS_DIR/ca/ca.pem" ]] && break; sleep 1; done
+
+echo "Waiting for API at https://$API_HOST:$API_PORT ..."
 set +e
 for i in {1..30}; do
-  code=$(curl -s -o /dev/null -w '%{http_code}' -H 'Content-Type: application/json' -d '{}' "http://$API_HOST:$API_PORT/v1/list-races") || code="000"
+  code=$("${CURL[@]}" -s -o /dev/null -w '%{http_code}' -H 'Content-Type: application/json' -d '{}' "https://$API_HOST:$API_PORT/v1/list-races") || code="000"
   [[ "$code" == "200" ]] && echo "API ready" && break
   sleep 1
 done
 set -e
 
 echo "Running checks..."
-resp=$(curl -sS -H 'Content-Type: application/json' -d '{}' "http://$API_HOST:$API_PORT/v1/list-races")
+# Plaintext HTTP is refused.
+code=$(curl -sS -o /dev/null -w '%{http_code}' "http://$API_HOST:$API_PORT/v1/list-races")
+test "$code" = "400"
+
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{}' "https://$API_HOST:$API_PORT/v1/list-races")
 echo "$resp" | jq -e 'has("races") and (.races|type=="array")' >/dev/null
 
-resp=$(curl -sS -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": false}}' "http://$API_HOST:$API_PORT/v1/list-races")
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": false}}' "https://$API_HOST:$API_PORT/v1/list-races")
 echo "$resp" | jq -e 'has("races") and (.races|type=="array")' >/dev/null
 
-code=$(curl -sS -o /dev/null -w '%{http_code}' "http://$API_HOST:$API_PORT/v1/races/1")
+# Anonymous callers never see hidden races, even when asking for them.
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": true}}' "https://$API_HOST:$API_PORT/v1/list-races")
+echo "$resp" | jq -e 'all(.races[]; .visible == true)' >/dev/null
+resp=$("${CURL[@]}" -sS -H "X-API-Key: $API_KEY" -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": true}}' "https://$API_HOST:$API_PORT/v1/list-races")
+echo "$resp" | jq -e 'any(.races[]; .visible != true)' >/dev/null
+
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' -H "X-API-Key: $API_KEY" "https://$API_HOST:$API_PORT/v1/races/1")
 test "$code" = "200"
-code=$(curl -sS -o /dev/null -w '%{http_code}' "http://$API_HOST:$API_PORT/v1/races/9999")
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' "https://$API_HOST:$API_PORT/v1/races/9999")
 test "$code" = "404"
 
-resp=$(curl -sS -H 'Content-Type: application/json' -d '{}' "http://$API_HOST:$API_PORT/v1/list-events")
+# Races carry an ETag, and asking again with it answers 304 Not Modified.
+headers=$("${CURL[@]}" -sS -o /dev/null -D - -H "X-API-Key: $API_KEY" "https://$API_HOST:$API_PORT/v1/races/1")
+etag=$(grep -i '^etag:' <<< "$headers" | cut -d' ' -f2 | tr -d '\r')
+grep -qi '^cache-control: private' <<< "$headers"
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' -H "X-API-Key: $API_KEY" -H "If-None-Match: $etag" "https://$API_HOST:$API_PORT/v1/races/1")
+test "$code" = "304"
+
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{}' "https://$API_HOST:$API_PORT/v1/list-events")
 echo "$resp" | jq -e 'has("events") and (.events|type=="array")' >/dev/null
 
-resp=$(curl -sS -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": false}}' "http://$API_HOST:$API_PORT/v1/list-events")
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"filter":{"show_hidden": false}}' "https://$API_HOST:$API_PORT/v1/list-events")
 echo "$resp" | jq -e 'has("events") and (.events|type=="array")' >/dev/null
 
-resp=$(curl -sS -H 'Content-Type: application/json' -d '{"filter":{"sport_ids": [1], "show_hidden": false}}' "http://$API_HOST:$API_PORT/v1/list-events")
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"filter":{"sport_ids": [1], "show_hidden": false}}' "https://$API_HOST:$API_PORT/v1/list-events")
 echo "$resp" | jq -e 'has("events") and (.events|type=="array")' >/dev/null
 
+resp=$("${CURL[@]}" -sS "https://$API_HOST:$API_PORT/v1/next-to-go?limit=5")
+echo "$resp" | jq -e '(.items|length) <= 5 and all(.items[]; .type == "TYPE_RACE" or .type == "TYPE_EVENT") and (.unavailable|length) == 0' >/dev/null
+
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"query": "{ races(meetingIds: [1]) { id meetingId runners { number } } }"}' "https://$API_HOST:$API_PORT/graphql")
+echo "$resp" | jq -e '(has("errors")|not) and (.data.races|length) > 0 and all(.data.races[]; .meetingId == "1" and (.runners|type=="array"))' >/dev/null
+
+# Callers can choose how the gateway writes JSON.
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -H 'X-JSON-Format: snake,enum-numbers' -d '{"filter": {"meetingIds": [1]}}' "https://$API_HOST:$API_PORT/v1/list-races")
+echo "$resp" | jq -e '(.races|length) > 0 and all(.races[]; .meeting_id == "1" and (.status|type) == "number")' >/dev/null
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' "https://$API_HOST:$API_PORT/v1/races/1?\$format=yaml")
+test "$code" = "400"
+
+# Browsers can call racing over Connect, whose unary calls are plain JSON.
+resp=$("${CURL[@]}" -sS -H 'Content-Type: application/json' -d '{"filter": {"meetingIds": ["1"]}}' "https://$API_HOST:$API_PORT/racing.Racing/ListRaces")
+echo "$resp" | jq -e '(.races|length) > 0 and all(.races[]; .meetingId == "1")' >/dev/null
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' -H 'Content-Type: application/json' -d '{"id": "9999"}' "https://$API_HOST:$API_PORT/racing.Racing/GetRace")
+test "$code" = "404"
+
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' "https://$API_HOST:$API_PORT/v1/bets/9999")
+test "$code" = "401"
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' -H "X-API-Key: wrong" "https://$API_HOST:$API_PORT/v1/bets/9999")
+test "$code" = "401"
+code=$("${CURL[@]}" -sS -o /dev/null -w '%{http_code}' -H "X-API-Key: $API_KEY" "https://$API_HOST:$API_PORT/v1/bets/9999")
+test "$code" = "404"
+
+resp=$("${CURL[@]}" -sS -H "X-API-Key: $API_KEY" -H 'Content-Type: application/json' -d '{"name":"Smoke Test"}' "https://$API_HOST:$API_PORT/v1/accounts")
+account_id=$(echo "$resp" | jq -r '.account.id')
+resp=$("${CURL[@]}" -sS -H "X-API-Key: $API_KEY" "https://$API_HOST:$API_PORT/v1/accounts/$account_id/balance")
+echo "$resp" | jq -e '.account.name == "Smoke Test"' >/dev/null
+
+# The request ID is returned to the caller and logged by the gateway and
+# the services the request reaches.
+headers=$("${CURL[@]}" -sS -o /dev/null -D - -H "X-Request-Id: smoke-request-1" -H 'Content-Type: application/json' -d '{"filter":{"meeting_ids":[1]}}' "https://$API_HOST:$API_PORT/v1/list-races")
+grep -qi '^x-request-id: smoke-request-1' <<< "$headers"
+# Every call to a backend reports the caller's rate limit.
+grep -qi '^ratelimit-remaining: [0-9]' <<< "$headers"
+grep -q '"msg":"http request".*"request_id":"smoke-request-1"' "$ROOT_DIR/api.out"
+grep -q '"method":"/racing.Racing/ListRaces".*"filter":{"meeting_ids":\[1\].*"request_id":"smoke-request-1"' "$ROOT_DIR/racing.out"
+
+# Metrics are served on each service's admin port.
+api_metrics=$(curl -sS "http://localhost:8001/metrics")
+racing_metrics=$(curl -sS "http://localhost:9100/metrics")
+sports_metrics=$(curl -sS "http://localhost:9101/metrics")
+grep -q 'http_requests_total{code="200",method="POST",route="/v1/list-races"}' <<< "$api_metrics"
+grep -q 'circuit_breaker_open{backend="racing"} 0' <<< "$api_metrics"
+grep -q 'db_query_duration_seconds_count{query="list",repo="races"}' <<< "$racing_metrics"
+grep -q 'grpc_server_handled_total{grpc_code="OK",grpc_method="ListRaces",grpc_service="racing.Racing"}' <<< "$racing_metrics"
+# Anonymous callers listing all races, with show_hidden false or not, share
+# a cached list.
+grep -q 'cache_requests_total{cache="races",result="hit"}' <<< "$racing_metrics"
+grep -q 'go_sql_open_connections{db_name="sports"}' <<< "$sports_metrics"
+# Racing and sports serve reflection and the admin service on their admin
+# gRPC ports.
+grep -q '"msg":"admin gRPC server listening".*"endpoint":"127.0.0.1:9200"' "$ROOT_DIR/racing.out"
+grep -q '"msg":"admin gRPC server listening".*"endpoint":"127.0.0.1:9201"' "$ROOT_DIR/sports.out"
+
+# A request's trace context reaches racing and its query. The filter is one
+# not listed before, so the races aren't cached. Spans are exported in
+# batches, so wait for them.
+TRACE_ID="4bf92f3577b34da6a3ce929d0e0e4736"
+"${CURL[@]}" -sS -o /dev/null -H "traceparent: 00-$TRACE_ID-00f067aa0ba902b7-01" -H 'Content-Type: application/json' -d '{"filter":{"meeting_ids":[2]}}' "https://$API_HOST:$API_PORT/v1/list-races"
+for i in {1..15}; do
+  grep -qs "$TRACE_ID" "$DIST_DIR/api-traces.jsonl" && grep -qs "$TRACE_ID" "$DIST_DIR/racing-traces.jsonl" && break
+  sleep 1
+done
+api_spans=$(grep "$TRACE_ID" "$DIST_DIR/api-traces.jsonl")
+racing_spans=$(grep "$TRACE_ID" "$DIST_DIR/racing-traces.jsonl")
+grep -q '"Name":"POST /v1/list-races"' <<< "$api_spans"
+grep -q '"Name":"racing.Racing/ListRaces"' <<< "$api_spans"
+grep -q '"Name":"racing.Racing/ListRaces"' <<< "$racing_spans"
+grep -q '"Name":"races.list"' <<< "$racing_spans"
+
+# The gateway is live, and ready while every backend is serving.
+"${CURL[@]}" -sS "https://$API_HOST:$API_PORT/healthz" | jq -e '.status == "ok"' >/dev/null
+"${CURL[@]}" -sS "https://$API_HOST:$API_PORT/readyz" | jq -e '.status == "ready" and (.backends | length == 4)' >/dev/null
+
+# The gateway serves its OpenAPI spec and the docs that render it.
+"${CURL[@]}" -sS "https://$API_HOST:$API_PORT/openapi.json" | jq -e '.paths["/v1/list-races"].post.operationId == "Racing_ListRaces"' >/dev/null
+"${CURL[@]}" -sS "https://$API_HOST:$API_PORT/docs" | grep -q 'data-spec="/openapi.json"'
+"${CURL[@]}" -sS "https://$API_HOST:$API_PORT/docs/docs.js" | grep -q 'function render'
+
+# On SIGTERM racing drains and exits cleanly, and the gateway stops being ready.
+racing_pid=$(cat "$ROOT_DIR/racing.pid")
+kill -TERM "$racing_pid"
+for i in {1..15}; do kill -0 "$racing_pid" 2>/dev/null || break; sleep 1; done
+! kill -0 "$racing_pid" 2>/dev/null
+grep -q '"msg":"shutting down"' "$ROOT_DIR/racing.out"
+code=$("${CURL[@]}" -sS -o "$DIST_DIR/readyz.json" -w '%{http_code}' "https://$API_HOST:$API_PORT/readyz")
+test "$code" = "503"
+jq -e '.status == "not ready" and .backends.racing != "SERVING" and .backends.sports == "SERVING"' "$DIST_DIR/readyz.json" >/dev/null
+
 echo "Smoke passed"
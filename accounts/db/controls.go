@@ -0,0 +1,402 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/accounts/limits"
+	"git.neds.sh/matty/entain/proto/accounts"
+)
+
+// ControlsRepo provides repository access to responsible gambling controls and their audit trail.
+//
+//go:generate mockery --name ControlsRepo --structname ControlsRepoMock --dir . --output . --outpkg db --filename controls_repo_mock.go
+type ControlsRepo interface {
+	// Init will initialise our controls repository.
+	Init() error
+
+	// GetControls returns the controls on a customer account.
+	GetControls(accountID int64) (*accounts.Controls, error)
+
+	// SetLimit requests a limit change, applying the mandated delay to increases.
+	SetLimit(accountID int64, t accounts.Limit_Type, w accounts.Limit_Window, amount *int64, reason string) (*accounts.Limit, error)
+
+	// StartCoolOff pauses betting and deposits for a period.
+	StartCoolOff(accountID int64, duration time.Duration, reason string) (*accounts.Controls, error)
+
+	// SelfExclude excludes the customer for a period, or permanently when duration is nil.
+	SelfExclude(accountID int64, duration *time.Duration, reason string) (*accounts.Controls, error)
+
+	// ListChanges returns the audit trail for an account, newest first.
+	ListChanges(accountID int64) ([]*accounts.ControlChange, error)
+}
+
+type controlsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewControlsRepo creates a new controls repository.
+func NewControlsRepo(db *sql.DB) ControlsRepo {
+	return &controlsRepo{db: db}
+}
+
+// Init prepares the controls schema.
+func (r *controlsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		err = migrate(r.db)
+	})
+
+	return err
+}
+
+func (r *controlsRepo) GetControls(accountID int64) (*accounts.Controls, error) {
+	if err := requireAccount(r.db, accountID); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(r.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toControls(accountID, state, time.Now()), nil
+}
+
+func (r *controlsRepo) SetLimit(accountID int64, t accounts.Limit_Type, w accounts.Limit_Window, amount *int64, reason string) (*accounts.Limit, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireAccount(tx, accountID); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var current *accounts.Limit
+	for _, l := range state.Limits {
+		if l.Type == t && l.Window == w {
+			current = l
+		}
+	}
+
+	now := time.Now().UTC()
+	next, effective, err := limits.Apply(current, t, w, amount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingEffective any
+	if next.Pending {
+		pendingEffective = next.PendingEffectiveTime.AsTime().UTC().Format(timeFormat)
+	}
+	if _, err := tx.Exec(
+		getControlQueries()[limitsUpsert],
+		accountID,
+		int32(t),
+		int32(w),
+		nullable(next.AmountCents),
+		next.Pending,
+		nullable(next.PendingAmountCents),
+		pendingEffective,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := recordChange(tx, &accounts.ControlChange{
+		AccountId:      accountID,
+		Kind:           accounts.ControlChange_KIND_LIMIT,
+		LimitType:      t,
+		LimitWindow:    w,
+		OldAmountCents: limits.Effective(current, now),
+		NewAmountCents: amount,
+		EffectiveTime:  timestamppb.New(effective),
+		ChangedTime:    timestamppb.New(now),
+		Reason:         reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	return next, tx.Commit()
+}
+
+func (r *controlsRepo) StartCoolOff(accountID int64, duration time.Duration, reason string) (*accounts.Controls, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireAccount(tx, accountID); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	until, err := limits.CoolOffUntil(state.CoolOffUntil, duration, now)
+	if err != nil {
+		return nil, err
+	}
+	state.CoolOffUntil = until
+
+	if err := saveExclusions(tx, accountID, state); err != nil {
+		return nil, err
+	}
+
+	if err := recordChange(tx, &accounts.ControlChange{
+		AccountId:     accountID,
+		Kind:          accounts.ControlChange_KIND_COOL_OFF,
+		Until:         timestamppb.New(until),
+		EffectiveTime: timestamppb.New(now),
+		ChangedTime:   timestamppb.New(now),
+		Reason:        reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	return toControls(accountID, state, now), tx.Commit()
+}
+
+func (r *controlsRepo) SelfExclude(accountID int64, duration *time.Duration, reason string) (*accounts.Controls, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireAccount(tx, accountID); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	until, permanent, err := limits.ExcludedUntil(state, duration, now)
+	if err != nil {
+		return nil, err
+	}
+	state.ExcludedUntil = until
+	state.ExcludedPermanently = permanent
+
+	if err := saveExclusions(tx, accountID, state); err != nil {
+		return nil, err
+	}
+
+	change := &accounts.ControlChange{
+		AccountId:     accountID,
+		Kind:          accounts.ControlChange_KIND_SELF_EXCLUSION,
+		EffectiveTime: timestamppb.New(now),
+		ChangedTime:   timestamppb.New(now),
+		Reason:        reason,
+	}
+	if !permanent {
+		change.Until = timestamppb.New(until)
+	}
+	if err := recordChange(tx, change); err != nil {
+		return nil, err
+	}
+
+	return toControls(accountID, state, now), tx.Commit()
+}
+
+func (r *controlsRepo) ListChanges(accountID int64) ([]*accounts.ControlChange, error) {
+	rows, err := r.db.Query(getControlQueries()[changesList], accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*accounts.ControlChange
+	for rows.Next() {
+		var (
+			change             accounts.ControlChange
+			kind, lType, lWin  int32
+			oldAmount, newAmnt sql.NullInt64
+			until              sql.NullTime
+			effective, changed time.Time
+		)
+		if err := rows.Scan(&change.Id, &change.AccountId, &kind, &lType, &lWin, &oldAmount, &newAmnt, &until, &effective, &changed, &change.Reason); err != nil {
+			return nil, err
+		}
+
+		change.Kind = accounts.ControlChange_Kind(kind)
+		change.LimitType = accounts.Limit_Type(lType)
+		change.LimitWindow = accounts.Limit_Window(lWin)
+		change.OldAmountCents = fromNullable(oldAmount)
+		change.NewAmountCents = fromNullable(newAmnt)
+		if until.Valid {
+			change.Until = timestamppb.New(until.Time)
+		}
+		change.EffectiveTime = timestamppb.New(effective)
+		change.ChangedTime = timestamppb.New(changed)
+
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
+
+// loadState reads the controls on an account. Accounts without any controls
+// get the zero State, which restricts nothing.
+func loadState(q querier, accountID int64) (limits.State, error) {
+	var (
+		state     limits.State
+		coolOff   sql.NullTime
+		excluded  sql.NullTime
+		permanent bool
+	)
+
+	err := q.QueryRow(getControlQueries()[controlsGet], accountID).Scan(&coolOff, &excluded, &permanent)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return state, err
+	default:
+		state.CoolOffUntil = coolOff.Time
+		state.ExcludedUntil = excluded.Time
+		state.ExcludedPermanently = permanent
+	}
+
+	rows, err := q.Query(getControlQueries()[limitsList], accountID)
+	if err != nil {
+		return state, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			limit            accounts.Limit
+			lType, lWin      int32
+			amount, pending  sql.NullInt64
+			pendingEffective sql.NullTime
+		)
+		if err := rows.Scan(&lType, &lWin, &amount, &limit.Pending, &pending, &pendingEffective); err != nil {
+			return state, err
+		}
+
+		limit.Type = accounts.Limit_Type(lType)
+		limit.Window = accounts.Limit_Window(lWin)
+		limit.AmountCents = fromNullable(amount)
+		limit.PendingAmountCents = fromNullable(pending)
+		if pendingEffective.Valid {
+			limit.PendingEffectiveTime = timestamppb.New(pendingEffective.Time)
+		}
+
+		state.Limits = append(state.Limits, &limit)
+	}
+
+	return state, rows.Err()
+}
+
+func saveExclusions(q querier, accountID int64, state limits.State) error {
+	_, err := q.Exec(
+		getControlQueries()[controlsUpsert],
+		accountID,
+		nullableTime(state.CoolOffUntil),
+		nullableTime(state.ExcludedUntil),
+		state.ExcludedPermanently,
+	)
+
+	return err
+}
+
+func recordChange(q querier, change *accounts.ControlChange) error {
+	var until any
+	if change.Until != nil {
+		until = change.Until.AsTime().UTC().Format(timeFormat)
+	}
+
+	_, err := q.Exec(
+		getControlQueries()[changesInsert],
+		change.AccountId,
+		int32(change.Kind),
+		int32(change.LimitType),
+		int32(change.LimitWindow),
+		nullable(change.OldAmountCents),
+		nullable(change.NewAmountCents),
+		until,
+		change.EffectiveTime.AsTime().UTC().Format(timeFormat),
+		change.ChangedTime.AsTime().UTC().Format(timeFormat),
+		change.Reason,
+	)
+
+	return err
+}
+
+// toControls presents state as seen at now, with matured pending changes applied.
+func toControls(accountID int64, state limits.State, now time.Time) *accounts.Controls {
+	controls := &accounts.Controls{
+		AccountId:           accountID,
+		ExcludedPermanently: state.ExcludedPermanently,
+	}
+
+	for _, l := range state.Limits {
+		limit := &accounts.Limit{Type: l.Type, Window: l.Window, AmountCents: limits.Effective(l, now)}
+		if l.Pending && now.Before(l.PendingEffectiveTime.AsTime()) {
+			limit.Pending = true
+			limit.PendingAmountCents = l.PendingAmountCents
+			limit.PendingEffectiveTime = l.PendingEffectiveTime
+		}
+		controls.Limits = append(controls.Limits, limit)
+	}
+	if now.Before(state.CoolOffUntil) {
+		controls.CoolOffUntil = timestamppb.New(state.CoolOffUntil)
+	}
+	if now.Before(state.ExcludedUntil) {
+		controls.ExcludedUntil = timestamppb.New(state.ExcludedUntil)
+	}
+
+	return controls
+}
+
+func requireAccount(q querier, accountID int64) error {
+	var id int64
+	err := q.QueryRow(getLedgerQueries()[accountsGet], accountID).Scan(&id, new(string), new(int64), new(time.Time))
+	if err == sql.ErrNoRows {
+		return ErrAccountNotFound
+	}
+
+	return err
+}
+
+func nullable(v *int64) any {
+	if v == nil {
+		return nil
+	}
+
+	return *v
+}
+
+func fromNullable(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Int64
+}
+
+func nullableTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(timeFormat)
+}
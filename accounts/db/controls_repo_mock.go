@@ -0,0 +1,198 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package db
+
+import (
+	accounts "git.neds.sh/matty/entain/proto/accounts"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ControlsRepoMock is an autogenerated mock type for the ControlsRepo type
+type ControlsRepoMock struct {
+	mock.Mock
+}
+
+// GetControls provides a mock function with given fields: accountID
+func (_m *ControlsRepoMock) GetControls(accountID int64) (*accounts.Controls, error) {
+	ret := _m.Called(accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetControls")
+	}
+
+	var r0 *accounts.Controls
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (*accounts.Controls, error)); ok {
+		return rf(accountID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *accounts.Controls); ok {
+		r0 = rf(accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Controls)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with no fields
+func (_m *ControlsRepoMock) Init() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Init")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListChanges provides a mock function with given fields: accountID
+func (_m *ControlsRepoMock) ListChanges(accountID int64) ([]*accounts.ControlChange, error) {
+	ret := _m.Called(accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChanges")
+	}
+
+	var r0 []*accounts.ControlChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]*accounts.ControlChange, error)); ok {
+		return rf(accountID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []*accounts.ControlChange); ok {
+		r0 = rf(accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*accounts.ControlChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelfExclude provides a mock function with given fields: accountID, duration, reason
+func (_m *ControlsRepoMock) SelfExclude(accountID int64, duration *time.Duration, reason string) (*accounts.Controls, error) {
+	ret := _m.Called(accountID, duration, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SelfExclude")
+	}
+
+	var r0 *accounts.Controls
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, *time.Duration, string) (*accounts.Controls, error)); ok {
+		return rf(accountID, duration, reason)
+	}
+	if rf, ok := ret.Get(0).(func(int64, *time.Duration, string) *accounts.Controls); ok {
+		r0 = rf(accountID, duration, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Controls)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, *time.Duration, string) error); ok {
+		r1 = rf(accountID, duration, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLimit provides a mock function with given fields: accountID, t, w, amount, reason
+func (_m *ControlsRepoMock) SetLimit(accountID int64, t accounts.Limit_Type, w accounts.Limit_Window, amount *int64, reason string) (*accounts.Limit, error) {
+	ret := _m.Called(accountID, t, w, amount, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLimit")
+	}
+
+	var r0 *accounts.Limit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, accounts.Limit_Type, accounts.Limit_Window, *int64, string) (*accounts.Limit, error)); ok {
+		return rf(accountID, t, w, amount, reason)
+	}
+	if rf, ok := ret.Get(0).(func(int64, accounts.Limit_Type, accounts.Limit_Window, *int64, string) *accounts.Limit); ok {
+		r0 = rf(accountID, t, w, amount, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Limit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, accounts.Limit_Type, accounts.Limit_Window, *int64, string) error); ok {
+		r1 = rf(accountID, t, w, amount, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartCoolOff provides a mock function with given fields: accountID, duration, reason
+func (_m *ControlsRepoMock) StartCoolOff(accountID int64, duration time.Duration, reason string) (*accounts.Controls, error) {
+	ret := _m.Called(accountID, duration, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartCoolOff")
+	}
+
+	var r0 *accounts.Controls
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, time.Duration, string) (*accounts.Controls, error)); ok {
+		return rf(accountID, duration, reason)
+	}
+	if rf, ok := ret.Get(0).(func(int64, time.Duration, string) *accounts.Controls); ok {
+		r0 = rf(accountID, duration, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Controls)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, time.Duration, string) error); ok {
+		r1 = rf(accountID, duration, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewControlsRepoMock creates a new instance of ControlsRepoMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewControlsRepoMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ControlsRepoMock {
+	mock := &ControlsRepoMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
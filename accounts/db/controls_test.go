@@ -0,0 +1,150 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/accounts/limits"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlsRepo_StakeLimit(t *testing.T) {
+	ledger := newTestLedger(t)
+	controls := &controlsRepo{db: ledger.db}
+
+	account, err := ledger.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, ledger, account.Id, 5000)
+
+	limit := int64(1000)
+	_, err = controls.SetLimit(account.Id, accounts.Limit_TYPE_STAKE, accounts.Limit_WINDOW_DAILY, &limit, "keeping it small")
+	require.NoError(t, err)
+
+	stake := func(key string, cents int64) error {
+		_, err := ledger.Post(&accounts.Transaction{
+			AccountId:      account.Id,
+			Type:           accounts.Transaction_TYPE_BET_STAKE,
+			AmountCents:    -cents,
+			IdempotencyKey: key,
+		})
+		return err
+	}
+
+	require.NoError(t, stake("bet:1", 600))
+	require.NoError(t, stake("bet:2", 400))
+
+	err = stake("bet:3", 1)
+	var violation *limits.Violation
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, limits.ReasonStakeLimit, violation.Reason)
+
+	got, err := ledger.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(4000), got.BalanceCents)
+}
+
+func TestControlsRepo_LossLimitNetsPayouts(t *testing.T) {
+	ledger := newTestLedger(t)
+	controls := &controlsRepo{db: ledger.db}
+
+	account, err := ledger.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, ledger, account.Id, 5000)
+
+	limit := int64(1000)
+	_, err = controls.SetLimit(account.Id, accounts.Limit_TYPE_LOSS, accounts.Limit_WINDOW_WEEKLY, &limit, "")
+	require.NoError(t, err)
+
+	post := func(txn *accounts.Transaction) error {
+		txn.AccountId = account.Id
+		_, err := ledger.Post(txn)
+		return err
+	}
+
+	require.NoError(t, post(&accounts.Transaction{Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: -1000, IdempotencyKey: "bet:1"}))
+	require.Error(t, post(&accounts.Transaction{Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: -100, IdempotencyKey: "bet:2"}))
+
+	require.NoError(t, post(&accounts.Transaction{Type: accounts.Transaction_TYPE_PAYOUT, AmountCents: 600, IdempotencyKey: "payout:1"}))
+	require.NoError(t, post(&accounts.Transaction{Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: -600, IdempotencyKey: "bet:3"}))
+}
+
+func TestControlsRepo_IncreaseIsDelayed(t *testing.T) {
+	ledger := newTestLedger(t)
+	controls := &controlsRepo{db: ledger.db}
+
+	account, err := ledger.CreateAccount("Punter")
+	require.NoError(t, err)
+
+	low, high := int64(1000), int64(5000)
+	_, err = controls.SetLimit(account.Id, accounts.Limit_TYPE_DEPOSIT, accounts.Limit_WINDOW_DAILY, &low, "")
+	require.NoError(t, err)
+
+	limit, err := controls.SetLimit(account.Id, accounts.Limit_TYPE_DEPOSIT, accounts.Limit_WINDOW_DAILY, &high, "payday")
+	require.NoError(t, err)
+	require.True(t, limit.Pending)
+	require.Equal(t, low, *limit.AmountCents)
+	require.Equal(t, high, *limit.PendingAmountCents)
+
+	got, err := controls.GetControls(account.Id)
+	require.NoError(t, err)
+	require.Len(t, got.Limits, 1)
+	require.Equal(t, low, *got.Limits[0].AmountCents)
+	require.True(t, got.Limits[0].Pending)
+	require.WithinDuration(t, time.Now().Add(limits.IncreaseDelay), got.Limits[0].PendingEffectiveTime.AsTime(), time.Minute)
+
+	_, err = ledger.Post(&accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 2000, IdempotencyKey: "d:1"})
+	var violation *limits.Violation
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, limits.ReasonDepositLimit, violation.Reason)
+}
+
+func TestControlsRepo_SelfExclusion(t *testing.T) {
+	ledger := newTestLedger(t)
+	controls := &controlsRepo{db: ledger.db}
+
+	account, err := ledger.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, ledger, account.Id, 5000)
+
+	_, err = controls.StartCoolOff(account.Id, 48*time.Hour, "taking a break")
+	require.NoError(t, err)
+
+	got, err := controls.SelfExclude(account.Id, nil, "for good")
+	require.NoError(t, err)
+	require.True(t, got.ExcludedPermanently)
+	require.NotNil(t, got.CoolOffUntil)
+
+	_, err = ledger.Post(&accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: -100, IdempotencyKey: "bet:1"})
+	var violation *limits.Violation
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, limits.ReasonSelfExcluded, violation.Reason)
+
+	// Customers can always take their money out.
+	_, err = ledger.Post(&accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_WITHDRAWAL, AmountCents: -5000, IdempotencyKey: "w:1"})
+	require.NoError(t, err)
+
+	_, err = controls.SelfExclude(account.Id, nil, "")
+	require.ErrorIs(t, err, limits.ErrInvalidChange)
+
+	changes, err := controls.ListChanges(account.Id)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, accounts.ControlChange_KIND_SELF_EXCLUSION, changes[0].Kind)
+	require.Nil(t, changes[0].Until)
+	require.Equal(t, "for good", changes[0].Reason)
+	require.Equal(t, accounts.ControlChange_KIND_COOL_OFF, changes[1].Kind)
+	require.NotNil(t, changes[1].Until)
+}
+
+func TestControlsRepo_UnknownAccount(t *testing.T) {
+	ledger := newTestLedger(t)
+	controls := &controlsRepo{db: ledger.db}
+
+	_, err := controls.GetControls(99)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	_, err = controls.StartCoolOff(99, 48*time.Hour, "")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
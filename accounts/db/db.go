@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"syreclabs.com/go/faker"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+)
+
+// House ledger accounts that balance every customer posting.
+const (
+	// cashAccountID mirrors money held at the bank: deposits and withdrawals.
+	cashAccountID int64 = 1
+	// houseAccountID holds bet stakes and funds payouts and refunds.
+	houseAccountID int64 = 2
+)
+
+// timeFormat stores times in UTC at a fixed width, so they compare correctly as text.
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// migrate creates the ledger and gambling controls schema. Both repositories
+// call it from Init; every statement is safe to repeat.
+func migrate(db *sql.DB) error {
+	statements := []string{
+		// Customer balances may never go negative; house accounts carry the other side.
+		`CREATE TABLE IF NOT EXISTS accounts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			house INTEGER NOT NULL DEFAULT 0,
+			balance_cents INTEGER NOT NULL DEFAULT 0,
+			created_time DATETIME NOT NULL,
+			CHECK (house = 1 OR balance_cents >= 0)
+		)`,
+		`CREATE TABLE IF NOT EXISTS transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			idempotency_key TEXT NOT NULL UNIQUE,
+			account_id INTEGER NOT NULL REFERENCES accounts (id),
+			type INTEGER NOT NULL,
+			amount_cents INTEGER NOT NULL,
+			balance_cents INTEGER NOT NULL,
+			reference TEXT NOT NULL DEFAULT '',
+			created_time DATETIME NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS transactions_account ON transactions (account_id, id)`,
+		`CREATE INDEX IF NOT EXISTS transactions_usage ON transactions (account_id, type, created_time)`,
+		// Each transaction writes one entry per side; the entries of a transaction sum to zero.
+		`CREATE TABLE IF NOT EXISTS entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+			account_id INTEGER NOT NULL REFERENCES accounts (id),
+			amount_cents INTEGER NOT NULL
+		)`,
+		`INSERT OR IGNORE INTO accounts (id, name, house, created_time) VALUES (1, 'House cash', 1, CURRENT_TIMESTAMP)`,
+		`INSERT OR IGNORE INTO accounts (id, name, house, created_time) VALUES (2, 'House betting', 1, CURRENT_TIMESTAMP)`,
+		// A pending change loosens or removes a limit once pending_effective_time passes.
+		`CREATE TABLE IF NOT EXISTS limits (
+			account_id INTEGER NOT NULL REFERENCES accounts (id),
+			type INTEGER NOT NULL,
+			period INTEGER NOT NULL,
+			amount_cents INTEGER,
+			pending INTEGER NOT NULL DEFAULT 0,
+			pending_amount_cents INTEGER,
+			pending_effective_time DATETIME,
+			PRIMARY KEY (account_id, type, period)
+		)`,
+		`CREATE TABLE IF NOT EXISTS controls (
+			account_id INTEGER PRIMARY KEY REFERENCES accounts (id),
+			cool_off_until DATETIME,
+			excluded_until DATETIME,
+			excluded_permanently INTEGER NOT NULL DEFAULT 0
+		)`,
+		// The audit trail is append-only: rows are never updated or deleted.
+		`CREATE TABLE IF NOT EXISTS control_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			account_id INTEGER NOT NULL REFERENCES accounts (id),
+			kind INTEGER NOT NULL,
+			limit_type INTEGER NOT NULL DEFAULT 0,
+			limit_period INTEGER NOT NULL DEFAULT 0,
+			old_amount_cents INTEGER,
+			new_amount_cents INTEGER,
+			until DATETIME,
+			effective_time DATETIME NOT NULL,
+			changed_time DATETIME NOT NULL,
+			reason TEXT NOT NULL DEFAULT ''
+		)`,
+	}
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seed opens a handful of funded customer accounts the first time the ledger is created.
+func (r *ledgerRepo) seed() error {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE house = 0`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for i := 1; i <= 10; i++ {
+		account, err := r.CreateAccount(faker.Name().Name())
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.Post(&accounts.Transaction{
+			AccountId:      account.Id,
+			Type:           accounts.Transaction_TYPE_DEPOSIT,
+			AmountCents:    int64(faker.RandomInt(50, 500)) * 100,
+			IdempotencyKey: fmt.Sprintf("seed-deposit-%d", account.Id),
+			Reference:      "Opening deposit",
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/accounts/limits"
+	"git.neds.sh/matty/entain/proto/accounts"
+)
+
+var (
+	// ErrAccountNotFound is returned when posting to an account that doesn't exist.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInsufficientFunds is returned when a debit would overdraw an account.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrIdempotencyConflict is returned when an idempotency key is replayed with a different posting.
+	ErrIdempotencyConflict = errors.New("idempotency key reused for a different transaction")
+)
+
+// LedgerRepo provides repository access to customer accounts and their double-entry ledger.
+//
+//go:generate mockery --name LedgerRepo --structname LedgerRepoMock --dir . --output . --outpkg db --filename ledger_repo_mock.go
+type LedgerRepo interface {
+	// Init will initialise our ledger repository.
+	Init() error
+
+	// CreateAccount opens a customer account with a zero balance.
+	CreateAccount(name string) (*accounts.Account, error)
+
+	// GetAccount returns a single customer account by id.
+	GetAccount(id int64) (*accounts.Account, error)
+
+	// ListTransactions returns up to limit transactions for an account, newest first.
+	ListTransactions(accountID int64, limit int) ([]*accounts.Transaction, error)
+
+	// Post atomically applies a signed amount to a customer account, balancing
+	// it against the matching house account. Deposits and stakes that breach
+	// the account's gambling controls fail with a *limits.Violation. Replaying
+	// an idempotency key returns the transaction it first created.
+	Post(txn *accounts.Transaction) (*accounts.Transaction, error)
+}
+
+type ledgerRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewLedgerRepo creates a new ledger repository.
+func NewLedgerRepo(db *sql.DB) LedgerRepo {
+	return &ledgerRepo{db: db}
+}
+
+// Init creates the ledger schema and seeds some dummy customer accounts.
+func (r *ledgerRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		if err = migrate(r.db); err != nil {
+			return
+		}
+		// For test/example purposes, we seed the DB with some funded customers.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *ledgerRepo) CreateAccount(name string) (*accounts.Account, error) {
+	res, err := r.db.Exec(getLedgerQueries()[accountsInsert], name, time.Now().UTC().Format(timeFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetAccount(id)
+}
+
+func (r *ledgerRepo) GetAccount(id int64) (*accounts.Account, error) {
+	var (
+		account accounts.Account
+		created time.Time
+	)
+
+	err := r.db.QueryRow(getLedgerQueries()[accountsGet], id).Scan(&account.Id, &account.Name, &account.BalanceCents, &created)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	account.CreatedTime = timestamppb.New(created)
+
+	return &account, nil
+}
+
+func (r *ledgerRepo) ListTransactions(accountID int64, limit int) ([]*accounts.Transaction, error) {
+	rows, err := r.db.Query(getLedgerQueries()[transactionsList], accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txns []*accounts.Transaction
+	for rows.Next() {
+		txn, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, rows.Err()
+}
+
+func (r *ledgerRepo) Post(txn *accounts.Transaction) (*accounts.Transaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := getLedgerQueries()
+
+	existing, err := scanTransaction(tx.QueryRow(queries[transactionsByKey], txn.IdempotencyKey))
+	switch {
+	case err == nil:
+		if existing.AccountId != txn.AccountId || existing.Type != txn.Type || existing.AmountCents != txn.AmountCents {
+			return nil, ErrIdempotencyConflict
+		}
+		return existing, tx.Commit()
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	// Gambling controls are checked inside the transaction, so concurrent
+	// stakes queue behind each other rather than each seeing headroom.
+	state, err := loadState(tx, txn.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.Check(state, txn, now, usage(tx, txn.AccountId)); err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(queries[accountsAdjust], txn.AmountCents, txn.AccountId, txn.AmountCents)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, r.postFailure(tx, txn.AccountId)
+	}
+
+	house := houseAccount(txn.Type)
+	if _, err := tx.Exec(queries[houseAdjust], -txn.AmountCents, house); err != nil {
+		return nil, err
+	}
+
+	res, err = tx.Exec(
+		queries[transactionsInsert],
+		txn.IdempotencyKey,
+		txn.AccountId,
+		int32(txn.Type),
+		txn.AmountCents,
+		txn.AccountId,
+		txn.Reference,
+		now.Format(timeFormat),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range []struct {
+		account int64
+		amount  int64
+	}{
+		{txn.AccountId, txn.AmountCents},
+		{house, -txn.AmountCents},
+	} {
+		if _, err := tx.Exec(queries[entriesInsert], id, entry.account, entry.amount); err != nil {
+			return nil, err
+		}
+	}
+
+	posted, err := scanTransaction(tx.QueryRow(queries[transactionsByKey], txn.IdempotencyKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return posted, tx.Commit()
+}
+
+// postFailure explains why a balance adjustment matched no rows.
+func (r *ledgerRepo) postFailure(tx querier, accountID int64) error {
+	var id int64
+	err := tx.QueryRow(getLedgerQueries()[accountsGet], accountID).Scan(&id, new(string), new(int64), new(time.Time))
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrAccountNotFound
+	case err != nil:
+		return err
+	default:
+		return ErrInsufficientFunds
+	}
+}
+
+// usage sums an account's recent transactions for the limit checks.
+func usage(q querier, accountID int64) limits.Usage {
+	return func(t accounts.Limit_Type, since time.Time) (int64, error) {
+		var (
+			query string
+			args  = []any{accountID, since.UTC().Format(timeFormat)}
+		)
+		switch t {
+		case accounts.Limit_TYPE_DEPOSIT:
+			query = getControlQueries()[usageDeposits]
+			args = append(args, int32(accounts.Transaction_TYPE_DEPOSIT))
+		case accounts.Limit_TYPE_STAKE:
+			query = getControlQueries()[usageStakes]
+			args = append(args, int32(accounts.Transaction_TYPE_BET_STAKE))
+		default:
+			query = getControlQueries()[usageLosses]
+			args = append(args,
+				int32(accounts.Transaction_TYPE_BET_STAKE),
+				int32(accounts.Transaction_TYPE_PAYOUT),
+				int32(accounts.Transaction_TYPE_REFUND),
+			)
+		}
+
+		var used int64
+		err := q.QueryRow(query, args...).Scan(&used)
+
+		return used, err
+	}
+}
+
+// houseAccount returns the house ledger account that takes the other side of a posting.
+func houseAccount(t accounts.Transaction_Type) int64 {
+	switch t {
+	case accounts.Transaction_TYPE_DEPOSIT, accounts.Transaction_TYPE_WITHDRAWAL:
+		return cashAccountID
+	default:
+		return houseAccountID
+	}
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTransaction(row scanner) (*accounts.Transaction, error) {
+	var (
+		txn     accounts.Transaction
+		txnType int32
+		created time.Time
+	)
+
+	if err := row.Scan(&txn.Id, &txn.AccountId, &txnType, &txn.AmountCents, &txn.BalanceCents, &txn.Reference, &txn.IdempotencyKey, &created); err != nil {
+		return nil, err
+	}
+
+	txn.Type = accounts.Transaction_Type(txnType)
+	txn.CreatedTime = timestamppb.New(created)
+
+	return &txn, nil
+}
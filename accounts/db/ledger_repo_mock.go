@@ -0,0 +1,166 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package db
+
+import (
+	accounts "git.neds.sh/matty/entain/proto/accounts"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LedgerRepoMock is an autogenerated mock type for the LedgerRepo type
+type LedgerRepoMock struct {
+	mock.Mock
+}
+
+// CreateAccount provides a mock function with given fields: name
+func (_m *LedgerRepoMock) CreateAccount(name string) (*accounts.Account, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAccount")
+	}
+
+	var r0 *accounts.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*accounts.Account, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *accounts.Account); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccount provides a mock function with given fields: id
+func (_m *LedgerRepoMock) GetAccount(id int64) (*accounts.Account, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccount")
+	}
+
+	var r0 *accounts.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (*accounts.Account, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *accounts.Account); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with no fields
+func (_m *LedgerRepoMock) Init() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Init")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListTransactions provides a mock function with given fields: accountID, limit
+func (_m *LedgerRepoMock) ListTransactions(accountID int64, limit int) ([]*accounts.Transaction, error) {
+	ret := _m.Called(accountID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTransactions")
+	}
+
+	var r0 []*accounts.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int) ([]*accounts.Transaction, error)); ok {
+		return rf(accountID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int) []*accounts.Transaction); ok {
+		r0 = rf(accountID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*accounts.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int) error); ok {
+		r1 = rf(accountID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Post provides a mock function with given fields: txn
+func (_m *LedgerRepoMock) Post(txn *accounts.Transaction) (*accounts.Transaction, error) {
+	ret := _m.Called(txn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Post")
+	}
+
+	var r0 *accounts.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*accounts.Transaction) (*accounts.Transaction, error)); ok {
+		return rf(txn)
+	}
+	if rf, ok := ret.Get(0).(func(*accounts.Transaction) *accounts.Transaction); ok {
+		r0 = rf(txn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*accounts.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*accounts.Transaction) error); ok {
+		r1 = rf(txn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLedgerRepoMock creates a new instance of LedgerRepoMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLedgerRepoMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LedgerRepoMock {
+	mock := &LedgerRepoMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLedger opens an empty ledger on a temp SQLite file, with the same
+// locking options the service runs with.
+func newTestLedger(t *testing.T) *ledgerRepo {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "accounts.db") + "?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on"
+	sqlDB, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	repo := &ledgerRepo{db: sqlDB}
+	require.NoError(t, migrate(sqlDB))
+
+	return repo
+}
+
+func deposit(t *testing.T, repo *ledgerRepo, accountID, cents int64) {
+	t.Helper()
+
+	_, err := repo.Post(&accounts.Transaction{
+		AccountId:      accountID,
+		Type:           accounts.Transaction_TYPE_DEPOSIT,
+		AmountCents:    cents,
+		IdempotencyKey: fmt.Sprintf("deposit-%d-%d", accountID, cents),
+	})
+	require.NoError(t, err)
+}
+
+func TestLedgerRepo_Post_DoubleEntry(t *testing.T) {
+	repo := newTestLedger(t)
+
+	account, err := repo.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, repo, account.Id, 5000)
+
+	stake, err := repo.Post(&accounts.Transaction{
+		AccountId:      account.Id,
+		Type:           accounts.Transaction_TYPE_BET_STAKE,
+		AmountCents:    -1200,
+		IdempotencyKey: "bet:1",
+		Reference:      "bet 1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3800), stake.BalanceCents)
+
+	got, err := repo.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(3800), got.BalanceCents)
+
+	// Every transaction's entries net to zero, and house accounts mirror the customer.
+	var unbalanced int
+	require.NoError(t, repo.db.QueryRow(`SELECT COUNT(*) FROM (SELECT transaction_id FROM entries GROUP BY transaction_id HAVING SUM(amount_cents) != 0)`).Scan(&unbalanced))
+	require.Zero(t, unbalanced)
+
+	var cash, house int64
+	require.NoError(t, repo.db.QueryRow(`SELECT balance_cents FROM accounts WHERE id = ?`, cashAccountID).Scan(&cash))
+	require.NoError(t, repo.db.QueryRow(`SELECT balance_cents FROM accounts WHERE id = ?`, houseAccountID).Scan(&house))
+	require.Equal(t, int64(-5000), cash)
+	require.Equal(t, int64(1200), house)
+
+	txns, err := repo.ListTransactions(account.Id, 10)
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	require.Equal(t, accounts.Transaction_TYPE_BET_STAKE, txns[0].Type)
+	require.Equal(t, int64(-1200), txns[0].AmountCents)
+}
+
+func TestLedgerRepo_Post_Idempotent(t *testing.T) {
+	repo := newTestLedger(t)
+
+	account, err := repo.CreateAccount("Punter")
+	require.NoError(t, err)
+
+	txn := &accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 1000, IdempotencyKey: "dep-1"}
+	first, err := repo.Post(txn)
+	require.NoError(t, err)
+	replay, err := repo.Post(txn)
+	require.NoError(t, err)
+	require.Equal(t, first.Id, replay.Id)
+
+	got, err := repo.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), got.BalanceCents)
+
+	_, err = repo.Post(&accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 2000, IdempotencyKey: "dep-1"})
+	require.ErrorIs(t, err, ErrIdempotencyConflict)
+}
+
+func TestLedgerRepo_Post_Failures(t *testing.T) {
+	repo := newTestLedger(t)
+
+	account, err := repo.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, repo, account.Id, 500)
+
+	_, err = repo.Post(&accounts.Transaction{AccountId: account.Id, Type: accounts.Transaction_TYPE_WITHDRAWAL, AmountCents: -501, IdempotencyKey: "wd-1"})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = repo.Post(&accounts.Transaction{AccountId: 999, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 100, IdempotencyKey: "dep-x"})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	// House accounts aren't customer accounts.
+	_, err = repo.Post(&accounts.Transaction{AccountId: houseAccountID, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 100, IdempotencyKey: "dep-house"})
+	require.Error(t, err)
+
+	got, err := repo.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), got.BalanceCents)
+}
+
+func TestLedgerRepo_Post_ConcurrentStakesNeverOverdraw(t *testing.T) {
+	repo := newTestLedger(t)
+
+	account, err := repo.CreateAccount("Punter")
+	require.NoError(t, err)
+	deposit(t, repo, account.Id, 1000)
+
+	// 25 concurrent $1 stakes against a $10 balance: exactly 10 may succeed.
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.Post(&accounts.Transaction{
+				AccountId:      account.Id,
+				Type:           accounts.Transaction_TYPE_BET_STAKE,
+				AmountCents:    -100,
+				IdempotencyKey: fmt.Sprintf("bet:%d", i),
+			})
+			if err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+				return
+			}
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 10, accepted)
+
+	got, err := repo.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Zero(t, got.BalanceCents)
+}
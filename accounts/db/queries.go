@@ -0,0 +1,183 @@
+package db
+
+const (
+	accountsInsert     = "insert"
+	accountsGet        = "get"
+	accountsAdjust     = "adjust"
+	houseAdjust        = "house-adjust"
+	transactionsByKey  = "by-key"
+	transactionsInsert = "insert-transaction"
+	transactionsList   = "list-transactions"
+	entriesInsert      = "insert-entry"
+)
+
+func getLedgerQueries() map[string]string {
+	return map[string]string{
+		accountsInsert: `
+			INSERT INTO accounts (name, created_time) VALUES (?, ?)
+		`,
+		accountsGet: `
+			SELECT
+				id,
+				name,
+				balance_cents,
+				created_time
+			FROM accounts
+			WHERE id = ? AND house = 0
+		`,
+		// The balance guard makes the overdraft check and the update a single atomic step.
+		accountsAdjust: `
+			UPDATE accounts
+			SET balance_cents = balance_cents + ?
+			WHERE id = ? AND house = 0 AND balance_cents + ? >= 0
+		`,
+		houseAdjust: `
+			UPDATE accounts
+			SET balance_cents = balance_cents + ?
+			WHERE id = ? AND house = 1
+		`,
+		transactionsByKey: `
+			SELECT
+				id,
+				account_id,
+				type,
+				amount_cents,
+				balance_cents,
+				reference,
+				idempotency_key,
+				created_time
+			FROM transactions
+			WHERE idempotency_key = ?
+		`,
+		transactionsInsert: `
+			INSERT INTO transactions (
+				idempotency_key,
+				account_id,
+				type,
+				amount_cents,
+				balance_cents,
+				reference,
+				created_time
+			) VALUES (?, ?, ?, ?, (SELECT balance_cents FROM accounts WHERE id = ?), ?, ?)
+		`,
+		transactionsList: `
+			SELECT
+				id,
+				account_id,
+				type,
+				amount_cents,
+				balance_cents,
+				reference,
+				idempotency_key,
+				created_time
+			FROM transactions
+			WHERE account_id = ?
+			ORDER BY id DESC
+			LIMIT ?
+		`,
+		entriesInsert: `
+			INSERT INTO entries (transaction_id, account_id, amount_cents) VALUES (?, ?, ?)
+		`,
+	}
+}
+
+const (
+	controlsGet    = "get-controls"
+	controlsUpsert = "upsert-controls"
+	limitsList     = "list-limits"
+	limitsUpsert   = "upsert-limit"
+	changesInsert  = "insert-change"
+	changesList    = "list-changes"
+	usageDeposits  = "usage-deposits"
+	usageStakes    = "usage-stakes"
+	usageLosses    = "usage-losses"
+)
+
+func getControlQueries() map[string]string {
+	return map[string]string{
+		controlsGet: `
+			SELECT
+				cool_off_until,
+				excluded_until,
+				excluded_permanently
+			FROM controls
+			WHERE account_id = ?
+		`,
+		controlsUpsert: `
+			INSERT INTO controls (account_id, cool_off_until, excluded_until, excluded_permanently)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (account_id) DO UPDATE SET
+				cool_off_until = excluded.cool_off_until,
+				excluded_until = excluded.excluded_until,
+				excluded_permanently = excluded.excluded_permanently
+		`,
+		limitsList: `
+			SELECT
+				type,
+				period,
+				amount_cents,
+				pending,
+				pending_amount_cents,
+				pending_effective_time
+			FROM limits
+			WHERE account_id = ?
+			ORDER BY type, period
+		`,
+		limitsUpsert: `
+			INSERT INTO limits (account_id, type, period, amount_cents, pending, pending_amount_cents, pending_effective_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (account_id, type, period) DO UPDATE SET
+				amount_cents = excluded.amount_cents,
+				pending = excluded.pending,
+				pending_amount_cents = excluded.pending_amount_cents,
+				pending_effective_time = excluded.pending_effective_time
+		`,
+		changesInsert: `
+			INSERT INTO control_changes (
+				account_id,
+				kind,
+				limit_type,
+				limit_period,
+				old_amount_cents,
+				new_amount_cents,
+				until,
+				effective_time,
+				changed_time,
+				reason
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+		changesList: `
+			SELECT
+				id,
+				account_id,
+				kind,
+				limit_type,
+				limit_period,
+				old_amount_cents,
+				new_amount_cents,
+				until,
+				effective_time,
+				changed_time,
+				reason
+			FROM control_changes
+			WHERE account_id = ?
+			ORDER BY id DESC
+		`,
+		usageDeposits: `
+			SELECT COALESCE(SUM(amount_cents), 0)
+			FROM transactions
+			WHERE account_id = ? AND created_time >= ? AND type = ?
+		`,
+		usageStakes: `
+			SELECT COALESCE(-SUM(amount_cents), 0)
+			FROM transactions
+			WHERE account_id = ? AND created_time >= ? AND type = ?
+		`,
+		// Net loss: stakes less payouts and refunds.
+		usageLosses: `
+			SELECT COALESCE(-SUM(amount_cents), 0)
+			FROM transactions
+			WHERE account_id = ? AND created_time >= ? AND type IN (?, ?, ?)
+		`,
+	}
+}
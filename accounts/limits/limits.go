@@ -0,0 +1,245 @@
+// Package limits implements the responsible gambling rules applied to customer
+// accounts: deposit, loss and stake limits over rolling windows, cool-off
+// periods and self-exclusion.
+//
+// The package holds no state. The ledger loads an account's controls and
+// recent usage inside its posting transaction and asks Check whether the
+// posting may proceed, so concurrent postings can't race past a limit.
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// IncreaseDelay is how long a loosened or removed limit waits before it applies.
+	IncreaseDelay = 7 * 24 * time.Hour
+	// MinCoolOff and MaxCoolOff bound the length of a cool-off period.
+	MinCoolOff = 24 * time.Hour
+	MaxCoolOff = 6 * 7 * 24 * time.Hour
+	// MinExclusion is the shortest fixed-term self-exclusion.
+	MinExclusion = 183 * 24 * time.Hour
+)
+
+// Rejection reasons, reported to callers alongside the gRPC status.
+const (
+	ReasonSelfExcluded  = "SELF_EXCLUDED"
+	ReasonCoolOff       = "COOL_OFF"
+	ReasonDepositLimit  = "DEPOSIT_LIMIT_EXCEEDED"
+	ReasonLossLimit     = "LOSS_LIMIT_EXCEEDED"
+	ReasonStakeLimit    = "STAKE_LIMIT_EXCEEDED"
+	ReasonInvalidChange = "INVALID_CONTROL_CHANGE"
+)
+
+// ErrInvalidChange is wrapped by errors for control changes the rules don't allow.
+var ErrInvalidChange = errors.New("invalid control change")
+
+// Violation is returned when a posting would breach an account's controls.
+type Violation struct {
+	// Reason is one of the Reason constants.
+	Reason string
+	// Message explains the rejection to the customer.
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return v.Message
+}
+
+// State is the set of controls in force on an account.
+type State struct {
+	Limits              []*accounts.Limit
+	CoolOffUntil        time.Time
+	ExcludedUntil       time.Time
+	ExcludedPermanently bool
+}
+
+// Usage returns the amount counted against a limit type since a point in time:
+// total deposits, total stakes, or net losses.
+type Usage func(t accounts.Limit_Type, since time.Time) (int64, error)
+
+// WindowDuration returns the length of a rolling limit window.
+func WindowDuration(w accounts.Limit_Window) time.Duration {
+	switch w {
+	case accounts.Limit_WINDOW_DAILY:
+		return 24 * time.Hour
+	case accounts.Limit_WINDOW_WEEKLY:
+		return 7 * 24 * time.Hour
+	case accounts.Limit_WINDOW_MONTHLY:
+		return 30 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Check reports whether a posting may proceed. Only deposits and bet stakes
+// are restricted; withdrawals, payouts and refunds always go through.
+func Check(state State, txn *accounts.Transaction, now time.Time, usage Usage) error {
+	var limited []accounts.Limit_Type
+	switch txn.Type {
+	case accounts.Transaction_TYPE_DEPOSIT:
+		limited = []accounts.Limit_Type{accounts.Limit_TYPE_DEPOSIT}
+	case accounts.Transaction_TYPE_BET_STAKE:
+		limited = []accounts.Limit_Type{accounts.Limit_TYPE_STAKE, accounts.Limit_TYPE_LOSS}
+	default:
+		return nil
+	}
+
+	if state.ExcludedPermanently || now.Before(state.ExcludedUntil) {
+		return &Violation{Reason: ReasonSelfExcluded, Message: "account is self-excluded"}
+	}
+	if now.Before(state.CoolOffUntil) {
+		return &Violation{
+			Reason:  ReasonCoolOff,
+			Message: fmt.Sprintf("account is in a cool-off period until %s", state.CoolOffUntil.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	amount := txn.AmountCents
+	if amount < 0 {
+		amount = -amount
+	}
+
+	for _, t := range limited {
+		for _, limit := range state.Limits {
+			if limit.Type != t {
+				continue
+			}
+			allowance := Effective(limit, now)
+			if allowance == nil {
+				continue
+			}
+
+			used, err := usage(t, now.Add(-WindowDuration(limit.Window)))
+			if err != nil {
+				return err
+			}
+			if used+amount > *allowance {
+				return &Violation{
+					Reason:  reason(t),
+					Message: fmt.Sprintf("%s %s limit of %d cents would be exceeded (%d used)", windowName(limit.Window), typeName(t), *allowance, used),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Effective returns the limit amount in force at now, applying a pending
+// change whose delay has passed. Nil means no limit.
+func Effective(limit *accounts.Limit, now time.Time) *int64 {
+	if limit == nil {
+		return nil
+	}
+	if limit.Pending && !now.Before(limit.PendingEffectiveTime.AsTime()) {
+		return limit.PendingAmountCents
+	}
+
+	return limit.AmountCents
+}
+
+// Apply works out the limit that results from a change request. Tightening
+// (a lower amount, or a limit where there was none) applies at once and
+// cancels any pending loosening. Loosening or removal keeps the current limit
+// and schedules the change for after IncreaseDelay. It also returns when the
+// requested amount takes effect.
+func Apply(current *accounts.Limit, t accounts.Limit_Type, w accounts.Limit_Window, requested *int64, now time.Time) (*accounts.Limit, time.Time, error) {
+	if requested != nil && *requested < 0 {
+		return nil, time.Time{}, fmt.Errorf("%w: limit can't be negative", ErrInvalidChange)
+	}
+
+	inForce := Effective(current, now)
+
+	if requested != nil && (inForce == nil || *requested <= *inForce) {
+		return &accounts.Limit{Type: t, Window: w, AmountCents: requested}, now, nil
+	}
+
+	if inForce == nil && requested == nil {
+		return &accounts.Limit{Type: t, Window: w}, now, nil
+	}
+
+	effective := now.Add(IncreaseDelay)
+	return &accounts.Limit{
+		Type:                 t,
+		Window:               w,
+		AmountCents:          inForce,
+		Pending:              true,
+		PendingAmountCents:   requested,
+		PendingEffectiveTime: timestamppb.New(effective),
+	}, effective, nil
+}
+
+// CoolOffUntil returns when a requested cool-off ends, refusing periods
+// outside the allowed range or that would cut short an existing cool-off.
+func CoolOffUntil(current time.Time, duration time.Duration, now time.Time) (time.Time, error) {
+	if duration < MinCoolOff || duration > MaxCoolOff {
+		return time.Time{}, fmt.Errorf("%w: cool-off must be between %s and %s", ErrInvalidChange, MinCoolOff, MaxCoolOff)
+	}
+
+	until := now.Add(duration)
+	if until.Before(current) {
+		return time.Time{}, fmt.Errorf("%w: cool-off can't be shortened", ErrInvalidChange)
+	}
+
+	return until, nil
+}
+
+// ExcludedUntil returns when a requested self-exclusion ends; a nil duration
+// means permanent, returned as the zero time with permanent set.
+func ExcludedUntil(state State, duration *time.Duration, now time.Time) (until time.Time, permanent bool, err error) {
+	if state.ExcludedPermanently {
+		return time.Time{}, false, fmt.Errorf("%w: account is already permanently excluded", ErrInvalidChange)
+	}
+	if duration == nil {
+		return time.Time{}, true, nil
+	}
+	if *duration < MinExclusion {
+		return time.Time{}, false, fmt.Errorf("%w: self-exclusion must be at least %s", ErrInvalidChange, MinExclusion)
+	}
+
+	until = now.Add(*duration)
+	if until.Before(state.ExcludedUntil) {
+		return time.Time{}, false, fmt.Errorf("%w: self-exclusion can't be shortened", ErrInvalidChange)
+	}
+
+	return until, false, nil
+}
+
+func reason(t accounts.Limit_Type) string {
+	switch t {
+	case accounts.Limit_TYPE_DEPOSIT:
+		return ReasonDepositLimit
+	case accounts.Limit_TYPE_LOSS:
+		return ReasonLossLimit
+	default:
+		return ReasonStakeLimit
+	}
+}
+
+func typeName(t accounts.Limit_Type) string {
+	switch t {
+	case accounts.Limit_TYPE_DEPOSIT:
+		return "deposit"
+	case accounts.Limit_TYPE_LOSS:
+		return "loss"
+	default:
+		return "stake"
+	}
+}
+
+func windowName(w accounts.Limit_Window) string {
+	switch w {
+	case accounts.Limit_WINDOW_DAILY:
+		return "daily"
+	case accounts.Limit_WINDOW_WEEKLY:
+		return "weekly"
+	default:
+		return "monthly"
+	}
+}
@@ -0,0 +1,192 @@
+package limits
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+	"github.com/stretchr/testify/require"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func cents(v int64) *int64 {
+	return &v
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stake := &accounts.Transaction{Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: -500}
+	deposit := &accounts.Transaction{Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 500}
+
+	tests := []struct {
+		name       string
+		state      State
+		txn        *accounts.Transaction
+		used       int64
+		wantReason string
+	}{
+		{
+			name: "no controls",
+			txn:  stake,
+		},
+		{
+			name:       "permanently excluded",
+			state:      State{ExcludedPermanently: true},
+			txn:        stake,
+			wantReason: ReasonSelfExcluded,
+		},
+		{
+			name:       "excluded until later",
+			state:      State{ExcludedUntil: now.Add(time.Hour)},
+			txn:        deposit,
+			wantReason: ReasonSelfExcluded,
+		},
+		{
+			name:  "exclusion lapsed",
+			state: State{ExcludedUntil: now.Add(-time.Hour)},
+			txn:   stake,
+		},
+		{
+			name:       "cool-off blocks stake",
+			state:      State{CoolOffUntil: now.Add(time.Hour)},
+			txn:        stake,
+			wantReason: ReasonCoolOff,
+		},
+		{
+			name:  "cool-off allows withdrawal",
+			state: State{CoolOffUntil: now.Add(time.Hour)},
+			txn:   &accounts.Transaction{Type: accounts.Transaction_TYPE_WITHDRAWAL, AmountCents: -500},
+		},
+		{
+			name:  "stake within limit",
+			state: State{Limits: []*accounts.Limit{{Type: accounts.Limit_TYPE_STAKE, Window: accounts.Limit_WINDOW_DAILY, AmountCents: cents(1000)}}},
+			txn:   stake,
+			used:  500,
+		},
+		{
+			name:       "stake over limit",
+			state:      State{Limits: []*accounts.Limit{{Type: accounts.Limit_TYPE_STAKE, Window: accounts.Limit_WINDOW_DAILY, AmountCents: cents(1000)}}},
+			txn:        stake,
+			used:       501,
+			wantReason: ReasonStakeLimit,
+		},
+		{
+			name:       "loss limit applies to stakes",
+			state:      State{Limits: []*accounts.Limit{{Type: accounts.Limit_TYPE_LOSS, Window: accounts.Limit_WINDOW_WEEKLY, AmountCents: cents(100)}}},
+			txn:        stake,
+			wantReason: ReasonLossLimit,
+		},
+		{
+			name:       "deposit over limit",
+			state:      State{Limits: []*accounts.Limit{{Type: accounts.Limit_TYPE_DEPOSIT, Window: accounts.Limit_WINDOW_MONTHLY, AmountCents: cents(0)}}},
+			txn:        deposit,
+			wantReason: ReasonDepositLimit,
+		},
+		{
+			name:  "deposit limit ignores stakes",
+			state: State{Limits: []*accounts.Limit{{Type: accounts.Limit_TYPE_DEPOSIT, Window: accounts.Limit_WINDOW_MONTHLY, AmountCents: cents(0)}}},
+			txn:   stake,
+		},
+		{
+			name: "matured removal lifts limit",
+			state: State{Limits: []*accounts.Limit{{
+				Type:                 accounts.Limit_TYPE_STAKE,
+				Window:               accounts.Limit_WINDOW_DAILY,
+				AmountCents:          cents(100),
+				Pending:              true,
+				PendingEffectiveTime: timestamppb.New(now.Add(-time.Minute)),
+			}}},
+			txn:  stake,
+			used: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.state, tt.txn, now, func(accounts.Limit_Type, time.Time) (int64, error) {
+				return tt.used, nil
+			})
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var violation *Violation
+			require.True(t, errors.As(err, &violation))
+			require.Equal(t, tt.wantReason, violation.Reason)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	daily := func(amount *int64) *accounts.Limit {
+		return &accounts.Limit{Type: accounts.Limit_TYPE_DEPOSIT, Window: accounts.Limit_WINDOW_DAILY, AmountCents: amount}
+	}
+
+	tests := []struct {
+		name          string
+		current       *accounts.Limit
+		requested     *int64
+		wantAmount    *int64
+		wantPending   bool
+		wantEffective time.Time
+		wantErr       bool
+	}{
+		{name: "new limit is immediate", requested: cents(1000), wantAmount: cents(1000), wantEffective: now},
+		{name: "decrease is immediate", current: daily(cents(1000)), requested: cents(500), wantAmount: cents(500), wantEffective: now},
+		{name: "increase is delayed", current: daily(cents(1000)), requested: cents(2000), wantAmount: cents(1000), wantPending: true, wantEffective: now.Add(IncreaseDelay)},
+		{name: "removal is delayed", current: daily(cents(1000)), wantAmount: cents(1000), wantPending: true, wantEffective: now.Add(IncreaseDelay)},
+		{name: "negative rejected", requested: cents(-1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, effective, err := Apply(tt.current, accounts.Limit_TYPE_DEPOSIT, accounts.Limit_WINDOW_DAILY, tt.requested, now)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidChange)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAmount, got.AmountCents)
+			require.Equal(t, tt.wantPending, got.Pending)
+			require.Equal(t, tt.wantEffective, effective)
+		})
+	}
+}
+
+func TestCoolOffUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	until, err := CoolOffUntil(time.Time{}, 48*time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(48*time.Hour), until)
+
+	_, err = CoolOffUntil(time.Time{}, time.Hour, now)
+	require.ErrorIs(t, err, ErrInvalidChange)
+
+	_, err = CoolOffUntil(now.Add(30*24*time.Hour), 48*time.Hour, now)
+	require.ErrorIs(t, err, ErrInvalidChange)
+}
+
+func TestExcludedUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	year := 365 * 24 * time.Hour
+	short := 30 * 24 * time.Hour
+
+	until, permanent, err := ExcludedUntil(State{}, &year, now)
+	require.NoError(t, err)
+	require.False(t, permanent)
+	require.Equal(t, now.Add(year), until)
+
+	_, permanent, err = ExcludedUntil(State{ExcludedUntil: now.Add(year)}, nil, now)
+	require.NoError(t, err)
+	require.True(t, permanent)
+
+	_, _, err = ExcludedUntil(State{}, &short, now)
+	require.ErrorIs(t, err, ErrInvalidChange)
+
+	_, _, err = ExcludedUntil(State{ExcludedPermanently: true}, &year, now)
+	require.ErrorIs(t, err, ErrInvalidChange)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"git.neds.sh/matty/entain/accounts/db"
+	"git.neds.sh/matty/entain/accounts/service"
+	"git.neds.sh/matty/entain/pkg/admin"
+	"git.neds.sh/matty/entain/pkg/config"
+	"git.neds.sh/matty/entain/pkg/health"
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tlsutil"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"google.golang.org/grpc"
+)
+
+var (
+	grpcEndpoint    = flag.String("grpc-endpoint", "localhost:9003", "gRPC server endpoint")
+	adminEndpoint   = flag.String("admin-endpoint", "localhost:9103", "Admin HTTP endpoint serving /metrics")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "How long to let RPCs in flight finish when shutting down")
+	dbDSN           = flag.String("db-dsn", "file:./db/accounts.db?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on", "SQLite database DSN")
+	healthInterval  = flag.Duration("health-interval", health.DefaultInterval, "How often to check that the database is still healthy")
+	tlsFlags        = tlsutil.RegisterFlags(flag.CommandLine, "")
+	traceFlags      = tracing.RegisterFlags(flag.CommandLine)
+	logFlags        = logging.RegisterFlags(flag.CommandLine)
+)
+
+func main() {
+	config.Parse("ACCOUNTS",
+		config.Endpoint("grpc-endpoint", grpcEndpoint),
+		config.Endpoint("admin-endpoint", adminEndpoint),
+		config.Required("db-dsn", dbDSN),
+		config.Positive("shutdown-timeout", shutdownTimeout),
+		config.Positive("health-interval", healthInterval),
+		tlsFlags,
+		traceFlags,
+		logFlags,
+	)
+
+	if err := logFlags.Setup("accounts"); err != nil {
+		log.Fatalf("invalid logging flags: %s\n", err)
+	}
+
+	if err := run(); err != nil {
+		slog.Error("failed running grpc server", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := traceFlags.Setup(context.Background(), "accounts")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	conn, err := net.Listen("tcp", *grpcEndpoint)
+	if err != nil {
+		return err
+	}
+
+	// Immediate transactions take the write lock up front, so concurrent
+	// postings queue on the busy timeout instead of failing on lock upgrade.
+	accountsDB, err := sql.Open("sqlite3", *dbDSN)
+	if err != nil {
+		return err
+	}
+	defer accountsDB.Close()
+
+	ledgerRepo := db.NewLedgerRepo(accountsDB)
+	if err := ledgerRepo.Init(); err != nil {
+		return err
+	}
+
+	controlsRepo := db.NewControlsRepo(accountsDB)
+	if err := controlsRepo.Init(); err != nil {
+		return err
+	}
+
+	reloader, err := tlsFlags.Reloader("accounts")
+	if err != nil {
+		return err
+	}
+	creds, err := tlsutil.ServerCredentials(reloader)
+	if err != nil {
+		return err
+	}
+
+	if err := metrics.RegisterDB(accountsDB, "accounts"); err != nil {
+		return err
+	}
+	if err := admin.New(*adminEndpoint).Start(); err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
+		health.KeepalivePolicy(),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			logging.UnaryServerInterceptor(),
+		),
+		grpc.StatsHandler(tracing.ServerHandler()),
+	)
+
+	accounts.RegisterAccountsServer(
+		grpcServer,
+		service.NewAccountsService(
+			ledgerRepo,
+			controlsRepo,
+		),
+	)
+
+	// The service is ready once its database is set up, and for as long as
+	// it answers.
+	healthServer := health.NewServer(grpcServer, accounts.Accounts_ServiceDesc.ServiceName)
+	go health.Watch(ctx, healthServer, accountsDB.PingContext, *healthInterval, accounts.Accounts_ServiceDesc.ServiceName)
+
+	slog.Info("gRPC server listening", "endpoint", *grpcEndpoint)
+
+	return health.Serve(ctx, grpcServer, conn, healthServer, *shutdownTimeout)
+}
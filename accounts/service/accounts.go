@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"git.neds.sh/matty/entain/accounts/db"
+	"git.neds.sh/matty/entain/accounts/limits"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// errorDomain identifies this service in error details.
+	errorDomain = "accounts.entain"
+)
+
+type Accounts interface {
+	// CreateAccount opens a new customer account with a zero balance.
+	CreateAccount(ctx context.Context, in *accounts.CreateAccountRequest) (*accounts.CreateAccountResponse, error)
+	// GetBalance returns the current balance of an account.
+	GetBalance(ctx context.Context, in *accounts.GetBalanceRequest) (*accounts.GetBalanceResponse, error)
+	// ListTransactions returns the transactions posted to an account, newest first.
+	ListTransactions(ctx context.Context, in *accounts.ListTransactionsRequest) (*accounts.ListTransactionsResponse, error)
+	// Debit takes funds from an account for a withdrawal or bet stake.
+	Debit(ctx context.Context, in *accounts.DebitRequest) (*accounts.DebitResponse, error)
+	// Credit pays funds into an account for a deposit, payout or refund.
+	Credit(ctx context.Context, in *accounts.CreditRequest) (*accounts.CreditResponse, error)
+	// SetLimit sets or removes a deposit, loss or stake limit.
+	SetLimit(ctx context.Context, in *accounts.SetLimitRequest) (*accounts.SetLimitResponse, error)
+	// GetControls returns the responsible gambling controls on an account.
+	GetControls(ctx context.Context, in *accounts.GetControlsRequest) (*accounts.GetControlsResponse, error)
+	// StartCoolOff pauses deposits and betting for a period.
+	StartCoolOff(ctx context.Context, in *accounts.StartCoolOffRequest) (*accounts.StartCoolOffResponse, error)
+	// SelfExclude excludes an account for a period or permanently.
+	SelfExclude(ctx context.Context, in *accounts.SelfExcludeRequest) (*accounts.SelfExcludeResponse, error)
+	// ListControlChanges returns the audit trail of control changes on an account.
+	ListControlChanges(ctx context.Context, in *accounts.ListControlChangesRequest) (*accounts.ListControlChangesResponse, error)
+}
+
+// accountsService implements the Accounts interface.
+type accountsService struct {
+	ledgerRepo   db.LedgerRepo
+	controlsRepo db.ControlsRepo
+}
+
+// NewAccountsService instantiates and returns a new accountsService.
+func NewAccountsService(ledgerRepo db.LedgerRepo, controlsRepo db.ControlsRepo) Accounts {
+	return &accountsService{ledgerRepo: ledgerRepo, controlsRepo: controlsRepo}
+}
+
+func (s *accountsService) CreateAccount(ctx context.Context, in *accounts.CreateAccountRequest) (*accounts.CreateAccountResponse, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	account, err := s.ledgerRepo.CreateAccount(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.CreateAccountResponse{Account: account}, nil
+}
+
+func (s *accountsService) GetBalance(ctx context.Context, in *accounts.GetBalanceRequest) (*accounts.GetBalanceResponse, error) {
+	account, err := s.ledgerRepo.GetAccount(in.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
+
+	return &accounts.GetBalanceResponse{Account: account}, nil
+}
+
+func (s *accountsService) ListTransactions(ctx context.Context, in *accounts.ListTransactionsRequest) (*accounts.ListTransactionsResponse, error) {
+	account, err := s.ledgerRepo.GetAccount(in.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
+
+	pageSize := int(in.PageSize)
+	switch {
+	case pageSize <= 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	txns, err := s.ledgerRepo.ListTransactions(in.AccountId, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.ListTransactionsResponse{Transactions: txns}, nil
+}
+
+func (s *accountsService) Debit(ctx context.Context, in *accounts.DebitRequest) (*accounts.DebitResponse, error) {
+	switch in.Type {
+	case accounts.Transaction_TYPE_WITHDRAWAL, accounts.Transaction_TYPE_BET_STAKE:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not a debit", in.Type)
+	}
+
+	txn, err := s.post(in.AccountId, in.Type, -in.AmountCents, in.AmountCents, in.IdempotencyKey, in.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.DebitResponse{Transaction: txn}, nil
+}
+
+func (s *accountsService) Credit(ctx context.Context, in *accounts.CreditRequest) (*accounts.CreditResponse, error) {
+	switch in.Type {
+	case accounts.Transaction_TYPE_DEPOSIT, accounts.Transaction_TYPE_PAYOUT, accounts.Transaction_TYPE_REFUND:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not a credit", in.Type)
+	}
+
+	txn, err := s.post(in.AccountId, in.Type, in.AmountCents, in.AmountCents, in.IdempotencyKey, in.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.CreditResponse{Transaction: txn}, nil
+}
+
+// post validates and records a signed posting, mapping ledger errors onto gRPC codes.
+func (s *accountsService) post(accountID int64, t accounts.Transaction_Type, signed, amount int64, key, reference string) (*accounts.Transaction, error) {
+	if amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+	if key == "" {
+		return nil, status.Error(codes.InvalidArgument, "idempotency_key is required")
+	}
+
+	txn, err := s.ledgerRepo.Post(&accounts.Transaction{
+		AccountId:      accountID,
+		Type:           t,
+		AmountCents:    signed,
+		IdempotencyKey: key,
+		Reference:      reference,
+	})
+	switch {
+	case errors.Is(err, db.ErrAccountNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrIdempotencyConflict):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case err != nil:
+		return nil, controlError(err)
+	}
+
+	return txn, nil
+}
+
+func (s *accountsService) SetLimit(ctx context.Context, in *accounts.SetLimitRequest) (*accounts.SetLimitResponse, error) {
+	if _, ok := accounts.Limit_Type_name[int32(in.Type)]; !ok || in.Type == accounts.Limit_TYPE_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+	if _, ok := accounts.Limit_Window_name[int32(in.Window)]; !ok || in.Window == accounts.Limit_WINDOW_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "window is required")
+	}
+
+	limit, err := s.controlsRepo.SetLimit(in.AccountId, in.Type, in.Window, in.AmountCents, in.Reason)
+	if err != nil {
+		return nil, controlError(err)
+	}
+
+	return &accounts.SetLimitResponse{Limit: limit}, nil
+}
+
+func (s *accountsService) GetControls(ctx context.Context, in *accounts.GetControlsRequest) (*accounts.GetControlsResponse, error) {
+	controls, err := s.controlsRepo.GetControls(in.AccountId)
+	if err != nil {
+		return nil, controlError(err)
+	}
+
+	return &accounts.GetControlsResponse{Controls: controls}, nil
+}
+
+func (s *accountsService) StartCoolOff(ctx context.Context, in *accounts.StartCoolOffRequest) (*accounts.StartCoolOffResponse, error) {
+	if in.Duration == nil {
+		return nil, status.Error(codes.InvalidArgument, "duration is required")
+	}
+	if err := in.Duration.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	controls, err := s.controlsRepo.StartCoolOff(in.AccountId, in.Duration.AsDuration(), in.Reason)
+	if err != nil {
+		return nil, controlError(err)
+	}
+
+	return &accounts.StartCoolOffResponse{Controls: controls}, nil
+}
+
+func (s *accountsService) SelfExclude(ctx context.Context, in *accounts.SelfExcludeRequest) (*accounts.SelfExcludeResponse, error) {
+	var duration *time.Duration
+	if in.Duration != nil {
+		if err := in.Duration.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		d := in.Duration.AsDuration()
+		duration = &d
+	}
+
+	controls, err := s.controlsRepo.SelfExclude(in.AccountId, duration, in.Reason)
+	if err != nil {
+		return nil, controlError(err)
+	}
+
+	return &accounts.SelfExcludeResponse{Controls: controls}, nil
+}
+
+func (s *accountsService) ListControlChanges(ctx context.Context, in *accounts.ListControlChangesRequest) (*accounts.ListControlChangesResponse, error) {
+	account, err := s.ledgerRepo.GetAccount(in.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
+
+	changes, err := s.controlsRepo.ListChanges(in.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.ListControlChangesResponse{Changes: changes}, nil
+}
+
+// controlError maps responsible gambling errors onto gRPC codes. Violations
+// carry their reason as an ErrorInfo detail so callers can tell them apart.
+func controlError(err error) error {
+	var violation *limits.Violation
+	switch {
+	case errors.As(err, &violation):
+		st, detailErr := status.New(codes.FailedPrecondition, violation.Message).WithDetails(&errdetails.ErrorInfo{
+			Reason: violation.Reason,
+			Domain: errorDomain,
+		})
+		if detailErr != nil {
+			return status.Error(codes.FailedPrecondition, violation.Message)
+		}
+		return st.Err()
+	case errors.Is(err, limits.ErrInvalidChange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrAccountNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/accounts/db"
+	"git.neds.sh/matty/entain/accounts/limits"
+	"git.neds.sh/matty/entain/accounts/service"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestAccountsService_Debit(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *accounts.DebitRequest
+		repoErr  error
+		callRepo bool
+		wantCode codes.Code
+	}{
+		{
+			name:     "bet stake posted as negative amount",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:1"},
+			callRepo: true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "credit type rejected",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_DEPOSIT, AmountCents: 500, IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "non-positive amount rejected",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_WITHDRAWAL, AmountCents: -5, IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "missing idempotency key rejected",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_WITHDRAWAL, AmountCents: 5},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "insufficient funds",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:2"},
+			repoErr:  db.ErrInsufficientFunds,
+			callRepo: true,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "unknown account",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:3"},
+			repoErr:  db.ErrAccountNotFound,
+			callRepo: true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "idempotency conflict",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:4"},
+			repoErr:  db.ErrIdempotencyConflict,
+			callRepo: true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "limit violation",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:6"},
+			repoErr:  &limits.Violation{Reason: limits.ReasonStakeLimit, Message: "daily stake limit of 100 cents would be exceeded (0 used)"},
+			callRepo: true,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "repo error bubbles",
+			req:      &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:5"},
+			repoErr:  assert.AnError,
+			callRepo: true,
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := db.NewLedgerRepoMock(t)
+			if tt.callRepo {
+				repo.On("Post", mock.MatchedBy(func(txn *accounts.Transaction) bool {
+					return txn.AmountCents == -tt.req.AmountCents && txn.IdempotencyKey == tt.req.IdempotencyKey
+				})).Return(func(txn *accounts.Transaction) (*accounts.Transaction, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return txn, nil
+				}).Once()
+			}
+
+			svc := service.NewAccountsService(repo, db.NewControlsRepoMock(t))
+			resp, err := svc.Debit(context.Background(), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				require.Equal(t, -tt.req.AmountCents, resp.Transaction.AmountCents)
+			}
+		})
+	}
+}
+
+func TestAccountsService_Credit(t *testing.T) {
+	repo := db.NewLedgerRepoMock(t)
+	repo.On("Post", mock.MatchedBy(func(txn *accounts.Transaction) bool {
+		return txn.AmountCents == 850 && txn.Type == accounts.Transaction_TYPE_PAYOUT
+	})).Return(&accounts.Transaction{Id: 7, AmountCents: 850}, nil).Once()
+
+	svc := service.NewAccountsService(repo, db.NewControlsRepoMock(t))
+
+	resp, err := svc.Credit(context.Background(), &accounts.CreditRequest{AccountId: 1, Type: accounts.Transaction_TYPE_PAYOUT, AmountCents: 850, IdempotencyKey: "payout:1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), resp.Transaction.Id)
+
+	_, err = svc.Credit(context.Background(), &accounts.CreditRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 850, IdempotencyKey: "k"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAccountsService_GetBalance(t *testing.T) {
+	repo := db.NewLedgerRepoMock(t)
+	repo.On("GetAccount", int64(1)).Return(&accounts.Account{Id: 1, BalanceCents: 2500}, nil).Once()
+	repo.On("GetAccount", int64(2)).Return((*accounts.Account)(nil), nil).Once()
+
+	svc := service.NewAccountsService(repo, db.NewControlsRepoMock(t))
+
+	resp, err := svc.GetBalance(context.Background(), &accounts.GetBalanceRequest{AccountId: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(2500), resp.Account.BalanceCents)
+
+	_, err = svc.GetBalance(context.Background(), &accounts.GetBalanceRequest{AccountId: 2})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAccountsService_ListTransactions_PageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int32
+		want     int
+	}{
+		{name: "default", pageSize: 0, want: 50},
+		{name: "explicit", pageSize: 10, want: 10},
+		{name: "capped", pageSize: 10000, want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := db.NewLedgerRepoMock(t)
+			repo.On("GetAccount", int64(1)).Return(&accounts.Account{Id: 1}, nil).Once()
+			repo.On("ListTransactions", int64(1), tt.want).Return([]*accounts.Transaction{}, nil).Once()
+
+			svc := service.NewAccountsService(repo, db.NewControlsRepoMock(t))
+			_, err := svc.ListTransactions(context.Background(), &accounts.ListTransactionsRequest{AccountId: 1, PageSize: tt.pageSize})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAccountsService_Debit_ViolationReason(t *testing.T) {
+	repo := db.NewLedgerRepoMock(t)
+	repo.On("Post", mock.Anything).Return(nil, &limits.Violation{Reason: limits.ReasonSelfExcluded, Message: "account is self-excluded"}).Once()
+
+	svc := service.NewAccountsService(repo, db.NewControlsRepoMock(t))
+
+	_, err := svc.Debit(context.Background(), &accounts.DebitRequest{AccountId: 1, Type: accounts.Transaction_TYPE_BET_STAKE, AmountCents: 500, IdempotencyKey: "bet:1"})
+	st := status.Convert(err)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, limits.ReasonSelfExcluded, info.Reason)
+}
+
+func TestAccountsService_SetLimit(t *testing.T) {
+	amount := int64(10000)
+
+	tests := []struct {
+		name     string
+		req      *accounts.SetLimitRequest
+		repoErr  error
+		callRepo bool
+		wantCode codes.Code
+	}{
+		{
+			name:     "sets limit",
+			req:      &accounts.SetLimitRequest{AccountId: 1, Type: accounts.Limit_TYPE_DEPOSIT, Window: accounts.Limit_WINDOW_DAILY, AmountCents: &amount},
+			callRepo: true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "type required",
+			req:      &accounts.SetLimitRequest{AccountId: 1, Window: accounts.Limit_WINDOW_DAILY, AmountCents: &amount},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown window rejected",
+			req:      &accounts.SetLimitRequest{AccountId: 1, Type: accounts.Limit_TYPE_LOSS, Window: 42, AmountCents: &amount},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid change",
+			req:      &accounts.SetLimitRequest{AccountId: 1, Type: accounts.Limit_TYPE_STAKE, Window: accounts.Limit_WINDOW_WEEKLY, AmountCents: &amount},
+			repoErr:  limits.ErrInvalidChange,
+			callRepo: true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown account",
+			req:      &accounts.SetLimitRequest{AccountId: 9, Type: accounts.Limit_TYPE_STAKE, Window: accounts.Limit_WINDOW_WEEKLY, AmountCents: &amount},
+			repoErr:  db.ErrAccountNotFound,
+			callRepo: true,
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controls := db.NewControlsRepoMock(t)
+			if tt.callRepo {
+				controls.On("SetLimit", tt.req.AccountId, tt.req.Type, tt.req.Window, tt.req.AmountCents, tt.req.Reason).
+					Return(func(_ int64, lt accounts.Limit_Type, w accounts.Limit_Window, amount *int64, _ string) (*accounts.Limit, error) {
+						if tt.repoErr != nil {
+							return nil, tt.repoErr
+						}
+						return &accounts.Limit{Type: lt, Window: w, AmountCents: amount}, nil
+					}).Once()
+			}
+
+			svc := service.NewAccountsService(db.NewLedgerRepoMock(t), controls)
+			resp, err := svc.SetLimit(context.Background(), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				require.Equal(t, amount, *resp.Limit.AmountCents)
+			}
+		})
+	}
+}
+
+func TestAccountsService_SelfExclude(t *testing.T) {
+	controls := db.NewControlsRepoMock(t)
+	controls.On("SelfExclude", int64(1), (*time.Duration)(nil), "permanent").
+		Return(&accounts.Controls{AccountId: 1, ExcludedPermanently: true}, nil).Once()
+	controls.On("SelfExclude", int64(1), mock.MatchedBy(func(d *time.Duration) bool { return d != nil && *d == 24*time.Hour }), "").
+		Return(nil, limits.ErrInvalidChange).Once()
+
+	svc := service.NewAccountsService(db.NewLedgerRepoMock(t), controls)
+
+	resp, err := svc.SelfExclude(context.Background(), &accounts.SelfExcludeRequest{AccountId: 1, Reason: "permanent"})
+	require.NoError(t, err)
+	require.True(t, resp.Controls.ExcludedPermanently)
+
+	_, err = svc.SelfExclude(context.Background(), &accounts.SelfExcludeRequest{AccountId: 1, Duration: durationpb.New(24 * time.Hour)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAccountsService_StartCoolOff(t *testing.T) {
+	controls := db.NewControlsRepoMock(t)
+	controls.On("StartCoolOff", int64(1), 48*time.Hour, "").
+		Return(&accounts.Controls{AccountId: 1}, nil).Once()
+
+	svc := service.NewAccountsService(db.NewLedgerRepoMock(t), controls)
+
+	_, err := svc.StartCoolOff(context.Background(), &accounts.StartCoolOffRequest{AccountId: 1, Duration: durationpb.New(48 * time.Hour)})
+	require.NoError(t, err)
+
+	_, err = svc.StartCoolOff(context.Background(), &accounts.StartCoolOffRequest{AccountId: 1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
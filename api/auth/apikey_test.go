@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeAPIKeys(t *testing.T, name, key string, roles string) string {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(key))
+	data := fmt.Sprintf(`{"keys":[{"name":%q,"sha256":%q,"roles":%s}]}`, name, hex.EncodeToString(sum[:]), roles)
+
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	return path
+}
+
+func TestAPIKeys_Verify(t *testing.T) {
+	keys, err := NewAPIKeys(writeAPIKeys(t, "acme", "s3cret", `["partner"]`))
+	require.NoError(t, err)
+
+	id, err := keys.Verify("s3cret")
+	require.NoError(t, err)
+	require.Equal(t, &Identity{Subject: "acme", Roles: []string{RolePartner}, Method: MethodAPIKey}, id)
+
+	_, err = keys.Verify("guess")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestNewAPIKeys_RejectsPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"name":"acme","sha256":"s3cret"}]}`), 0o600))
+
+	_, err := NewAPIKeys(path)
+	require.Error(t, err)
+}
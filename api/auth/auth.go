@@ -0,0 +1,90 @@
+// Package auth authenticates API callers and authorizes the gRPC calls the
+// gateway makes on their behalf.
+//
+// Callers present either a JWT bearer token, verified against a local JWKS
+// file, or a partner API key. Requests without credentials proceed as
+// anonymous and may only read the public racing and sports catalogue. The
+// verified identity is forwarded to backend services as gRPC metadata.
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Roles a caller may hold.
+const (
+	// RoleCustomer may bet and manage their own account.
+	RoleCustomer = "customer"
+	// RolePartner may read the catalogue with an API key.
+	RolePartner = "partner"
+	// RoleTrading may see hidden races and events and settle races.
+	RoleTrading = "trading"
+	// RoleAdmin may do anything.
+	RoleAdmin = "admin"
+)
+
+// Methods by which a caller was authenticated.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Metadata keys carrying the verified identity to backend services. The
+// gateway always overwrites them, so callers can't supply their own.
+const (
+	MetadataSubject   = "x-auth-subject"
+	MetadataRoles     = "x-auth-roles"
+	MetadataMethod    = "x-auth-method"
+	MetadataAccountID = "x-auth-account-id"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when credentials fail verification.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity is an authenticated caller.
+type Identity struct {
+	// Subject identifies the caller: the token subject or API key name.
+	Subject string
+	// Roles held by the caller.
+	Roles []string
+	// Method is how the caller authenticated, MethodJWT or MethodAPIKey.
+	Method string
+	// AccountID is the customer account the caller owns, or zero.
+	AccountID int64
+}
+
+// HasRole reports whether the identity holds any of roles. Admins hold every role.
+func (id *Identity) HasRole(roles ...string) bool {
+	if id == nil {
+		return false
+	}
+	if slices.Contains(id.Roles, RoleAdmin) {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(id.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type identityKey struct{}
+
+// NewContext returns a context carrying id.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity on ctx, or nil for anonymous callers.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	minSecretBytes = 32
+	minRSABits     = 2048
+)
+
+// JWTVerifier verifies HS256 and RS256 bearer tokens against the keys of a JWKS file.
+type JWTVerifier struct {
+	keys    []verificationKey
+	options []jwt.ParserOption
+}
+
+type verificationKey struct {
+	id    string
+	alg   string
+	value any
+}
+
+// jwk is the subset of RFC 7517 the verifier understands: "oct" keys for
+// HS256 and "RSA" public keys for RS256.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// claims are the token claims the gateway reads.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles     []string `json:"roles"`
+	AccountID int64    `json:"account_id,omitempty"`
+}
+
+// NewJWTVerifier loads the JWKS file at path. Tokens must be issued by issuer
+// and intended for audience, where those are set.
+func NewJWTVerifier(path, issuer, audience string) (*JWTVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	v := &JWTVerifier{
+		options: []jwt.ParserOption{
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+			jwt.WithExpirationRequired(),
+		},
+	}
+	if issuer != "" {
+		v.options = append(v.options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		v.options = append(v.options, jwt.WithAudience(audience))
+	}
+
+	for i, k := range set.Keys {
+		key, err := k.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %d: %w", path, i, err)
+		}
+		v.keys = append(v.keys, key)
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("%s: no keys", path)
+	}
+
+	return v, nil
+}
+
+// Verify checks a raw token and returns the identity it asserts.
+func (v *JWTVerifier) Verify(raw string) (*Identity, error) {
+	var c claims
+	if _, err := jwt.ParseWithClaims(raw, &c, v.keyFunc, v.options...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	return &Identity{
+		Subject:   c.Subject,
+		Roles:     c.Roles,
+		Method:    MethodJWT,
+		AccountID: c.AccountID,
+	}, nil
+}
+
+// keyFunc offers every key matching the token's algorithm, and its key ID
+// when it has one. Matching on algorithm stops an RSA public key being used
+// as an HMAC secret.
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var set jwt.VerificationKeySet
+	for _, key := range v.keys {
+		if key.alg != token.Method.Alg() {
+			continue
+		}
+		if kid != "" && key.id != kid {
+			continue
+		}
+		set.Keys = append(set.Keys, key.value)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no %s key %q", token.Method.Alg(), kid)
+	}
+
+	return set, nil
+}
+
+func (k jwk) verificationKey() (verificationKey, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != jwt.SigningMethodHS256.Alg() {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q for oct key", k.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("decoding k: %w", err)
+		}
+		if len(secret) < minSecretBytes {
+			return verificationKey{}, fmt.Errorf("HS256 secret must be at least %d bytes", minSecretBytes)
+		}
+		return verificationKey{id: k.Kid, alg: jwt.SigningMethodHS256.Alg(), value: secret}, nil
+
+	case "RSA":
+		if k.Alg != "" && k.Alg != jwt.SigningMethodRS256.Alg() {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q for RSA key", k.Alg)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("decoding n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("decoding e: %w", err)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < minRSABits {
+			return verificationKey{}, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		return verificationKey{id: k.Kid, alg: jwt.SigningMethodRS256.Alg(), value: pub}, nil
+
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+}
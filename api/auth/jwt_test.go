@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// writeJWKS writes a JWKS holding an HS256 secret and the public half of rsaKey.
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey) string {
+	t.Helper()
+
+	enc := base64.RawURLEncoding
+	set := map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac", "k": enc.EncodeToString(hmacSecret)},
+		{
+			"kty": "RSA",
+			"kid": "rsa",
+			"alg": "RS256",
+			"n":   enc.EncodeToString(rsaKey.N.Bytes()),
+			"e":   enc.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+	}}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, c claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v, err := NewJWTVerifier(writeJWKS(t, rsaKey), "entain", "api")
+	require.NoError(t, err)
+
+	valid := func() claims {
+		return claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "punter-3",
+				Issuer:    "entain",
+				Audience:  jwt.ClaimStrings{"api"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Roles:     []string{RoleCustomer},
+			AccountID: 3,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "HS256",
+			token: func() string { return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, valid()) },
+		},
+		{
+			name:  "RS256",
+			token: func() string { return sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, valid()) },
+		},
+		{
+			name:  "no key ID",
+			token: func() string { return sign(t, jwt.SigningMethodRS256, "", rsaKey, valid()) },
+		},
+		{
+			name:    "unknown key ID",
+			token:   func() string { return sign(t, jwt.SigningMethodRS256, "other", rsaKey, valid()) },
+			wantErr: true,
+		},
+		{
+			name:    "wrong RSA key",
+			token:   func() string { return sign(t, jwt.SigningMethodRS256, "rsa", otherKey, valid()) },
+			wantErr: true,
+		},
+		{
+			name: "RSA public key used as HMAC secret",
+			token: func() string {
+				return sign(t, jwt.SigningMethodHS256, "rsa", rsaKey.N.Bytes(), valid())
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := valid()
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "no expiry",
+			token: func() string {
+				c := valid()
+				c.ExpiresAt = nil
+				return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := valid()
+				c.Issuer = "someone-else"
+				return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := valid()
+				c.Audience = jwt.ClaimStrings{"admin"}
+				return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, c)
+			},
+			wantErr: true,
+		},
+		{
+			name: "no subject",
+			token: func() string {
+				c := valid()
+				c.Subject = ""
+				return sign(t, jwt.SigningMethodHS256, "hmac", hmacSecret, c)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unsigned",
+			token:   func() string { return sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, valid()) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := v.Verify(tt.token())
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidCredentials)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &Identity{Subject: "punter-3", Roles: []string{RoleCustomer}, Method: MethodJWT, AccountID: 3}, id)
+		})
+	}
+}
+
+func TestNewJWTVerifier_RejectsWeakKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","k":"c2hvcnQ"}]}`), 0o600))
+
+	_, err := NewJWTVerifier(path, "", "")
+	require.Error(t, err)
+}
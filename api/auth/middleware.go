@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// APIKeyHeader carries a partner API key.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator resolves the caller of each HTTP request. A nil verifier
+// rejects every credential of that kind.
+type Authenticator struct {
+	JWT     *JWTVerifier
+	APIKeys *APIKeys
+}
+
+// Handler authenticates requests before passing them to next, with the
+// caller's identity on the request context. Requests without credentials pass
+// through as anonymous; requests with bad credentials are refused.
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := a.authenticate(r)
+		switch {
+		case errors.Is(err, ErrNoCredentials):
+			next.ServeHTTP(w, r)
+		case err != nil:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="entain"`)
+			writeError(w, http.StatusUnauthorized, status.New(codes.Unauthenticated, err.Error()))
+		default:
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+		}
+	})
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*Identity, error) {
+	header := r.Header.Get("Authorization")
+	key := r.Header.Get(APIKeyHeader)
+
+	switch {
+	case header != "" && key != "":
+		return nil, errors.New("send either a bearer token or an API key, not both")
+	case header != "":
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return nil, errors.New("authorization header must be a bearer token")
+		}
+		if a.JWT == nil {
+			return nil, errors.New("bearer tokens are not accepted")
+		}
+		return a.JWT.Verify(token)
+	case key != "":
+		if a.APIKeys == nil {
+			return nil, errors.New("API keys are not accepted")
+		}
+		return a.APIKeys.Verify(key)
+	default:
+		return nil, ErrNoCredentials
+	}
+}
+
+// writeError writes st in the same JSON shape the gateway uses for errors.
+func writeError(w http.ResponseWriter, code int, st *status.Status) {
+	body, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		body = []byte(`{"code":2,"message":"failed to marshal error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
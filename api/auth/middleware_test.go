@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticator_Handler(t *testing.T) {
+	keys, err := NewAPIKeys(writeAPIKeys(t, "acme", "s3cret", `["partner"]`))
+	require.NoError(t, err)
+
+	a := &Authenticator{APIKeys: keys}
+
+	var got *Identity
+	handler := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		wantCode int
+		wantID   *Identity
+	}{
+		{name: "anonymous", wantCode: http.StatusOK},
+		{name: "API key", headers: map[string]string{APIKeyHeader: "s3cret"}, wantCode: http.StatusOK, wantID: &Identity{Subject: "acme", Roles: []string{RolePartner}, Method: MethodAPIKey}},
+		{name: "bad API key", headers: map[string]string{APIKeyHeader: "guess"}, wantCode: http.StatusUnauthorized},
+		{name: "bearer without verifier", headers: map[string]string{"Authorization": "Bearer abc"}, wantCode: http.StatusUnauthorized},
+		{name: "basic auth", headers: map[string]string{"Authorization": "Basic YTpi"}, wantCode: http.StatusUnauthorized},
+		{name: "both", headers: map[string]string{"Authorization": "Bearer abc", APIKeyHeader: "s3cret"}, wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/v1/races/1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Equal(t, tt.wantID, got)
+			if tt.wantCode == http.StatusUnauthorized {
+				require.Contains(t, rec.Body.String(), `"code":16`)
+			}
+		})
+	}
+}
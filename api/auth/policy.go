@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// rule says who may call a method.
+type rule struct {
+	// roles that may call the method; empty allows anyone, even anonymous callers.
+	roles []string
+	// own limits customers to their own account. Trading staff may act on any account.
+	own bool
+}
+
+var (
+	public   = rule{}
+	customer = rule{roles: []string{RoleCustomer, RoleTrading}, own: true}
+	trading  = rule{roles: []string{RoleTrading}}
+)
+
+// policy covers every method the gateway proxies. Methods not listed are refused.
+var policy = map[string]rule{
+	racing.Racing_ListRaces_FullMethodName:  public,
+	racing.Racing_GetRace_FullMethodName:    public,
+	sports.Sports_ListEvents_FullMethodName: public,
+
+	betting.Betting_PlaceBet_FullMethodName:   customer,
+	betting.Betting_GetBet_FullMethodName:     customer,
+	betting.Betting_SettleRace_FullMethodName: trading,
+
+	accounts.Accounts_CreateAccount_FullMethodName:      trading,
+	accounts.Accounts_GetBalance_FullMethodName:         customer,
+	accounts.Accounts_ListTransactions_FullMethodName:   customer,
+	accounts.Accounts_SetLimit_FullMethodName:           customer,
+	accounts.Accounts_GetControls_FullMethodName:        customer,
+	accounts.Accounts_StartCoolOff_FullMethodName:       customer,
+	accounts.Accounts_SelfExclude_FullMethodName:        customer,
+	accounts.Accounts_ListControlChanges_FullMethodName: customer,
+}
+
+// UnaryClientInterceptor authorizes the gateway's backend calls against the
+// identity on the context, and forwards that identity as metadata. Callers
+// without the trading role never see hidden races or events.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		id := FromContext(ctx)
+
+		r, ok := policy[method]
+		if !ok {
+			return status.Errorf(codes.PermissionDenied, "%s is not available through the API", method)
+		}
+		if len(r.roles) > 0 {
+			if id == nil {
+				return status.Error(codes.Unauthenticated, "authentication required")
+			}
+			if !id.HasRole(r.roles...) {
+				return status.Errorf(codes.PermissionDenied, "requires one of roles %s", strings.Join(r.roles, ", "))
+			}
+		}
+
+		trader := id.HasRole(RoleTrading)
+		if r.own && !trader {
+			if accountID, ok := requestAccountID(req); ok && accountID != id.AccountID {
+				return status.Error(codes.PermissionDenied, "account belongs to another customer")
+			}
+		}
+		if !trader {
+			hideHidden(req)
+		}
+
+		if err := invoker(forward(ctx, id), method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+
+		if !trader {
+			return checkReply(reply, id)
+		}
+
+		return nil
+	}
+}
+
+// forward replaces any identity metadata on ctx with the verified identity.
+func forward(ctx context.Context, id *Identity) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+
+	for _, key := range []string{MetadataSubject, MetadataRoles, MetadataMethod, MetadataAccountID} {
+		md.Delete(key)
+	}
+	if id != nil {
+		md.Set(MetadataSubject, id.Subject)
+		md.Set(MetadataRoles, strings.Join(id.Roles, ","))
+		md.Set(MetadataMethod, id.Method)
+		if id.AccountID != 0 {
+			md.Set(MetadataAccountID, strconv.FormatInt(id.AccountID, 10))
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// requestAccountID returns the account a request acts on, if it names one.
+func requestAccountID(req any) (int64, bool) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return 0, false
+	}
+
+	fd := msg.ProtoReflect().Descriptor().Fields().ByName("account_id")
+	if fd == nil || fd.Kind() != protoreflect.Int64Kind {
+		return 0, false
+	}
+
+	return msg.ProtoReflect().Get(fd).Int(), true
+}
+
+// hideHidden restricts list requests to visible races and events.
+func hideHidden(req any) {
+	hidden := false
+
+	switch in := req.(type) {
+	case *racing.ListRacesRequest:
+		if in.Filter == nil {
+			in.Filter = &racing.ListRacesRequestFilter{}
+		}
+		in.Filter.ShowHidden = &hidden
+	case *sports.ListEventsRequest:
+		if in.Filter == nil {
+			in.Filter = &sports.ListEventsRequestFilter{}
+		}
+		in.Filter.ShowHidden = &hidden
+	}
+}
+
+// checkReply hides replies the caller may not see, reporting them as not
+// found so their existence isn't revealed.
+func checkReply(reply any, id *Identity) error {
+	switch out := reply.(type) {
+	case *racing.GetRaceResponse:
+		if out.Race != nil && !out.Race.Visible {
+			return status.Error(codes.NotFound, "race not found")
+		}
+	case *betting.GetBetResponse:
+		if out.Bet != nil && (id == nil || out.Bet.AccountId != id.AccountID) {
+			return status.Error(codes.NotFound, "bet not found")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+var (
+	punter  = &Identity{Subject: "punter-3", Roles: []string{RoleCustomer}, Method: MethodJWT, AccountID: 3}
+	partner = &Identity{Subject: "acme", Roles: []string{RolePartner}, Method: MethodAPIKey}
+	trader  = &Identity{Subject: "trader-1", Roles: []string{RoleTrading}, Method: MethodJWT}
+	admin   = &Identity{Subject: "root", Roles: []string{RoleAdmin}, Method: MethodJWT}
+)
+
+// call runs the interceptor for method, capturing the outgoing metadata and
+// filling reply with fill.
+func call(id *Identity, method string, req, reply any, fill func(reply any)) (metadata.MD, error) {
+	ctx := context.Background()
+	if id != nil {
+		ctx = NewContext(ctx, id)
+	}
+	// A caller trying to claim roles through gateway metadata headers.
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(MetadataRoles, RoleTrading))
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		if fill != nil {
+			fill(reply)
+		}
+		return nil
+	}
+
+	err := UnaryClientInterceptor()(ctx, method, req, reply, nil, invoker)
+	return sent, err
+}
+
+func TestUnaryClientInterceptor_Authorization(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       *Identity
+		method   string
+		req      any
+		wantCode codes.Code
+	}{
+		{name: "anonymous lists races", method: racing.Racing_ListRaces_FullMethodName, req: &racing.ListRacesRequest{}},
+		{name: "anonymous can't bet", method: betting.Betting_PlaceBet_FullMethodName, req: &betting.PlaceBetRequest{AccountId: 3}, wantCode: codes.Unauthenticated},
+		{name: "partner can't bet", id: partner, method: betting.Betting_PlaceBet_FullMethodName, req: &betting.PlaceBetRequest{AccountId: 3}, wantCode: codes.PermissionDenied},
+		{name: "customer bets on own account", id: punter, method: betting.Betting_PlaceBet_FullMethodName, req: &betting.PlaceBetRequest{AccountId: 3}},
+		{name: "customer can't bet on another account", id: punter, method: betting.Betting_PlaceBet_FullMethodName, req: &betting.PlaceBetRequest{AccountId: 4}, wantCode: codes.PermissionDenied},
+		{name: "customer can't read another balance", id: punter, method: accounts.Accounts_GetBalance_FullMethodName, req: &accounts.GetBalanceRequest{AccountId: 4}, wantCode: codes.PermissionDenied},
+		{name: "customer can't open an account", id: punter, method: accounts.Accounts_CreateAccount_FullMethodName, req: &accounts.CreateAccountRequest{Name: "x"}, wantCode: codes.PermissionDenied},
+		{name: "partner can't open an account", id: partner, method: accounts.Accounts_CreateAccount_FullMethodName, req: &accounts.CreateAccountRequest{Name: "x"}, wantCode: codes.PermissionDenied},
+		{name: "customer can't settle", id: punter, method: betting.Betting_SettleRace_FullMethodName, req: &betting.SettleRaceRequest{}, wantCode: codes.PermissionDenied},
+		{name: "trader settles", id: trader, method: betting.Betting_SettleRace_FullMethodName, req: &betting.SettleRaceRequest{}},
+		{name: "trader reads any balance", id: trader, method: accounts.Accounts_GetBalance_FullMethodName, req: &accounts.GetBalanceRequest{AccountId: 4}},
+		{name: "trader opens an account", id: trader, method: accounts.Accounts_CreateAccount_FullMethodName, req: &accounts.CreateAccountRequest{Name: "x"}},
+		{name: "admin settles", id: admin, method: betting.Betting_SettleRace_FullMethodName, req: &betting.SettleRaceRequest{}},
+		{name: "unlisted method refused", id: admin, method: accounts.Accounts_Credit_FullMethodName, req: &accounts.CreditRequest{}, wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := call(tt.id, tt.method, tt.req, nil, nil)
+			require.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestUnaryClientInterceptor_HidesHidden(t *testing.T) {
+	showHidden := true
+
+	for _, id := range []*Identity{nil, punter, partner} {
+		req := &racing.ListRacesRequest{Filter: &racing.ListRacesRequestFilter{ShowHidden: &showHidden}}
+		_, err := call(id, racing.Racing_ListRaces_FullMethodName, req, nil, nil)
+		require.NoError(t, err)
+		require.False(t, req.Filter.GetShowHidden())
+
+		// Unset show_hidden includes hidden events, so it must be forced off too.
+		events := &sports.ListEventsRequest{}
+		_, err = call(id, sports.Sports_ListEvents_FullMethodName, events, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, events.Filter.ShowHidden)
+		require.False(t, *events.Filter.ShowHidden)
+
+		_, err = call(id, racing.Racing_GetRace_FullMethodName, &racing.GetRaceRequest{Id: 1}, &racing.GetRaceResponse{}, func(reply any) {
+			reply.(*racing.GetRaceResponse).Race = &racing.Race{Id: 1, Visible: false}
+		})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	req := &racing.ListRacesRequest{Filter: &racing.ListRacesRequestFilter{ShowHidden: &showHidden}}
+	_, err := call(trader, racing.Racing_ListRaces_FullMethodName, req, nil, nil)
+	require.NoError(t, err)
+	require.True(t, req.Filter.GetShowHidden())
+
+	_, err = call(trader, racing.Racing_GetRace_FullMethodName, &racing.GetRaceRequest{Id: 1}, &racing.GetRaceResponse{}, func(reply any) {
+		reply.(*racing.GetRaceResponse).Race = &racing.Race{Id: 1, Visible: false}
+	})
+	require.NoError(t, err)
+}
+
+func TestUnaryClientInterceptor_GetBetOwnership(t *testing.T) {
+	fill := func(reply any) {
+		reply.(*betting.GetBetResponse).Bet = &betting.Bet{Id: 1, AccountId: 4}
+	}
+
+	_, err := call(punter, betting.Betting_GetBet_FullMethodName, &betting.GetBetRequest{Id: 1}, &betting.GetBetResponse{}, fill)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = call(trader, betting.Betting_GetBet_FullMethodName, &betting.GetBetRequest{Id: 1}, &betting.GetBetResponse{}, fill)
+	require.NoError(t, err)
+}
+
+func TestUnaryClientInterceptor_ForwardsIdentity(t *testing.T) {
+	md, err := call(punter, betting.Betting_PlaceBet_FullMethodName, &betting.PlaceBetRequest{AccountId: 3}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"punter-3"}, md.Get(MetadataSubject))
+	require.Equal(t, []string{RoleCustomer}, md.Get(MetadataRoles))
+	require.Equal(t, []string{MethodJWT}, md.Get(MetadataMethod))
+	require.Equal(t, []string{"3"}, md.Get(MetadataAccountID))
+
+	md, err = call(nil, racing.Racing_ListRaces_FullMethodName, &racing.ListRacesRequest{}, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, md.Get(MetadataRoles))
+	require.Empty(t, md.Get(MetadataSubject))
+}
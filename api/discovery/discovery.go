@@ -0,0 +1,238 @@
+// Package discovery finds the replicas of each backend service, so the
+// gateway can balance its calls across them.
+//
+// A backend endpoint is one of:
+//
+//   - host:port, resolved through DNS, as before;
+//   - host:port,host:port,..., a fixed list of replicas;
+//   - dns:///host:port, every address DNS has for host, looked up again
+//     when a connection fails;
+//   - file:///path, a file listing a replica's host:port per line, read
+//     again whenever it changes, so replicas come and go without a restart.
+//
+// Each replica is verified over TLS against its own host name.
+package discovery
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+
+	"git.neds.sh/matty/entain/pkg/config"
+)
+
+// Schemes of the endpoints this package resolves itself.
+const (
+	SchemeStatic = "static"
+	SchemeFile   = "file"
+)
+
+// Flags are the command line options for finding backends.
+type Flags struct {
+	Interval time.Duration
+}
+
+// RegisterFlags registers -backend-file-interval on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.DurationVar(&f.Interval, "backend-file-interval", 5*time.Second, "How often to check file:// backend lists for changes")
+
+	return &f
+}
+
+// Validate checks the interval is positive.
+func (f *Flags) Validate() error {
+	if f.Interval <= 0 {
+		return fmt.Errorf("-backend-file-interval must be positive, not %s", f.Interval)
+	}
+	return nil
+}
+
+// Endpoint checks that the flag name holds a backend endpoint this package
+// can resolve.
+func Endpoint(name string, endpoint *string) config.Validator {
+	return config.Check(func() error {
+		switch {
+		case *endpoint == "":
+			return fmt.Errorf("-%s is required", name)
+		case strings.HasPrefix(*endpoint, SchemeFile+"://"):
+			if _, err := readAddrs(strings.TrimPrefix(*endpoint, SchemeFile+"://")); err != nil {
+				return fmt.Errorf("-%s: %w", name, err)
+			}
+		case strings.HasPrefix(*endpoint, "dns:"):
+		default:
+			if _, err := parseAddrs(strings.Split(*endpoint, ",")); err != nil {
+				return fmt.Errorf("-%s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Dial returns the target and options to dial endpoint with. Endpoints
+// grpc resolves itself come back as they are.
+func (f *Flags) Dial(endpoint string) (string, []grpc.DialOption, error) {
+	switch {
+	case strings.HasPrefix(endpoint, SchemeFile+"://"):
+		b := &builder{scheme: SchemeFile, path: strings.TrimPrefix(endpoint, SchemeFile+"://"), interval: f.Interval}
+		return SchemeFile + ":///backends", []grpc.DialOption{grpc.WithResolvers(b)}, nil
+	case strings.Contains(endpoint, ","):
+		addrs, err := parseAddrs(strings.Split(endpoint, ","))
+		if err != nil {
+			return "", nil, err
+		}
+		b := &builder{scheme: SchemeStatic, addrs: addrs}
+		return SchemeStatic + ":///backends", []grpc.DialOption{grpc.WithResolvers(b)}, nil
+	default:
+		return endpoint, nil, nil
+	}
+}
+
+// parseAddrs checks each of lines is a host:port, skipping blank lines and
+// # comments.
+func parseAddrs(lines []string) ([]resolver.Address, error) {
+	var addrs []resolver.Address
+	for _, line := range lines {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		host, _, err := net.SplitHostPort(line)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, resolver.Address{Addr: line, ServerName: host})
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no backend addresses")
+	}
+	return addrs, nil
+}
+
+// readAddrs reads the addresses listed in the file at path.
+func readAddrs(path string) ([]resolver.Address, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := parseAddrs(strings.Split(string(data), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return addrs, nil
+}
+
+// builder builds resolvers for a fixed list of addresses, or for a file
+// listing them.
+type builder struct {
+	scheme   string
+	addrs    []resolver.Address
+	path     string
+	interval time.Duration
+}
+
+func (b *builder) Scheme() string {
+	return b.scheme
+}
+
+func (b *builder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if b.path == "" {
+		return staticResolver{}, cc.UpdateState(state(b.addrs))
+	}
+
+	r := &fileResolver{path: b.path, cc: cc, reload: make(chan struct{}, 1), done: make(chan struct{})}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	go r.watch(b.interval)
+	return r, nil
+}
+
+// state lists each address as an endpoint of its own, one replica.
+func state(addrs []resolver.Address) resolver.State {
+	s := resolver.State{Addresses: addrs}
+	for _, addr := range addrs {
+		s.Endpoints = append(s.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{addr}})
+	}
+	return s
+}
+
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+func (staticResolver) Close()                                {}
+
+// fileResolver reads the addresses in a file, and again whenever the file
+// changes. A file that can't be read or lists nothing keeps the addresses
+// from before.
+type fileResolver struct {
+	path   string
+	cc     resolver.ClientConn
+	reload chan struct{}
+	done   chan struct{}
+	close  sync.Once
+
+	modTime time.Time
+	size    int64
+}
+
+// load reads the file if it has changed.
+func (r *fileResolver) load() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return nil
+	}
+
+	addrs, err := readAddrs(r.path)
+	if err != nil {
+		return err
+	}
+
+	r.modTime, r.size = info.ModTime(), info.Size()
+	slog.Info("backend list loaded", "file", r.path, "backends", len(addrs))
+	return r.cc.UpdateState(state(addrs))
+}
+
+func (r *fileResolver) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.reload:
+		}
+
+		if err := r.load(); err != nil {
+			slog.Warn("failed reloading backend list; keeping the previous one", "file", r.path, "error", err)
+		}
+	}
+}
+
+// ResolveNow checks the file for changes, as gRPC asks when a connection
+// fails.
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.reload <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fileResolver) Close() {
+	r.close.Do(func() { close(r.done) })
+}
@@ -0,0 +1,157 @@
+package discovery
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"git.neds.sh/matty/entain/api/resilience"
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+func TestEndpoint(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "racing")
+	require.NoError(t, os.WriteFile(file, []byte("# racing replicas\nracing-1:9000\n\nracing-2:9000 # spare\n"), 0o600))
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("# none yet\n"), 0o600))
+
+	tests := []struct {
+		endpoint string
+		wantErr  string
+	}{
+		{endpoint: "localhost:9000"},
+		{endpoint: "racing-1:9000,racing-2:9000"},
+		{endpoint: "dns:///racing.internal:9000"},
+		{endpoint: "file://" + file},
+		{endpoint: "", wantErr: "-racing-grpc-endpoint is required"},
+		{endpoint: "racing-1:9000,racing-2", wantErr: "missing port"},
+		{endpoint: "file://" + empty, wantErr: "no backend addresses"},
+		{endpoint: "file:///nonexistent", wantErr: "no such file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			err := Endpoint("racing-grpc-endpoint", &tt.endpoint).Validate()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// replica is a racing server counting the calls it gets, with a health
+// server reporting it serving.
+type replica struct {
+	racing.UnimplementedRacingServer
+	addr   string
+	calls  atomic.Int32
+	health *grpchealth.Server
+}
+
+func (r *replica) ListRaces(context.Context, *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
+	r.calls.Add(1)
+	return &racing.ListRacesResponse{}, nil
+}
+
+func newReplica(t *testing.T) *replica {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := &replica{addr: lis.Addr().String(), health: grpchealth.NewServer()}
+	r.health.SetServingStatus(racing.Racing_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer()
+	racing.RegisterRacingServer(server, r)
+	healthpb.RegisterHealthServer(server, r.health)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return r
+}
+
+// client dials endpoint as the gateway would, balancing round robin.
+func client(t *testing.T, flags *Flags, endpoint string) racing.RacingClient {
+	t.Helper()
+
+	balancing := resilience.RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError))
+	target, opts, err := flags.Dial(endpoint)
+	require.NoError(t, err)
+	opts = append(opts, balancing.DialOptions("racing", racing.Racing_ServiceDesc.ServiceName)...)
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	conn, err := grpc.NewClient(target, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return racing.NewRacingClient(conn)
+}
+
+// callsTo makes n calls, and reports how many each replica got.
+func callsTo(t *testing.T, c racing.RacingClient, n int, replicas ...*replica) []int32 {
+	t.Helper()
+
+	before := make([]int32, len(replicas))
+	for i, r := range replicas {
+		before[i] = r.calls.Load()
+	}
+	for range n {
+		_, err := c.ListRaces(context.Background(), &racing.ListRacesRequest{})
+		require.NoError(t, err)
+	}
+	got := make([]int32, len(replicas))
+	for i, r := range replicas {
+		got[i] = r.calls.Load() - before[i]
+	}
+	return got
+}
+
+func TestStaticBalancing(t *testing.T) {
+	a, b := newReplica(t), newReplica(t)
+	c := client(t, &Flags{Interval: time.Second}, a.addr+","+b.addr)
+
+	// Once both are connected, calls alternate between them.
+	require.Eventually(t, func() bool {
+		got := callsTo(t, c, 4, a, b)
+		return got[0] == 2 && got[1] == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A replica whose health check fails gets no more calls.
+	b.health.SetServingStatus(racing.Racing_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	require.Eventually(t, func() bool {
+		got := callsTo(t, c, 4, a, b)
+		return got[1] == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFileResolver(t *testing.T) {
+	a, b := newReplica(t), newReplica(t)
+	file := filepath.Join(t.TempDir(), "racing")
+	require.NoError(t, os.WriteFile(file, []byte(a.addr+"\n"), 0o600))
+
+	c := client(t, &Flags{Interval: 10 * time.Millisecond}, "file://"+file)
+	require.Equal(t, []int32{4, 0}, callsTo(t, c, 4, a, b))
+
+	// Replacing the list moves calls to the replicas it names.
+	require.NoError(t, os.WriteFile(file, []byte("# moved\n"+b.addr+"\n"), 0o600))
+	require.Eventually(t, func() bool {
+		got := callsTo(t, c, 4, a, b)
+		return got[0] == 0 && got[1] == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A list that's emptied by mistake keeps the replicas from before.
+	require.NoError(t, os.WriteFile(file, []byte(strings.Repeat("#", 40)+"\n"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, []int32{0, 4}, callsTo(t, c, 4, a, b))
+}
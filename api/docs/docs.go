@@ -0,0 +1,75 @@
+// Package docs serves the gateway's API documentation: the OpenAPI spec
+// generated from the protos, and a page that renders it. The page's scripts
+// and styles are embedded and served with it, so it runs no third-party code
+// and works without internet access.
+package docs
+
+import (
+	"embed"
+	"net/http"
+
+	"git.neds.sh/matty/entain/proto"
+)
+
+// Paths the documentation is served on.
+const (
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs"
+)
+
+// contentSecurityPolicy lets the page load only what the gateway serves, and
+// only be framed by no one, as it handles the reader's credentials.
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; form-action 'none'; frame-ancestors 'none'; base-uri 'none'"
+
+//go:embed index.html docs.js docs.css
+var ui embed.FS
+
+// assets are the files of the page, by the path each is served on.
+var assets = map[string]struct {
+	file        string
+	contentType string
+}{
+	UIPath:               {"index.html", "text/html; charset=utf-8"},
+	UIPath + "/docs.js":  {"docs.js", "text/javascript; charset=utf-8"},
+	UIPath + "/docs.css": {"docs.css", "text/css; charset=utf-8"},
+}
+
+// Handler serves the spec on SpecPath and the page rendering it on UIPath,
+// and passes every other request to next. The documentation is public, so
+// it's answered before next authenticates anything.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == SpecPath {
+			serve(w, r, "application/json", proto.OpenAPI)
+			return
+		}
+		asset, ok := assets[r.URL.Path]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ui.ReadFile(asset.file)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		serve(w, r, asset.contentType, body)
+	})
+}
+
+func serve(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
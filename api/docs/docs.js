@@ -0,0 +1,177 @@
+// Renders the gateway's OpenAPI v2 spec, and lets readers try each route with
+// the credentials they enter. Everything is served by the gateway itself, so
+// the page runs no third-party code.
+"use strict";
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    node.setAttribute(name, value);
+  }
+  for (const child of children) {
+    node.append(child);
+  }
+  return node;
+}
+
+// resolve follows a schema's $ref into the spec's definitions.
+function resolve(spec, schema) {
+  while (schema && schema.$ref) {
+    schema = spec.definitions[schema.$ref.replace("#/definitions/", "")];
+  }
+  return schema || {};
+}
+
+// example builds a value of schema, as the gateway writes it in JSON.
+function example(spec, schema, depth) {
+  schema = resolve(spec, schema);
+  if (depth > 6) {
+    return null;
+  }
+  if (schema.enum) {
+    return schema.enum[0];
+  }
+  switch (schema.type) {
+    case "array":
+      return [example(spec, schema.items, depth + 1)];
+    case "string":
+      if (schema.format === "int64" || schema.format === "uint64") {
+        return "0";
+      }
+      return schema.format === "date-time" ? new Date().toISOString() : "";
+    case "integer":
+    case "number":
+      return 0;
+    case "boolean":
+      return false;
+  }
+  const value = {};
+  for (const [name, property] of Object.entries(schema.properties || {})) {
+    value[name] = example(spec, property, depth + 1);
+  }
+  return value;
+}
+
+function json(value) {
+  return JSON.stringify(value, null, 2);
+}
+
+function parametersTable(params) {
+  const rows = params.map((p) =>
+    el("tr", {}, el("td", {}, el("code", {}, p.name)), el("td", {}, p.in), el("td", {}, p.type || "object"), el("td", {}, p.description || "")),
+  );
+  return el("table", {}, el("tr", {}, el("th", {}, "Name"), el("th", {}, "In"), el("th", {}, "Type"), el("th", {}, "Description")), ...rows);
+}
+
+// tryIt returns a form that sends the operation with the reader's values and
+// credentials, and shows the answer.
+function tryIt(spec, verb, path, op) {
+  const params = op.parameters || [];
+  const form = el("form", {});
+  const inputs = {};
+  for (const p of params.filter((p) => p.in === "path" || p.in === "query")) {
+    inputs[p.name] = el("input", { name: p.name, placeholder: p.in });
+    form.append(el("label", {}, p.name + " ", inputs[p.name]), " ");
+  }
+  const bodyParam = params.find((p) => p.in === "body");
+  let body;
+  if (bodyParam) {
+    body = el("textarea", { name: "body" });
+    body.value = json(example(spec, bodyParam.schema, 0));
+    form.append(body);
+  }
+  const output = el("pre", {});
+  form.append(el("button", { type: "submit" }, "Send"), output);
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    let url = path;
+    const query = new URLSearchParams();
+    for (const p of params) {
+      const value = inputs[p.name] && inputs[p.name].value;
+      if (!value) {
+        continue;
+      }
+      if (p.in === "path") {
+        url = url.replace(new RegExp("\\{" + p.name + "(=[^}]*)?\\}"), encodeURIComponent(value));
+      } else {
+        query.append(p.name, value);
+      }
+    }
+    if ([...query].length > 0) {
+      url += "?" + query;
+    }
+
+    const credentials = new FormData(document.getElementById("credentials"));
+    const headers = { "Content-Type": "application/json" };
+    if (credentials.get("token")) {
+      headers["Authorization"] = "Bearer " + credentials.get("token");
+    }
+    if (credentials.get("apiKey")) {
+      headers["X-API-Key"] = credentials.get("apiKey");
+    }
+
+    output.textContent = "…";
+    try {
+      const resp = await fetch(url, { method: verb.toUpperCase(), headers, body: body ? body.value : undefined });
+      const text = await resp.text();
+      let pretty = text;
+      try {
+        pretty = json(JSON.parse(text));
+      } catch (e) {
+        // Not JSON; show it as it came.
+      }
+      output.textContent = resp.status + " " + resp.statusText + "\n\n" + pretty;
+    } catch (e) {
+      output.textContent = String(e);
+    }
+  });
+  return form;
+}
+
+function operation(spec, verb, path, op) {
+  const body = el("div", { class: "operation" });
+  if (op.description) {
+    body.append(el("p", {}, op.description));
+  }
+  if ((op.parameters || []).length > 0) {
+    body.append(el("h4", {}, "Parameters"), parametersTable(op.parameters));
+  }
+  const ok = op.responses && op.responses["200"];
+  if (ok && ok.schema) {
+    body.append(el("h4", {}, "Response"), el("pre", {}, json(example(spec, ok.schema, 0))));
+  }
+  body.append(el("h4", {}, "Try it"), tryIt(spec, verb, path, op));
+
+  return el("details", {}, el("summary", {}, el("span", { class: "method " + verb }, verb), el("code", {}, path), " ", op.summary || ""), body);
+}
+
+async function render() {
+  const docs = document.getElementById("docs");
+  let spec;
+  try {
+    const resp = await fetch(docs.dataset.spec);
+    spec = await resp.json();
+  } catch (e) {
+    docs.append(el("p", { class: "error" }, "Failed to load the spec: " + e));
+    return;
+  }
+
+  const byTag = new Map((spec.tags || []).map((t) => [t.name, []]));
+  for (const [path, operations] of Object.entries(spec.paths)) {
+    for (const [verb, op] of Object.entries(operations)) {
+      const tag = (op.tags || ["Other"])[0];
+      if (!byTag.has(tag)) {
+        byTag.set(tag, []);
+      }
+      byTag.get(tag).push(operation(spec, verb, path, op));
+    }
+  }
+  for (const [tag, operations] of byTag) {
+    if (operations.length > 0) {
+      docs.append(el("h2", {}, tag), ...operations);
+    }
+  }
+}
+
+document.addEventListener("DOMContentLoaded", render);
@@ -0,0 +1,221 @@
+package docs
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apiproto "git.neds.sh/matty/entain/proto"
+	"git.neds.sh/matty/entain/proto/nexttogo"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// documented are the protos whose gateway routes the spec covers.
+var documented = []protoreflect.FileDescriptor{
+	racing.File_racing_racing_proto,
+	sports.File_sports_sports_proto,
+	nexttogo.File_nexttogo_nexttogo_proto,
+}
+
+// spec is the part of an OpenAPI v2 document the tests check.
+type spec struct {
+	Paths       map[string]map[string]operation `json:"paths"`
+	Definitions map[string]definition           `json:"definitions"`
+}
+
+type operation struct {
+	OperationID string `json:"operationId"`
+	Summary     string `json:"summary"`
+}
+
+type definition struct {
+	Properties map[string]struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"properties"`
+	Enum []string `json:"enum"`
+}
+
+func loadSpec(t *testing.T) spec {
+	t.Helper()
+
+	var s spec
+	require.NoError(t, json.Unmarshal(apiproto.OpenAPI, &s))
+	return s
+}
+
+// TestSpecMatchesProtos fails when the spec drifts from the protos: a route,
+// message field or enum value added, removed or renamed without running
+// go generate.
+func TestSpecMatchesProtos(t *testing.T) {
+	s := loadSpec(t)
+
+	var (
+		routes      = make(map[string]string)
+		descriptors = make(map[string]protoreflect.Descriptor)
+		referenced  []string
+	)
+	for _, file := range documented {
+		pkg := string(file.Package())
+		collect(descriptors, pkg, file.Messages(), file.Enums())
+
+		services := file.Services()
+		for i := range services.Len() {
+			methods := services.Get(i).Methods()
+			for j := range methods.Len() {
+				method := methods.Get(j)
+				rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+				require.True(t, ok && rule != nil, "%s has no HTTP route", method.FullName())
+
+				verb, path := route(rule)
+				routes[verb+" "+path] = string(services.Get(i).Name()) + "_" + string(method.Name())
+
+				referenced = append(referenced, pkg+string(method.Output().Name()))
+				if rule.GetBody() == "*" {
+					referenced = append(referenced, pkg+string(method.Input().Name()))
+				}
+			}
+		}
+	}
+
+	got := make(map[string]string)
+	for path, operations := range s.Paths {
+		for verb, op := range operations {
+			got[verb+" "+path] = op.OperationID
+			require.NotEmpty(t, op.Summary, "%s %s has no summary", verb, path)
+		}
+	}
+	require.Equal(t, routes, got)
+
+	for _, name := range referenced {
+		require.Contains(t, slices.Collect(maps.Keys(s.Definitions)), name)
+	}
+
+	for name, def := range s.Definitions {
+		if name == "googlerpcStatus" || name == "protobufAny" {
+			continue
+		}
+		desc, ok := descriptors[name]
+		require.True(t, ok, "%s is in the spec but not the protos", name)
+
+		switch desc := desc.(type) {
+		case protoreflect.EnumDescriptor:
+			var values []string
+			for i := range desc.Values().Len() {
+				values = append(values, string(desc.Values().Get(i).Name()))
+			}
+			require.Equal(t, values, def.Enum, name)
+
+		case protoreflect.MessageDescriptor:
+			var fields []string
+			for i := range desc.Fields().Len() {
+				fields = append(fields, desc.Fields().Get(i).JSONName())
+			}
+			require.ElementsMatch(t, fields, slices.Collect(maps.Keys(def.Properties)), name)
+
+			for field, property := range def.Properties {
+				require.NotEmpty(t, property.Title+property.Description, "%s.%s has no description; comment it in the proto", name, field)
+			}
+		}
+	}
+}
+
+// collect adds messages and enums, and those nested in them, to descriptors
+// under the names the spec defines them by: their package and enclosing
+// messages, as in racingRaceStatus.
+func collect(descriptors map[string]protoreflect.Descriptor, prefix string, messages protoreflect.MessageDescriptors, enums protoreflect.EnumDescriptors) {
+	for i := range enums.Len() {
+		descriptors[prefix+string(enums.Get(i).Name())] = enums.Get(i)
+	}
+	for i := range messages.Len() {
+		message := messages.Get(i)
+		name := prefix + string(message.Name())
+		descriptors[name] = message
+		collect(descriptors, name, message.Messages(), message.Enums())
+	}
+}
+
+// route returns the HTTP method and path template of rule, as the spec
+// writes them.
+func route(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "get", pattern.Get
+	case *annotations.HttpRule_Post:
+		return "post", pattern.Post
+	case *annotations.HttpRule_Put:
+		return "put", pattern.Put
+	case *annotations.HttpRule_Delete:
+		return "delete", pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return "patch", pattern.Patch
+	default:
+		return "", ""
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		method      string
+		path        string
+		wantCode    int
+		wantType    string
+		wantContent string
+	}{
+		{method: http.MethodGet, path: SpecPath, wantCode: http.StatusOK, wantType: "application/json", wantContent: `"swagger": "2.0"`},
+		{method: http.MethodGet, path: UIPath, wantCode: http.StatusOK, wantType: "text/html; charset=utf-8", wantContent: SpecPath},
+		{method: http.MethodGet, path: UIPath + "/docs.js", wantCode: http.StatusOK, wantType: "text/javascript; charset=utf-8", wantContent: "function render"},
+		{method: http.MethodGet, path: UIPath + "/docs.css", wantCode: http.StatusOK, wantType: "text/css; charset=utf-8", wantContent: ".method"},
+		{method: http.MethodPost, path: SpecPath, wantCode: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/v1/races/1", wantCode: http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantType != "" {
+				require.Equal(t, tt.wantType, rec.Header().Get("Content-Type"))
+				require.True(t, strings.Contains(rec.Body.String(), tt.wantContent))
+			}
+		})
+	}
+}
+
+// TestHandlerServesUIItself fails if the page loads anything from another
+// origin, such as a CDN.
+func TestHandlerServesUIItself(t *testing.T) {
+	handler := Handler(http.NotFoundHandler())
+
+	for path, asset := range assets {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		require.Equal(t, contentSecurityPolicy, rec.Header().Get("Content-Security-Policy"), path)
+		require.NotRegexp(t, `(src|href)="(https?:)?//`, rec.Body.String(), "%s loads %s from another origin", path, asset.file)
+
+		for _, src := range regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(rec.Body.String(), -1) {
+			if src[1] == SpecPath {
+				continue
+			}
+			_, ok := assets[src[1]]
+			require.True(t, ok, "%s refers to %s, which isn't served", path, src[1])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package feed serves the next to go feed: the open races and sports
+// events starting soonest, in one list.
+//
+// The gateway serves it itself, asking racing and sports at once and merging
+// their answers by advertised start time. When one of them fails, the feed
+// lists what the other had, and names the one missing in unavailable.
+package feed
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/proto/nexttogo"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// Limits on how many items a feed lists.
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// orderBy asks each service for its earliest races and events first.
+const orderBy = "advertised_start_time"
+
+// Server serves the feed from the racing and sports services.
+type Server struct {
+	racing racing.RacingClient
+	sports sports.SportsClient
+}
+
+// NewServer returns a Server asking r for races and s for events.
+func NewServer(r racing.RacingClient, s sports.SportsClient) *Server {
+	return &Server{racing: r, sports: s}
+}
+
+// source lists up to limit open items of one type, those starting after now.
+type source struct {
+	name     string
+	itemType nexttogo.Item_Type
+	list     func(ctx context.Context, now *timestamppb.Timestamp, limit int32) ([]*nexttogo.Item, error)
+}
+
+// ListNextToGo lists the open races and events yet to start, soonest first.
+// It fails only when every service asked fails, with the first one's error.
+func (s *Server) ListNextToGo(ctx context.Context, in *nexttogo.ListNextToGoRequest) (*nexttogo.ListNextToGoResponse, error) {
+	limit := int(in.Limit)
+	switch {
+	case limit == 0:
+		limit = DefaultLimit
+	case limit < 0 || limit > MaxLimit:
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be from 1 to %d", MaxLimit)
+	}
+
+	sources, err := s.sources(in.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each service is asked only for its soonest items still to start: no
+	// more than the feed lists in all.
+	var (
+		wg    sync.WaitGroup
+		now   = timestamppb.New(time.Now())
+		items = make([][]*nexttogo.Item, len(sources))
+		errs  = make([]error, len(sources))
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items[i], errs[i] = src.list(ctx, now, int32(limit))
+		}()
+	}
+	wg.Wait()
+
+	resp := &nexttogo.ListNextToGoResponse{}
+	for i, src := range sources {
+		if errs[i] != nil {
+			slog.WarnContext(ctx, "next to go source failed; serving the feed without it", "source", src.name, "error", errs[i])
+			resp.Unavailable = append(resp.Unavailable, src.name)
+			continue
+		}
+		resp.Items = append(resp.Items, items[i]...)
+	}
+	if len(resp.Unavailable) == len(sources) {
+		return nil, errs[0]
+	}
+
+	slices.SortStableFunc(resp.Items, func(a, b *nexttogo.Item) int {
+		return a.AdvertisedStartTime.AsTime().Compare(b.AdvertisedStartTime.AsTime())
+	})
+	if len(resp.Items) > limit {
+		resp.Items = resp.Items[:limit]
+	}
+	return resp, nil
+}
+
+// sources returns the sources of the types asked for, or of every type.
+func (s *Server) sources(types []nexttogo.Item_Type) ([]source, error) {
+	want := make(map[nexttogo.Item_Type]bool)
+	for _, t := range types {
+		if t != nexttogo.Item_TYPE_RACE && t != nexttogo.Item_TYPE_EVENT {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown item type %s", t)
+		}
+		want[t] = true
+	}
+
+	var sources []source
+	for _, src := range []source{
+		{name: "racing", itemType: nexttogo.Item_TYPE_RACE, list: s.races},
+		{name: "sports", itemType: nexttogo.Item_TYPE_EVENT, list: s.events},
+	} {
+		if len(want) == 0 || want[src.itemType] {
+			sources = append(sources, src)
+		}
+	}
+	return sources, nil
+}
+
+func (s *Server) races(ctx context.Context, now *timestamppb.Timestamp, limit int32) ([]*nexttogo.Item, error) {
+	resp, err := s.racing.ListRaces(ctx, &racing.ListRacesRequest{Filter: &racing.ListRacesRequestFilter{
+		OrderBy:     orderBy,
+		StartsAfter: now,
+		Limit:       limit,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*nexttogo.Item
+	for _, race := range resp.Races {
+		if race.Status != racing.Race_STATUS_OPEN {
+			continue
+		}
+		items = append(items, &nexttogo.Item{
+			Type:                nexttogo.Item_TYPE_RACE,
+			AdvertisedStartTime: race.AdvertisedStartTime,
+			Item:                &nexttogo.Item_Race{Race: race},
+		})
+	}
+	return items, nil
+}
+
+func (s *Server) events(ctx context.Context, now *timestamppb.Timestamp, limit int32) ([]*nexttogo.Item, error) {
+	resp, err := s.sports.ListEvents(ctx, &sports.ListEventsRequest{Filter: &sports.ListEventsRequestFilter{
+		OrderBy:     orderBy,
+		StartsAfter: now,
+		Limit:       limit,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*nexttogo.Item
+	for _, event := range resp.Events {
+		if event.Status != sports.Event_STATUS_OPEN {
+			continue
+		}
+		items = append(items, &nexttogo.Item{
+			Type:                nexttogo.Item_TYPE_EVENT,
+			AdvertisedStartTime: event.AdvertisedStartTime,
+			Item:                &nexttogo.Item_Event{Event: event},
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,187 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/proto/nexttogo"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+var start = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func at(minutes int) *timestamppb.Timestamp {
+	return timestamppb.New(start.Add(time.Duration(minutes) * time.Minute))
+}
+
+// racingClient lists races, or fails with err.
+type racingClient struct {
+	racing.RacingClient
+	races []*racing.Race
+	err   error
+}
+
+func (c racingClient) ListRaces(context.Context, *racing.ListRacesRequest, ...grpc.CallOption) (*racing.ListRacesResponse, error) {
+	return &racing.ListRacesResponse{Races: c.races}, c.err
+}
+
+// sportsClient lists events, or fails with err.
+type sportsClient struct {
+	sports.SportsClient
+	events []*sports.Event
+	err    error
+}
+
+func (c sportsClient) ListEvents(context.Context, *sports.ListEventsRequest, ...grpc.CallOption) (*sports.ListEventsResponse, error) {
+	return &sports.ListEventsResponse{Events: c.events}, c.err
+}
+
+var (
+	races = []*racing.Race{
+		{Id: 1, AdvertisedStartTime: at(-5), Status: racing.Race_STATUS_CLOSED},
+		{Id: 2, AdvertisedStartTime: at(2)},
+		{Id: 3, AdvertisedStartTime: at(10)},
+	}
+	events = []*sports.Event{
+		{Id: 7, AdvertisedStartTime: at(1)},
+		{Id: 8, AdvertisedStartTime: at(2)},
+		{Id: 9, AdvertisedStartTime: at(30)},
+	}
+)
+
+// ids describes items as race or event IDs, such as "race 2".
+func ids(items []*nexttogo.Item) []string {
+	var got []string
+	for _, item := range items {
+		switch item.Type {
+		case nexttogo.Item_TYPE_RACE:
+			got = append(got, fmt.Sprintf("race %d", item.GetRace().Id))
+		case nexttogo.Item_TYPE_EVENT:
+			got = append(got, fmt.Sprintf("event %d", item.GetEvent().Id))
+		}
+	}
+	return got
+}
+
+func TestListNextToGo(t *testing.T) {
+	server := NewServer(racingClient{races: races}, sportsClient{events: events})
+
+	resp, err := server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"event 7", "race 2", "event 8", "race 3", "event 9"}, ids(resp.Items))
+	require.Empty(t, resp.Unavailable)
+
+	resp, err = server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"event 7", "race 2"}, ids(resp.Items))
+
+	resp, err = server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{Types: []nexttogo.Item_Type{nexttogo.Item_TYPE_RACE}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"race 2", "race 3"}, ids(resp.Items))
+}
+
+// askingClients record the filters the feed asks racing and sports for.
+type askingClients struct {
+	racing.RacingClient
+	sports.SportsClient
+	races  *racing.ListRacesRequestFilter
+	events *sports.ListEventsRequestFilter
+}
+
+func (c *askingClients) ListRaces(_ context.Context, in *racing.ListRacesRequest, _ ...grpc.CallOption) (*racing.ListRacesResponse, error) {
+	c.races = in.Filter
+	return &racing.ListRacesResponse{}, nil
+}
+
+func (c *askingClients) ListEvents(_ context.Context, in *sports.ListEventsRequest, _ ...grpc.CallOption) (*sports.ListEventsResponse, error) {
+	c.events = in.Filter
+	return &sports.ListEventsResponse{}, nil
+}
+
+func TestListNextToGoAsksForUpcoming(t *testing.T) {
+	clients := &askingClients{}
+	server := NewServer(clients, clients)
+
+	before := time.Now()
+	_, err := server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{Limit: 3})
+	require.NoError(t, err)
+	after := time.Now()
+
+	// Each service lists only what's yet to start, and no more than the feed
+	// could list.
+	require.Equal(t, orderBy, clients.races.OrderBy)
+	require.EqualValues(t, 3, clients.races.Limit)
+	require.WithinRange(t, clients.races.StartsAfter.AsTime(), before, after)
+	require.Equal(t, orderBy, clients.events.OrderBy)
+	require.EqualValues(t, 3, clients.events.Limit)
+	require.WithinRange(t, clients.events.StartsAfter.AsTime(), before, after)
+
+	_, err = server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{})
+	require.NoError(t, err)
+	require.EqualValues(t, DefaultLimit, clients.races.Limit)
+	require.EqualValues(t, DefaultLimit, clients.events.Limit)
+}
+
+func TestListNextToGoPartial(t *testing.T) {
+	down := status.Error(codes.Unavailable, "sports service unavailable: circuit breaker open")
+	server := NewServer(racingClient{races: races}, sportsClient{err: down})
+
+	resp, err := server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"race 2", "race 3"}, ids(resp.Items))
+	require.Equal(t, []string{"sports"}, resp.Unavailable)
+
+	// Only racing is asked for races, so sports failing doesn't matter.
+	resp, err = server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{Types: []nexttogo.Item_Type{nexttogo.Item_TYPE_RACE}})
+	require.NoError(t, err)
+	require.Empty(t, resp.Unavailable)
+
+	// With nothing to list, the feed fails as the services did.
+	server = NewServer(racingClient{err: status.Error(codes.ResourceExhausted, "rate limit exceeded")}, sportsClient{err: down})
+	_, err = server.ListNextToGo(context.Background(), &nexttogo.ListNextToGoRequest{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestListNextToGoInvalid(t *testing.T) {
+	server := NewServer(racingClient{}, sportsClient{})
+
+	for _, in := range []*nexttogo.ListNextToGoRequest{
+		{Limit: -1},
+		{Limit: MaxLimit + 1},
+		{Types: []nexttogo.Item_Type{nexttogo.Item_TYPE_UNSPECIFIED}},
+	} {
+		_, err := server.ListNextToGo(context.Background(), in)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), in)
+	}
+}
+
+func TestGateway(t *testing.T) {
+	mux := runtime.NewServeMux()
+	server := NewServer(racingClient{races: races}, sportsClient{err: status.Error(codes.Unavailable, "down")})
+	require.NoError(t, nexttogo.RegisterNextToGoHandlerServer(context.Background(), mux, server))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/next-to-go?limit=1&types=TYPE_RACE&types=TYPE_EVENT", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{
+		"items": [{
+			"type": "TYPE_RACE",
+			"advertisedStartTime": "2026-10-19T12:02:00Z",
+			"race": {"id": "2", "meetingId": "0", "name": "", "number": "0", "visible": false,
+				"advertisedStartTime": "2026-10-19T12:02:00Z", "status": "STATUS_OPEN", "runners": []}
+		}],
+		"unavailable": ["sports"]
+	}`, rec.Body.String())
+}
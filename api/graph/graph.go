@@ -0,0 +1,186 @@
+// Package graph serves a GraphQL API over racing and sports, so a client can
+// fetch a race, its meeting and runners, and upcoming sports events in one
+// round trip.
+//
+// Fields resolve against the gateway's gRPC clients, so calls are
+// authorized, rate limited and traced like any other. The backend calls a
+// query needs are batched and deduplicated: the meetings of many races are
+// listed in one ListRaces call, every event looked up by ID in one
+// ListEvents call, and each race is fetched once however often it appears.
+// Queries are limited in depth, and in how many backend calls they make.
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// Path is where the API is served.
+const Path = "/graphql"
+
+// maxBodySize is the largest request accepted, which bounds how many fields
+// and aliases a query can ask for.
+const maxBodySize = 64 << 10
+
+//go:embed schema.graphql
+var schema string
+
+// Flags are the command line options limiting queries.
+type Flags struct {
+	MaxDepth int
+	MaxCalls int
+}
+
+// RegisterFlags registers -graphql-max-depth and -graphql-max-calls on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.IntVar(&f.MaxDepth, "graphql-max-depth", 6, "Deepest a GraphQL query may nest fields")
+	fs.IntVar(&f.MaxCalls, "graphql-max-calls", 20, "Most backend calls one GraphQL query may make")
+
+	return &f
+}
+
+// Validate checks the limits are positive.
+func (f *Flags) Validate() error {
+	var errs []error
+	if f.MaxDepth <= 0 {
+		errs = append(errs, fmt.Errorf("-graphql-max-depth must be positive, not %d", f.MaxDepth))
+	}
+	if f.MaxCalls <= 0 {
+		errs = append(errs, fmt.Errorf("-graphql-max-calls must be positive, not %d", f.MaxCalls))
+	}
+	return errors.Join(errs...)
+}
+
+// Server answers GraphQL queries from the racing and sports services.
+type Server struct {
+	schema   *graphql.Schema
+	racing   racing.RacingClient
+	sports   sports.SportsClient
+	maxCalls int
+}
+
+// New returns a Server asking r for races and s for events, within the
+// limits the flags set.
+func (f *Flags) New(r racing.RacingClient, s sports.SportsClient) (*Server, error) {
+	server := &Server{racing: r, sports: s, maxCalls: f.MaxCalls}
+
+	var err error
+	server.schema, err = graphql.ParseSchema(schema, &query{server},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(f.MaxDepth),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// request is a GraphQL request, sent as JSON in a POST body or as the query
+// parameters of a GET.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler serves the API on Path, and passes every other request to next.
+// It belongs beneath the authentication and rate limiting handlers, whose
+// work the backend calls rely on.
+func (s *Server) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != Path {
+			next.ServeHTTP(w, r)
+			return
+		}
+		metrics.SetRoute(r.Context(), Path)
+		tracing.SetRoute(r.Context(), r.Method, Path)
+
+		var req request
+		switch r.Method {
+		case http.MethodGet:
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+			if v := r.URL.Query().Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					http.Error(w, "variables must be a JSON object", http.StatusBadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+				http.Error(w, "request must be a JSON object of query, operationName and variables", http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if len(req.Query) > maxBodySize {
+			http.Error(w, "query too long", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		ctx := s.newLoaders(r.Context())
+		resp := s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// budget counts the backend calls a query makes.
+type budget struct {
+	max   int
+	calls atomic.Int32
+}
+
+// spend counts a backend call, and fails once the query has made too many.
+func (b *budget) spend() error {
+	if int(b.calls.Add(1)) > b.max {
+		return status.Errorf(codes.ResourceExhausted, "query too complex: it needs more than %d backend calls", b.max)
+	}
+	return nil
+}
+
+// queryError reports a backend call's failure with its gRPC code, such as
+// {"code": "Unavailable"}, in the GraphQL error's extensions.
+type queryError struct {
+	err error
+}
+
+func (e queryError) Error() string {
+	return status.Convert(e.err).Message()
+}
+
+func (e queryError) Unwrap() error {
+	return e.err
+}
+
+func (e queryError) Extensions() map[string]any {
+	return map[string]any{"code": status.Code(e.err).String()}
+}
+
+// wrap returns err as a queryError, or nil.
+func wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return queryError{err}
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+var start = timestamppb.New(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+
+// racingClient serves races from memory, and records the calls made.
+type racingClient struct {
+	racing.RacingClient
+	races []*racing.Race
+	err   error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *racingClient) record(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func (c *racingClient) ListRaces(_ context.Context, in *racing.ListRacesRequest, _ ...grpc.CallOption) (*racing.ListRacesResponse, error) {
+	c.record("ListRaces")
+	if c.err != nil {
+		return nil, c.err
+	}
+	var races []*racing.Race
+	for _, race := range c.races {
+		if ids := in.Filter.GetMeetingIds(); len(ids) > 0 && !slices.Contains(ids, race.MeetingId) {
+			continue
+		}
+		if ids := in.Filter.GetIds(); len(ids) > 0 && !slices.Contains(ids, race.Id) {
+			continue
+		}
+		listed := &racing.Race{Id: race.Id, MeetingId: race.MeetingId, Name: race.Name, AdvertisedStartTime: race.AdvertisedStartTime, Status: race.Status}
+		if in.Filter.GetIncludeRunners() {
+			listed.Runners = race.Runners
+		}
+		races = append(races, listed)
+	}
+	return &racing.ListRacesResponse{Races: races}, nil
+}
+
+func (c *racingClient) GetRace(_ context.Context, in *racing.GetRaceRequest, _ ...grpc.CallOption) (*racing.GetRaceResponse, error) {
+	c.record("GetRace")
+	for _, race := range c.races {
+		if race.Id == in.Id {
+			return &racing.GetRaceResponse{Race: race}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "race not found")
+}
+
+// sportsClient serves events from memory, and records the filters asked for.
+type sportsClient struct {
+	sports.SportsClient
+	events  []*sports.Event
+	filters []*sports.ListEventsRequestFilter
+}
+
+func (c *sportsClient) ListEvents(_ context.Context, in *sports.ListEventsRequest, _ ...grpc.CallOption) (*sports.ListEventsResponse, error) {
+	c.filters = append(c.filters, in.Filter)
+	var events []*sports.Event
+	for _, event := range c.events {
+		if ids := in.Filter.GetIds(); len(ids) == 0 || slices.Contains(ids, event.Id) {
+			events = append(events, event)
+		}
+	}
+	return &sports.ListEventsResponse{Events: events}, nil
+}
+
+func newRacing() *racingClient {
+	return &racingClient{races: []*racing.Race{
+		{Id: 1, MeetingId: 10, Name: "Maiden", AdvertisedStartTime: start, Runners: []*racing.Runner{{Number: 1, Name: "Phar Lap"}}},
+		{Id: 2, MeetingId: 10, Name: "Handicap", AdvertisedStartTime: start, Runners: []*racing.Runner{{Number: 1, Name: "Makybe Diva"}, {Number: 2, Name: "Winx", Scratched: true}}},
+		{Id: 3, MeetingId: 20, Name: "Cup", AdvertisedStartTime: start, Status: racing.Race_STATUS_CLOSED},
+	}}
+}
+
+func newServer(t *testing.T, r racing.RacingClient, s sports.SportsClient, maxCalls int) *Server {
+	t.Helper()
+	server, err := (&Flags{MaxDepth: 6, MaxCalls: maxCalls}).New(r, s)
+	require.NoError(t, err)
+	return server
+}
+
+// post sends query to server as a POST, and returns the response body.
+func post(t *testing.T, server *Server, query string) string {
+	t.Helper()
+	body, err := json.Marshal(request{Query: query})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	server.Handler(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestQuery(t *testing.T) {
+	server := newServer(t, newRacing(), &sportsClient{events: []*sports.Event{
+		{Id: 7, SportId: 1, Name: "Final", HomeTeam: "Swans", AwayTeam: "Lions", AdvertisedStartTime: start},
+	}}, 20)
+
+	require.JSONEq(t, `{"data": {
+		"race": {"id": "2", "name": "Handicap", "status": "OPEN", "advertisedStartTime": "2026-10-19T12:00:00Z",
+			"runners": [{"number": 1, "name": "Makybe Diva", "scratched": false}, {"number": 2, "name": "Winx", "scratched": true}]},
+		"missing": null,
+		"event": {"id": "7", "sportId": "1", "homeTeam": "Swans", "awayTeam": "Lions", "status": "OPEN"},
+		"races": [{"id": "3", "status": "CLOSED", "meeting": {"id": "20"}}]
+	}}`, post(t, server, `{
+		race(id: 2) { id name status advertisedStartTime runners { number name scratched } }
+		missing: race(id: 99) { id }
+		event(id: 7) { id sportId homeTeam awayTeam status }
+		races(meetingIds: [20]) { id status meeting { id } }
+	}`))
+}
+
+func TestQueryBatches(t *testing.T) {
+	r := newRacing()
+	s := &sportsClient{events: []*sports.Event{{Id: 7}, {Id: 8}, {Id: 9}}}
+	server := newServer(t, r, s, 20)
+
+	// The same list twice is fetched once, both meetings' races come from one
+	// ListRaces, and every race's runners from one more.
+	body := post(t, server, `{
+		a: races { id meeting { races { id } } }
+		b: races { id runners { name } }
+		x: event(id: 7) { id }
+		y: event(id: 8) { id }
+	}`)
+	require.NotContains(t, body, "errors")
+	slices.Sort(r.calls)
+	require.Equal(t, []string{"ListRaces", "ListRaces", "ListRaces"}, r.calls)
+
+	// Both events come from one ListEvents, asking for just them, hidden or
+	// not.
+	require.Len(t, s.filters, 1)
+	require.ElementsMatch(t, []int64{7, 8}, s.filters[0].Ids)
+	require.True(t, s.filters[0].GetShowHidden())
+}
+
+func TestQueryRunnersBatched(t *testing.T) {
+	r := &racingClient{}
+	for id := range int64(30) {
+		r.races = append(r.races, &racing.Race{Id: id + 1, MeetingId: 10, AdvertisedStartTime: start, Runners: []*racing.Runner{{Number: 1, Name: "Phar Lap"}}})
+	}
+	server := newServer(t, r, &sportsClient{}, 20)
+
+	// Thirty races' runners fit in the default budget: one call lists the
+	// races, and one more their runners.
+	var resp struct {
+		Data struct {
+			Races []struct {
+				ID      string
+				Runners []struct{ Name string }
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	require.NoError(t, json.Unmarshal([]byte(post(t, server, `{ races { id runners { name } } }`)), &resp))
+	require.Empty(t, resp.Errors)
+	require.Len(t, resp.Data.Races, 30)
+	for _, race := range resp.Data.Races {
+		require.Equal(t, []struct{ Name string }{{Name: "Phar Lap"}}, race.Runners)
+	}
+	require.Equal(t, []string{"ListRaces", "ListRaces"}, r.calls)
+}
+
+func TestQueryLimits(t *testing.T) {
+	r := newRacing()
+	server := newServer(t, r, &sportsClient{}, 2)
+
+	// Listing the races, their runners and their meetings' races needs three
+	// ListRaces calls, past the budget of two.
+	var resp struct {
+		Errors []struct {
+			Message    string
+			Extensions map[string]string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(post(t, server, `{ races { runners { name } meeting { races { id } } } }`)), &resp))
+	require.NotEmpty(t, resp.Errors)
+	require.Equal(t, "query too complex: it needs more than 2 backend calls", resp.Errors[0].Message)
+	require.Equal(t, map[string]string{"code": "ResourceExhausted"}, resp.Errors[0].Extensions)
+
+	body := post(t, server, `{ races { meeting { races { meeting { races { meeting { id } } } } } } }`)
+	require.Contains(t, body, "exceeds max depth 6")
+}
+
+func TestQueryError(t *testing.T) {
+	server := newServer(t, &racingClient{err: status.Error(codes.Unavailable, "racing service unavailable: circuit breaker open")}, &sportsClient{}, 20)
+
+	require.JSONEq(t, `{
+		"errors": [{"message": "racing service unavailable: circuit breaker open", "path": ["races"], "extensions": {"code": "Unavailable"}}],
+		"data": null
+	}`, post(t, server, `{ races { id } }`))
+}
+
+func TestHandler(t *testing.T) {
+	server := newServer(t, newRacing(), &sportsClient{}, 20)
+	handler := server.Handler(http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?"+url.Values{
+		"query":     {`query Race($id: ID!) { race(id: $id) { name } }`},
+		"variables": {`{"id": "1"}`},
+	}.Encode(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"data": {"race": {"name": "Maiden"}}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, strings.NewReader("not json")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, Path, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+
+	// Every other path is left to the next handler.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/races", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
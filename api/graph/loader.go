@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// batchWait is how long a loader collects keys before fetching them. The
+// resolvers of a query's fields run concurrently, so the keys of sibling
+// fields arrive well within it.
+const batchWait = time.Millisecond
+
+// loader batches the keys loaded while a query resolves into one fetch, and
+// remembers what it fetched, so each key is fetched at most once per query.
+// A key the fetch leaves out loads as the zero V.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(context.Context, []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(context.Context, []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch, results: make(map[K]*result[V])}
+}
+
+// load returns the value of key, fetching it with the other keys loaded
+// around the same time unless it has been already.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	r := l.queue(key)[0]
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// queue adds the keys not fetched or pending yet to the next fetch, without
+// waiting for it, and returns their results.
+func (l *loader[K, V]) queue(keys ...K) []*result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		r, ok := l.results[key]
+		if !ok {
+			r = &result[V]{done: make(chan struct{})}
+			l.results[key] = r
+			if len(l.pending) == 0 {
+				time.AfterFunc(batchWait, l.dispatch)
+			}
+			l.pending = append(l.pending, key)
+		}
+		results[i] = r
+	}
+	return results
+}
+
+// dispatch fetches the keys collected so far.
+func (l *loader[K, V]) dispatch() {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	values, err := l.fetch(l.ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		r := l.results[key]
+		r.value, r.err = values[key], err
+		close(r.done)
+	}
+}
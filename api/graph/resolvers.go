@@ -0,0 +1,385 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// loaders are the batching loaders of one query, with its call budget.
+type loaders struct {
+	budget *budget
+
+	races    *loader[string, []*racing.Race]
+	race     *loader[int64, *racing.Race]
+	meetings *loader[int64, []*racing.Race]
+	events   *loader[string, []*sports.Event]
+	event    *loader[int64, *sports.Event]
+}
+
+type loadersKey struct{}
+
+// newLoaders returns ctx carrying fresh loaders for a query.
+func (s *Server) newLoaders(ctx context.Context) context.Context {
+	l := &loaders{budget: &budget{max: s.maxCalls}}
+
+	l.races = newLoader(ctx, func(ctx context.Context, keys []string) (map[string][]*racing.Race, error) {
+		return each(keys, func(key string) ([]*racing.Race, error) {
+			var filter racing.ListRacesRequestFilter
+			if err := proto.Unmarshal([]byte(key), &filter); err != nil {
+				return nil, err
+			}
+			if err := l.budget.spend(); err != nil {
+				return nil, err
+			}
+			resp, err := s.racing.ListRaces(ctx, &racing.ListRacesRequest{Filter: &filter})
+			return resp.GetRaces(), err
+		})
+	})
+	// Every race asked for by ID comes from one ListRaces call listing just
+	// those with their runners, so a list's runners cost one call however
+	// long it is. Hidden races are asked for too, and the service drops them
+	// for callers who may not see them, as GetRace would.
+	l.race = newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64]*racing.Race, error) {
+		if err := l.budget.spend(); err != nil {
+			return nil, err
+		}
+		resp, err := s.racing.ListRaces(ctx, &racing.ListRacesRequest{Filter: &racing.ListRacesRequestFilter{
+			Ids:            ids,
+			IncludeRunners: true,
+			ShowHidden:     proto.Bool(true),
+		}})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*racing.Race, len(ids))
+		for _, race := range resp.Races {
+			byID[race.Id] = race
+		}
+		return byID, nil
+	})
+	// The races of every meeting asked about come from one ListRaces call.
+	l.meetings = newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]*racing.Race, error) {
+		if err := l.budget.spend(); err != nil {
+			return nil, err
+		}
+		resp, err := s.racing.ListRaces(ctx, &racing.ListRacesRequest{Filter: &racing.ListRacesRequestFilter{
+			MeetingIds: ids,
+			OrderBy:    "advertised_start_time",
+		}})
+		if err != nil {
+			return nil, err
+		}
+		byMeeting := make(map[int64][]*racing.Race, len(ids))
+		for _, race := range resp.Races {
+			byMeeting[race.MeetingId] = append(byMeeting[race.MeetingId], race)
+		}
+		return byMeeting, nil
+	})
+	l.events = newLoader(ctx, func(ctx context.Context, keys []string) (map[string][]*sports.Event, error) {
+		return each(keys, func(key string) ([]*sports.Event, error) {
+			var filter sports.ListEventsRequestFilter
+			if err := proto.Unmarshal([]byte(key), &filter); err != nil {
+				return nil, err
+			}
+			if err := l.budget.spend(); err != nil {
+				return nil, err
+			}
+			resp, err := s.sports.ListEvents(ctx, &sports.ListEventsRequest{Filter: &filter})
+			return resp.GetEvents(), err
+		})
+	})
+	// The sports service has no GetEvent, so every event asked for by ID
+	// comes from one ListEvents call listing just those. Hidden events are
+	// asked for too, and the service drops them for callers who may not see
+	// them.
+	l.event = newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64]*sports.Event, error) {
+		if err := l.budget.spend(); err != nil {
+			return nil, err
+		}
+		resp, err := s.sports.ListEvents(ctx, &sports.ListEventsRequest{Filter: &sports.ListEventsRequestFilter{
+			Ids:        ids,
+			ShowHidden: proto.Bool(true),
+		}})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*sports.Event, len(ids))
+		for _, event := range resp.Events {
+			byID[event.Id] = event
+		}
+		return byID, nil
+	})
+
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// each gets the value of every key concurrently, failing if any get does.
+func each[K comparable, V any](keys []K, get func(K) (V, error)) (map[K]V, error) {
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = get(key)
+		}()
+	}
+	wg.Wait()
+
+	byKey := make(map[K]V, len(keys))
+	for i, key := range keys {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		byKey[key] = values[i]
+	}
+	return byKey, nil
+}
+
+// filterKey encodes a list filter as a loader key, so lists asked for with
+// the same filter are fetched once.
+func filterKey(filter proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+	return string(b), err
+}
+
+// parseID parses an ID argument as the int64 the services use.
+func parseID(id graphql.ID) (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, queryError{status.Errorf(codes.InvalidArgument, "invalid ID %q", id)}
+	}
+	return n, nil
+}
+
+func parseIDs(ids *[]graphql.ID) ([]int64, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	parsed := make([]int64, 0, len(*ids))
+	for _, id := range *ids {
+		n, err := parseID(id)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed, nil
+}
+
+func formatID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+// query resolves the Query type.
+type query struct {
+	*Server
+}
+
+func (q *query) Races(ctx context.Context, args struct {
+	MeetingIds *[]graphql.ID
+	ShowHidden *bool
+	OrderBy    *string
+}) ([]*raceResolver, error) {
+	ids, err := parseIDs(args.MeetingIds)
+	if err != nil {
+		return nil, err
+	}
+	key, err := filterKey(&racing.ListRacesRequestFilter{
+		MeetingIds: ids,
+		ShowHidden: args.ShowHidden,
+		OrderBy:    deref(args.OrderBy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	races, err := loadersFrom(ctx).races.load(ctx, key)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return raceResolvers(races), nil
+}
+
+func (q *query) Race(ctx context.Context, args struct{ ID graphql.ID }) (*raceResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	race, err := loadersFrom(ctx).race.load(ctx, id)
+	if err != nil || race == nil {
+		return nil, wrap(err)
+	}
+	return &raceResolver{race: race, full: true}, nil
+}
+
+func (q *query) Events(ctx context.Context, args struct {
+	SportIds   *[]graphql.ID
+	ShowHidden *bool
+	OrderBy    *string
+}) ([]*eventResolver, error) {
+	ids, err := parseIDs(args.SportIds)
+	if err != nil {
+		return nil, err
+	}
+	key, err := filterKey(&sports.ListEventsRequestFilter{
+		SportIds:   ids,
+		ShowHidden: args.ShowHidden,
+		OrderBy:    deref(args.OrderBy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	events, err := loadersFrom(ctx).events.load(ctx, key)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	resolvers := make([]*eventResolver, len(events))
+	for i, event := range events {
+		resolvers[i] = &eventResolver{event}
+	}
+	return resolvers, nil
+}
+
+func (q *query) Event(ctx context.Context, args struct{ ID graphql.ID }) (*eventResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := loadersFrom(ctx).event.load(ctx, id)
+	if err != nil || event == nil {
+		return nil, wrap(err)
+	}
+	return &eventResolver{event}, nil
+}
+
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// statusName returns a proto status, such as STATUS_OPEN, as the Status
+// enum's OPEN.
+func statusName(s interface{ String() string }) string {
+	return strings.TrimPrefix(s.String(), "STATUS_")
+}
+
+// raceResolver resolves the Race type. Races that are listed come without
+// their runners, which are fetched with the race loader if asked for; full
+// is set when the race came from the race loader already.
+type raceResolver struct {
+	race *racing.Race
+	full bool
+
+	// listed are the IDs of the races listed alongside this one, whose
+	// runners are fetched together with its own.
+	listed []int64
+}
+
+func raceResolvers(races []*racing.Race) []*raceResolver {
+	listed := make([]int64, len(races))
+	for i, race := range races {
+		listed[i] = race.Id
+	}
+	resolvers := make([]*raceResolver, len(races))
+	for i, race := range races {
+		resolvers[i] = &raceResolver{race: race, listed: listed}
+	}
+	return resolvers
+}
+
+func (r *raceResolver) ID() graphql.ID        { return formatID(r.race.Id) }
+func (r *raceResolver) MeetingID() graphql.ID { return formatID(r.race.MeetingId) }
+func (r *raceResolver) Name() string          { return r.race.Name }
+func (r *raceResolver) Number() int32         { return int32(r.race.Number) }
+func (r *raceResolver) Visible() bool         { return r.race.Visible }
+func (r *raceResolver) Status() string        { return statusName(r.race.Status) }
+
+func (r *raceResolver) AdvertisedStartTime() graphql.Time {
+	return graphql.Time{Time: r.race.AdvertisedStartTime.AsTime()}
+}
+
+func (r *raceResolver) Meeting() *meetingResolver {
+	return &meetingResolver{id: r.race.MeetingId}
+}
+
+func (r *raceResolver) Runners(ctx context.Context) ([]*runnerResolver, error) {
+	race := r.race
+	if !r.full {
+		// Only a few fields resolve at once, so queue the whole list
+		// rather than leave the rest of it to later fetches.
+		l := loadersFrom(ctx)
+		l.race.queue(r.listed...)
+		var err error
+		race, err = l.race.load(ctx, r.race.Id)
+		if err != nil {
+			return nil, wrap(err)
+		}
+	}
+	runners := make([]*runnerResolver, len(race.GetRunners()))
+	for i, runner := range race.GetRunners() {
+		runners[i] = &runnerResolver{runner}
+	}
+	return runners, nil
+}
+
+// meetingResolver resolves the Meeting type.
+type meetingResolver struct {
+	id int64
+}
+
+func (m *meetingResolver) ID() graphql.ID { return formatID(m.id) }
+
+func (m *meetingResolver) Races(ctx context.Context) ([]*raceResolver, error) {
+	races, err := loadersFrom(ctx).meetings.load(ctx, m.id)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return raceResolvers(races), nil
+}
+
+// runnerResolver resolves the Runner type.
+type runnerResolver struct {
+	runner *racing.Runner
+}
+
+func (r *runnerResolver) Number() int32         { return int32(r.runner.Number) }
+func (r *runnerResolver) Name() string          { return r.runner.Name }
+func (r *runnerResolver) Scratched() bool       { return r.runner.Scratched }
+func (r *runnerResolver) FinishPosition() int32 { return int32(r.runner.FinishPosition) }
+
+// eventResolver resolves the Event type.
+type eventResolver struct {
+	event *sports.Event
+}
+
+func (e *eventResolver) ID() graphql.ID      { return formatID(e.event.Id) }
+func (e *eventResolver) SportID() graphql.ID { return formatID(e.event.SportId) }
+func (e *eventResolver) Name() string        { return e.event.Name }
+func (e *eventResolver) Venue() string       { return e.event.Venue }
+func (e *eventResolver) HomeTeam() string    { return e.event.HomeTeam }
+func (e *eventResolver) AwayTeam() string    { return e.event.AwayTeam }
+func (e *eventResolver) Visible() bool       { return e.event.Visible }
+func (e *eventResolver) Status() string      { return statusName(e.event.Status) }
+
+func (e *eventResolver) AdvertisedStartTime() graphql.Time {
+	return graphql.Time{Time: e.event.AdvertisedStartTime.AsTime()}
+}
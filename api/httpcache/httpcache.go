@@ -0,0 +1,203 @@
+// Package httpcache adds HTTP caching headers to the gateway's GET
+// responses: an ETag computed from the response message, answered with 304
+// Not Modified when it matches If-None-Match, and a Cache-Control lifetime
+// suited to the resource.
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+// DefaultCacheControl is the Cache-Control of responses without a lifetime
+// of their own, such as lists and account resources: caches may keep them,
+// but must check they're current, by ETag, before each use.
+const DefaultCacheControl = "no-cache"
+
+// response is what ForwardResponseOption learns of the message a request
+// is answered with.
+type response struct {
+	// representation is how the message is written, such as the JSON format
+	// the request asked for.
+	representation string
+
+	etag string
+	// maxAge is how long caches may use the response without checking it's
+	// current, or 0 if they must always check.
+	maxAge time.Duration
+}
+
+type responseKey struct{}
+
+// now returns the current time; tests replace it.
+var now = time.Now
+
+// Handler sets ETag and Cache-Control on the successful GET responses that
+// next answers with a message, and answers 304 Not Modified when
+// If-None-Match matches the ETag. Responses are cached publicly for at most
+// maxAge, and privately for callers sending credentials. Other requests
+// pass through untouched.
+func Handler(next http.Handler, maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resp := new(response)
+		cw := &writer{
+			ResponseWriter: w,
+			resp:           resp,
+			ifNoneMatch:    r.Header.Get("If-None-Match"),
+			private:        r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != "",
+			maxAge:         maxAge,
+		}
+		next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), responseKey{}, resp)))
+	})
+}
+
+// ForwardResponseOption records the ETag and lifetime of the message a GET
+// request is answered with, for Handler to send. Register it with
+// runtime.WithForwardResponseOption.
+func ForwardResponseOption(ctx context.Context, _ http.ResponseWriter, m proto.Message) error {
+	resp, ok := ctx.Value(responseKey{}).(*response)
+	if !ok {
+		return nil
+	}
+
+	etag, err := ETag(m, resp.representation)
+	if err != nil {
+		return err
+	}
+	resp.etag = etag
+	if race, ok := m.(*racing.GetRaceResponse); ok {
+		resp.maxAge = raceMaxAge(race.GetRace(), now())
+	}
+	return nil
+}
+
+// SetRepresentation records how the response to the request of ctx is
+// written, such as the JSON format it asked for, so its ETag tells it apart
+// from the same message written another way. Call it before the gateway
+// answers; it does nothing outside Handler.
+func SetRepresentation(ctx context.Context, representation string) {
+	if resp, ok := ctx.Value(responseKey{}).(*response); ok {
+		resp.representation = representation
+	}
+}
+
+// ETag returns a strong entity tag for m written as representation: a hash
+// of its deterministic wire encoding and the representation, which changes
+// whenever any field does, and differs between representations.
+func ETag(m proto.Message, representation string) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	h.Write([]byte{0})
+	h.Write([]byte(representation))
+	sum := h.Sum(nil)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// raceMaxAge lets caches keep race until it jumps, when its status changes
+// to closed. Races that have jumped go on changing as they're resulted, so
+// caches must check they're current.
+func raceMaxAge(race *racing.Race, now time.Time) time.Duration {
+	return max(race.GetAdvertisedStartTime().AsTime().Sub(now), 0)
+}
+
+// writer sends the headers recorded in resp with a successful response.
+type writer struct {
+	http.ResponseWriter
+	resp        *response
+	ifNoneMatch string
+	private     bool
+	maxAge      time.Duration
+
+	wroteHeader bool
+	notModified bool
+}
+
+func (w *writer) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if code != http.StatusOK || w.resp.etag == "" {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	h := w.Header()
+	h.Set("ETag", w.resp.etag)
+	h.Set("Cache-Control", w.cacheControl())
+	// Callers with credentials may see more, such as hidden races.
+	h.Add("Vary", "Authorization, X-API-Key")
+
+	if matches(w.ifNoneMatch, w.resp.etag) {
+		w.notModified = true
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.notModified {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// cacheControl caps the lifetime of the response at maxAge, and makes it
+// private to callers sending credentials.
+func (w *writer) cacheControl() string {
+	cc := "public, "
+	if w.private {
+		cc = "private, "
+	}
+
+	seconds := int(min(w.resp.maxAge, w.maxAge) / time.Second)
+	if seconds <= 0 {
+		if !w.private {
+			return DefaultCacheControl
+		}
+		return cc + DefaultCacheControl
+	}
+	return cc + "max-age=" + strconv.Itoa(seconds)
+}
+
+// matches reports whether the If-None-Match header ifNoneMatch matches
+// etag, comparing weakly as RFC 9110 requires.
+func matches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+// racingServer serves races from a map, in process.
+type racingServer struct {
+	races map[int64]*racing.Race
+}
+
+func (s *racingServer) ListRaces(context.Context, *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
+	return &racing.ListRacesResponse{}, nil
+}
+
+func (s *racingServer) GetRace(_ context.Context, in *racing.GetRaceRequest) (*racing.GetRaceResponse, error) {
+	race, ok := s.races[in.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "race not found")
+	}
+	return &racing.GetRaceResponse{Race: race}, nil
+}
+
+func gateway(t *testing.T, server *racingServer) http.Handler {
+	t.Helper()
+
+	mux := runtime.NewServeMux(runtime.WithForwardResponseOption(ForwardResponseOption))
+	require.NoError(t, racing.RegisterRacingHandlerServer(context.Background(), mux, server))
+	return Handler(mux, time.Hour)
+}
+
+func get(handler http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	start := time.Now()
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	server := &racingServer{races: map[int64]*racing.Race{
+		1: {Id: 1, Name: "Upcoming", AdvertisedStartTime: timestamppb.New(start.Add(90 * time.Second))},
+		2: {Id: 2, Name: "Jumped", AdvertisedStartTime: timestamppb.New(start.Add(-time.Minute))},
+		3: {Id: 3, Name: "Tomorrow", AdvertisedStartTime: timestamppb.New(start.Add(24 * time.Hour))},
+	}}
+	handler := gateway(t, server)
+
+	tests := []struct {
+		name             string
+		path             string
+		header           http.Header
+		wantCacheControl string
+	}{
+		{name: "cached until the race jumps", path: "/v1/races/1", wantCacheControl: "public, max-age=90"},
+		{name: "jumped races must be checked", path: "/v1/races/2", wantCacheControl: "no-cache"},
+		{name: "capped at the longest max age", path: "/v1/races/3", wantCacheControl: "public, max-age=3600"},
+		{name: "private to callers with credentials", path: "/v1/races/1", header: http.Header{"X-Api-Key": {"key"}}, wantCacheControl: "private, max-age=90"},
+		{name: "private and checked", path: "/v1/races/2", header: http.Header{"Authorization": {"Bearer token"}}, wantCacheControl: "private, no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(handler, tt.path, tt.header)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.NotEmpty(t, rec.Header().Get("ETag"))
+			require.Equal(t, tt.wantCacheControl, rec.Header().Get("Cache-Control"))
+			require.Equal(t, "Authorization, X-API-Key", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestHandlerNotModified(t *testing.T) {
+	race := &racing.Race{Id: 1, Name: "Upcoming", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}
+	handler := gateway(t, &racingServer{races: map[int64]*racing.Race{1: race}})
+
+	first := get(handler, "/v1/races/1", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec := get(handler, "/v1/races/1", http.Header{"If-None-Match": {ifNoneMatch}})
+		require.Equal(t, http.StatusNotModified, rec.Code, ifNoneMatch)
+		require.Empty(t, rec.Body.String())
+		require.Equal(t, etag, rec.Header().Get("ETag"))
+		require.Empty(t, rec.Header().Get("Content-Type"))
+	}
+
+	// A changed race has a new ETag, so the old one no longer matches.
+	race.Name = "Renamed"
+	rec := get(handler, "/v1/races/1", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEqual(t, etag, rec.Header().Get("ETag"))
+	require.Contains(t, rec.Body.String(), "Renamed")
+}
+
+func TestHandlerRepresentations(t *testing.T) {
+	race := &racing.Race{Id: 1, Name: "Upcoming", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}
+	mux := runtime.NewServeMux(runtime.WithForwardResponseOption(ForwardResponseOption))
+	require.NoError(t, racing.RegisterRacingHandlerServer(context.Background(), mux, &racingServer{races: map[int64]*racing.Race{1: race}}))
+	// The race is written in the format the request asks for.
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRepresentation(r.Context(), r.Header.Get("Format"))
+		mux.ServeHTTP(w, r)
+	}), time.Hour)
+
+	camel := get(handler, "/v1/races/1", http.Header{"Format": {"camel"}})
+	snake := get(handler, "/v1/races/1", http.Header{"Format": {"snake"}})
+	require.NotEqual(t, camel.Header().Get("ETag"), snake.Header().Get("ETag"))
+
+	rec := get(handler, "/v1/races/1", http.Header{"Format": {"snake"}, "If-None-Match": {camel.Header().Get("ETag")}})
+	require.Equal(t, http.StatusOK, rec.Code, "one representation's ETag doesn't match another")
+
+	rec = get(handler, "/v1/races/1", http.Header{"Format": {"snake"}, "If-None-Match": {snake.Header().Get("ETag")}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestHandlerSkips(t *testing.T) {
+	handler := gateway(t, &racingServer{})
+
+	// Errors aren't cached.
+	rec := get(handler, "/v1/races/9", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Empty(t, rec.Header().Get("ETag"))
+	require.Empty(t, rec.Header().Get("Cache-Control"))
+
+	// Nor are responses to anything but GET.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/list-races", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("ETag"))
+}
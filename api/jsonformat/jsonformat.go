@@ -0,0 +1,218 @@
+// Package jsonformat chooses how the gateway writes messages as JSON: whether
+// field names are camelCase or snake_case, enums are names or numbers, and
+// fields holding their zero value are written or left out.
+//
+// The -json-format flag sets the gateway's format. Callers may ask for another
+// with the $format query parameter or the X-JSON-Format header, each a
+// comma-separated list of the options to change, such as
+// "snake,enum-numbers".
+package jsonformat
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"git.neds.sh/matty/entain/api/httpcache"
+)
+
+const (
+	// QueryParam is the query parameter requesting a format; it takes
+	// precedence over Header.
+	QueryParam = "$format"
+	// Header is the request header requesting a format.
+	Header = "X-JSON-Format"
+)
+
+// Format is how messages are written as JSON. The zero Format is the
+// gateway's own default: camelCase names, enum names, and every field
+// written.
+type Format struct {
+	// SnakeCase writes field names as declared in the proto files, such as
+	// meeting_id, rather than as camelCase.
+	SnakeCase bool
+	// EnumNumbers writes enums as their numbers, such as 1, rather than their
+	// names, such as "STATUS_CLOSED".
+	EnumNumbers bool
+	// OmitUnpopulated leaves out fields holding their zero value, such as
+	// visible: false and status: STATUS_OPEN.
+	OmitUnpopulated bool
+}
+
+// Parse returns base with the options of spec applied: camel or snake,
+// enum-names or enum-numbers, and emit-unpopulated or omit-unpopulated.
+func Parse(spec string, base Format) (Format, error) {
+	f := base
+	for _, option := range strings.Split(spec, ",") {
+		switch option = strings.TrimSpace(option); option {
+		case "":
+		case "camel":
+			f.SnakeCase = false
+		case "snake":
+			f.SnakeCase = true
+		case "enum-names":
+			f.EnumNumbers = false
+		case "enum-numbers":
+			f.EnumNumbers = true
+		case "emit-unpopulated":
+			f.OmitUnpopulated = false
+		case "omit-unpopulated":
+			f.OmitUnpopulated = true
+		default:
+			return Format{}, fmt.Errorf("unknown JSON format option %q: want camel, snake, enum-names, enum-numbers, emit-unpopulated or omit-unpopulated", option)
+		}
+	}
+	return f, nil
+}
+
+// String returns the spec of every option of f, which Parse reads back.
+func (f Format) String() string {
+	options := []string{"camel", "enum-names", "emit-unpopulated"}
+	if f.SnakeCase {
+		options[0] = "snake"
+	}
+	if f.EnumNumbers {
+		options[1] = "enum-numbers"
+	}
+	if f.OmitUnpopulated {
+		options[2] = "omit-unpopulated"
+	}
+	return strings.Join(options, ",")
+}
+
+// marshaler returns the gateway marshaler writing f. Like the gateway's
+// default, it reads both name styles and ignores unknown fields in requests.
+func (f Format) marshaler() runtime.Marshaler {
+	return &runtime.HTTPBodyMarshaler{
+		Marshaler: &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   f.SnakeCase,
+				UseEnumNumbers:  f.EnumNumbers,
+				EmitUnpopulated: !f.OmitUnpopulated,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		},
+	}
+}
+
+// mime is the media type the marshaler of f is registered under, which
+// Handler asks for on behalf of callers requesting f.
+func (f Format) mime() string {
+	return "application/json;format=" + f.String()
+}
+
+// Flags are the command line options setting the gateway's format.
+type Flags struct {
+	Format string
+}
+
+// RegisterFlags registers -json-format on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.Format, "json-format", Format{}.String(), "How REST responses are written as JSON: camel or snake field names, enum-names or enum-numbers, and emit-unpopulated or omit-unpopulated zero values; callers may ask for others with ?$format= or the X-JSON-Format header")
+
+	return &f
+}
+
+// Validate checks the format is known.
+func (f *Flags) Validate() error {
+	if _, err := Parse(f.Format, Format{}); err != nil {
+		return fmt.Errorf("-json-format: %w", err)
+	}
+	return nil
+}
+
+// Server writes the gateway's responses in the format each caller asks for.
+type Server struct {
+	format Format
+}
+
+// New returns a Server writing the flags' format unless callers ask for
+// another.
+func (f *Flags) New() (*Server, error) {
+	format, err := Parse(f.Format, Format{})
+	if err != nil {
+		return nil, err
+	}
+	return &Server{format: format}, nil
+}
+
+// ServeMuxOptions register a marshaler for every format on the gateway's mux,
+// the server's own answering requests that ask for none.
+func (s *Server) ServeMuxOptions() []runtime.ServeMuxOption {
+	opts := []runtime.ServeMuxOption{
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, s.format.marshaler()),
+	}
+	for _, snake := range []bool{false, true} {
+		for _, numbers := range []bool{false, true} {
+			for _, omit := range []bool{false, true} {
+				f := Format{SnakeCase: snake, EnumNumbers: numbers, OmitUnpopulated: omit}
+				opts = append(opts, runtime.WithMarshalerOption(f.mime(), f.marshaler()))
+			}
+		}
+	}
+	return opts
+}
+
+// Handler passes requests to next, the gateway's mux, asking it for the
+// format requested by the $format query parameter or X-JSON-Format header.
+// Requests for unknown formats are refused with 400 Bad Request. It belongs
+// beneath httpcache.Handler, so ETags tell formats apart.
+func (s *Server) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caches must keep each format requested by header apart; those
+		// requested by query parameter have URLs of their own.
+		w.Header().Add("Vary", Header)
+
+		query := r.URL.Query()
+		spec, requested := query.Get(QueryParam), query.Has(QueryParam)
+		if !requested {
+			spec, requested = r.Header.Get(Header), r.Header.Get(Header) != ""
+		}
+		if !requested {
+			// The gateway's format is still told apart by ETag, in case it
+			// changes.
+			httpcache.SetRepresentation(r.Context(), s.format.String())
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		format, err := Parse(spec, s.format)
+		if err != nil {
+			writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+		httpcache.SetRepresentation(r.Context(), format.String())
+
+		r = r.Clone(r.Context())
+		r.Header.Set("Accept", format.mime())
+		// The gateway would otherwise try to read $format into the request
+		// message.
+		query.Del(QueryParam)
+		r.URL.RawQuery = query.Encode()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError writes st with 400 Bad Request, in the same JSON shape the
+// gateway uses for errors.
+func writeError(w http.ResponseWriter, st *status.Status) {
+	body, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		body = []byte(`{"code":2,"message":"failed to marshal error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(body)
+}
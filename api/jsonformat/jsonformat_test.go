@@ -0,0 +1,175 @@
+package jsonformat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+
+	"git.neds.sh/matty/entain/api/httpcache"
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+// racingServer serves race 1, open and hidden, so its zero values show.
+type racingServer struct {
+	racing.UnimplementedRacingServer
+}
+
+func (racingServer) GetRace(_ context.Context, in *racing.GetRaceRequest) (*racing.GetRaceResponse, error) {
+	return &racing.GetRaceResponse{Race: &racing.Race{Id: in.Id, MeetingId: 5, Name: "Cup"}}, nil
+}
+
+// serve serves racing through the gateway, writing flags' format unless
+// asked for another.
+func serve(t *testing.T, flags string) *httptest.Server {
+	t.Helper()
+
+	s, err := (&Flags{Format: flags}).New()
+	require.NoError(t, err)
+	mux := runtime.NewServeMux(s.ServeMuxOptions()...)
+	require.NoError(t, racing.RegisterRacingHandlerServer(context.Background(), mux, racingServer{}))
+
+	srv := httptest.NewServer(s.Handler(mux))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, srv *httptest.Server, query, header string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/races/1?"+query, nil)
+	require.NoError(t, err)
+	if header != "" {
+		req.Header.Set(Header, header)
+	}
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+// formats are every format, and race 1 written in it.
+var formats = []struct {
+	spec string
+	want string
+}{
+	{"camel,enum-names,emit-unpopulated", `{"race": {"id": "1", "meetingId": "5", "name": "Cup", "number": "0", "visible": false, "advertisedStartTime": null, "status": "STATUS_OPEN", "runners": []}}`},
+	{"camel,enum-names,omit-unpopulated", `{"race": {"id": "1", "meetingId": "5", "name": "Cup"}}`},
+	{"camel,enum-numbers,emit-unpopulated", `{"race": {"id": "1", "meetingId": "5", "name": "Cup", "number": "0", "visible": false, "advertisedStartTime": null, "status": 0, "runners": []}}`},
+	{"camel,enum-numbers,omit-unpopulated", `{"race": {"id": "1", "meetingId": "5", "name": "Cup"}}`},
+	{"snake,enum-names,emit-unpopulated", `{"race": {"id": "1", "meeting_id": "5", "name": "Cup", "number": "0", "visible": false, "advertised_start_time": null, "status": "STATUS_OPEN", "runners": []}}`},
+	{"snake,enum-names,omit-unpopulated", `{"race": {"id": "1", "meeting_id": "5", "name": "Cup"}}`},
+	{"snake,enum-numbers,emit-unpopulated", `{"race": {"id": "1", "meeting_id": "5", "name": "Cup", "number": "0", "visible": false, "advertised_start_time": null, "status": 0, "runners": []}}`},
+	{"snake,enum-numbers,omit-unpopulated", `{"race": {"id": "1", "meeting_id": "5", "name": "Cup"}}`},
+}
+
+func TestFlagsFormat(t *testing.T) {
+	for _, tc := range formats {
+		t.Run(tc.spec, func(t *testing.T) {
+			code, body := get(t, serve(t, tc.spec), "", "")
+			require.Equal(t, http.StatusOK, code)
+			require.JSONEq(t, tc.want, body)
+		})
+	}
+}
+
+func TestRequestedFormat(t *testing.T) {
+	// The gateway's format is the opposite of each requested, so every
+	// option requested shows.
+	srv := serve(t, "snake,enum-numbers,omit-unpopulated")
+
+	for _, tc := range formats {
+		t.Run(tc.spec, func(t *testing.T) {
+			code, body := get(t, srv, url.Values{QueryParam: {tc.spec}}.Encode(), "")
+			require.Equal(t, http.StatusOK, code)
+			require.JSONEq(t, tc.want, body, "by query parameter")
+
+			code, body = get(t, srv, "", tc.spec)
+			require.Equal(t, http.StatusOK, code)
+			require.JSONEq(t, tc.want, body, "by header")
+		})
+	}
+}
+
+func TestRequestedFormatOverridesOptionsGiven(t *testing.T) {
+	srv := serve(t, "camel,enum-numbers,omit-unpopulated")
+
+	code, body := get(t, srv, "", "snake")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"race": {"id": "1", "meeting_id": "5", "name": "Cup"}}`, body, "options not requested are the gateway's")
+
+	code, body = get(t, srv, "$format=emit-unpopulated", "snake")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, formats[2].want, body, "the query parameter wins over the header")
+}
+
+func TestRequestedFormatETag(t *testing.T) {
+	s, err := (&Flags{}).New()
+	require.NoError(t, err)
+	mux := runtime.NewServeMux(append(s.ServeMuxOptions(), runtime.WithForwardResponseOption(httpcache.ForwardResponseOption))...)
+	require.NoError(t, racing.RegisterRacingHandlerServer(context.Background(), mux, racingServer{}))
+	srv := httptest.NewServer(httpcache.Handler(s.Handler(mux), time.Minute))
+	t.Cleanup(srv.Close)
+
+	etags := make(map[string]string)
+	for _, tc := range formats {
+		resp, err := srv.Client().Get(srv.URL + "/v1/races/1?" + url.Values{QueryParam: {tc.spec}}.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+		etags[resp.Header.Get("ETag")] = tc.spec
+	}
+	require.Len(t, etags, len(formats), "every format has an ETag of its own")
+
+	// A cache holding the race in one format can't have it confirmed for
+	// another.
+	for etag := range etags {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/races/1", nil)
+		require.NoError(t, err)
+		req.Header.Set(Header, "snake")
+		req.Header.Set("If-None-Match", etag)
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		want := http.StatusOK
+		if etags[etag] == "snake,enum-names,emit-unpopulated" {
+			want = http.StatusNotModified
+		}
+		require.Equal(t, want, resp.StatusCode, etags[etag])
+	}
+}
+
+func TestRequestedFormatUnknown(t *testing.T) {
+	srv := serve(t, "")
+
+	code, body := get(t, srv, "$format=kebab", "")
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, body, `unknown JSON format option \"kebab\"`)
+
+	code, _ = get(t, srv, "", "snake,yaml")
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestFormatString(t *testing.T) {
+	for _, tc := range formats {
+		f, err := Parse(tc.spec, Format{})
+		require.NoError(t, err)
+		require.Equal(t, tc.spec, f.String())
+	}
+	require.Equal(t, formats[0].spec, Format{}.String())
+}
+
+func TestFlagsValidate(t *testing.T) {
+	require.NoError(t, (&Flags{Format: ""}).Validate())
+	require.NoError(t, (&Flags{Format: "snake, omit-unpopulated"}).Validate())
+	require.Error(t, (&Flags{Format: "snake_case"}).Validate())
+}
@@ -2,27 +2,108 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
-	"git.neds.sh/matty/entain/api/proto/racing"
-	"git.neds.sh/matty/entain/api/proto/sports"
+	"git.neds.sh/matty/entain/api/auth"
+	"git.neds.sh/matty/entain/api/discovery"
+	"git.neds.sh/matty/entain/api/docs"
+	"git.neds.sh/matty/entain/api/feed"
+	"git.neds.sh/matty/entain/api/graph"
+	"git.neds.sh/matty/entain/api/httpcache"
+	"git.neds.sh/matty/entain/api/jsonformat"
+	"git.neds.sh/matty/entain/api/ratelimit"
+	"git.neds.sh/matty/entain/api/resilience"
+	"git.neds.sh/matty/entain/api/webrpc"
+	"git.neds.sh/matty/entain/pkg/admin"
+	"git.neds.sh/matty/entain/pkg/config"
+	"git.neds.sh/matty/entain/pkg/health"
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tlsutil"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/nexttogo"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/sports"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
-	apiEndpoint        = flag.String("api-endpoint", "localhost:8000", "API endpoint")
-	racingGrpcEndpoint = flag.String("racing-grpc-endpoint", "localhost:9000", "Racing gRPC server endpoint")
-	sportsGrpcEndpoint = flag.String("sports-grpc-endpoint", "localhost:9001", "Sports gRPC server endpoint")
+	apiEndpoint          = flag.String("api-endpoint", "localhost:8000", "API endpoint")
+	adminEndpoint        = flag.String("admin-endpoint", "localhost:8001", "Admin HTTP endpoint serving /metrics")
+	racingGrpcEndpoint   = flag.String("racing-grpc-endpoint", "localhost:9000", "Racing gRPC server endpoint: host:port, replicas as host:port,host:port, dns:///host:port or file:///path listing replicas")
+	sportsGrpcEndpoint   = flag.String("sports-grpc-endpoint", "localhost:9001", "Sports gRPC server endpoint: host:port, replicas as host:port,host:port, dns:///host:port or file:///path listing replicas")
+	bettingGrpcEndpoint  = flag.String("betting-grpc-endpoint", "localhost:9002", "Betting gRPC server endpoint: host:port, replicas as host:port,host:port, dns:///host:port or file:///path listing replicas")
+	accountsGrpcEndpoint = flag.String("accounts-grpc-endpoint", "localhost:9003", "Accounts gRPC server endpoint: host:port, replicas as host:port,host:port, dns:///host:port or file:///path listing replicas")
+	jwksFile             = flag.String("jwks-file", "", "JWKS file of keys that verify bearer tokens; bearer tokens are refused when unset")
+	jwtIssuer            = flag.String("jwt-issuer", "", "Required issuer of bearer tokens")
+	jwtAudience          = flag.String("jwt-audience", "", "Required audience of bearer tokens")
+	apiKeysFile          = flag.String("api-keys-file", "", "File of hashed partner API keys; API keys are refused when unset")
+	shutdownTimeout      = flag.Duration("shutdown-timeout", 15*time.Second, "How long to let requests in flight finish when shutting down")
+	shutdownDrainDelay   = flag.Duration("shutdown-drain-delay", 5*time.Second, "How long to keep serving while reporting not ready at shutdown, so load balancers stop sending requests first")
+	readinessTimeout     = flag.Duration("readiness-timeout", 2*time.Second, "How long /readyz waits for each backend to answer")
+	httpCacheMaxAge      = flag.Duration("http-cache-max-age", time.Minute, "Longest a CDN or browser may cache a race before checking it's current")
+
+	// The public HTTPS listener and the gateway's mutual TLS to backend
+	// services use separate identities.
+	tlsFlags     = tlsutil.RegisterFlags(flag.CommandLine, "")
+	grpcTLSFlags = tlsutil.RegisterFlags(flag.CommandLine, "grpc-")
+
+	rateLimitFlags  = ratelimit.RegisterFlags(flag.CommandLine)
+	discoveryFlags  = discovery.RegisterFlags(flag.CommandLine)
+	resilienceFlags = resilience.RegisterFlags(flag.CommandLine)
+	graphQLFlags    = graph.RegisterFlags(flag.CommandLine)
+	webFlags        = webrpc.RegisterFlags(flag.CommandLine)
+	jsonFlags       = jsonformat.RegisterFlags(flag.CommandLine)
+
+	traceFlags = tracing.RegisterFlags(flag.CommandLine)
+	logFlags   = logging.RegisterFlags(flag.CommandLine)
 )
 
 func main() {
-	flag.Parse()
+	config.Parse("API",
+		config.Endpoint("api-endpoint", apiEndpoint),
+		config.Endpoint("admin-endpoint", adminEndpoint),
+		discovery.Endpoint("racing-grpc-endpoint", racingGrpcEndpoint),
+		discovery.Endpoint("sports-grpc-endpoint", sportsGrpcEndpoint),
+		discovery.Endpoint("betting-grpc-endpoint", bettingGrpcEndpoint),
+		discovery.Endpoint("accounts-grpc-endpoint", accountsGrpcEndpoint),
+		config.Positive("shutdown-timeout", shutdownTimeout),
+		config.Check(validateDrainDelay),
+		config.Positive("readiness-timeout", readinessTimeout),
+		config.Positive("http-cache-max-age", httpCacheMaxAge),
+		tlsFlags,
+		grpcTLSFlags,
+		rateLimitFlags,
+		discoveryFlags,
+		resilienceFlags,
+		graphQLFlags,
+		webFlags,
+		jsonFlags,
+		traceFlags,
+		logFlags,
+	)
+
+	if err := logFlags.Setup("api"); err != nil {
+		log.Fatalf("invalid logging flags: %s\n", err)
+	}
 
 	if err := run(); err != nil {
-		log.Printf("failed running api server: %s\n", err)
+		slog.Error("failed running api server", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -31,29 +112,215 @@ func run() error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	mux := runtime.NewServeMux()
+	// The gateway's backend connections live on ctx, so requests still in
+	// flight can finish after a signal to stop.
+	stopCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := traceFlags.Setup(ctx, "api")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+
+	publicTLS, err := tlsFlags.Reloader("api")
+	if err != nil {
+		return err
+	}
+	backendTLS, err := grpcTLSFlags.Reloader("api-gateway")
+	if err != nil {
+		return err
+	}
+	creds, err := tlsutil.ClientCredentials(backendTLS)
+	if err != nil {
+		return err
+	}
+
+	limiter, closeLimiter, err := rateLimitFlags.New()
+	if err != nil {
+		return err
+	}
+	defer closeLimiter()
+
+	jsonFormat, err := jsonFlags.New()
+	if err != nil {
+		return err
+	}
+
+	mux := runtime.NewServeMux(append(jsonFormat.ServeMuxOptions(),
+		runtime.WithMiddlewares(labelRoute),
+		runtime.WithForwardResponseOption(httpcache.ForwardResponseOption),
+	)...)
+	// Every backend shares these options, and adds its own deadlines,
+	// retries and circuit breaker.
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(tracing.ClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			limiter.UnaryClientInterceptor(),
+			auth.UnaryClientInterceptor(),
+			logging.UnaryClientInterceptor(),
+		),
+	}
+
+	// Register each backend service, balancing calls across its replicas.
+	services := []struct {
+		name     string
+		endpoint string
+		service  string
+		register func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error
+	}{
+		{"racing", *racingGrpcEndpoint, racing.Racing_ServiceDesc.ServiceName, racing.RegisterRacingHandler},
+		{"sports", *sportsGrpcEndpoint, sports.Sports_ServiceDesc.ServiceName, sports.RegisterSportsHandler},
+		{"betting", *bettingGrpcEndpoint, betting.Betting_ServiceDesc.ServiceName, betting.RegisterBettingHandler},
+		{"accounts", *accountsGrpcEndpoint, accounts.Accounts_ServiceDesc.ServiceName, accounts.RegisterAccountsHandler},
+	}
+	conns := make(map[string]*grpc.ClientConn, len(services))
+	for _, svc := range services {
+		target, resolverOpts, err := discoveryFlags.Dial(svc.endpoint)
+		if err != nil {
+			return err
+		}
+		conn, err := grpc.NewClient(target, slices.Concat(opts, resolverOpts, resilienceFlags.DialOptions(svc.name, svc.service))...)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if err := svc.register(ctx, mux, conn); err != nil {
+			return err
+		}
+		conns[svc.name] = conn
+	}
 
-	// Register racing service
-	if err := racing.RegisterRacingHandlerFromEndpoint(
-		ctx,
-		mux,
-		*racingGrpcEndpoint,
-		[]grpc.DialOption{grpc.WithInsecure()},
-	); err != nil {
+	// The gateway serves the next to go feed itself, from racing and sports.
+	nextToGo := feed.NewServer(racing.NewRacingClient(conns["racing"]), sports.NewSportsClient(conns["sports"]))
+	if err := nexttogo.RegisterNextToGoHandlerServer(ctx, mux, nextToGo); err != nil {
 		return err
 	}
 
-	// Register sports service
-	if err := sports.RegisterSportsHandlerFromEndpoint(
-		ctx,
-		mux,
-		*sportsGrpcEndpoint,
-		[]grpc.DialOption{grpc.WithInsecure()},
-	); err != nil {
+	// GraphQL queries resolve against the same clients, so each backend call
+	// they make is authorized and rate limited like a REST call.
+	graphQL, err := graphQLFlags.New(racing.NewRacingClient(conns["racing"]), sports.NewSportsClient(conns["sports"]))
+	if err != nil {
 		return err
 	}
 
-	log.Printf("API server listening on: %s\n", *apiEndpoint)
+	// Browsers call racing and sports over Connect and gRPC-Web through the
+	// same clients too.
+	web := webFlags.New(racing.NewRacingClient(conns["racing"]), sports.NewSportsClient(conns["sports"]))
+
+	backends, err := newBackends(creds)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("API server listening", "endpoint", *apiEndpoint)
+
+	if err := admin.New(*adminEndpoint).Start(); err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:      *apiEndpoint,
+		Handler:   limiter.Handler(backends.Handler(docs.Handler(tracing.Handler(logging.Handler(metrics.Handler(authenticator.Handler(graphQL.Handler(web.Handler(httpcache.Handler(jsonFormat.Handler(mux), *httpCacheMaxAge)))))))))),
+		TLSConfig: publicTLS.ServerConfig(tls.NoClientCert),
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-stopCtx.Done():
+	}
+
+	// Report not ready and keep serving until load balancers have seen it and
+	// stopped sending requests, then let those in flight finish.
+	slog.Info("shutting down", "drain_delay", shutdownDrainDelay.String(), "timeout", shutdownTimeout.String())
+	backends.Drain()
+	time.Sleep(*shutdownDrainDelay)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancelShutdown()
+
+	return server.Shutdown(shutdownCtx)
+}
+
+func validateDrainDelay() error {
+	if *shutdownDrainDelay < 0 {
+		return fmt.Errorf("-shutdown-drain-delay can't be negative, not %s", *shutdownDrainDelay)
+	}
+	return nil
+}
+
+// labelRoute labels request metrics and the request's trace span with the
+// gateway route that matched, such as "/v1/races/{id=*}".
+func labelRoute(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+			metrics.SetRoute(r.Context(), pattern.String())
+			tracing.SetRoute(r.Context(), r.Method, pattern.String())
+		}
+		next(w, r, pathParams)
+	}
+}
+
+func newAuthenticator() (*auth.Authenticator, error) {
+	var (
+		authenticator auth.Authenticator
+		err           error
+	)
+
+	if *jwksFile != "" {
+		if authenticator.JWT, err = auth.NewJWTVerifier(*jwksFile, *jwtIssuer, *jwtAudience); err != nil {
+			return nil, err
+		}
+	}
+	if *apiKeysFile != "" {
+		if authenticator.APIKeys, err = auth.NewAPIKeys(*apiKeysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &authenticator, nil
+}
+
+// newBackends checks the health of the backend services for /readyz. Health
+// checks aren't proxied, so they use connections of their own, outside the
+// gateway's auth policy, balanced across replicas like the gateway's. Only
+// racing and sports decide readiness: without betting or accounts the
+// gateway still serves the catalogue, the feed and GraphQL, so taking it out
+// of rotation would only turn failed bets into an outage.
+func newBackends(creds credentials.TransportCredentials) (*health.Backends, error) {
+	backends := map[string]struct{ endpoint, service string }{
+		"racing":   {*racingGrpcEndpoint, racing.Racing_ServiceDesc.ServiceName},
+		"sports":   {*sportsGrpcEndpoint, sports.Sports_ServiceDesc.ServiceName},
+		"betting":  {*bettingGrpcEndpoint, betting.Betting_ServiceDesc.ServiceName},
+		"accounts": {*accountsGrpcEndpoint, accounts.Accounts_ServiceDesc.ServiceName},
+	}
+
+	conns := make(map[string]grpc.ClientConnInterface, len(backends))
+	for name, backend := range backends {
+		target, resolverOpts, err := discoveryFlags.Dial(backend.endpoint)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := grpc.NewClient(target, slices.Concat(resolverOpts, resilienceFlags.HealthDialOptions(backend.service), []grpc.DialOption{grpc.WithTransportCredentials(creds)})...)
+		if err != nil {
+			return nil, err
+		}
+		conns[name] = conn
+	}
 
-	return http.ListenAndServe(*apiEndpoint, mux)
+	return health.NewBackends(conns, *readinessTimeout, "betting", "accounts"), nil
 }
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Stores the -rate-limit-store flag accepts.
+const (
+	StoreNone   = "none"
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Flags are the command line options setting the quotas, and where their
+// buckets are kept.
+type Flags struct {
+	Store     string
+	RedisAddr string
+	IP        string
+	APIKey    string
+	Routes    string
+	// TrustedProxies lists the CIDRs of proxies whose X-Forwarded-For
+	// headers are believed.
+	TrustedProxies string
+}
+
+// RegisterFlags registers -rate-limit-store, -rate-limit-redis-addr,
+// -rate-limit-ip, -rate-limit-api-key, -rate-limit-routes and
+// -rate-limit-trusted-proxies on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.Store, "rate-limit-store", StoreMemory, "Where to keep rate limit buckets: none (no limits), memory (for one gateway) or redis (shared by every gateway)")
+	fs.StringVar(&f.RedisAddr, "rate-limit-redis-addr", "localhost:6379", "Address of the Redis server the redis store uses")
+	fs.StringVar(&f.IP, "rate-limit-ip", "1200/m", "Requests each client IP may make, such as 1200/m; 0 for no limit")
+	fs.StringVar(&f.APIKey, "rate-limit-api-key", "6000/m", "Requests each partner API key may make; 0 for no limit")
+	fs.StringVar(&f.TrustedProxies, "rate-limit-trusted-proxies", "", "Comma-separated CIDRs of the load balancers and proxies in front of the gateway, such as 10.0.0.0/8, whose X-Forwarded-For headers name the client IP; the peer address is the client IP when empty")
+	fs.StringVar(&f.Routes, "rate-limit-routes", "", "Requests each caller may make to a route, by gRPC method, such as betting.Betting/PlaceBet=30/m,racing.Racing/ListRaces=300/m")
+
+	return &f
+}
+
+// Validate checks that the flags name a known store and parse as quotas.
+func (f *Flags) Validate() error {
+	var errs []error
+	switch f.Store {
+	case StoreNone, StoreMemory:
+	case StoreRedis:
+		if _, _, err := net.SplitHostPort(f.RedisAddr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid rate limit Redis address %q: %w", f.RedisAddr, err))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown rate limit store %q: want none, memory or redis", f.Store))
+	}
+
+	_, _, _, err := f.quotas()
+	_, proxiesErr := f.trustedProxies()
+	return errors.Join(append(errs, err, proxiesErr)...)
+}
+
+// New returns the limiter the flags configure, or nil when the store is
+// none, and a function releasing its store.
+func (f *Flags) New() (*Limiter, func() error, error) {
+	if err := f.Validate(); err != nil {
+		return nil, nil, err
+	}
+	ip, apiKey, routes, _ := f.quotas()
+	proxies, _ := f.trustedProxies()
+
+	var (
+		store   Store
+		closeFn = func() error { return nil }
+	)
+	switch f.Store {
+	case StoreMemory:
+		store = NewMemoryStore()
+	case StoreRedis:
+		redisStore := NewRedisStore(f.RedisAddr)
+		store, closeFn = redisStore, redisStore.Close
+	default:
+		return nil, closeFn, nil
+	}
+
+	l := New(store, ip, apiKey, routes)
+	l.TrustedProxies = proxies
+	return l, closeFn, nil
+}
+
+func (f *Flags) quotas() (ip, apiKey Quota, routes map[string]Quota, err error) {
+	if ip, err = ParseQuota(f.IP); err != nil {
+		return ip, apiKey, nil, fmt.Errorf("invalid -rate-limit-ip: %w", err)
+	}
+	if apiKey, err = ParseQuota(f.APIKey); err != nil {
+		return ip, apiKey, nil, fmt.Errorf("invalid -rate-limit-api-key: %w", err)
+	}
+	if routes, err = ParseRouteQuotas(f.Routes); err != nil {
+		return ip, apiKey, nil, fmt.Errorf("invalid -rate-limit-routes: %w", err)
+	}
+	return ip, apiKey, routes, nil
+}
+
+// trustedProxies parses -rate-limit-trusted-proxies. A bare address is a
+// proxy of its own.
+func (f *Flags) trustedProxies() ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, cidr := range strings.Split(f.TrustedProxies, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid -rate-limit-trusted-proxies: %q is not a CIDR such as 10.0.0.0/8", cidr)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
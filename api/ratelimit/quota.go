@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota is a token bucket: up to Limit requests at once, refilled at Limit
+// per Period. A zero Quota doesn't limit anything.
+type Quota struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseQuota parses a quota such as "600/m": a limit, and a period of s, m,
+// h or a duration such as 10s. An empty string or "0" is no limit.
+func ParseQuota(s string) (Quota, error) {
+	if s == "" || s == "0" {
+		return Quota{}, nil
+	}
+
+	limit, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Quota{}, fmt.Errorf("quota %q: want a limit per period, such as 600/m", s)
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		return Quota{}, fmt.Errorf("quota %q: limit must be a positive number", s)
+	}
+
+	q := Quota{Limit: n}
+	switch period {
+	case "s":
+		q.Period = time.Second
+	case "m":
+		q.Period = time.Minute
+	case "h":
+		q.Period = time.Hour
+	default:
+		if q.Period, err = time.ParseDuration(period); err != nil || q.Period <= 0 {
+			return Quota{}, fmt.Errorf("quota %q: period must be s, m, h or a positive duration", s)
+		}
+	}
+	return q, nil
+}
+
+// ParseRouteQuotas parses a comma-separated list of quotas by gRPC method,
+// such as "betting.Betting/PlaceBet=30/m,racing.Racing/ListRaces=300/m".
+func ParseRouteQuotas(s string) (map[string]Quota, error) {
+	quotas := make(map[string]Quota)
+	if s == "" {
+		return quotas, nil
+	}
+
+	for _, route := range strings.Split(s, ",") {
+		method, quota, ok := strings.Cut(strings.TrimSpace(route), "=")
+		if !ok || !strings.Contains(method, "/") {
+			return nil, fmt.Errorf("route quota %q: want service/method=quota, such as betting.Betting/PlaceBet=30/m", route)
+		}
+		q, err := ParseQuota(quota)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", method, err)
+		}
+		quotas["/"+strings.TrimPrefix(method, "/")] = q
+	}
+	return quotas, nil
+}
+
+// perNanosecond is the rate q refills at.
+func (q Quota) perNanosecond() float64 {
+	return float64(q.Limit) / float64(q.Period)
+}
+
+// decide describes the bucket of q left holding tokens, after a request
+// that was allowed or not.
+func (q Quota) decide(allowed bool, tokens float64) Decision {
+	rate := q.perNanosecond()
+	d := Decision{
+		Allowed:   allowed,
+		Limit:     q.Limit,
+		Remaining: int(tokens),
+		Reset:     time.Duration(math.Round((float64(q.Limit) - tokens) / rate)),
+	}
+	if !allowed {
+		d.RetryAfter = time.Duration(math.Round((1 - tokens) / rate))
+	}
+	return d
+}
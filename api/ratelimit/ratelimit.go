@@ -0,0 +1,308 @@
+// Package ratelimit limits how often each caller may call the gateway's
+// backends, with token buckets per client IP, per API key and per route.
+//
+// Every request takes a token from its client IP's bucket before it's
+// authenticated or routed, so unauthenticated callers, documentation and
+// unknown paths are limited too. API key and route buckets are taken from as
+// the gateway calls a backend, so a caller out of those tokens gets a
+// ResourceExhausted status, which the gateway answers with 429 Too Many
+// Requests. Every answer carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers for the tightest bucket the request took from,
+// however many backend calls it made, and a 429 carries Retry-After.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"git.neds.sh/matty/entain/api/auth"
+)
+
+// Limiter enforces quotas, keeping its buckets in a Store. A nil Limiter
+// limits nothing.
+type Limiter struct {
+	store Store
+	// IP limits each client IP address.
+	IP Quota
+	// APIKey limits each partner API key.
+	APIKey Quota
+	// Routes limit each caller's calls to a gRPC method, such as
+	// "/betting.Betting/PlaceBet". Callers are told apart by API key or
+	// token subject, or else by IP address.
+	Routes map[string]Quota
+	// TrustedProxies are the load balancers and proxies in front of the
+	// gateway, whose X-Forwarded-For headers name the client IP.
+	TrustedProxies []netip.Prefix
+}
+
+// New returns a Limiter keeping buckets in store.
+func New(store Store, ip, apiKey Quota, routes map[string]Quota) *Limiter {
+	return &Limiter{store: store, IP: ip, APIKey: apiKey, Routes: routes}
+}
+
+// request is what the interceptor needs of the HTTP request a call is made
+// for, and the tightest decision made for it.
+type request struct {
+	ip string
+
+	mu       sync.Mutex
+	decision *Decision
+}
+
+// decide records d when it limits the caller more than every decision made
+// for the request so far.
+func (r *request) decide(d Decision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.decision == nil || tighter(d, *r.decision) {
+		r.decision = &d
+	}
+}
+
+type requestKey struct{}
+
+// Handler takes a token from the client IP's bucket for every request, and
+// answers 429 Too Many Requests itself when it's empty. It passes the IP on
+// to the interceptor, and sets the rate limit headers on the response from
+// the tightest decision made for the request. Put it in front of everything
+// else, so requests are limited before any work is done on them.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &request{ip: l.clientIP(r)}
+		w = &writer{ResponseWriter: w, req: req}
+
+		if l.IP.Limit > 0 {
+			d, err := l.store.Take(r.Context(), "ip:"+req.ip, l.IP)
+			if err != nil {
+				slog.WarnContext(r.Context(), "rate limiting failed; allowing the request", "error", err)
+			} else {
+				req.decide(d)
+				if !d.Allowed {
+					refuse(w)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestKey{}, req)))
+	})
+}
+
+// refuse answers 429 Too Many Requests with the body the gateway gives a
+// ResourceExhausted status.
+func refuse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}{codes.ResourceExhausted, "rate limit exceeded"})
+}
+
+// clientIP returns the IP address of the client making r: its peer's, unless
+// that's a trusted proxy, when it's the last address in X-Forwarded-For that
+// isn't one. Addresses left of it could be made up by the client.
+func (l *Limiter) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !l.trusted(ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(value, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// A malformed hop can't be trusted, nor anything it forwarded.
+			return ip
+		}
+		ip = hop
+		if !l.trusted(ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+// trusted reports whether ip is one of the trusted proxies.
+func (l *Limiter) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range l.TrustedProxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryClientInterceptor takes a token from the caller's API key and route
+// buckets before calling a backend, and fails the call with ResourceExhausted
+// instead when any is empty. A store that fails is logged and lets the call
+// through, so rate limiting going down doesn't take the gateway with it.
+func (l *Limiter) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		r, _ := ctx.Value(requestKey{}).(*request)
+		if l == nil || r == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		d, err := l.take(ctx, method, r.ip, auth.FromContext(ctx))
+		if err != nil {
+			slog.WarnContext(ctx, "rate limiting failed; allowing the call", "error", err)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if d == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		r.decide(*d)
+
+		if !d.Allowed {
+			st, _ := status.New(codes.ResourceExhausted, "rate limit exceeded").WithDetails(
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryAfter)},
+			)
+			return st.Err()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// take takes a token from each API key and route bucket that applies to a
+// call of method, and
+// returns the tightest decision: a refusal if there is one, else the one
+// with the fewest tokens left. It returns nil if no quota applies. A refused
+// call's tokens are put back in the buckets that allowed it, so it uses up
+// none of the caller's quota.
+func (l *Limiter) take(ctx context.Context, method, ip string, id *auth.Identity) (*Decision, error) {
+	type bucket struct {
+		key   string
+		quota Quota
+	}
+
+	caller := "ip:" + ip
+	var buckets []bucket
+	if id != nil {
+		caller = id.Method + ":" + id.Subject
+		if id.Method == auth.MethodAPIKey {
+			buckets = append(buckets, bucket{caller, l.APIKey})
+		}
+	}
+	buckets = append(buckets, bucket{"route:" + method + ":" + caller, l.Routes[method]})
+
+	var (
+		tightest *Decision
+		taken    []bucket
+	)
+	for _, b := range buckets {
+		if b.quota.Limit == 0 {
+			continue
+		}
+		d, err := l.store.Take(ctx, b.key, b.quota)
+		if err != nil {
+			return nil, err
+		}
+		if d.Allowed {
+			taken = append(taken, b)
+		}
+		if tightest == nil || tighter(d, *tightest) {
+			tightest = &d
+		}
+	}
+
+	if tightest != nil && !tightest.Allowed {
+		for _, b := range taken {
+			if err := l.store.Refund(ctx, b.key, b.quota); err != nil {
+				slog.WarnContext(ctx, "failed to refund a refused call's rate limit token", "error", err)
+			}
+		}
+	}
+	return tightest, nil
+}
+
+// tighter reports whether a limits the caller more than b.
+func tighter(a, b Decision) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+// writer sets the rate limit headers as the response is written.
+type writer struct {
+	http.ResponseWriter
+	req         *request
+	wroteHeader bool
+}
+
+func (w *writer) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setHeaders()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *writer) setHeaders() {
+	w.req.mu.Lock()
+	d := w.req.decision
+	w.req.mu.Unlock()
+	if d == nil {
+		return
+	}
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", seconds(d.Reset))
+	if !d.Allowed {
+		h.Set("Retry-After", seconds(d.RetryAfter))
+	}
+}
+
+// seconds rounds d up to whole seconds, so a caller waiting that long finds
+// the token there.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
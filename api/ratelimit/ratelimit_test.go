@@ -0,0 +1,385 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"git.neds.sh/matty/entain/api/auth"
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+func TestParseQuota(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quota
+		wantErr string
+	}{
+		{in: "600/m", want: Quota{Limit: 600, Period: time.Minute}},
+		{in: "5/s", want: Quota{Limit: 5, Period: time.Second}},
+		{in: "100/h", want: Quota{Limit: 100, Period: time.Hour}},
+		{in: "30/10s", want: Quota{Limit: 30, Period: 10 * time.Second}},
+		{in: "0"},
+		{in: ""},
+		{in: "600", wantErr: "want a limit per period"},
+		{in: "-1/m", wantErr: "limit must be a positive number"},
+		{in: "10/fortnight", wantErr: "period must be s, m, h or a positive duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseQuota(tt.in)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRouteQuotas(t *testing.T) {
+	got, err := ParseRouteQuotas("betting.Betting/PlaceBet=30/m, /racing.Racing/ListRaces=5/s")
+	require.NoError(t, err)
+	require.Equal(t, map[string]Quota{
+		"/betting.Betting/PlaceBet": {Limit: 30, Period: time.Minute},
+		"/racing.Racing/ListRaces":  {Limit: 5, Period: time.Second},
+	}, got)
+
+	_, err = ParseRouteQuotas("PlaceBet=30/m")
+	require.ErrorContains(t, err, "want service/method=quota")
+	_, err = ParseRouteQuotas("betting.Betting/PlaceBet=lots")
+	require.ErrorContains(t, err, "route betting.Betting/PlaceBet")
+}
+
+func TestStores(t *testing.T) {
+	start := time.Now()
+	now := start
+	clock := func() time.Time { return now }
+
+	memory := NewMemoryStore()
+	memory.now = clock
+	redisStore := NewRedisStore(miniredis.RunT(t).Addr())
+	redisStore.now = clock
+	t.Cleanup(func() { redisStore.Close() })
+
+	for name, store := range map[string]Store{"memory": memory, "redis": redisStore} {
+		t.Run(name, func(t *testing.T) {
+			now = start
+			ctx := context.Background()
+			q := Quota{Limit: 2, Period: time.Second}
+
+			d, err := store.Take(ctx, "ip:192.0.2.1", q)
+			require.NoError(t, err)
+			require.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}, d)
+
+			d, err = store.Take(ctx, "ip:192.0.2.1", q)
+			require.NoError(t, err)
+			require.True(t, d.Allowed)
+			require.Equal(t, 0, d.Remaining)
+
+			d, err = store.Take(ctx, "ip:192.0.2.1", q)
+			require.NoError(t, err)
+			require.False(t, d.Allowed)
+			require.Equal(t, 500*time.Millisecond, d.RetryAfter)
+			require.Equal(t, time.Second, d.Reset)
+
+			// Other keys have buckets of their own.
+			d, err = store.Take(ctx, "ip:192.0.2.2", q)
+			require.NoError(t, err)
+			require.True(t, d.Allowed)
+
+			// Half a period refills half the bucket.
+			now = now.Add(500 * time.Millisecond)
+			d, err = store.Take(ctx, "ip:192.0.2.1", q)
+			require.NoError(t, err)
+			require.True(t, d.Allowed)
+			require.Equal(t, 0, d.Remaining)
+
+			// A refunded token can be taken again, and refunds don't fill a
+			// bucket past its capacity.
+			require.NoError(t, store.Refund(ctx, "ip:192.0.2.1", q))
+			d, err = store.Take(ctx, "ip:192.0.2.1", q)
+			require.NoError(t, err)
+			require.True(t, d.Allowed)
+			require.Equal(t, 0, d.Remaining)
+
+			require.NoError(t, store.Refund(ctx, "ip:192.0.2.3", q))
+			require.NoError(t, store.Refund(ctx, "ip:192.0.2.2", q))
+			require.NoError(t, store.Refund(ctx, "ip:192.0.2.2", q))
+			d, err = store.Take(ctx, "ip:192.0.2.2", q)
+			require.NoError(t, err)
+			require.Equal(t, 1, d.Remaining)
+		})
+	}
+}
+
+func TestMemoryStoreSweeps(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	q := Quota{Limit: 10, Period: time.Second}
+
+	_, _ = store.Take(context.Background(), "gone", q)
+	now = now.Add(time.Second)
+	for range sweepEvery {
+		_, _ = store.Take(context.Background(), "busy", q)
+	}
+	require.NotContains(t, store.buckets, "gone")
+	require.Contains(t, store.buckets, "busy")
+}
+
+// racingServer lists no races.
+type racingServer struct{}
+
+func (racingServer) ListRaces(context.Context, *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
+	return &racing.ListRacesResponse{}, nil
+}
+
+func (racingServer) GetRace(context.Context, *racing.GetRaceRequest) (*racing.GetRaceResponse, error) {
+	return nil, status.Error(codes.NotFound, "race not found")
+}
+
+// racingConn returns a connection to a racing server, calling it through
+// limiter's interceptor.
+func racingConn(t *testing.T, limiter *Limiter) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	racing.RegisterRacingServer(server, racingServer{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(limiter.UnaryClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// gateway serves the racing routes through limiter, calling a racing server
+// over gRPC. Requests with an X-API-Key header are authenticated as that key.
+func gateway(t *testing.T, limiter *Limiter) http.Handler {
+	t.Helper()
+
+	mux := runtime.NewServeMux()
+	require.NoError(t, racing.RegisterRacingHandler(context.Background(), mux, racingConn(t, limiter)))
+
+	return limiter.Handler(authenticate(mux))
+}
+
+// authenticate authenticates requests with an X-API-Key header as that key.
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(auth.APIKeyHeader); key != "" {
+			r = r.WithContext(auth.NewContext(r.Context(), &auth.Identity{Subject: key, Method: auth.MethodAPIKey}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func post(handler http.Handler, remoteAddr, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/list-races", nil)
+	req.RemoteAddr = remoteAddr
+	if apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, apiKey)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{Limit: 2, Period: time.Minute}, Quota{Limit: 3, Period: time.Minute}, nil)
+	handler := gateway(t, limiter)
+
+	rec := post(handler, "192.0.2.1:1234", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "").Code)
+
+	rec = post(handler, "192.0.2.1:5678", "")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "30", rec.Header().Get("Retry-After"))
+	require.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	require.Contains(t, rec.Body.String(), `"code":8`)
+
+	// Another IP has a bucket of its own.
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.2:1234", "").Code)
+}
+
+func TestLimiterAPIKey(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{Limit: 10, Period: time.Minute}, Quota{Limit: 2, Period: time.Minute}, nil)
+	handler := gateway(t, limiter)
+
+	// A key is limited wherever it's used from.
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "partner").Code)
+	rec := post(handler, "192.0.2.2:1234", "partner")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("RateLimit-Limit"), "reports the tighter API key bucket")
+	require.Equal(t, http.StatusTooManyRequests, post(handler, "192.0.2.3:1234", "partner").Code)
+
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.3:1234", "other").Code)
+}
+
+func TestLimiterRoutes(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{}, Quota{}, map[string]Quota{
+		"/racing.Racing/ListRaces": {Limit: 1, Period: time.Second},
+	})
+	handler := gateway(t, limiter)
+
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "").Code)
+	require.Equal(t, http.StatusTooManyRequests, post(handler, "192.0.2.1:1234", "").Code)
+
+	// Routes without a quota aren't limited, and send no headers.
+	req := httptest.NewRequest(http.MethodGet, "/v1/races/1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestLimiterRefundsRefused(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{}, Quota{Limit: 10, Period: time.Minute}, map[string]Quota{
+		"/racing.Racing/ListRaces": {Limit: 1, Period: time.Minute},
+	})
+	handler := gateway(t, limiter)
+
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "partner").Code)
+	for range 3 {
+		require.Equal(t, http.StatusTooManyRequests, post(handler, "192.0.2.1:1234", "partner").Code)
+	}
+
+	// The refused calls took nothing from the API key bucket: the first
+	// call and this one have.
+	req := httptest.NewRequest(http.MethodGet, "/v1/races/1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set(auth.APIKeyHeader, "partner")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, "10", rec.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "8", rec.Header().Get("RateLimit-Remaining"))
+}
+
+func TestLimiterChargesEveryRequest(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{Limit: 2, Period: time.Minute}, Quota{}, nil)
+	authenticated := false
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = true
+		http.NotFound(w, r)
+	}))
+
+	// Requests that never reach a backend, such as for unknown paths, take
+	// from the IP bucket all the same.
+	for _, want := range []int{http.StatusNotFound, http.StatusNotFound, http.StatusTooManyRequests} {
+		authenticated = false
+		req := httptest.NewRequest(http.MethodGet, "/nonexistent", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, want, rec.Code)
+		require.Equal(t, want != http.StatusTooManyRequests, authenticated, "refused requests are answered before authentication")
+	}
+}
+
+func TestLimiterReportsTightestCall(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{Limit: 10, Period: time.Minute}, Quota{}, map[string]Quota{
+		"/racing.Racing/GetRace":   {Limit: 2, Period: time.Minute},
+		"/racing.Racing/ListRaces": {Limit: 5, Period: time.Minute},
+	})
+	client := racing.NewRacingClient(racingConn(t, limiter))
+
+	// A request fanning out to several backend calls reports the tightest
+	// bucket any of them took from, not the last.
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = client.GetRace(r.Context(), &racing.GetRaceRequest{Id: 1})
+		_, err := client.ListRaces(r.Context(), &racing.ListRacesRequest{})
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := post(handler, "192.0.2.1:1234", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+}
+
+func TestLimiterTrustedProxies(t *testing.T) {
+	limiter := New(NewMemoryStore(), Quota{Limit: 1, Period: time.Minute}, Quota{}, nil)
+	limiter.TrustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	handler := gateway(t, limiter)
+
+	post := func(remoteAddr string, forwardedFor ...string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/list-races", nil)
+		req.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Clients behind the proxies have buckets of their own, whichever proxy
+	// forwards them.
+	require.Equal(t, http.StatusOK, post("10.0.0.1:1234", "192.0.2.1"))
+	require.Equal(t, http.StatusOK, post("10.0.0.1:1234", "192.0.2.2"))
+	require.Equal(t, http.StatusTooManyRequests, post("10.0.0.2:1234", "192.0.2.1"))
+
+	// Addresses a client makes up, left of those the proxies add, are
+	// ignored; so are the proxies themselves.
+	require.Equal(t, http.StatusTooManyRequests, post("10.0.0.1:1234", "198.51.100.1, 192.0.2.1, 10.0.0.3"))
+	require.Equal(t, http.StatusTooManyRequests, post("10.0.0.1:1234", "198.51.100.1", "192.0.2.2"))
+
+	// Nor is X-Forwarded-For believed from anyone else.
+	require.Equal(t, http.StatusOK, post("192.0.2.9:1234", "198.51.100.2"))
+	require.Equal(t, http.StatusTooManyRequests, post("192.0.2.9:1234", "198.51.100.3"))
+}
+
+func TestLimiterFailsOpen(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := NewRedisStore(server.Addr())
+	t.Cleanup(func() { store.Close() })
+	server.Close()
+
+	handler := gateway(t, New(store, Quota{Limit: 1, Period: time.Minute}, Quota{}, nil))
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "").Code)
+	require.Equal(t, http.StatusOK, post(handler, "192.0.2.1:1234", "").Code)
+}
+
+func TestFlagsValidate(t *testing.T) {
+	f := Flags{Store: StoreMemory, IP: "10/s", APIKey: "0", Routes: "betting.Betting/PlaceBet=1/s"}
+	require.NoError(t, f.Validate())
+
+	f = Flags{Store: "etcd", IP: "lots", APIKey: "0", Routes: "PlaceBet"}
+	err := f.Validate()
+	require.ErrorContains(t, err, `unknown rate limit store "etcd"`)
+	require.ErrorContains(t, err, "invalid -rate-limit-ip")
+
+	f = Flags{Store: StoreMemory, TrustedProxies: "10.0.0.0/8, 192.0.2.1"}
+	require.NoError(t, f.Validate())
+	f = Flags{Store: StoreMemory, TrustedProxies: "10.0.0.0/33"}
+	require.ErrorContains(t, f.Validate(), "invalid -rate-limit-trusted-proxies")
+}
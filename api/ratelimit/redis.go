@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// take refills and takes a token from the bucket in KEYS[1] atomically. Its
+// arguments are the capacity, the refill rate in tokens per millisecond, the
+// time in milliseconds and how long an untouched bucket lasts. It returns
+// whether the token was taken, and the tokens left.
+var take = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1]) or capacity
+local updated = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`)
+
+// refund puts a token back in the bucket in KEYS[1], up to the capacity in
+// ARGV[1], if the bucket is still there.
+var refund = redis.NewScript(`
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+if tokens then
+  redis.call('HSET', KEYS[1], 'tokens', tostring(math.min(tonumber(ARGV[1]), tokens + 1)))
+end
+return 0
+`)
+
+// RedisStore holds buckets in a Redis server, or anything that speaks its
+// protocol, shared by every gateway pointed at it.
+type RedisStore struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+// NewRedisStore returns a store in the Redis server at addr. It connects on
+// first use.
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{client: redis.NewClient(&redis.Options{Addr: addr}), now: time.Now}
+}
+
+// Take takes a token from the bucket key in one round trip.
+func (s *RedisStore) Take(ctx context.Context, key string, q Quota) (Decision, error) {
+	// A bucket left alone for a period has refilled, so needn't be kept.
+	expiry := q.Period.Milliseconds() + 1
+	result, err := take.Run(ctx, s.client, []string{"ratelimit:" + key},
+		q.Limit, q.perNanosecond()*float64(time.Millisecond), s.now().UnixMilli(), expiry,
+	).Slice()
+	if err != nil {
+		return Decision{}, err
+	}
+
+	allowed, _ := result[0].(int64)
+	left, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return Decision{}, err
+	}
+	return q.decide(allowed == 1, tokens), nil
+}
+
+// Refund puts a token back in the bucket key in one round trip.
+func (s *RedisStore) Refund(ctx context.Context, key string, q Quota) error {
+	return refund.Run(ctx, s.client, []string{"ratelimit:" + key}, q.Limit).Err()
+}
+
+// Close closes the connections to the server.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
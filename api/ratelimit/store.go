@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of taking a token from a bucket.
+type Decision struct {
+	// Allowed is whether the bucket had a token for the request.
+	Allowed bool
+	// Limit is the bucket's capacity.
+	Limit int
+	// Remaining is how many tokens are left.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a refused request would be allowed.
+	RetryAfter time.Duration
+}
+
+// Store holds token buckets by key.
+type Store interface {
+	// Take takes a token from the bucket key, of quota q, if it has one.
+	Take(ctx context.Context, key string, q Quota) (Decision, error)
+	// Refund puts back a token taken from the bucket key, of quota q, for a
+	// request another bucket refused.
+	Refund(ctx context.Context, key string, q Quota) error
+}
+
+// sweepEvery is how many takes a MemoryStore lets pass between sweeps of the
+// buckets that have refilled.
+const sweepEvery = 1024
+
+// MemoryStore holds buckets in process, for a gateway running on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // When the bucket will have refilled, and can be dropped.
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take takes a token from the bucket key, refilling it for the time since
+// the last take first.
+func (s *MemoryStore) Take(_ context.Context, key string, q Quota) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(q.Limit), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = min(float64(q.Limit), b.tokens+float64(now.Sub(b.updated))*q.perNanosecond())
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	d := q.decide(allowed, b.tokens)
+	b.full = now.Add(d.Reset)
+	return d, nil
+}
+
+// Refund puts a token back in the bucket key, up to its capacity.
+func (s *MemoryStore) Refund(_ context.Context, key string, q Quota) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = min(float64(q.Limit), b.tokens+1)
+	}
+	return nil
+}
+
+// sweep drops the buckets that have refilled, every sweepEvery takes, so
+// callers that have gone away don't hold memory.
+func (s *MemoryStore) sweep(now time.Time) {
+	if s.takes++; s.takes < sweepEvery {
+		return
+	}
+	s.takes = 0
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"git.neds.sh/matty/entain/pkg/metrics"
+)
+
+// ReasonCircuitOpen is the ErrorInfo reason on calls refused by an open
+// breaker.
+const ReasonCircuitOpen = "CIRCUIT_OPEN"
+
+// Breaker stops calling a backend after Failures calls in a row fail, and
+// refuses calls straight away for Cooldown. After that, one call is let
+// through to probe the backend: if it succeeds, calls flow again; if not,
+// the breaker opens for another Cooldown.
+type Breaker struct {
+	backend  string
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	failed   int
+	openedAt time.Time
+	open     bool
+	probing  bool
+}
+
+// NewBreaker returns a closed breaker in front of backend, a name such as
+// "racing" used in errors, logs and metrics.
+func NewBreaker(backend string, failures int, cooldown time.Duration) *Breaker {
+	metrics.SetBreakerOpen(backend, false)
+	return &Breaker{backend: backend, failures: failures, cooldown: cooldown, now: time.Now}
+}
+
+// UnaryClientInterceptor refuses calls with Unavailable while the breaker is
+// open, and counts the calls it lets through that fail. The gateway answers
+// a refused call with 503 Service Unavailable.
+func (b *Breaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if b == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if err := b.allow(); err != nil {
+			return err
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(ctx, err)
+		return err
+	}
+}
+
+// allow returns the error refusing a call, or nil to let it through.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	wait := b.openedAt.Add(b.cooldown).Sub(b.now())
+	if wait <= 0 && !b.probing {
+		b.probing = true
+		return nil
+	}
+
+	st, _ := status.New(codes.Unavailable, b.backend+" service unavailable: circuit breaker open").WithDetails(
+		&errdetails.ErrorInfo{Reason: ReasonCircuitOpen, Domain: "api", Metadata: map[string]string{"backend": b.backend}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(max(wait, 0))},
+	)
+	return st.Err()
+}
+
+// record counts the outcome of a call let through.
+func (b *Breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := b.open
+	b.probing = false
+
+	// A call its caller gave up on says nothing about the backend.
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	if !failure(err) {
+		b.failed = 0
+		b.open = false
+		if wasOpen {
+			slog.InfoContext(ctx, "circuit breaker closed", "backend", b.backend)
+			metrics.SetBreakerOpen(b.backend, false)
+		}
+		return
+	}
+
+	b.failed++
+	if wasOpen || b.failed >= b.failures {
+		b.open = true
+		b.openedAt = b.now()
+		if !wasOpen {
+			slog.WarnContext(ctx, "circuit breaker opened", "backend", b.backend, "failures", b.failed, "cooldown", b.cooldown.String(), "error", err)
+			metrics.SetBreakerOpen(b.backend, true)
+		}
+	}
+}
+
+// failure reports whether err means the backend is in trouble, rather than
+// the call being refused on its merits.
+func failure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,173 @@
+package resilience
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Flags are the command line options setting how the gateway calls its
+// backends.
+type Flags struct {
+	Balancer         string
+	Timeout          time.Duration
+	RouteTimeouts    string
+	RetryMethods     string
+	MaxAttempts      int
+	Keepalive        time.Duration
+	KeepaliveTimeout time.Duration
+	BreakerFailures  int
+	BreakerCooldown  time.Duration
+
+	OutlierFailurePercentage int
+	OutlierMinRequests       int
+	OutlierInterval          time.Duration
+	OutlierEjectionTime      time.Duration
+}
+
+// RegisterFlags registers -backend-balancer, -backend-timeout,
+// -backend-route-timeouts, -backend-retry-methods, -backend-max-attempts,
+// -backend-keepalive, -backend-keepalive-timeout, -breaker-failures,
+// -breaker-cooldown, -outlier-failure-percentage, -outlier-min-requests,
+// -outlier-interval and -outlier-ejection-time on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.Balancer, "backend-balancer", BalancerRoundRobin, "How to balance calls across a backend's replicas: round_robin or least_request")
+	fs.DurationVar(&f.Timeout, "backend-timeout", 5*time.Second, "Deadline for each call to a backend, retries included")
+	fs.StringVar(&f.RouteTimeouts, "backend-route-timeouts", "racing.Racing/ListRaces=2s,racing.Racing/GetRace=1s,sports.Sports/ListEvents=2s", "Deadlines by gRPC method, overriding -backend-timeout, such as betting.Betting/PlaceBet=10s")
+	fs.StringVar(&f.RetryMethods, "backend-retry-methods", "racing.Racing/ListRaces,racing.Racing/GetRace,sports.Sports/ListEvents", "Idempotent gRPC methods retried when their backend is unavailable")
+	fs.IntVar(&f.MaxAttempts, "backend-max-attempts", 3, "Most times a retried method is tried; 1 for no retries")
+	fs.DurationVar(&f.Keepalive, "backend-keepalive", 30*time.Second, "How often to ping an idle backend connection; 0 for never")
+	fs.DurationVar(&f.KeepaliveTimeout, "backend-keepalive-timeout", 10*time.Second, "How long to wait for a ping's answer before closing the connection")
+	fs.IntVar(&f.BreakerFailures, "breaker-failures", 5, "Calls to a backend failing in a row that open its circuit breaker; 0 for no breaker")
+	fs.DurationVar(&f.BreakerCooldown, "breaker-cooldown", 10*time.Second, "How long an open circuit breaker refuses calls before trying the backend again")
+	fs.IntVar(&f.OutlierFailurePercentage, "outlier-failure-percentage", 50, "Percentage of its calls in an interval a replica must fail for more than to be ejected from balancing; 0 to eject none")
+	fs.IntVar(&f.OutlierMinRequests, "outlier-min-requests", 20, "Calls a replica must have taken in an interval to be considered for ejection")
+	fs.DurationVar(&f.OutlierInterval, "outlier-interval", 10*time.Second, "How often replicas' failures are counted up and ejections decided")
+	fs.DurationVar(&f.OutlierEjectionTime, "outlier-ejection-time", 30*time.Second, "How long a replica is first ejected for, longer each time it's ejected again")
+
+	return &f
+}
+
+// Validate checks that the flags name a known balancer, are in range and
+// name gRPC methods.
+func (f *Flags) Validate() error {
+	var errs []error
+	if f.Balancer != BalancerRoundRobin && f.Balancer != BalancerLeastRequest {
+		errs = append(errs, fmt.Errorf("unknown -backend-balancer %q: want round_robin or least_request", f.Balancer))
+	}
+	if f.Timeout < 0 {
+		errs = append(errs, errors.New("-backend-timeout must not be negative"))
+	}
+	if f.MaxAttempts < 1 || f.MaxAttempts > 5 {
+		// gRPC caps attempts at 5.
+		errs = append(errs, fmt.Errorf("-backend-max-attempts must be from 1 to 5, not %d", f.MaxAttempts))
+	}
+	if f.Keepalive < 0 {
+		errs = append(errs, errors.New("-backend-keepalive must not be negative"))
+	}
+	if f.KeepaliveTimeout <= 0 {
+		errs = append(errs, errors.New("-backend-keepalive-timeout must be positive"))
+	}
+	if f.BreakerFailures < 0 {
+		errs = append(errs, errors.New("-breaker-failures must not be negative"))
+	}
+	if f.BreakerCooldown <= 0 {
+		errs = append(errs, errors.New("-breaker-cooldown must be positive"))
+	}
+	if f.OutlierFailurePercentage < 0 || f.OutlierFailurePercentage > 100 {
+		errs = append(errs, fmt.Errorf("-outlier-failure-percentage must be from 0 to 100, not %d", f.OutlierFailurePercentage))
+	}
+	if f.OutlierFailurePercentage > 0 {
+		if f.OutlierMinRequests < 1 {
+			errs = append(errs, errors.New("-outlier-min-requests must be positive"))
+		}
+		if f.OutlierInterval <= 0 {
+			errs = append(errs, errors.New("-outlier-interval must be positive"))
+		}
+		if f.OutlierEjectionTime <= 0 {
+			errs = append(errs, errors.New("-outlier-ejection-time must be positive"))
+		}
+	}
+
+	_, err := f.policy()
+	return errors.Join(append(errs, err)...)
+}
+
+// DialOptions returns the options for the connection to backend, a name
+// such as "racing", serving the gRPC service, such as "racing.Racing". Each
+// backend needs options of its own, so its breaker opens on its failures
+// alone.
+func (f *Flags) DialOptions(backend, service string) []grpc.DialOption {
+	p, _ := f.policy()
+	opts := []grpc.DialOption{grpc.WithDefaultServiceConfig(p.ServiceConfig(service))}
+
+	if f.Keepalive > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                f.Keepalive,
+			Timeout:             f.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if f.BreakerFailures > 0 {
+		breaker := NewBreaker(backend, f.BreakerFailures, f.BreakerCooldown)
+		opts = append(opts, grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor()))
+	}
+	return opts
+}
+
+// HealthDialOptions returns the options for a connection checking the health
+// of the backend serving service, such as "racing.Racing". It's balanced
+// across replicas like the backend's own connection, skipping those not
+// serving, so the backend is healthy while any replica is.
+func (f *Flags) HealthDialOptions(service string) []grpc.DialOption {
+	p, _ := f.policy()
+	return []grpc.DialOption{grpc.WithDefaultServiceConfig(p.ServiceConfig(service))}
+}
+
+func (f *Flags) policy() (Policy, error) {
+	p := Policy{Balancer: f.Balancer, Timeout: f.Timeout, Timeouts: make(map[string]time.Duration), MaxAttempts: f.MaxAttempts}
+	p.Outliers = Outliers{
+		FailurePercentage: f.OutlierFailurePercentage,
+		MinRequests:       f.OutlierMinRequests,
+		Interval:          f.OutlierInterval,
+		EjectionTime:      f.OutlierEjectionTime,
+	}
+
+	for _, route := range split(f.RouteTimeouts) {
+		method, timeout, ok := strings.Cut(route, "=")
+		if !ok || !strings.Contains(method, "/") {
+			return p, fmt.Errorf("invalid -backend-route-timeouts: %q: want service/method=timeout, such as betting.Betting/PlaceBet=10s", route)
+		}
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return p, fmt.Errorf("invalid -backend-route-timeouts: %s: timeout must be a positive duration", method)
+		}
+		p.Timeouts["/"+strings.TrimPrefix(method, "/")] = d
+	}
+
+	for _, method := range split(f.RetryMethods) {
+		if !strings.Contains(method, "/") {
+			return p, fmt.Errorf("invalid -backend-retry-methods: %q: want service/method, such as racing.Racing/ListRaces", method)
+		}
+		p.Retry = append(p.Retry, "/"+strings.TrimPrefix(method, "/"))
+	}
+	return p, nil
+}
+
+// split splits a comma-separated list, dropping blanks.
+func split(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,199 @@
+// Package resilience keeps the gateway answering when a backend is slow or
+// down.
+//
+// Calls are balanced across a backend's replicas, skipping any its health
+// checks report not serving, and ejecting for a while any failing too many
+// of its calls. Each call to a backend has a deadline, per gRPC
+// method, so a slow backend can't hold a request forever. Idempotent methods
+// are retried with backoff when their backend is unavailable, and keepalive
+// pings find dead connections while they are idle. A circuit breaker per
+// backend stops calling one that keeps failing, so the gateway answers 503
+// Service Unavailable at once instead of waiting on it, until a probe finds
+// it healthy again.
+package resilience
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/balancer/roundrobin"
+	// Registers client side health checking.
+	_ "google.golang.org/grpc/health"
+	// Registers the outlier detection balancer.
+	_ "google.golang.org/grpc/xds"
+)
+
+// Policy is how the gateway calls the methods of one backend service.
+type Policy struct {
+	// Timeout is the deadline for each call, retries included, unless
+	// Timeouts sets one for the method.
+	Timeout time.Duration
+	// Timeouts are deadlines by gRPC method, such as
+	// "/racing.Racing/ListRaces".
+	Timeouts map[string]time.Duration
+	// Retry lists the idempotent methods that are retried when the backend
+	// is unavailable.
+	Retry []string
+	// MaxAttempts is how many times a retried method is tried at most.
+	MaxAttempts int
+	// Balancer balances calls across the service's replicas: BalancerRoundRobin
+	// or BalancerLeastRequest. Either checks the health of each replica, and
+	// skips those not serving.
+	Balancer string
+	// Outliers ejects replicas that fail too many of their calls.
+	Outliers Outliers
+}
+
+// Outliers ejects a replica from balancing when more than FailurePercentage
+// of its calls in an Interval fail, given it had at least MinRequests. It's
+// ejected for EjectionTime, longer each time it's ejected again. At most
+// half a backend's replicas are ejected at once, and only while at least two
+// are taking calls. A zero FailurePercentage ejects none.
+type Outliers struct {
+	FailurePercentage int
+	MinRequests       int
+	Interval          time.Duration
+	EjectionTime      time.Duration
+}
+
+// Balancers Policy accepts.
+const (
+	BalancerRoundRobin   = "round_robin"
+	BalancerLeastRequest = "least_request"
+)
+
+// outlierDetection is the name gRPC registers its outlier detection
+// balancer under.
+const outlierDetection = "outlier_detection_experimental"
+
+// The backoff between attempts of a retried call.
+const (
+	initialBackoff = 100 * time.Millisecond
+	maxBackoff     = time.Second
+)
+
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]any   `json:"loadBalancingConfig,omitempty"`
+	HealthCheckConfig   *healthCheckConfig `json:"healthCheckConfig,omitempty"`
+	MethodConfig        []methodConfig     `json:"methodConfig"`
+}
+
+type outlierDetectionConfig struct {
+	Interval                  string                    `json:"interval"`
+	BaseEjectionTime          string                    `json:"baseEjectionTime"`
+	MaxEjectionPercent        int                       `json:"maxEjectionPercent"`
+	FailurePercentageEjection failurePercentageEjection `json:"failurePercentageEjection"`
+	ChildPolicy               []map[string]any          `json:"childPolicy"`
+}
+
+type failurePercentageEjection struct {
+	Threshold             int `json:"threshold"`
+	EnforcementPercentage int `json:"enforcementPercentage"`
+	MinimumHosts          int `json:"minimumHosts"`
+	RequestVolume         int `json:"requestVolume"`
+}
+
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// ServiceConfig returns the gRPC service config applying p to service, such
+// as "racing.Racing", for grpc.WithDefaultServiceConfig.
+func (p Policy) ServiceConfig(service string) string {
+	methods := make(map[string]*methodConfig)
+	method := func(name string) *methodConfig {
+		svc, m, ok := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+		if !ok || svc != service {
+			return nil
+		}
+		if methods[m] == nil {
+			methods[m] = &methodConfig{Name: []methodName{{Service: svc, Method: m}}, Timeout: seconds(p.Timeout)}
+		}
+		return methods[m]
+	}
+
+	for name, timeout := range p.Timeouts {
+		if mc := method(name); mc != nil {
+			mc.Timeout = seconds(timeout)
+		}
+	}
+	if p.MaxAttempts > 1 {
+		for _, name := range p.Retry {
+			if mc := method(name); mc != nil {
+				mc.RetryPolicy = &retryPolicy{
+					MaxAttempts:          p.MaxAttempts,
+					InitialBackoff:       seconds(initialBackoff),
+					MaxBackoff:           seconds(maxBackoff),
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: []string{"UNAVAILABLE"},
+				}
+			}
+		}
+	}
+
+	// The service's own entry covers the methods not named above.
+	config := serviceConfig{MethodConfig: []methodConfig{{Name: []methodName{{Service: service}}, Timeout: seconds(p.Timeout)}}}
+	for _, m := range slices.Sorted(maps.Keys(methods)) {
+		config.MethodConfig = append(config.MethodConfig, *methods[m])
+	}
+
+	switch p.Balancer {
+	case BalancerRoundRobin:
+		config.LoadBalancingConfig = []map[string]any{{roundrobin.Name: struct{}{}}}
+	case BalancerLeastRequest:
+		config.LoadBalancingConfig = []map[string]any{{leastrequest.Name: map[string]int{"choiceCount": 2}}}
+	}
+	if config.LoadBalancingConfig != nil {
+		config.HealthCheckConfig = &healthCheckConfig{ServiceName: service}
+	}
+	if o := p.Outliers; o.FailurePercentage > 0 {
+		config.LoadBalancingConfig = []map[string]any{{outlierDetection: outlierDetectionConfig{
+			Interval:           seconds(o.Interval),
+			BaseEjectionTime:   seconds(o.EjectionTime),
+			MaxEjectionPercent: 50,
+			FailurePercentageEjection: failurePercentageEjection{
+				Threshold:             o.FailurePercentage,
+				EnforcementPercentage: 100,
+				MinimumHosts:          2,
+				RequestVolume:         o.MinRequests,
+			},
+			ChildPolicy: config.LoadBalancingConfig,
+		}}}
+	}
+
+	b, _ := json.Marshal(config)
+	return string(b)
+}
+
+// seconds formats d as a service config duration, such as "1.5s", or ""
+// for no deadline.
+func seconds(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
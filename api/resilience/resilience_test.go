@@ -0,0 +1,344 @@
+package resilience
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+func TestServiceConfig(t *testing.T) {
+	p := Policy{
+		Timeout:     5 * time.Second,
+		Timeouts:    map[string]time.Duration{"/racing.Racing/GetRace": 1500 * time.Millisecond, "/sports.Sports/ListEvents": time.Second},
+		Retry:       []string{"/racing.Racing/ListRaces", "/sports.Sports/ListEvents"},
+		MaxAttempts: 3,
+	}
+	require.JSONEq(t, `{"methodConfig":[
+		{"name":[{"service":"racing.Racing"}],"timeout":"5s"},
+		{"name":[{"service":"racing.Racing","method":"GetRace"}],"timeout":"1.5s"},
+		{"name":[{"service":"racing.Racing","method":"ListRaces"}],"timeout":"5s","retryPolicy":{
+			"maxAttempts":3,"initialBackoff":"0.1s","maxBackoff":"1s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE"]
+		}}
+	]}`, p.ServiceConfig("racing.Racing"))
+
+	p = Policy{Retry: []string{"/racing.Racing/ListRaces"}, MaxAttempts: 1, Balancer: BalancerLeastRequest}
+	require.JSONEq(t, `{
+		"loadBalancingConfig":[{"least_request_experimental":{"choiceCount":2}}],
+		"healthCheckConfig":{"serviceName":"racing.Racing"},
+		"methodConfig":[{"name":[{"service":"racing.Racing"}]}]
+	}`, p.ServiceConfig("racing.Racing"))
+}
+
+func TestServiceConfigOutliers(t *testing.T) {
+	p := Policy{
+		Balancer: BalancerRoundRobin,
+		Outliers: Outliers{FailurePercentage: 50, MinRequests: 20, Interval: 10 * time.Second, EjectionTime: 30 * time.Second},
+	}
+	require.JSONEq(t, `{
+		"loadBalancingConfig":[{"outlier_detection_experimental":{
+			"interval":"10s","baseEjectionTime":"30s","maxEjectionPercent":50,
+			"failurePercentageEjection":{"threshold":50,"enforcementPercentage":100,"minimumHosts":2,"requestVolume":20},
+			"childPolicy":[{"round_robin":{}}]
+		}}],
+		"healthCheckConfig":{"serviceName":"racing.Racing"},
+		"methodConfig":[{"name":[{"service":"racing.Racing"}]}]
+	}`, p.ServiceConfig("racing.Racing"))
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker("racing", 2, 10*time.Second)
+	breaker.now = func() time.Time { return now }
+	interceptor := breaker.UnaryClientInterceptor()
+
+	var calls int
+	call := func(err error) error {
+		return interceptor(context.Background(), "/racing.Racing/ListRaces", nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				calls++
+				return err
+			})
+	}
+	down := status.Error(codes.Unavailable, "connection refused")
+
+	// Answers on a call's merits don't count against the backend.
+	require.Error(t, call(status.Error(codes.NotFound, "race not found")))
+	require.Error(t, call(down))
+	require.NoError(t, call(nil))
+	require.Error(t, call(down))
+	require.Error(t, call(down))
+	require.Equal(t, 5, calls)
+
+	// Open, it refuses calls without making them.
+	err := call(nil)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Contains(t, err.Error(), "racing service unavailable: circuit breaker open")
+	require.Equal(t, 5, calls)
+
+	// After the cooldown, a failed probe opens it again.
+	now = now.Add(10 * time.Second)
+	require.ErrorIs(t, call(down), down)
+	require.Equal(t, 6, calls)
+	require.Error(t, call(nil))
+	require.Equal(t, 6, calls)
+
+	// A probe that succeeds closes it.
+	now = now.Add(10 * time.Second)
+	require.NoError(t, call(nil))
+	require.NoError(t, call(nil))
+	require.Equal(t, 8, calls)
+}
+
+func TestBreakerProbesOnce(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker("racing", 1, time.Second)
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(context.Background(), status.Error(codes.Unavailable, "down"))
+	now = now.Add(time.Second)
+
+	require.NoError(t, breaker.allow(), "the first call probes")
+	require.Error(t, breaker.allow(), "others wait for the probe")
+}
+
+// racingServer answers with err, after a delay, counting the calls it gets.
+type racingServer struct {
+	calls atomic.Int32
+	fail  atomic.Int32
+	delay time.Duration
+}
+
+func (s *racingServer) ListRaces(ctx context.Context, _ *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
+	s.calls.Add(1)
+	if s.fail.Add(-1) >= 0 {
+		return nil, status.Error(codes.Unavailable, "overloaded")
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &racing.ListRacesResponse{}, nil
+}
+
+func (s *racingServer) GetRace(context.Context, *racing.GetRaceRequest) (*racing.GetRaceResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.Unavailable, "overloaded")
+}
+
+// gateway serves the racing routes, calling server with the options flags
+// set.
+func gateway(t *testing.T, flags *Flags, server *racingServer) http.Handler {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	racing.RegisterRacingServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	require.NoError(t, flags.Validate())
+	opts := append(flags.DialOptions("racing", racing.Racing_ServiceDesc.ServiceName),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	mux := runtime.NewServeMux()
+	require.NoError(t, racing.RegisterRacingHandler(context.Background(), mux, conn))
+	return mux
+}
+
+func flags() *Flags {
+	return &Flags{
+		Balancer:         BalancerRoundRobin,
+		Timeout:          time.Second,
+		RetryMethods:     "racing.Racing/ListRaces",
+		MaxAttempts:      3,
+		KeepaliveTimeout: time.Second,
+		BreakerFailures:  2,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+func serve(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestGatewayRetries(t *testing.T) {
+	server := &racingServer{}
+	server.fail.Store(2)
+	handler := gateway(t, flags(), server)
+
+	require.Equal(t, http.StatusOK, serve(handler, http.MethodPost, "/v1/list-races").Code)
+	require.EqualValues(t, 3, server.calls.Load())
+
+	// Methods not listed aren't retried.
+	require.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/v1/races/1").Code)
+	require.EqualValues(t, 4, server.calls.Load())
+}
+
+func TestGatewayTimeouts(t *testing.T) {
+	f := flags()
+	f.RouteTimeouts = "racing.Racing/ListRaces=50ms"
+	handler := gateway(t, f, &racingServer{delay: time.Second})
+
+	start := time.Now()
+	require.Equal(t, http.StatusGatewayTimeout, serve(handler, http.MethodPost, "/v1/list-races").Code)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestGatewayBreaker(t *testing.T) {
+	server := &racingServer{}
+	handler := gateway(t, flags(), server)
+
+	require.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/v1/races/1").Code)
+	require.Equal(t, http.StatusServiceUnavailable, serve(handler, http.MethodGet, "/v1/races/1").Code)
+	require.EqualValues(t, 2, server.calls.Load())
+
+	rec := serve(handler, http.MethodPost, "/v1/list-races")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "racing service unavailable: circuit breaker open")
+	require.Contains(t, rec.Body.String(), ReasonCircuitOpen)
+	require.EqualValues(t, 2, server.calls.Load(), "an open breaker doesn't call the backend")
+}
+
+func TestHealthDialOptions(t *testing.T) {
+	// The first replica has stopped serving; the second still serves.
+	var addrs []resolver.Address
+	for _, serving := range []healthpb.HealthCheckResponse_ServingStatus{healthpb.HealthCheckResponse_NOT_SERVING, healthpb.HealthCheckResponse_SERVING} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := grpc.NewServer()
+		hs := grpchealth.NewServer()
+		hs.SetServingStatus("", serving)
+		hs.SetServingStatus(racing.Racing_ServiceDesc.ServiceName, serving)
+		healthpb.RegisterHealthServer(s, hs)
+		go func() { _ = s.Serve(lis) }()
+		t.Cleanup(s.Stop)
+		addrs = append(addrs, resolver.Address{Addr: lis.Addr().String()})
+	}
+
+	r := manual.NewBuilderWithScheme("replicas")
+	r.InitialState(resolver.State{Addresses: addrs})
+	opts := append(flags().HealthDialOptions(racing.Racing_ServiceDesc.ServiceName),
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("replicas:///racing", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	client := healthpb.NewHealthClient(conn)
+	for range 10 {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+		require.NoError(t, err)
+		require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, "checks skip the replica not serving")
+	}
+}
+
+func TestGatewayEjectsOutliers(t *testing.T) {
+	// The second replica fails every call.
+	good, bad := &racingServer{}, &racingServer{}
+	bad.fail.Store(1 << 20)
+	var addrs []resolver.Address
+	for _, server := range []*racingServer{good, bad} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := grpc.NewServer()
+		racing.RegisterRacingServer(s, server)
+		hs := grpchealth.NewServer()
+		hs.SetServingStatus(racing.Racing_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(s, hs)
+		go func() { _ = s.Serve(lis) }()
+		t.Cleanup(s.Stop)
+		addrs = append(addrs, resolver.Address{Addr: lis.Addr().String()})
+	}
+
+	f := flags()
+	f.RetryMethods = ""
+	f.BreakerFailures = 0
+	f.OutlierFailurePercentage = 50
+	f.OutlierMinRequests = 5
+	f.OutlierInterval = 50 * time.Millisecond
+	f.OutlierEjectionTime = time.Minute
+	require.NoError(t, f.Validate())
+
+	r := manual.NewBuilderWithScheme("replicas")
+	r.InitialState(resolver.State{Addresses: addrs})
+	opts := append(f.DialOptions("racing", racing.Racing_ServiceDesc.ServiceName),
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("replicas:///racing", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := racing.NewRacingClient(conn)
+
+	// Once an interval has counted the failing replica's calls, it's
+	// ejected, and the other replica takes every call.
+	require.Eventually(t, func() bool {
+		ok := true
+		for range 10 {
+			if _, err := client.ListRaces(context.Background(), &racing.ListRacesRequest{}, grpc.WaitForReady(true)); err != nil {
+				ok = false
+			}
+		}
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ejected := bad.calls.Load()
+	for range 20 {
+		_, err := client.ListRaces(context.Background(), &racing.ListRacesRequest{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, ejected, bad.calls.Load(), "the ejected replica gets no calls")
+	require.Positive(t, ejected)
+}
+
+func TestFlagsValidate(t *testing.T) {
+	require.NoError(t, flags().Validate())
+
+	f := flags()
+	f.Balancer = "random"
+	f.MaxAttempts = 6
+	f.BreakerCooldown = 0
+	f.RouteTimeouts = "ListRaces=1s"
+	err := f.Validate()
+	require.ErrorContains(t, err, `unknown -backend-balancer "random"`)
+	require.ErrorContains(t, err, "-backend-max-attempts must be from 1 to 5")
+	require.ErrorContains(t, err, "-breaker-cooldown must be positive")
+	require.ErrorContains(t, err, "invalid -backend-route-timeouts")
+
+	f = flags()
+	f.OutlierFailurePercentage = 101
+	require.ErrorContains(t, f.Validate(), "-outlier-failure-percentage must be from 0 to 100")
+	f.OutlierFailurePercentage = 50
+	require.ErrorContains(t, f.Validate(), "-outlier-min-requests must be positive")
+
+	f = flags()
+	f.RetryMethods = "ListRaces"
+	require.ErrorContains(t, f.Validate(), "invalid -backend-retry-methods")
+}
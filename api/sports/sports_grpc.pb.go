@@ -1,163 +0,0 @@
-// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
-// versions:
-// - protoc-gen-go-grpc v1.5.1
-// - protoc             v5.29.3
-// source: sports/sports.proto
-
-package sports
-
-import (
-	context "context"
-	grpc "google.golang.org/grpc"
-	codes "google.golang.org/grpc/codes"
-	status "google.golang.org/grpc/status"
-)
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.64.0 or later.
-const _ = grpc.SupportPackageIsVersion9
-
-const (
-	Sports_ListEvents_FullMethodName = "/sports.Sports/ListEvents"
-	Sports_GetEvent_FullMethodName   = "/sports.Sports/GetEvent"
-)
-
-// SportsClient is the client API for Sports service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type SportsClient interface {
-	// ListEvents returns a list of all sports events.
-	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
-	// GetEvent returns a single sports event by ID.
-	GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*GetEventResponse, error)
-}
-
-type sportsClient struct {
-	cc grpc.ClientConnInterface
-}
-
-func NewSportsClient(cc grpc.ClientConnInterface) SportsClient {
-	return &sportsClient{cc}
-}
-
-func (c *sportsClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListEventsResponse)
-	err := c.cc.Invoke(ctx, Sports_ListEvents_FullMethodName, in, out, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *sportsClient) GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*GetEventResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(GetEventResponse)
-	err := c.cc.Invoke(ctx, Sports_GetEvent_FullMethodName, in, out, cOpts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-// SportsServer is the server API for Sports service.
-// All implementations must embed UnimplementedSportsServer
-// for forward compatibility.
-type SportsServer interface {
-	// ListEvents returns a list of all sports events.
-	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
-	// GetEvent returns a single sports event by ID.
-	GetEvent(context.Context, *GetEventRequest) (*GetEventResponse, error)
-	mustEmbedUnimplementedSportsServer()
-}
-
-// UnimplementedSportsServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
-// pointer dereference when methods are called.
-type UnimplementedSportsServer struct{}
-
-func (UnimplementedSportsServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
-}
-func (UnimplementedSportsServer) GetEvent(context.Context, *GetEventRequest) (*GetEventResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
-}
-func (UnimplementedSportsServer) mustEmbedUnimplementedSportsServer() {}
-func (UnimplementedSportsServer) testEmbeddedByValue()                {}
-
-// UnsafeSportsServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to SportsServer will
-// result in compilation errors.
-type UnsafeSportsServer interface {
-	mustEmbedUnimplementedSportsServer()
-}
-
-func RegisterSportsServer(s grpc.ServiceRegistrar, srv SportsServer) {
-	// If the following call pancis, it indicates UnimplementedSportsServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
-	}
-	s.RegisterService(&Sports_ServiceDesc, srv)
-}
-
-func _Sports_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListEventsRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SportsServer).ListEvents(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: Sports_ListEvents_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SportsServer).ListEvents(ctx, req.(*ListEventsRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Sports_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetEventRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SportsServer).GetEvent(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: Sports_GetEvent_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SportsServer).GetEvent(ctx, req.(*GetEventRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-// Sports_ServiceDesc is the grpc.ServiceDesc for Sports service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var Sports_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "sports.Sports",
-	HandlerType: (*SportsServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "ListEvents",
-			Handler:    _Sports_ListEvents_Handler,
-		},
-		{
-			MethodName: "GetEvent",
-			Handler:    _Sports_GetEvent_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "sports/sports.proto",
-}
@@ -0,0 +1,192 @@
+// Package webrpc serves the racing and sports services to browsers over the
+// Connect and gRPC-Web protocols, so web clients can use the typed protobuf
+// APIs without a proxy translating gRPC-Web in front of the gateway.
+//
+// Each call is handed on to the gateway's gRPC client of the service, so it's
+// authorized, rate limited and traced like the same call made over REST, and
+// fails with the same status and error details.
+package webrpc
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/rs/cors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/racing/racingconnect"
+	"git.neds.sh/matty/entain/proto/sports"
+	"git.neds.sh/matty/entain/proto/sports/sportsconnect"
+)
+
+// Flags are the command line options for browser access.
+type Flags struct {
+	AllowedOrigins string
+}
+
+// RegisterFlags registers -web-allowed-origins on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.AllowedOrigins, "web-allowed-origins", "", "Comma-separated origins whose pages may call racing and sports over Connect and gRPC-Web, such as https://app.example.com, or * for any; same-origin pages only when empty")
+
+	return &f
+}
+
+// Validate checks each origin is a scheme and host, or *.
+func (f *Flags) Validate() error {
+	var errs []error
+	for _, origin := range f.origins() {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("-web-allowed-origins: %q is not an origin such as https://app.example.com", origin))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Flags) origins() []string {
+	var origins []string
+	for _, origin := range strings.Split(f.AllowedOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// Server serves racing and sports calls from browsers.
+type Server struct {
+	mux *http.ServeMux
+	// procedures are the routes served, such as "/racing.Racing/ListRaces".
+	procedures map[string]bool
+}
+
+// New returns a Server handing calls on to r and s, answering cross-origin
+// requests from the origins the flags allow.
+func (f *Flags) New(r racing.RacingClient, s sports.SportsClient) *Server {
+	server := &Server{
+		mux: http.NewServeMux(),
+		procedures: map[string]bool{
+			racingconnect.RacingListRacesProcedure:  true,
+			racingconnect.RacingGetRaceProcedure:    true,
+			sportsconnect.SportsListEventsProcedure: true,
+		},
+	}
+
+	// Pages of other origins are refused unless allowed, so cors is only
+	// used once they are: it allows every origin when given none.
+	withCORS := func(h http.Handler) http.Handler { return h }
+	if origins := f.origins(); len(origins) > 0 {
+		withCORS = cors.New(cors.Options{
+			AllowedOrigins: origins,
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			AllowedHeaders: []string{
+				"Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms",
+				"Grpc-Timeout", "X-Grpc-Web", "X-User-Agent",
+				"Authorization", "X-Api-Key", "X-Request-Id",
+			},
+			ExposedHeaders: []string{
+				"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin", "X-Request-Id",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			},
+			MaxAge: 7200,
+		}).Handler
+	}
+
+	path, handler := racingconnect.NewRacingHandler(racingHandler{r})
+	server.mux.Handle(path, withCORS(handler))
+	path, handler = sportsconnect.NewSportsHandler(sportsHandler{s})
+	server.mux.Handle(path, withCORS(handler))
+
+	return server
+}
+
+// Handler serves the racing and sports procedures, and passes every other
+// request to next. Like the GraphQL handler, it belongs beneath the
+// authentication and rate limiting handlers.
+func (s *Server) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := s.mux.Handler(r); pattern == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Unknown procedures share a route, to keep metric labels bounded.
+		route := r.URL.Path
+		if !s.procedures[route] {
+			route = route[:strings.LastIndex(route, "/")+1] + "{method}"
+		}
+		metrics.SetRoute(r.Context(), route)
+		tracing.SetRoute(r.Context(), r.Method, route)
+
+		s.mux.ServeHTTP(w, r)
+	})
+}
+
+// racingHandler implements the Racing service by calling client.
+type racingHandler struct {
+	client racing.RacingClient
+}
+
+func (h racingHandler) ListRaces(ctx context.Context, req *connect.Request[racing.ListRacesRequest]) (*connect.Response[racing.ListRacesResponse], error) {
+	return call(ctx, h.client.ListRaces, req)
+}
+
+func (h racingHandler) GetRace(ctx context.Context, req *connect.Request[racing.GetRaceRequest]) (*connect.Response[racing.GetRaceResponse], error) {
+	return call(ctx, h.client.GetRace, req)
+}
+
+// sportsHandler implements the Sports service by calling client.
+type sportsHandler struct {
+	client sports.SportsClient
+}
+
+func (h sportsHandler) ListEvents(ctx context.Context, req *connect.Request[sports.ListEventsRequest]) (*connect.Response[sports.ListEventsResponse], error) {
+	return call(ctx, h.client.ListEvents, req)
+}
+
+// call hands req on to a gRPC client method, and its answer back.
+func call[Req, Resp any](ctx context.Context, method func(context.Context, *Req, ...grpc.CallOption) (*Resp, error), req *connect.Request[Req]) (*connect.Response[Resp], error) {
+	resp, err := method(ctx, req.Msg)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// connectError converts a gRPC status error to a Connect error of the same
+// code, message and details, such as the ErrorInfo of an open circuit
+// breaker.
+func connectError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+
+	cerr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Details() {
+		msg, ok := detail.(proto.Message)
+		if !ok {
+			continue
+		}
+		if d, err := connect.NewErrorDetail(msg); err == nil {
+			cerr.AddDetail(d)
+		}
+	}
+	return cerr
+}
@@ -0,0 +1,146 @@
+package webrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/matty/entain/proto/racing"
+	"git.neds.sh/matty/entain/proto/racing/racingconnect"
+	"git.neds.sh/matty/entain/proto/sports"
+	"git.neds.sh/matty/entain/proto/sports/sportsconnect"
+)
+
+// racingClient serves race 1, and fails with err.
+type racingClient struct {
+	racing.RacingClient
+	err error
+}
+
+func (c racingClient) ListRaces(_ context.Context, in *racing.ListRacesRequest, _ ...grpc.CallOption) (*racing.ListRacesResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &racing.ListRacesResponse{Races: []*racing.Race{{Id: 1, MeetingId: in.Filter.GetMeetingIds()[0]}}}, nil
+}
+
+func (c racingClient) GetRace(_ context.Context, in *racing.GetRaceRequest, _ ...grpc.CallOption) (*racing.GetRaceResponse, error) {
+	if in.Id != 1 {
+		return nil, status.Error(codes.NotFound, "race not found")
+	}
+	return &racing.GetRaceResponse{Race: &racing.Race{Id: 1, Runners: []*racing.Runner{{Number: 1, Name: "Winx"}}}}, nil
+}
+
+// sportsClient serves event 7.
+type sportsClient struct {
+	sports.SportsClient
+}
+
+func (sportsClient) ListEvents(context.Context, *sports.ListEventsRequest, ...grpc.CallOption) (*sports.ListEventsResponse, error) {
+	return &sports.ListEventsResponse{Events: []*sports.Event{{Id: 7}}}, nil
+}
+
+// serve serves s in front of a handler answering 404, as the REST routes
+// would.
+func serve(t *testing.T, s *Server) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(s.Handler(http.NotFoundHandler()))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer(t *testing.T) {
+	srv := serve(t, (&Flags{}).New(racingClient{}, sportsClient{}))
+
+	for name, opts := range map[string][]connect.ClientOption{
+		"connect":      nil,
+		"connect json": {connect.WithProtoJSON()},
+		"grpc-web":     {connect.WithGRPCWeb()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			races := racingconnect.NewRacingClient(srv.Client(), srv.URL, opts...)
+			list, err := races.ListRaces(context.Background(), connect.NewRequest(&racing.ListRacesRequest{
+				Filter: &racing.ListRacesRequestFilter{MeetingIds: []int64{5}},
+			}))
+			require.NoError(t, err)
+			require.Len(t, list.Msg.Races, 1)
+			require.Equal(t, int64(5), list.Msg.Races[0].MeetingId)
+
+			race, err := races.GetRace(context.Background(), connect.NewRequest(&racing.GetRaceRequest{Id: 1}))
+			require.NoError(t, err)
+			require.Equal(t, "Winx", race.Msg.Race.Runners[0].Name)
+
+			_, err = races.GetRace(context.Background(), connect.NewRequest(&racing.GetRaceRequest{Id: 2}))
+			require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+			events, err := sportsconnect.NewSportsClient(srv.Client(), srv.URL, opts...).ListEvents(context.Background(), connect.NewRequest(&sports.ListEventsRequest{}))
+			require.NoError(t, err)
+			require.Equal(t, int64(7), events.Msg.Events[0].Id)
+		})
+	}
+}
+
+func TestServerErrorDetails(t *testing.T) {
+	st, err := status.New(codes.Unavailable, "racing service unavailable: circuit breaker open").
+		WithDetails(&errdetails.ErrorInfo{Reason: "CIRCUIT_OPEN", Domain: "api"})
+	require.NoError(t, err)
+	srv := serve(t, (&Flags{}).New(racingClient{err: st.Err()}, sportsClient{}))
+
+	_, err = racingconnect.NewRacingClient(srv.Client(), srv.URL, connect.WithGRPCWeb()).
+		ListRaces(context.Background(), connect.NewRequest(&racing.ListRacesRequest{}))
+
+	var cerr *connect.Error
+	require.ErrorAs(t, err, &cerr)
+	require.Equal(t, connect.CodeUnavailable, cerr.Code())
+	require.Equal(t, "racing service unavailable: circuit breaker open", cerr.Message())
+	require.Len(t, cerr.Details(), 1)
+	detail, err := cerr.Details()[0].Value()
+	require.NoError(t, err)
+	require.Equal(t, "CIRCUIT_OPEN", detail.(*errdetails.ErrorInfo).Reason)
+}
+
+func TestServerPassesOtherRequests(t *testing.T) {
+	srv := serve(t, (&Flags{}).New(racingClient{}, sportsClient{}))
+
+	resp, err := srv.Client().Get(srv.URL + "/v1/races/1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServerCORS(t *testing.T) {
+	preflight := func(srv *httptest.Server) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, srv.URL+racingconnect.RacingListRacesProcedure, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "connect-protocol-version,content-type,x-api-key")
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := preflight(serve(t, (&Flags{AllowedOrigins: "https://app.example.com, https://admin.example.com"}).New(racingClient{}, sportsClient{})))
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = preflight(serve(t, (&Flags{}).New(racingClient{}, sportsClient{})))
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"), "no origin is allowed unless given")
+}
+
+func TestFlagsValidate(t *testing.T) {
+	require.NoError(t, (&Flags{AllowedOrigins: ""}).Validate())
+	require.NoError(t, (&Flags{AllowedOrigins: "*"}).Validate())
+	require.NoError(t, (&Flags{AllowedOrigins: "https://app.example.com,http://localhost:3000"}).Validate())
+	require.Error(t, (&Flags{AllowedOrigins: "app.example.com"}).Validate())
+	require.Error(t, (&Flags{AllowedOrigins: "https://app.example.com/path"}).Validate())
+}
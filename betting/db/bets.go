@@ -0,0 +1,250 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/proto/betting"
+)
+
+var (
+	// ErrAlreadySettled is returned when settling a bet that is no longer pending.
+	ErrAlreadySettled = errors.New("bet already settled")
+	// ErrDuplicateKey is returned when creating a bet under an idempotency key
+	// another bet already holds.
+	ErrDuplicateKey = errors.New("idempotency key already holds a bet")
+)
+
+// BetsRepo provides repository access to bets.
+//
+//go:generate mockery --name BetsRepo --structname BetsRepoMock --dir . --output . --outpkg db --filename bets_repo_mock.go
+type BetsRepo interface {
+	// Init will initialise our bets repository.
+	Init() error
+
+	// Create stores a new pending bet under an idempotency key and returns it with its ID assigned.
+	Create(bet *betting.Bet, idempotencyKey string) (*betting.Bet, error)
+
+	// Get returns a single bet by id.
+	Get(id int64) (*betting.Bet, error)
+
+	// GetByIdempotencyKey returns the bet placed with an idempotency key, if any.
+	GetByIdempotencyKey(key string) (*betting.Bet, error)
+
+	// Reversals returns how many stakes taken under an idempotency key were
+	// handed back because the bet wasn't recorded.
+	Reversals(key string) (int64, error)
+
+	// AddReversal counts one more reversed stake under an idempotency key,
+	// returning the new count.
+	AddReversal(key string) (int64, error)
+
+	// ListPending returns the bets on a race that are awaiting settlement.
+	ListPending(raceID int64) ([]*betting.Bet, error)
+
+	// Settle records the outcome of a pending bet.
+	Settle(bet *betting.Bet) error
+}
+
+type betsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewBetsRepo creates a new bets repository.
+func NewBetsRepo(db *sql.DB) BetsRepo {
+	return &betsRepo{db: db}
+}
+
+// Init prepares the bets schema.
+func (r *betsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		err = r.migrate()
+	})
+
+	return err
+}
+
+func (r *betsRepo) Create(bet *betting.Bet, idempotencyKey string) (*betting.Bet, error) {
+	legs, err := encodeLegs(bet.Legs)
+	if err != nil {
+		return nil, err
+	}
+
+	placed := bet.PlacedTime.AsTime()
+	res, err := r.db.Exec(
+		getBetQueries()[betsInsert],
+		idempotencyKey,
+		bet.AccountId,
+		bet.RaceId,
+		int32(bet.Type),
+		bet.Boxed,
+		legs,
+		bet.StakeCents,
+		bet.Combinations,
+		int32(betting.Bet_STATUS_PENDING),
+		placed.Format(time.RFC3339Nano),
+	)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, ErrDuplicateKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(id)
+}
+
+func (r *betsRepo) Get(id int64) (*betting.Bet, error) {
+	rows, err := r.db.Query(getBetQueries()[betsGet], id)
+	if err != nil {
+		return nil, err
+	}
+
+	bets, err := r.scanBets(rows)
+	if err != nil || len(bets) == 0 {
+		return nil, err
+	}
+
+	return bets[0], nil
+}
+
+func (r *betsRepo) GetByIdempotencyKey(key string) (*betting.Bet, error) {
+	rows, err := r.db.Query(getBetQueries()[betsByKey], key)
+	if err != nil {
+		return nil, err
+	}
+
+	bets, err := r.scanBets(rows)
+	if err != nil || len(bets) == 0 {
+		return nil, err
+	}
+
+	return bets[0], nil
+}
+
+func (r *betsRepo) Reversals(key string) (int64, error) {
+	var reversals int64
+	err := r.db.QueryRow(getBetQueries()[betsReversals], key).Scan(&reversals)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return reversals, err
+}
+
+func (r *betsRepo) AddReversal(key string) (int64, error) {
+	var reversals int64
+	err := r.db.QueryRow(getBetQueries()[betsAddReversal], key).Scan(&reversals)
+
+	return reversals, err
+}
+
+func (r *betsRepo) ListPending(raceID int64) ([]*betting.Bet, error) {
+	rows, err := r.db.Query(getBetQueries()[betsPending], raceID, int32(betting.Bet_STATUS_PENDING))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanBets(rows)
+}
+
+func (r *betsRepo) Settle(bet *betting.Bet) error {
+	res, err := r.db.Exec(
+		getBetQueries()[betsSettle],
+		int32(bet.Status),
+		bet.PayoutCents,
+		bet.Id,
+		int32(betting.Bet_STATUS_PENDING),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The status guard stops a bet being paid twice by concurrent settlements.
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAlreadySettled
+	}
+
+	return nil
+}
+
+func (r *betsRepo) scanBets(
+	rows *sql.Rows,
+) ([]*betting.Bet, error) {
+	defer rows.Close()
+
+	var bets []*betting.Bet
+
+	for rows.Next() {
+		var (
+			bet        betting.Bet
+			betType    int32
+			status     int32
+			legs       string
+			placedTime time.Time
+		)
+
+		if err := rows.Scan(&bet.Id, &bet.AccountId, &bet.RaceId, &betType, &bet.Boxed, &legs, &bet.StakeCents, &bet.Combinations, &status, &bet.PayoutCents, &placedTime); err != nil {
+			return nil, err
+		}
+
+		decoded, err := decodeLegs(legs)
+		if err != nil {
+			return nil, err
+		}
+
+		bet.Type = betting.Bet_Type(betType)
+		bet.Status = betting.Bet_Status(status)
+		bet.Legs = decoded
+		bet.PlacedTime = timestamppb.New(placedTime)
+
+		bets = append(bets, &bet)
+	}
+
+	return bets, rows.Err()
+}
+
+// encodeLegs stores legs as a JSON array of runner number arrays, e.g. [[1,2],[3]].
+func encodeLegs(legs []*betting.Leg) (string, error) {
+	runners := make([][]int64, len(legs))
+	for i, leg := range legs {
+		runners[i] = leg.RunnerNumbers
+	}
+
+	b, err := json.Marshal(runners)
+
+	return string(b), err
+}
+
+func decodeLegs(encoded string) ([]*betting.Leg, error) {
+	var runners [][]int64
+	if err := json.Unmarshal([]byte(encoded), &runners); err != nil {
+		return nil, err
+	}
+
+	legs := make([]*betting.Leg, len(runners))
+	for i, r := range runners {
+		legs[i] = &betting.Leg{RunnerNumbers: r}
+	}
+
+	return legs, nil
+}
@@ -0,0 +1,240 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package db
+
+import (
+	betting "git.neds.sh/matty/entain/proto/betting"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BetsRepoMock is an autogenerated mock type for the BetsRepo type
+type BetsRepoMock struct {
+	mock.Mock
+}
+
+// AddReversal provides a mock function with given fields: key
+func (_m *BetsRepoMock) AddReversal(key string) (int64, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddReversal")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: bet, idempotencyKey
+func (_m *BetsRepoMock) Create(bet *betting.Bet, idempotencyKey string) (*betting.Bet, error) {
+	ret := _m.Called(bet, idempotencyKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *betting.Bet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*betting.Bet, string) (*betting.Bet, error)); ok {
+		return rf(bet, idempotencyKey)
+	}
+	if rf, ok := ret.Get(0).(func(*betting.Bet, string) *betting.Bet); ok {
+		r0 = rf(bet, idempotencyKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*betting.Bet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*betting.Bet, string) error); ok {
+		r1 = rf(bet, idempotencyKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: id
+func (_m *BetsRepoMock) Get(id int64) (*betting.Bet, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *betting.Bet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (*betting.Bet, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *betting.Bet); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*betting.Bet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByIdempotencyKey provides a mock function with given fields: key
+func (_m *BetsRepoMock) GetByIdempotencyKey(key string) (*betting.Bet, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdempotencyKey")
+	}
+
+	var r0 *betting.Bet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*betting.Bet, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) *betting.Bet); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*betting.Bet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Init provides a mock function with no fields
+func (_m *BetsRepoMock) Init() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Init")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListPending provides a mock function with given fields: raceID
+func (_m *BetsRepoMock) ListPending(raceID int64) ([]*betting.Bet, error) {
+	ret := _m.Called(raceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPending")
+	}
+
+	var r0 []*betting.Bet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]*betting.Bet, error)); ok {
+		return rf(raceID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []*betting.Bet); ok {
+		r0 = rf(raceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*betting.Bet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(raceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reversals provides a mock function with given fields: key
+func (_m *BetsRepoMock) Reversals(key string) (int64, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reversals")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Settle provides a mock function with given fields: bet
+func (_m *BetsRepoMock) Settle(bet *betting.Bet) error {
+	ret := _m.Called(bet)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Settle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*betting.Bet) error); ok {
+		r0 = rf(bet)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBetsRepoMock creates a new instance of BetsRepoMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBetsRepoMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BetsRepoMock {
+	mock := &BetsRepoMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,187 @@
+package db
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/proto/betting"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var betCols = []string{"id", "account_id", "race_id", "type", "boxed", "legs", "stake_cents", "combinations", "status", "payout_cents", "placed_time"}
+
+func TestBetsRepo_Create(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := &betsRepo{db: sqlDB}
+	placed := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(getBetQueries()[betsInsert])).
+		WithArgs("key-1", int64(21), int64(7), int32(betting.Bet_TYPE_TRIFECTA), true, "[[1,2,3]]", int64(600), int64(6), int32(betting.Bet_STATUS_PENDING), placed.Format(time.RFC3339Nano)).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(getBetQueries()[betsGet])).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(betCols).AddRow(int64(3), int64(21), int64(7), int32(3), true, "[[1,2,3]]", int64(600), int64(6), int32(0), int64(0), placed))
+
+	got, err := repo.Create(&betting.Bet{
+		AccountId:    21,
+		RaceId:       7,
+		Type:         betting.Bet_TYPE_TRIFECTA,
+		Boxed:        true,
+		Legs:         []*betting.Leg{{RunnerNumbers: []int64{1, 2, 3}}},
+		StakeCents:   600,
+		Combinations: 6,
+		PlacedTime:   timestamppb.New(placed),
+	}, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), got.Id)
+	require.Equal(t, int64(21), got.AccountId)
+	require.Equal(t, betting.Bet_TYPE_TRIFECTA, got.Type)
+	require.Equal(t, []int64{1, 2, 3}, got.Legs[0].RunnerNumbers)
+	require.True(t, placed.Equal(got.PlacedTime.AsTime()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBetsRepo_Get_WithSQLMock(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     []any
+		willErr error
+		wantNil bool
+	}{
+		{
+			name: "found",
+			row:  []any{int64(5), int64(21), int64(1), int32(2), false, "[[4],[5,6]]", int64(200), int64(2), int32(1), int64(1500), time.Now()},
+		},
+		{
+			name:    "not found (no rows)",
+			wantNil: true,
+		},
+		{
+			name:    "db error",
+			willErr: assert.AnError,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			repo := &betsRepo{db: sqlDB}
+
+			exp := mock.ExpectQuery(regexp.QuoteMeta(getBetQueries()[betsGet])).WithArgs(int64(5))
+			if tt.willErr != nil {
+				exp.WillReturnError(tt.willErr)
+			} else {
+				rows := sqlmock.NewRows(betCols)
+				if tt.row != nil {
+					var drvVals []driver.Value
+					for _, v := range tt.row {
+						drvVals = append(drvVals, v)
+					}
+					rows.AddRow(drvVals...)
+				}
+				exp.WillReturnRows(rows)
+			}
+
+			got, err := repo.Get(5)
+			if tt.willErr != nil {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantNil {
+				require.Nil(t, got)
+			} else {
+				require.NotNil(t, got)
+				require.Equal(t, betting.Bet_STATUS_WON, got.Status)
+				require.Len(t, got.Legs, 2)
+				require.Equal(t, []int64{5, 6}, got.Legs[1].RunnerNumbers)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBetsRepo_Settle(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "pending bet settled", affected: 1},
+		{name: "already settled", affected: 0, wantErr: ErrAlreadySettled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			repo := &betsRepo{db: sqlDB}
+
+			mock.ExpectExec(regexp.QuoteMeta(getBetQueries()[betsSettle])).
+				WithArgs(int32(betting.Bet_STATUS_WON), int64(1250), int64(9), int32(betting.Bet_STATUS_PENDING)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = repo.Settle(&betting.Bet{Id: 9, Status: betting.Bet_STATUS_WON, PayoutCents: 1250})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBetsRepo_Create_DuplicateKey(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := &betsRepo{db: sqlDB}
+
+	mock.ExpectExec(regexp.QuoteMeta(getBetQueries()[betsInsert])).
+		WillReturnError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique})
+
+	_, err = repo.Create(&betting.Bet{PlacedTime: timestamppb.Now()}, "key-1")
+	require.ErrorIs(t, err, ErrDuplicateKey)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBetsRepo_Reversals(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := &betsRepo{db: sqlDB}
+
+	mock.ExpectQuery(regexp.QuoteMeta(getBetQueries()[betsReversals])).
+		WithArgs("key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"reversals"}))
+	mock.ExpectQuery(regexp.QuoteMeta(getBetQueries()[betsAddReversal])).
+		WithArgs("key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"reversals"}).AddRow(int64(1)))
+
+	reversals, err := repo.Reversals("key-1")
+	require.NoError(t, err)
+	require.Zero(t, reversals, "a key never reversed has no row")
+
+	reversals, err = repo.AddReversal("key-1")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), reversals)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
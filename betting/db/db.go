@@ -0,0 +1,41 @@
+package db
+
+// migrate creates the bets table. Unlike racing and sports there is no dummy
+// data: bets only exist once customers place them.
+func (r *betsRepo) migrate() error {
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS bets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			idempotency_key TEXT NOT NULL UNIQUE,
+			account_id INTEGER NOT NULL,
+			race_id INTEGER NOT NULL,
+			type INTEGER NOT NULL,
+			boxed INTEGER NOT NULL,
+			legs TEXT NOT NULL,
+			stake_cents INTEGER NOT NULL,
+			combinations INTEGER NOT NULL,
+			status INTEGER NOT NULL,
+			payout_cents INTEGER NOT NULL DEFAULT 0,
+			placed_time DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`CREATE INDEX IF NOT EXISTS bets_race_status ON bets (race_id, status)`)
+	if err != nil {
+		return err
+	}
+
+	// Placements whose stake was handed back take the next stake under a key
+	// of their own, so the ledger doesn't replay the reversed one.
+	_, err = r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS bet_reversals (
+			idempotency_key TEXT PRIMARY KEY,
+			reversals INTEGER NOT NULL
+		)
+	`)
+
+	return err
+}
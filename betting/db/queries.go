@@ -0,0 +1,95 @@
+package db
+
+const (
+	betsInsert  = "insert"
+	betsGet     = "get"
+	betsByKey   = "by-key"
+	betsPending = "pending"
+	betsSettle  = "settle"
+
+	betsReversals   = "reversals"
+	betsAddReversal = "add-reversal"
+)
+
+func getBetQueries() map[string]string {
+	return map[string]string{
+		betsInsert: `
+			INSERT INTO bets (
+				idempotency_key,
+				account_id,
+				race_id,
+				type,
+				boxed,
+				legs,
+				stake_cents,
+				combinations,
+				status,
+				placed_time
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+		betsGet: `
+			SELECT
+				id,
+				account_id,
+				race_id,
+				type,
+				boxed,
+				legs,
+				stake_cents,
+				combinations,
+				status,
+				payout_cents,
+				placed_time
+			FROM bets
+			WHERE id = ?
+		`,
+		betsByKey: `
+			SELECT
+				id,
+				account_id,
+				race_id,
+				type,
+				boxed,
+				legs,
+				stake_cents,
+				combinations,
+				status,
+				payout_cents,
+				placed_time
+			FROM bets
+			WHERE idempotency_key = ?
+		`,
+		betsPending: `
+			SELECT
+				id,
+				account_id,
+				race_id,
+				type,
+				boxed,
+				legs,
+				stake_cents,
+				combinations,
+				status,
+				payout_cents,
+				placed_time
+			FROM bets
+			WHERE race_id = ? AND status = ?
+			ORDER BY id ASC
+		`,
+		betsSettle: `
+			UPDATE bets
+			SET status = ?, payout_cents = ?
+			WHERE id = ? AND status = ?
+		`,
+		betsReversals: `
+			SELECT reversals
+			FROM bet_reversals
+			WHERE idempotency_key = ?
+		`,
+		betsAddReversal: `
+			INSERT INTO bet_reversals (idempotency_key, reversals) VALUES (?, 1)
+			ON CONFLICT (idempotency_key) DO UPDATE SET reversals = reversals + 1
+			RETURNING reversals
+		`,
+	}
+}
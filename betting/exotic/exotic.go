@@ -0,0 +1,207 @@
+// Package exotic implements the combination rules for exotic race bets.
+//
+// A selection is made up of legs, one per placing, each holding the runner
+// numbers that may fill that placing. Boxed selections hold a single leg whose
+// runners may fill any placing. A combination is a single finishing order
+// covered by a selection.
+package exotic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"git.neds.sh/matty/entain/proto/betting"
+)
+
+// ErrInvalidSelection is returned when legs don't make a valid selection for a bet type.
+var ErrInvalidSelection = errors.New("invalid selection")
+
+// Places returns how many placings a bet type must name, or 0 for an unknown type.
+func Places(betType betting.Bet_Type) int {
+	switch betType {
+	case betting.Bet_TYPE_QUINELLA, betting.Bet_TYPE_EXACTA:
+		return 2
+	case betting.Bet_TYPE_TRIFECTA:
+		return 3
+	case betting.Bet_TYPE_FIRST_FOUR:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Combinations expands a selection into every distinct combination it covers.
+// Quinella combinations are unordered, so each is returned sorted ascending.
+func Combinations(betType betting.Bet_Type, boxed bool, legs [][]int64) ([][]int64, error) {
+	places := Places(betType)
+	if places == 0 {
+		return nil, fmt.Errorf("%w: unknown bet type %s", ErrInvalidSelection, betType)
+	}
+
+	// A quinella is always boxed: its two runners may finish in either order.
+	if betType == betting.Bet_TYPE_QUINELLA {
+		boxed = true
+	}
+
+	selected := make([][]int64, places)
+	if boxed {
+		if len(legs) != 1 {
+			return nil, fmt.Errorf("%w: boxed %s takes a single leg", ErrInvalidSelection, betType)
+		}
+		runners := unique(legs[0])
+		if len(runners) < places {
+			return nil, fmt.Errorf("%w: boxed %s needs at least %d runners", ErrInvalidSelection, betType, places)
+		}
+		for i := range selected {
+			selected[i] = runners
+		}
+	} else {
+		if len(legs) != places {
+			return nil, fmt.Errorf("%w: %s takes %d legs", ErrInvalidSelection, betType, places)
+		}
+		for i, leg := range legs {
+			if len(leg) == 0 {
+				return nil, fmt.Errorf("%w: leg %d has no runners", ErrInvalidSelection, i+1)
+			}
+			selected[i] = unique(leg)
+		}
+	}
+
+	var combinations [][]int64
+	expand(selected, nil, func(combination []int64) {
+		combinations = append(combinations, combination)
+	})
+
+	if betType == betting.Bet_TYPE_QUINELLA {
+		combinations = unordered(combinations)
+	}
+
+	if len(combinations) == 0 {
+		return nil, fmt.Errorf("%w: no combinations selected", ErrInvalidSelection)
+	}
+
+	return combinations, nil
+}
+
+// Count returns the number of combinations a selection covers.
+func Count(betType betting.Bet_Type, boxed bool, legs [][]int64) (int64, error) {
+	combinations, err := Combinations(betType, boxed, legs)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(combinations)), nil
+}
+
+// FlexiPercent returns the share of a $1 unit invested in each combination.
+func FlexiPercent(stakeCents, combinations int64) float64 {
+	if combinations == 0 {
+		return 0
+	}
+
+	return float64(stakeCents) / float64(combinations)
+}
+
+// WithoutScratched drops every combination that includes a scratched runner.
+func WithoutScratched(combinations [][]int64, scratched map[int64]bool) [][]int64 {
+	var remaining [][]int64
+	for _, combination := range combinations {
+		if !containsAny(combination, scratched) {
+			remaining = append(remaining, combination)
+		}
+	}
+
+	return remaining
+}
+
+// Wins reports whether a combination is a winner given the official placings,
+// which are runner numbers ordered from first place.
+func Wins(betType betting.Bet_Type, combination, placings []int64) bool {
+	places := Places(betType)
+	if places == 0 || len(combination) != places || len(placings) < places {
+		return false
+	}
+
+	if betType == betting.Bet_TYPE_QUINELLA {
+		return (combination[0] == placings[0] && combination[1] == placings[1]) ||
+			(combination[0] == placings[1] && combination[1] == placings[0])
+	}
+
+	for i := range combination {
+		if combination[i] != placings[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expand walks every runner in each leg, skipping runners already used in an earlier placing.
+func expand(legs [][]int64, prefix []int64, emit func([]int64)) {
+	if len(prefix) == len(legs) {
+		emit(append([]int64(nil), prefix...))
+		return
+	}
+
+	for _, runner := range legs[len(prefix)] {
+		if contains(prefix, runner) {
+			continue
+		}
+		expand(legs, append(prefix, runner), emit)
+	}
+}
+
+// unordered collapses ordered pairs to their sorted form, dropping duplicates.
+func unordered(combinations [][]int64) [][]int64 {
+	seen := make(map[[2]int64]bool)
+	var result [][]int64
+	for _, combination := range combinations {
+		key := [2]int64{combination[0], combination[1]}
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, []int64{key[0], key[1]})
+	}
+
+	return result
+}
+
+// unique returns the distinct runners in a leg, sorted ascending.
+func unique(runners []int64) []int64 {
+	seen := make(map[int64]bool)
+	var result []int64
+	for _, runner := range runners {
+		if !seen[runner] {
+			seen[runner] = true
+			result = append(result, runner)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+func contains(runners []int64, runner int64) bool {
+	for _, r := range runners {
+		if r == runner {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(runners []int64, set map[int64]bool) bool {
+	for _, r := range runners {
+		if set[r] {
+			return true
+		}
+	}
+
+	return false
+}
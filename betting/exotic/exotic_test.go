@@ -0,0 +1,84 @@
+package exotic
+
+import (
+	"testing"
+
+	"git.neds.sh/matty/entain/proto/betting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		betType betting.Bet_Type
+		boxed   bool
+		legs    [][]int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "quinella pair", betType: betting.Bet_TYPE_QUINELLA, legs: [][]int64{{1, 2}}, want: 1},
+		{name: "quinella four runners", betType: betting.Bet_TYPE_QUINELLA, legs: [][]int64{{1, 2, 3, 4}}, want: 6},
+		{name: "quinella needs two runners", betType: betting.Bet_TYPE_QUINELLA, legs: [][]int64{{1}}, wantErr: true},
+		{name: "exacta straight", betType: betting.Bet_TYPE_EXACTA, legs: [][]int64{{1}, {2}}, want: 1},
+		{name: "exacta standout", betType: betting.Bet_TYPE_EXACTA, legs: [][]int64{{1}, {2, 3, 4}}, want: 3},
+		{name: "exacta overlapping legs", betType: betting.Bet_TYPE_EXACTA, legs: [][]int64{{1, 2}, {1, 2}}, want: 2},
+		{name: "exacta boxed three", betType: betting.Bet_TYPE_EXACTA, boxed: true, legs: [][]int64{{1, 2, 3}}, want: 6},
+		{name: "exacta wrong leg count", betType: betting.Bet_TYPE_EXACTA, legs: [][]int64{{1}}, wantErr: true},
+		{name: "trifecta boxed four", betType: betting.Bet_TYPE_TRIFECTA, boxed: true, legs: [][]int64{{1, 2, 3, 4}}, want: 24},
+		{name: "trifecta duplicates ignored", betType: betting.Bet_TYPE_TRIFECTA, boxed: true, legs: [][]int64{{1, 2, 2, 3}}, want: 6},
+		{name: "trifecta legs", betType: betting.Bet_TYPE_TRIFECTA, legs: [][]int64{{1, 2}, {1, 2, 3}, {3, 4}}, want: 6},
+		{name: "trifecta empty leg", betType: betting.Bet_TYPE_TRIFECTA, legs: [][]int64{{1}, {}, {3}}, wantErr: true},
+		{name: "trifecta no distinct combination", betType: betting.Bet_TYPE_TRIFECTA, legs: [][]int64{{1}, {1}, {1}}, wantErr: true},
+		{name: "first four boxed five", betType: betting.Bet_TYPE_FIRST_FOUR, boxed: true, legs: [][]int64{{1, 2, 3, 4, 5}}, want: 120},
+		{name: "first four boxed too few", betType: betting.Bet_TYPE_FIRST_FOUR, boxed: true, legs: [][]int64{{1, 2, 3}}, wantErr: true},
+		{name: "unspecified type", betType: betting.Bet_TYPE_UNSPECIFIED, legs: [][]int64{{1, 2}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Count(tt.betType, tt.boxed, tt.legs)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSelection)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithoutScratched(t *testing.T) {
+	combinations, err := Combinations(betting.Bet_TYPE_EXACTA, true, [][]int64{{1, 2, 3}})
+	require.NoError(t, err)
+
+	remaining := WithoutScratched(combinations, map[int64]bool{3: true})
+	require.ElementsMatch(t, [][]int64{{1, 2}, {2, 1}}, remaining)
+}
+
+func TestWins(t *testing.T) {
+	placings := []int64{4, 7, 1, 9}
+
+	tests := []struct {
+		name        string
+		betType     betting.Bet_Type
+		combination []int64
+		want        bool
+	}{
+		{name: "quinella in order", betType: betting.Bet_TYPE_QUINELLA, combination: []int64{4, 7}, want: true},
+		{name: "quinella reversed", betType: betting.Bet_TYPE_QUINELLA, combination: []int64{7, 4}, want: true},
+		{name: "quinella miss", betType: betting.Bet_TYPE_QUINELLA, combination: []int64{4, 1}, want: false},
+		{name: "exacta in order", betType: betting.Bet_TYPE_EXACTA, combination: []int64{4, 7}, want: true},
+		{name: "exacta reversed", betType: betting.Bet_TYPE_EXACTA, combination: []int64{7, 4}, want: false},
+		{name: "trifecta", betType: betting.Bet_TYPE_TRIFECTA, combination: []int64{4, 7, 1}, want: true},
+		{name: "first four", betType: betting.Bet_TYPE_FIRST_FOUR, combination: []int64{4, 7, 1, 9}, want: true},
+		{name: "first four miss", betType: betting.Bet_TYPE_FIRST_FOUR, combination: []int64{4, 7, 9, 1}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Wins(tt.betType, tt.combination, placings))
+		})
+	}
+
+	require.False(t, Wins(betting.Bet_TYPE_FIRST_FOUR, []int64{4, 7, 1, 9}, placings[:3]), "not enough placings")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"git.neds.sh/matty/entain/betting/db"
+	"git.neds.sh/matty/entain/betting/service"
+	"git.neds.sh/matty/entain/pkg/admin"
+	"git.neds.sh/matty/entain/pkg/config"
+	"git.neds.sh/matty/entain/pkg/health"
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tlsutil"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/racing"
+	"google.golang.org/grpc"
+)
+
+var (
+	grpcEndpoint         = flag.String("grpc-endpoint", "localhost:9002", "gRPC server endpoint")
+	adminEndpoint        = flag.String("admin-endpoint", "localhost:9102", "Admin HTTP endpoint serving /metrics")
+	shutdownTimeout      = flag.Duration("shutdown-timeout", 15*time.Second, "How long to let RPCs in flight finish when shutting down")
+	dbDSN                = flag.String("db-dsn", "file:./db/betting.db?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL", "SQLite database DSN")
+	healthInterval       = flag.Duration("health-interval", health.DefaultInterval, "How often to check that the database is still healthy")
+	racingGrpcEndpoint   = flag.String("racing-grpc-endpoint", "localhost:9000", "Racing gRPC server endpoint")
+	accountsGrpcEndpoint = flag.String("accounts-grpc-endpoint", "localhost:9003", "Accounts gRPC server endpoint")
+	tlsFlags             = tlsutil.RegisterFlags(flag.CommandLine, "")
+	traceFlags           = tracing.RegisterFlags(flag.CommandLine)
+	logFlags             = logging.RegisterFlags(flag.CommandLine)
+)
+
+func main() {
+	config.Parse("BETTING",
+		config.Endpoint("grpc-endpoint", grpcEndpoint),
+		config.Endpoint("admin-endpoint", adminEndpoint),
+		config.Required("racing-grpc-endpoint", racingGrpcEndpoint),
+		config.Required("accounts-grpc-endpoint", accountsGrpcEndpoint),
+		config.Required("db-dsn", dbDSN),
+		config.Positive("shutdown-timeout", shutdownTimeout),
+		config.Positive("health-interval", healthInterval),
+		tlsFlags,
+		traceFlags,
+		logFlags,
+	)
+
+	if err := logFlags.Setup("betting"); err != nil {
+		log.Fatalf("invalid logging flags: %s\n", err)
+	}
+
+	if err := run(); err != nil {
+		slog.Error("failed running grpc server", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := traceFlags.Setup(context.Background(), "betting")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	conn, err := net.Listen("tcp", *grpcEndpoint)
+	if err != nil {
+		return err
+	}
+
+	bettingDB, err := sql.Open("sqlite3", *dbDSN)
+	if err != nil {
+		return err
+	}
+	defer bettingDB.Close()
+
+	betsRepo := db.NewBetsRepo(bettingDB)
+	if err := betsRepo.Init(); err != nil {
+		return err
+	}
+
+	// One identity serves betting's own clients and its calls to racing and accounts.
+	reloader, err := tlsFlags.Reloader("betting")
+	if err != nil {
+		return err
+	}
+	serverCreds, err := tlsutil.ServerCredentials(reloader)
+	if err != nil {
+		return err
+	}
+	clientCreds, err := tlsutil.ClientCredentials(reloader)
+	if err != nil {
+		return err
+	}
+
+	clientOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(clientCreds),
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			logging.UnaryClientInterceptor(),
+		),
+		grpc.WithStatsHandler(tracing.ClientHandler()),
+	}
+
+	racingConn, err := grpc.NewClient(*racingGrpcEndpoint, clientOpts...)
+	if err != nil {
+		return err
+	}
+	defer racingConn.Close()
+
+	accountsConn, err := grpc.NewClient(*accountsGrpcEndpoint, clientOpts...)
+	if err != nil {
+		return err
+	}
+	defer accountsConn.Close()
+
+	if err := metrics.RegisterDB(bettingDB, "betting"); err != nil {
+		return err
+	}
+	if err := admin.New(*adminEndpoint).Start(); err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(serverCreds),
+		health.KeepalivePolicy(),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			logging.UnaryServerInterceptor(),
+		),
+		grpc.StatsHandler(tracing.ServerHandler()),
+	)
+
+	betting.RegisterBettingServer(
+		grpcServer,
+		service.NewBettingService(
+			betsRepo,
+			racing.NewRacingClient(racingConn),
+			accounts.NewAccountsClient(accountsConn),
+		),
+	)
+
+	// The service is ready once its database is set up, and for as long as
+	// it answers.
+	healthServer := health.NewServer(grpcServer, betting.Betting_ServiceDesc.ServiceName)
+	go health.Watch(ctx, healthServer, bettingDB.PingContext, *healthInterval, betting.Betting_ServiceDesc.ServiceName)
+
+	slog.Info("gRPC server listening", "endpoint", *grpcEndpoint)
+
+	return health.Serve(ctx, grpcServer, conn, healthServer, *shutdownTimeout)
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"git.neds.sh/matty/entain/betting/db"
+	"git.neds.sh/matty/entain/betting/exotic"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/racing"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Betting interface {
+	// PlaceBet places an exotic bet against the runners of an open race.
+	PlaceBet(ctx context.Context, in *betting.PlaceBetRequest) (*betting.PlaceBetResponse, error)
+	// GetBet returns a single bet by ID.
+	GetBet(ctx context.Context, in *betting.GetBetRequest) (*betting.GetBetResponse, error)
+	// SettleRace settles all pending bets on a race from its official result placings.
+	SettleRace(ctx context.Context, in *betting.SettleRaceRequest) (*betting.SettleRaceResponse, error)
+}
+
+// bettingService implements the Betting interface.
+type bettingService struct {
+	betsRepo db.BetsRepo
+	racing   racing.RacingClient
+	accounts accounts.AccountsClient
+}
+
+// NewBettingService instantiates and returns a new bettingService.
+func NewBettingService(betsRepo db.BetsRepo, racingClient racing.RacingClient, accountsClient accounts.AccountsClient) Betting {
+	return &bettingService{betsRepo: betsRepo, racing: racingClient, accounts: accountsClient}
+}
+
+func (s *bettingService) PlaceBet(ctx context.Context, in *betting.PlaceBetRequest) (*betting.PlaceBetResponse, error) {
+	if in.StakeCents <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "stake must be positive")
+	}
+	if in.IdempotencyKey == "" {
+		return nil, status.Error(codes.InvalidArgument, "idempotency_key is required")
+	}
+
+	// A retried placement returns the bet it already created.
+	existing, err := s.betsRepo.GetByIdempotencyKey(in.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return placed(existing, in)
+	}
+
+	race, err := s.getRace(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+	if race.Status != racing.Race_STATUS_OPEN {
+		return nil, status.Error(codes.FailedPrecondition, "race is closed")
+	}
+
+	// Every selected runner must be a current starter in the race.
+	runners := make(map[int64]*racing.Runner, len(race.Runners))
+	for _, r := range race.Runners {
+		runners[r.Number] = r
+	}
+	for _, leg := range in.Legs {
+		for _, number := range leg.RunnerNumbers {
+			runner, ok := runners[number]
+			if !ok {
+				return nil, status.Errorf(codes.InvalidArgument, "runner %d is not in race %d", number, in.RaceId)
+			}
+			if runner.Scratched {
+				return nil, status.Errorf(codes.InvalidArgument, "runner %d is scratched", number)
+			}
+		}
+	}
+
+	combinations, err := exotic.Count(in.Type, in.Boxed, legRunners(in.Legs))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Take the stake before recording the bet. The ledger deduplicates on the
+	// key, so a retry after a failure below never takes the stake twice. Once
+	// a stake has been handed back, though, the next is taken under a key of
+	// its own: replaying the reversed one would take nothing.
+	attempt, err := s.betsRepo.Reversals(in.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if attempt > 0 {
+		// The last reversal may have failed after it was counted; posting it
+		// again is a no-op if it didn't.
+		if err := s.reverseStake(ctx, in, attempt-1); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.accounts.Debit(ctx, &accounts.DebitRequest{
+		AccountId:      in.AccountId,
+		Type:           accounts.Transaction_TYPE_BET_STAKE,
+		AmountCents:    in.StakeCents,
+		IdempotencyKey: attemptKey("bet:", in.IdempotencyKey, attempt),
+		Reference:      fmt.Sprintf("%s stake on race %d", in.Type, in.RaceId),
+	}); err != nil {
+		return nil, err
+	}
+
+	bet, err := s.betsRepo.Create(&betting.Bet{
+		AccountId:    in.AccountId,
+		RaceId:       in.RaceId,
+		Type:         in.Type,
+		Boxed:        in.Boxed,
+		Legs:         in.Legs,
+		StakeCents:   in.StakeCents,
+		Combinations: combinations,
+		Status:       betting.Bet_STATUS_PENDING,
+		PlacedTime:   timestamppb.Now(),
+	}, in.IdempotencyKey)
+	if errors.Is(err, db.ErrDuplicateKey) {
+		// A concurrent placement under the same key recorded the bet first.
+		// Both took the one stake, so there's nothing to hand back.
+		existing, err := s.betsRepo.GetByIdempotencyKey(in.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		return placed(existing, in)
+	}
+	if err != nil {
+		// Hand the stake back rather than hold funds against a bet that
+		// doesn't exist. The reversal is counted first, so a retry never
+		// replays the stake it hands back.
+		if _, countErr := s.betsRepo.AddReversal(in.IdempotencyKey); countErr != nil {
+			return nil, errors.Join(err, countErr)
+		}
+		if refundErr := s.reverseStake(ctx, in, attempt); refundErr != nil {
+			return nil, errors.Join(err, refundErr)
+		}
+		return nil, err
+	}
+
+	bet.FlexiPercent = exotic.FlexiPercent(bet.StakeCents, bet.Combinations)
+
+	return &betting.PlaceBetResponse{Bet: bet}, nil
+}
+
+// placed answers a placement whose idempotency key already holds bet.
+func placed(bet *betting.Bet, in *betting.PlaceBetRequest) (*betting.PlaceBetResponse, error) {
+	if bet.AccountId != in.AccountId {
+		return nil, status.Error(codes.AlreadyExists, "idempotency key reused for a different bet")
+	}
+	bet.FlexiPercent = exotic.FlexiPercent(bet.StakeCents, bet.Combinations)
+
+	return &betting.PlaceBetResponse{Bet: bet}, nil
+}
+
+// reverseStake hands back the stake a placement took on its attempt-th try.
+func (s *bettingService) reverseStake(ctx context.Context, in *betting.PlaceBetRequest, attempt int64) error {
+	_, err := s.accounts.Credit(ctx, &accounts.CreditRequest{
+		AccountId:      in.AccountId,
+		Type:           accounts.Transaction_TYPE_REFUND,
+		AmountCents:    in.StakeCents,
+		IdempotencyKey: attemptKey("bet-reversal:", in.IdempotencyKey, attempt),
+		Reference:      "Reversal of unrecorded bet",
+	})
+
+	return err
+}
+
+// attemptKey is the ledger key of the stake or reversal of a placement's
+// attempt-th try: the first try's is the placement's key as it is.
+func attemptKey(prefix, key string, attempt int64) string {
+	if attempt == 0 {
+		return prefix + key
+	}
+
+	return fmt.Sprintf("%s%s#%d", prefix, key, attempt)
+}
+
+func (s *bettingService) GetBet(ctx context.Context, in *betting.GetBetRequest) (*betting.GetBetResponse, error) {
+	bet, err := s.betsRepo.Get(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if bet == nil {
+		return nil, status.Error(codes.NotFound, "bet not found")
+	}
+
+	bet.FlexiPercent = exotic.FlexiPercent(bet.StakeCents, bet.Combinations)
+
+	return &betting.GetBetResponse{Bet: bet}, nil
+}
+
+func (s *bettingService) SettleRace(ctx context.Context, in *betting.SettleRaceRequest) (*betting.SettleRaceResponse, error) {
+	race, err := s.getRace(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	placings, scratched := results(race)
+	if len(placings) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "race has not been resulted")
+	}
+
+	dividends := make(map[betting.Bet_Type]int64, len(in.Dividends))
+	for _, d := range in.Dividends {
+		dividends[d.Type] = d.AmountCents
+	}
+
+	pending, err := s.betsRepo.ListPending(in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out every outcome before persisting any, so a missing dividend
+	// doesn't leave the race half settled.
+	refunds := make(map[int64]int64, len(pending))
+	for _, bet := range pending {
+		refund, err := settle(bet, placings, scratched, dividends)
+		if err != nil {
+			return nil, err
+		}
+		refunds[bet.Id] = refund
+	}
+
+	var settled []*betting.Bet
+	for _, bet := range pending {
+		// Pay out before marking the bet settled: if marking fails, a retry
+		// replays the same payout keys and the ledger pays only once.
+		if err := s.payout(ctx, bet, refunds[bet.Id]); err != nil {
+			return nil, err
+		}
+
+		if err := s.betsRepo.Settle(bet); err != nil {
+			if errors.Is(err, db.ErrAlreadySettled) {
+				continue
+			}
+			return nil, err
+		}
+		bet.FlexiPercent = exotic.FlexiPercent(bet.StakeCents, bet.Combinations)
+		settled = append(settled, bet)
+	}
+
+	return &betting.SettleRaceResponse{Bets: settled}, nil
+}
+
+// getRace looks a race up as the caller, so racing applies the caller's
+// visibility: customers can't bet on hidden races, but traders can settle them.
+func (s *bettingService) getRace(ctx context.Context, id int64) (*racing.Race, error) {
+	resp, err := s.racing.GetRace(forwardCaller(ctx), &racing.GetRaceRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Race, nil
+}
+
+// payout credits a bet's return to the customer's account: its winnings as a
+// payout, and refund, the stake of combinations voided by scratchings, as a
+// refund.
+func (s *bettingService) payout(ctx context.Context, bet *betting.Bet, refund int64) error {
+	if bet.Status == betting.Bet_STATUS_REFUNDED {
+		return s.credit(ctx, bet, accounts.Transaction_TYPE_REFUND, bet.PayoutCents, fmt.Sprintf("payout:%d", bet.Id))
+	}
+
+	if winnings := bet.PayoutCents - refund; winnings > 0 {
+		if err := s.credit(ctx, bet, accounts.Transaction_TYPE_PAYOUT, winnings, fmt.Sprintf("payout:%d", bet.Id)); err != nil {
+			return err
+		}
+	}
+	if refund > 0 {
+		return s.credit(ctx, bet, accounts.Transaction_TYPE_REFUND, refund, fmt.Sprintf("refund:%d", bet.Id))
+	}
+
+	return nil
+}
+
+// credit posts amount to the account of bet under key.
+func (s *bettingService) credit(ctx context.Context, bet *betting.Bet, txnType accounts.Transaction_Type, amount int64, key string) error {
+	_, err := s.accounts.Credit(ctx, &accounts.CreditRequest{
+		AccountId:      bet.AccountId,
+		Type:           txnType,
+		AmountCents:    amount,
+		IdempotencyKey: key,
+		Reference:      fmt.Sprintf("%s bet %d on race %d", bet.Status, bet.Id, bet.RaceId),
+	})
+
+	return err
+}
+
+// settle sets the status and payout of a bet from the race result, returning
+// the part of the payout that refunds voided combinations. Combinations
+// running a scratched runner are void, and their share of the stake refunded;
+// a bet with none left is refunded in full.
+func settle(bet *betting.Bet, placings []int64, scratched map[int64]bool, dividends map[betting.Bet_Type]int64) (int64, error) {
+	combinations, err := exotic.Combinations(bet.Type, bet.Boxed, legRunners(bet.Legs))
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "bet %d: %s", bet.Id, err)
+	}
+
+	live := exotic.WithoutScratched(combinations, scratched)
+	if len(live) == 0 {
+		bet.Status = betting.Bet_STATUS_REFUNDED
+		bet.PayoutCents = bet.StakeCents
+		return bet.StakeCents, nil
+	}
+
+	// The live combinations keep the stake they were placed with, and the
+	// rest is refunded.
+	refund := bet.StakeCents * (bet.Combinations - int64(len(live))) / bet.Combinations
+	liveStake := bet.StakeCents - refund
+
+	bet.Status = betting.Bet_STATUS_LOST
+	bet.PayoutCents = refund
+	for _, combination := range live {
+		if !exotic.Wins(bet.Type, combination, placings) {
+			continue
+		}
+
+		dividend, ok := dividends[bet.Type]
+		if !ok {
+			return 0, status.Errorf(codes.FailedPrecondition, "no dividend declared for %s", bet.Type)
+		}
+
+		// Dividends are per $1 unit; each live combination holds
+		// liveStake/live of a unit.
+		bet.Status = betting.Bet_STATUS_WON
+		bet.PayoutCents += dividend * liveStake / (100 * int64(len(live)))
+		break
+	}
+
+	return refund, nil
+}
+
+// results returns the placed runner numbers in finishing order, and the set of scratched runners.
+func results(race *racing.Race) ([]int64, map[int64]bool) {
+	var placed []*racing.Runner
+	scratched := make(map[int64]bool)
+	for _, r := range race.Runners {
+		if r.Scratched {
+			scratched[r.Number] = true
+			continue
+		}
+		if r.FinishPosition > 0 {
+			placed = append(placed, r)
+		}
+	}
+
+	sort.Slice(placed, func(i, j int) bool { return placed[i].FinishPosition < placed[j].FinishPosition })
+
+	placings := make([]int64, len(placed))
+	for i, r := range placed {
+		placings[i] = r.Number
+	}
+
+	return placings, scratched
+}
+
+func legRunners(legs []*betting.Leg) [][]int64 {
+	runners := make([][]int64, len(legs))
+	for i, leg := range legs {
+		runners[i] = leg.RunnerNumbers
+	}
+
+	return runners
+}
+
+// callerMetadata are the identity keys the API gateway sets from verified credentials.
+var callerMetadata = []string{"x-auth-subject", "x-auth-roles", "x-auth-method", "x-auth-account-id"}
+
+// forwardCaller copies the caller's identity from the incoming request onto
+// outgoing calls.
+func forwardCaller(ctx context.Context) context.Context {
+	in, _ := metadata.FromIncomingContext(ctx)
+	out, _ := metadata.FromOutgoingContext(ctx)
+	out = out.Copy()
+
+	for _, key := range callerMetadata {
+		out.Delete(key)
+		if values := in.Get(key); len(values) > 0 {
+			out.Set(key, values...)
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, out)
+}
@@ -0,0 +1,486 @@
+package service_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/betting/db"
+	"git.neds.sh/matty/entain/betting/service"
+	"git.neds.sh/matty/entain/proto/accounts"
+	"git.neds.sh/matty/entain/proto/betting"
+	"git.neds.sh/matty/entain/proto/racing"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// racingStub serves a single canned race to the betting service. A non-nil
+// barrier holds every lookup until the barrier is done.
+type racingStub struct {
+	racing.RacingClient
+	race    *racing.Race
+	md      metadata.MD
+	barrier *sync.WaitGroup
+}
+
+func (s *racingStub) GetRace(ctx context.Context, in *racing.GetRaceRequest, opts ...grpc.CallOption) (*racing.GetRaceResponse, error) {
+	if s.barrier != nil {
+		s.barrier.Done()
+		s.barrier.Wait()
+	} else {
+		s.md, _ = metadata.FromOutgoingContext(ctx)
+	}
+	if s.race == nil || s.race.Id != in.Id {
+		return nil, status.Error(codes.NotFound, "race not found")
+	}
+	return &racing.GetRaceResponse{Race: s.race}, nil
+}
+
+// accountsStub records postings and can be told to reject debits. Like the
+// ledger, it posts each idempotency key once: replays record nothing.
+type accountsStub struct {
+	accounts.AccountsClient
+	debitErr error
+	mu       sync.Mutex
+	posted   map[string]bool
+	debits   []*accounts.DebitRequest
+	credits  []*accounts.CreditRequest
+}
+
+// post reports whether key is posted for the first time.
+func (s *accountsStub) post(key string) bool {
+	if s.posted == nil {
+		s.posted = make(map[string]bool)
+	}
+	first := !s.posted[key]
+	s.posted[key] = true
+	return first
+}
+
+func (s *accountsStub) Debit(ctx context.Context, in *accounts.DebitRequest, opts ...grpc.CallOption) (*accounts.DebitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.debitErr != nil {
+		return nil, s.debitErr
+	}
+	if s.post(in.IdempotencyKey) {
+		s.debits = append(s.debits, in)
+	}
+	return &accounts.DebitResponse{Transaction: &accounts.Transaction{AmountCents: -in.AmountCents}}, nil
+}
+
+func (s *accountsStub) Credit(ctx context.Context, in *accounts.CreditRequest, opts ...grpc.CallOption) (*accounts.CreditResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.post(in.IdempotencyKey) {
+		s.credits = append(s.credits, in)
+	}
+	return &accounts.CreditResponse{Transaction: &accounts.Transaction{AmountCents: in.AmountCents}}, nil
+}
+
+func openRace() *racing.Race {
+	return &racing.Race{
+		Id:                  1,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Status:              racing.Race_STATUS_OPEN,
+		Runners: []*racing.Runner{
+			{Number: 1}, {Number: 2}, {Number: 3}, {Number: 4}, {Number: 5, Scratched: true},
+		},
+	}
+}
+
+func legs(runners ...[]int64) []*betting.Leg {
+	var out []*betting.Leg
+	for _, r := range runners {
+		out = append(out, &betting.Leg{RunnerNumbers: r})
+	}
+	return out
+}
+
+func TestBettingService_PlaceBet(t *testing.T) {
+	tests := []struct {
+		name             string
+		race             *racing.Race
+		req              *betting.PlaceBetRequest
+		debitErr         error
+		wantCode         codes.Code
+		wantCombinations int64
+		wantFlexi        float64
+	}{
+		{
+			name:             "boxed trifecta at flexi",
+			race:             openRace(),
+			req:              &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_TRIFECTA, Boxed: true, Legs: legs([]int64{1, 2, 3, 4}), StakeCents: 1200, AccountId: 3, IdempotencyKey: "k1"},
+			wantCode:         codes.OK,
+			wantCombinations: 24,
+			wantFlexi:        50,
+		},
+		{
+			name:             "exacta standout",
+			race:             openRace(),
+			req:              &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_EXACTA, Legs: legs([]int64{1}, []int64{2, 3}), StakeCents: 200, AccountId: 3, IdempotencyKey: "k2"},
+			wantCode:         codes.OK,
+			wantCombinations: 2,
+			wantFlexi:        100,
+		},
+		{
+			name:     "missing idempotency key rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "insufficient funds rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			debitErr: status.Error(codes.FailedPrecondition, "insufficient funds"),
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "scratched runner rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 5}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown runner rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 42}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid selection rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_FIRST_FOUR, Legs: legs([]int64{1}, []int64{2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "zero stake rejected",
+			race:     openRace(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), IdempotencyKey: "k"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "closed race rejected",
+			race: func() *racing.Race {
+				r := openRace()
+				r.Status = racing.Race_STATUS_CLOSED
+				return r
+			}(),
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "unknown race",
+			race:     nil,
+			req:      &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := db.NewBetsRepoMock(t)
+			if tt.req.IdempotencyKey != "" && tt.req.StakeCents > 0 {
+				repo.On("GetByIdempotencyKey", tt.req.IdempotencyKey).Return((*betting.Bet)(nil), nil).Once()
+				repo.On("Reversals", tt.req.IdempotencyKey).Return(int64(0), nil).Maybe()
+			}
+			if tt.wantCode == codes.OK {
+				repo.On("Create", mock.AnythingOfType("*betting.Bet"), tt.req.IdempotencyKey).Return(func(b *betting.Bet, key string) (*betting.Bet, error) {
+					b.Id = 10
+					return b, nil
+				}).Once()
+			}
+
+			wallet := &accountsStub{debitErr: tt.debitErr}
+			svc := service.NewBettingService(repo, &racingStub{race: tt.race}, wallet)
+			resp, err := svc.PlaceBet(context.Background(), tt.req)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				require.Empty(t, wallet.debits)
+				return
+			}
+
+			require.Len(t, wallet.debits, 1)
+			require.Equal(t, tt.req.StakeCents, wallet.debits[0].AmountCents)
+			require.Equal(t, accounts.Transaction_TYPE_BET_STAKE, wallet.debits[0].Type)
+			require.Equal(t, "bet:"+tt.req.IdempotencyKey, wallet.debits[0].IdempotencyKey)
+
+			require.Equal(t, int64(10), resp.Bet.Id)
+			require.Equal(t, betting.Bet_STATUS_PENDING, resp.Bet.Status)
+			require.Equal(t, tt.wantCombinations, resp.Bet.Combinations)
+			require.InDelta(t, tt.wantFlexi, resp.Bet.FlexiPercent, 0.001)
+		})
+	}
+}
+
+func TestBettingService_PlaceBet_Retry(t *testing.T) {
+	existing := &betting.Bet{Id: 10, AccountId: 3, RaceId: 1, StakeCents: 100, Combinations: 1}
+
+	repo := db.NewBetsRepoMock(t)
+	repo.On("GetByIdempotencyKey", "k").Return(existing, nil).Once()
+
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: openRace()}, wallet)
+	resp, err := svc.PlaceBet(context.Background(), &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), resp.Bet.Id)
+	require.Empty(t, wallet.debits, "a retried placement must not take the stake again")
+}
+
+func TestBettingService_PlaceBet_ReversesStakeWhenBetNotRecorded(t *testing.T) {
+	repo := db.NewBetsRepoMock(t)
+	repo.On("GetByIdempotencyKey", "k").Return((*betting.Bet)(nil), nil).Once()
+	repo.On("Reversals", "k").Return(int64(0), nil).Once()
+	repo.On("Create", mock.AnythingOfType("*betting.Bet"), "k").Return((*betting.Bet)(nil), context.Canceled).Once()
+	repo.On("AddReversal", "k").Return(int64(1), nil).Once()
+
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: openRace()}, wallet)
+	_, err := svc.PlaceBet(context.Background(), &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, wallet.debits, 1)
+	require.Len(t, wallet.credits, 1)
+	require.Equal(t, accounts.Transaction_TYPE_REFUND, wallet.credits[0].Type)
+	require.Equal(t, int64(100), wallet.credits[0].AmountCents)
+	require.Equal(t, "bet-reversal:k", wallet.credits[0].IdempotencyKey)
+}
+
+func TestBettingService_PlaceBet_RetryAfterReversal(t *testing.T) {
+	req := &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"}
+
+	repo := db.NewBetsRepoMock(t)
+	repo.On("GetByIdempotencyKey", "k").Return((*betting.Bet)(nil), nil).Twice()
+	repo.On("Reversals", "k").Return(int64(0), nil).Once()
+	repo.On("Create", mock.AnythingOfType("*betting.Bet"), "k").Return((*betting.Bet)(nil), context.Canceled).Once()
+	repo.On("AddReversal", "k").Return(int64(1), nil).Once()
+	repo.On("Reversals", "k").Return(int64(1), nil).Once()
+	repo.On("Create", mock.AnythingOfType("*betting.Bet"), "k").Return(func(b *betting.Bet, key string) (*betting.Bet, error) {
+		b.Id = 10
+		return b, nil
+	}).Once()
+
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: openRace()}, wallet)
+	_, err := svc.PlaceBet(context.Background(), req)
+	require.ErrorIs(t, err, context.Canceled)
+	resp, err := svc.PlaceBet(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), resp.Bet.Id)
+
+	// The stake handed back is taken afresh, not replayed: the bet holds one
+	// stake in all.
+	require.Len(t, wallet.debits, 2)
+	require.Equal(t, "bet:k", wallet.debits[0].IdempotencyKey)
+	require.Equal(t, "bet:k#1", wallet.debits[1].IdempotencyKey)
+	require.Len(t, wallet.credits, 1)
+	require.Equal(t, "bet-reversal:k", wallet.credits[0].IdempotencyKey)
+}
+
+func TestBettingService_PlaceBet_Concurrent(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "betting.db")+"?_txlock=immediate&_busy_timeout=5000&_journal_mode=WAL")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	repo := db.NewBetsRepo(sqlDB)
+	require.NoError(t, repo.Init())
+
+	// Both placements look the key up before either records the bet.
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: openRace(), barrier: &barrier}, wallet)
+
+	req := &betting.PlaceBetRequest{RaceId: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{1, 2}), StakeCents: 100, AccountId: 3, IdempotencyKey: "k"}
+	var (
+		wg    sync.WaitGroup
+		resps [2]*betting.PlaceBetResponse
+		errs  [2]error
+	)
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i], errs[i] = svc.PlaceBet(context.Background(), req)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, resps[0].Bet.Id, resps[1].Bet.Id, "both placements return the one bet")
+	require.Len(t, wallet.debits, 1)
+	require.Empty(t, wallet.credits, "the stake of a bet that was recorded is never handed back")
+}
+
+func TestBettingService_GetBet_NotFound(t *testing.T) {
+	repo := db.NewBetsRepoMock(t)
+	repo.On("Get", int64(3)).Return((*betting.Bet)(nil), nil).Once()
+
+	svc := service.NewBettingService(repo, &racingStub{}, &accountsStub{})
+	_, err := svc.GetBet(context.Background(), &betting.GetBetRequest{Id: 3})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestBettingService_ForwardsCallerToRacing(t *testing.T) {
+	stub := &racingStub{race: openRace()}
+	svc := service.NewBettingService(db.NewBetsRepoMock(t), stub, &accountsStub{})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-auth-subject", "trader-1",
+		"x-auth-roles", "trading",
+		"x-unrelated", "dropped",
+	))
+	_, err := svc.SettleRace(ctx, &betting.SettleRaceRequest{RaceId: 1})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	require.Equal(t, []string{"trading"}, stub.md.Get("x-auth-roles"))
+	require.Equal(t, []string{"trader-1"}, stub.md.Get("x-auth-subject"))
+	require.Empty(t, stub.md.Get("x-unrelated"))
+}
+
+func TestBettingService_SettleRace(t *testing.T) {
+	// Official result: 3, 1, 4, 2 with 5 scratched after bets were taken.
+	resulted := &racing.Race{
+		Id:     1,
+		Status: racing.Race_STATUS_CLOSED,
+		Runners: []*racing.Runner{
+			{Number: 1, FinishPosition: 2},
+			{Number: 2, FinishPosition: 4},
+			{Number: 3, FinishPosition: 1},
+			{Number: 4, FinishPosition: 3},
+			{Number: 5, Scratched: true},
+		},
+	}
+	dividends := []*betting.Dividend{
+		{Type: betting.Bet_TYPE_QUINELLA, AmountCents: 850},
+		{Type: betting.Bet_TYPE_TRIFECTA, AmountCents: 12000},
+	}
+
+	pending := []*betting.Bet{
+		// Quinella 1-3 at 100% returns the full dividend.
+		{Id: 1, Type: betting.Bet_TYPE_QUINELLA, Legs: legs([]int64{3, 1}), StakeCents: 100, Combinations: 1},
+		// Boxed trifecta of four runners at 50% returns half the dividend.
+		{Id: 2, Type: betting.Bet_TYPE_TRIFECTA, Boxed: true, Legs: legs([]int64{1, 2, 3, 4}), StakeCents: 1200, Combinations: 24},
+		// Exacta 1-3 in the wrong order loses.
+		{Id: 3, Type: betting.Bet_TYPE_EXACTA, Legs: legs([]int64{1}, []int64{3}), StakeCents: 100, Combinations: 1},
+		// Every combination runs the scratched runner, so the stake is refunded.
+		{Id: 4, Type: betting.Bet_TYPE_EXACTA, Legs: legs([]int64{5}, []int64{1, 3}), StakeCents: 200, Combinations: 2},
+	}
+
+	repo := db.NewBetsRepoMock(t)
+	repo.On("ListPending", int64(1)).Return(pending, nil).Once()
+	repo.On("Settle", mock.AnythingOfType("*betting.Bet")).Return(nil).Times(len(pending))
+
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: resulted}, wallet)
+	resp, err := svc.SettleRace(context.Background(), &betting.SettleRaceRequest{RaceId: 1, Dividends: dividends})
+	require.NoError(t, err)
+	require.Len(t, resp.Bets, 4)
+
+	// Winners and refunds are credited once each, keyed by bet.
+	require.Len(t, wallet.credits, 3)
+	credited := map[string]*accounts.CreditRequest{}
+	for _, c := range wallet.credits {
+		credited[c.IdempotencyKey] = c
+	}
+	require.Equal(t, accounts.Transaction_TYPE_PAYOUT, credited["payout:2"].Type)
+	require.Equal(t, int64(6000), credited["payout:2"].AmountCents)
+	require.Equal(t, accounts.Transaction_TYPE_REFUND, credited["payout:4"].Type)
+
+	want := map[int64]struct {
+		status betting.Bet_Status
+		payout int64
+	}{
+		1: {betting.Bet_STATUS_WON, 850},
+		2: {betting.Bet_STATUS_WON, 6000},
+		3: {betting.Bet_STATUS_LOST, 0},
+		4: {betting.Bet_STATUS_REFUNDED, 200},
+	}
+	for _, bet := range resp.Bets {
+		require.Equal(t, want[bet.Id].status, bet.Status, "bet %d", bet.Id)
+		require.Equal(t, want[bet.Id].payout, bet.PayoutCents, "bet %d", bet.Id)
+	}
+}
+
+func TestBettingService_SettleRace_Scratched(t *testing.T) {
+	// Official result: 3, 1, 4, 2 with 5 scratched after bets were taken.
+	resulted := &racing.Race{
+		Id: 1,
+		Runners: []*racing.Runner{
+			{Number: 1, FinishPosition: 2},
+			{Number: 2, FinishPosition: 4},
+			{Number: 3, FinishPosition: 1},
+			{Number: 4, FinishPosition: 3},
+			{Number: 5, Scratched: true},
+		},
+	}
+
+	pending := []*betting.Bet{
+		// A boxed trifecta of 1, 3, 4 and 5 keeps the 6 of its 24
+		// combinations without 5: $3 of its $12 stake, at 50%. It wins half
+		// the dividend, and $9 is refunded.
+		{Id: 1, AccountId: 3, Type: betting.Bet_TYPE_TRIFECTA, Boxed: true, Legs: legs([]int64{1, 3, 4, 5}), StakeCents: 1200, Combinations: 24},
+		// An exacta 1 over 2 or 5 keeps 1-2, which loses, and refunds the
+		// half of its stake on 1-5.
+		{Id: 2, AccountId: 3, Type: betting.Bet_TYPE_EXACTA, Legs: legs([]int64{1}, []int64{2, 5}), StakeCents: 100, Combinations: 2},
+	}
+
+	repo := db.NewBetsRepoMock(t)
+	repo.On("ListPending", int64(1)).Return(pending, nil).Once()
+	repo.On("Settle", mock.AnythingOfType("*betting.Bet")).Return(nil).Times(len(pending))
+
+	wallet := &accountsStub{}
+	svc := service.NewBettingService(repo, &racingStub{race: resulted}, wallet)
+	resp, err := svc.SettleRace(context.Background(), &betting.SettleRaceRequest{RaceId: 1, Dividends: []*betting.Dividend{
+		{Type: betting.Bet_TYPE_TRIFECTA, AmountCents: 12000},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.Bets, 2)
+
+	require.Equal(t, betting.Bet_STATUS_WON, resp.Bets[0].Status)
+	require.Equal(t, int64(6000+900), resp.Bets[0].PayoutCents)
+	require.Equal(t, betting.Bet_STATUS_LOST, resp.Bets[1].Status)
+	require.Equal(t, int64(50), resp.Bets[1].PayoutCents)
+
+	credited := map[string]*accounts.CreditRequest{}
+	for _, c := range wallet.credits {
+		credited[c.IdempotencyKey] = c
+	}
+	require.Len(t, credited, 3)
+	require.Equal(t, accounts.Transaction_TYPE_PAYOUT, credited["payout:1"].Type)
+	require.Equal(t, int64(6000), credited["payout:1"].AmountCents)
+	require.Equal(t, accounts.Transaction_TYPE_REFUND, credited["refund:1"].Type)
+	require.Equal(t, int64(900), credited["refund:1"].AmountCents)
+	require.Equal(t, accounts.Transaction_TYPE_REFUND, credited["refund:2"].Type)
+	require.Equal(t, int64(50), credited["refund:2"].AmountCents)
+}
+
+func TestBettingService_SettleRace_Preconditions(t *testing.T) {
+	t.Run("race not resulted", func(t *testing.T) {
+		repo := db.NewBetsRepoMock(t)
+		svc := service.NewBettingService(repo, &racingStub{race: openRace()}, &accountsStub{})
+
+		_, err := svc.SettleRace(context.Background(), &betting.SettleRaceRequest{RaceId: 1})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("winning pool without dividend settles nothing", func(t *testing.T) {
+		race := &racing.Race{Id: 1, Runners: []*racing.Runner{{Number: 1, FinishPosition: 1}, {Number: 2, FinishPosition: 2}}}
+		repo := db.NewBetsRepoMock(t)
+		repo.On("ListPending", int64(1)).Return([]*betting.Bet{
+			{Id: 1, Type: betting.Bet_TYPE_EXACTA, Legs: legs([]int64{1}, []int64{2}), StakeCents: 100, Combinations: 1},
+		}, nil).Once()
+
+		svc := service.NewBettingService(repo, &racingStub{race: race}, &accountsStub{})
+		_, err := svc.SettleRace(context.Background(), &betting.SettleRaceRequest{RaceId: 1})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
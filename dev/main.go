@@ -0,0 +1,190 @@
+// Command dev runs the whole stack for local development: it builds each
+// service, runs them as supervised children on consistent ports with shared
+// settings, prefixes their logs with the service name, and stops them all
+// gracefully on Ctrl-C.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/config"
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/pkg/tlsutil"
+	"git.neds.sh/matty/entain/pkg/tracing"
+)
+
+var (
+	root            = flag.String("root", "..", "Repository root, holding a directory for each service")
+	binDir          = flag.String("bin-dir", "", "Directory to build the services into (defaults to a temporary directory)")
+	services        = flag.String("services", strings.Join(allServices, ","), "Comma-separated services to run")
+	host            = flag.String("host", "localhost", "Host every service listens on")
+	restartDelay    = flag.Duration("restart-delay", time.Second, "How long to wait before restarting a service that exits")
+	startTimeout    = flag.Duration("start-timeout", 30*time.Second, "How long to wait for a service to become healthy before starting the services that call it anyway")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "How long each service may take to finish requests in flight when stopping")
+	tlsDevDir       = flag.String("tls-dev-dir", tlsutil.DefaultDevDir(), "Directory holding the development CA every service's certificate comes from")
+
+	// Logging and tracing settings are passed on to every service.
+	logFlags   = logging.RegisterFlags(flag.CommandLine)
+	traceFlags = tracing.RegisterFlags(flag.CommandLine)
+)
+
+// shared names the dev command's flags passed on to every service.
+var shared = []string{
+	"shutdown-timeout",
+	"tls-dev-dir",
+	"log-level",
+	"log-format",
+	"trace-exporter",
+	"trace-otlp-endpoint",
+	"trace-otlp-insecure",
+	"trace-file",
+	"trace-sample-ratio",
+}
+
+// killGrace is how long after its shutdown timeout a service that hasn't
+// stopped is killed.
+const killGrace = 5 * time.Second
+
+func main() {
+	// Several services share a terminal; text logs are easier to follow.
+	logFormat := flag.Lookup("log-format")
+	logFormat.DefValue = logging.FormatText
+	_ = logFormat.Value.Set(logging.FormatText)
+
+	config.Parse("DEV",
+		config.Required("root", root),
+		config.Check(validateServices),
+		config.Positive("restart-delay", restartDelay),
+		config.Positive("start-timeout", startTimeout),
+		config.Positive("shutdown-timeout", shutdownTimeout),
+		logFlags,
+		traceFlags,
+	)
+
+	if err := logFlags.Setup("dev"); err != nil {
+		log.Fatalf("invalid logging flags: %s\n", err)
+	}
+
+	if err := run(); err != nil {
+		slog.Error("failed running dev stack", "error", err)
+		os.Exit(1)
+	}
+}
+
+func validateServices() error {
+	for _, name := range strings.Split(*services, ",") {
+		if !slices.Contains(allServices, name) {
+			return fmt.Errorf("-services: unknown service %q: want some of %s", name, strings.Join(allServices, ", "))
+		}
+	}
+	return nil
+}
+
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rootDir, err := filepath.Abs(*root)
+	if err != nil {
+		return err
+	}
+	bin := *binDir
+	if bin == "" {
+		if bin, err = os.MkdirTemp("", "entain-dev-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(bin)
+	}
+	if bin, err = filepath.Abs(bin); err != nil {
+		return err
+	}
+
+	settings := map[string]string{"tls-dev": "true"}
+	for _, name := range shared {
+		settings[name] = flag.Lookup(name).Value.String()
+	}
+	stack := newServices(strings.Split(*services, ","), *host, settings)
+
+	// The dev command checks each service's health with a dev certificate
+	// of its own, from the same CA as the services'.
+	identity, err := (&tlsutil.Flags{Dev: true, DevDir: *tlsDevDir}).Reloader("dev")
+	if err != nil {
+		return err
+	}
+	creds, err := tlsutil.ClientCredentials(identity)
+	if err != nil {
+		return err
+	}
+
+	sv := &supervisor{
+		root:         rootDir,
+		binDir:       bin,
+		restartDelay: *restartDelay,
+		killAfter:    *shutdownTimeout + killGrace,
+		creds:        creds,
+	}
+
+	var (
+		mu      sync.Mutex
+		width   int
+		outputs = make(map[string]*prefixWriter, len(stack))
+	)
+	for _, s := range stack {
+		width = max(width, len(s.name))
+	}
+	for _, s := range stack {
+		outputs[s.name] = newPrefixWriter(&mu, os.Stdout, fmt.Sprintf("%-*s | ", width, s.name))
+	}
+
+	for _, s := range stack {
+		slog.Info("building service", "name", s.name)
+		if err := sv.build(ctx, s, outputs[s.name]); err != nil {
+			return err
+		}
+	}
+
+	// Start a stage at a time, waiting for each to serve before the next.
+	var wg sync.WaitGroup
+	for _, stage := range stages {
+		var starting []service
+		for _, s := range stack {
+			if slices.Contains(stage, s.name) {
+				starting = append(starting, s)
+			}
+		}
+
+		for _, s := range starting {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sv.run(ctx, s, outputs[s.name])
+			}()
+		}
+		for _, s := range starting {
+			if err := sv.waitServing(ctx, s, *startTimeout); err != nil && ctx.Err() == nil {
+				slog.Warn("service isn't serving; starting the next services anyway", "name", s.name, "error", err)
+			}
+		}
+	}
+
+	slog.Info("stack running; Ctrl-C to stop",
+		"services", *services,
+		"ca", filepath.Join(*tlsDevDir, "ca", "ca.pem"),
+	)
+	wg.Wait()
+	slog.Info("stack stopped")
+
+	return nil
+}
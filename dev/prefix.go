@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter writes each line written to it to out, after prefix. Writers
+// sharing a mutex never interleave their lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix []byte
+	buf    []byte
+}
+
+func newPrefixWriter(mu *sync.Mutex, out io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{mu: mu, out: out, prefix: []byte(prefix)}
+}
+
+// Write writes the complete lines in p, holding back any partial line until
+// the rest of it arrives.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes any partial line held back, ending it.
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.out.Write(append(w.prefix[:len(w.prefix):len(w.prefix)], line...))
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net"
+	"os"
+	"slices"
+	"strconv"
+
+	"git.neds.sh/matty/entain/pkg/config"
+)
+
+// service is a binary the dev command runs, and the settings, keyed by flag
+// name, that it's started with.
+type service struct {
+	name     string
+	settings map[string]string
+}
+
+// stages groups the services by when they start. Each stage starts once every
+// service in the one before is serving, so a service never starts before the
+// backends it calls: betting calls racing and accounts, and the gateway calls
+// them all.
+var stages = [][]string{{"accounts"}, {"racing", "sports"}, {"betting"}, {"api"}}
+
+// allServices lists every service in the order they start.
+var allServices = slices.Concat(stages...)
+
+// newServices returns the services named, listening on host on the ports
+// each binary defaults to, with shared added to the settings of each.
+func newServices(names []string, host string, shared map[string]string) []service {
+	addr := func(port int) string { return net.JoinHostPort(host, strconv.Itoa(port)) }
+
+	settings := map[string]map[string]string{
+		"racing": {
+			"grpc-endpoint":       addr(9000),
+			"admin-endpoint":      addr(9100),
+			"admin-grpc-endpoint": addr(9200),
+			// Development data can be thrown away.
+			"admin-allow-reseed": "true",
+		},
+		"sports": {
+			"grpc-endpoint":       addr(9001),
+			"admin-endpoint":      addr(9101),
+			"admin-grpc-endpoint": addr(9201),
+			// Development data can be thrown away.
+			"admin-allow-reseed": "true",
+		},
+		"betting": {
+			"grpc-endpoint":          addr(9002),
+			"admin-endpoint":         addr(9102),
+			"racing-grpc-endpoint":   addr(9000),
+			"accounts-grpc-endpoint": addr(9003),
+		},
+		"accounts": {
+			"grpc-endpoint":  addr(9003),
+			"admin-endpoint": addr(9103),
+		},
+		"api": {
+			"api-endpoint":           addr(8000),
+			"admin-endpoint":         addr(8001),
+			"racing-grpc-endpoint":   addr(9000),
+			"sports-grpc-endpoint":   addr(9001),
+			"betting-grpc-endpoint":  addr(9002),
+			"accounts-grpc-endpoint": addr(9003),
+			// The gateway's identity towards the services.
+			"grpc-tls-dev":     "true",
+			"grpc-tls-dev-dir": shared["tls-dev-dir"],
+		},
+	}
+
+	out := make([]service, 0, len(names))
+	for _, name := range names {
+		s := service{name: name, settings: settings[name]}
+		for k, v := range shared {
+			s.settings[k] = v
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// grpcEndpoint returns where s serves gRPC, from the environment when a
+// variable overrides the dev command's setting. It's empty for the gateway.
+func (s service) grpcEndpoint() string {
+	if addr, ok := os.LookupEnv(config.EnvName(s.name, "grpc-endpoint")); ok {
+		return addr
+	}
+	return s.settings["grpc-endpoint"]
+}
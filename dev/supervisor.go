@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthPoll is how often waitServing asks a starting service for its health.
+const healthPoll = 250 * time.Millisecond
+
+// supervisor builds and runs services, restarting any that exit until it's
+// told to stop.
+type supervisor struct {
+	root         string
+	binDir       string
+	restartDelay time.Duration
+	killAfter    time.Duration
+	// creds dial services to check their health.
+	creds credentials.TransportCredentials
+}
+
+// build compiles s into the supervisor's bin directory.
+func (sv *supervisor) build(ctx context.Context, s service, out *prefixWriter) error {
+	defer out.Flush()
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", sv.binary(s), ".")
+	cmd.Dir = filepath.Join(sv.root, s.name)
+	cmd.Stdout, cmd.Stderr = out, out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// run runs s from its module directory until ctx is done, restarting it
+// after restartDelay whenever it exits. When ctx is done, s is sent SIGTERM
+// and given killAfter to stop before it's killed.
+func (sv *supervisor) run(ctx context.Context, s service, out *prefixWriter) {
+	for {
+		start := time.Now()
+		err := sv.start(ctx, s, out)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("service exited; restarting", "name", s.name, "error", err, "ran_for", time.Since(start).Round(time.Millisecond).String(), "restart_in", sv.restartDelay.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sv.restartDelay):
+		}
+	}
+}
+
+func (sv *supervisor) start(ctx context.Context, s service, out *prefixWriter) error {
+	defer out.Flush()
+
+	cmd := exec.CommandContext(ctx, sv.binary(s))
+	cmd.Dir = filepath.Join(sv.root, s.name)
+	cmd.Env = environ(os.Environ(), s)
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = sv.killAfter
+
+	return cmd.Run()
+}
+
+// waitServing waits until s reports SERVING through grpc.health.v1, ctx is
+// done or timeout passes. Services without a gRPC endpoint are ready as soon
+// as they start.
+func (sv *supervisor) waitServing(ctx context.Context, s service, timeout time.Duration) error {
+	endpoint := s.grpcEndpoint()
+	if endpoint == "" {
+		return nil
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(sv.creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPoll)
+	defer ticker.Stop()
+	for {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("status %s", resp.GetStatus())
+			}
+			return fmt.Errorf("%s not serving after %s: %w", s.name, timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (sv *supervisor) binary(s service) string {
+	return filepath.Join(sv.binDir, s.name)
+}
+
+// environ returns env with each of s's settings added as the variable the
+// service reads it from, such as RACING_GRPC_ENDPOINT. Variables already in
+// env are kept, so they override the dev command's settings.
+func environ(env []string, s service) []string {
+	set := make(map[string]bool, len(env))
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		set[k] = true
+	}
+
+	for name, value := range s.settings {
+		if k := config.EnvName(s.name, name); !set[k] {
+			env = append(env, k+"="+value)
+		}
+	}
+	return env
+}
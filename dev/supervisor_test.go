@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		out bytes.Buffer
+	)
+	racing := newPrefixWriter(&mu, &out, "racing | ")
+	api := newPrefixWriter(&mu, &out, "api    | ")
+
+	_, err := racing.Write([]byte("listening\nhealth"))
+	require.NoError(t, err)
+	_, err = api.Write([]byte("ready\n"))
+	require.NoError(t, err)
+	_, err = racing.Write([]byte(" check passed\nshutting"))
+	require.NoError(t, err)
+	require.NoError(t, racing.Flush())
+	require.NoError(t, api.Flush())
+
+	require.Equal(t, "racing | listening\napi    | ready\nracing | health check passed\nracing | shutting\n", out.String())
+}
+
+func TestEnviron(t *testing.T) {
+	s := newServices([]string{"betting"}, "localhost", map[string]string{"log-level": "debug"})[0]
+
+	env := environ([]string{"PATH=/bin", "BETTING_LOG_LEVEL=warn"}, s)
+
+	require.Contains(t, env, "PATH=/bin")
+	require.Contains(t, env, "BETTING_LOG_LEVEL=warn")
+	require.NotContains(t, env, "BETTING_LOG_LEVEL=debug")
+	require.Contains(t, env, "BETTING_GRPC_ENDPOINT=localhost:9002")
+	require.Contains(t, env, "BETTING_RACING_GRPC_ENDPOINT=localhost:9000")
+}
+
+func TestStagesStartBackendsFirst(t *testing.T) {
+	require.Equal(t, []string{"accounts", "racing", "sports", "betting", "api"}, allServices)
+}
+
+func TestWaitServing(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	sv := &supervisor{creds: insecure.NewCredentials()}
+	s := service{name: "accounts", settings: map[string]string{"grpc-endpoint": lis.Addr().String()}}
+
+	err = sv.waitServing(context.Background(), s, 3*healthPoll)
+	require.ErrorContains(t, err, "accounts not serving")
+
+	time.AfterFunc(2*healthPoll, func() { hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING) })
+	require.NoError(t, sv.waitServing(context.Background(), s, 10*time.Second))
+
+	require.NoError(t, sv.waitServing(context.Background(), service{name: "api", settings: map[string]string{}}, time.Millisecond))
+}
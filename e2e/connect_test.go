@@ -0,0 +1,44 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Browsers call racing and sports over Connect, whose unary calls are JSON
+// posted to the RPC's gRPC path.
+func TestConnect(t *testing.T) {
+	code, body := stack.do(t, http.MethodPost, "/racing.Racing/ListRaces", `{"filter": {"meetingIds": ["501"], "showHidden": true}}`, false)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var list struct {
+		Races []race `json:"races"`
+	}
+	require.NoError(t, json.Unmarshal(body, &list))
+	var ids []string
+	for _, r := range list.Races {
+		ids = append(ids, r.ID)
+	}
+	require.ElementsMatch(t, []string{"1001", "1002"}, ids, "hidden races stay hidden from anonymous callers")
+
+	code, body = stack.do(t, http.MethodPost, "/racing.Racing/ListRaces", `{"filter": {"meetingIds": ["501"], "showHidden": true}}`, true)
+	require.Equal(t, http.StatusOK, code, string(body))
+	require.NoError(t, json.Unmarshal(body, &list))
+	require.Len(t, list.Races, 3, "traders see hidden races")
+
+	code, body = stack.do(t, http.MethodPost, "/sports.Sports/ListEvents", `{"filter": {"sportIds": ["2"]}}`, false)
+	require.Equal(t, http.StatusOK, code, string(body))
+	require.Contains(t, string(body), "Cats v Hawks")
+}
+
+func TestConnectErrors(t *testing.T) {
+	code, body := stack.do(t, http.MethodPost, "/racing.Racing/GetRace", `{"id": "1003"}`, false)
+	require.Equal(t, http.StatusNotFound, code)
+	require.JSONEq(t, `{"code": "not_found", "message": "race not found"}`, string(body))
+
+	code, _ = stack.do(t, http.MethodPost, "/racing.Racing/Nope", `{}`, false)
+	require.Equal(t, http.StatusNotFound, code)
+}
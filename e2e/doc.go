@@ -0,0 +1,6 @@
+// Package e2e tests the stack end to end: it builds racing, sports and the
+// gateway, runs them on ephemeral ports against SQLite databases of fixture
+// data, and checks the JSON the gateway answers with over HTTPS.
+//
+// The tests build the services, so they're skipped with -short.
+package e2e
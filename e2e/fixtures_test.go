@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"database/sql"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// now is when the fixtures are relative to: races and events before it have
+// closed, and those after it are open.
+var now = time.Now().Truncate(time.Second)
+
+// fixtureRace is a race in the racing fixtures.
+type fixtureRace struct {
+	id, meetingID, number int64
+	name                  string
+	visible               bool
+	start                 time.Time
+}
+
+// Racing seeds random races with IDs 1 to 100 at meetings 1 to 10, so the
+// fixtures use IDs and meetings of their own, and tests filter by meeting.
+const (
+	flemington = 501
+	randwick   = 502
+)
+
+var races = []fixtureRace{
+	{id: 1001, meetingID: flemington, number: 1, name: "Flemington R1", visible: true, start: now.Add(-2 * time.Hour)},
+	{id: 1002, meetingID: flemington, number: 2, name: "Flemington R2", visible: true, start: now.Add(time.Hour)},
+	{id: 1003, meetingID: flemington, number: 3, name: "Flemington R3", visible: false, start: now.Add(2 * time.Hour)},
+	{id: 1004, meetingID: randwick, number: 1, name: "Randwick R1", visible: true, start: now.Add(30 * time.Minute)},
+}
+
+// runners are the field of race 1002.
+var runners = []struct {
+	number    int64
+	name      string
+	scratched bool
+}{
+	{number: 1, name: "Fast Lane"},
+	{number: 2, name: "Slow Coach", scratched: true},
+	{number: 3, name: "Steady Eddie"},
+}
+
+// fixtureEvent is an event in the sports fixtures. Sports only seeds an
+// empty database, so the fixtures are all there is.
+type fixtureEvent struct {
+	id, sportID int64
+	name, venue string
+	home, away  string
+	visible     bool
+	start       time.Time
+}
+
+var events = []fixtureEvent{
+	{id: 1, sportID: 1, name: "Storm v Broncos", venue: "AAMI Park", home: "Storm", away: "Broncos", visible: true, start: now.Add(-time.Hour)},
+	{id: 2, sportID: 1, name: "Panthers v Eels", venue: "BlueBet Stadium", home: "Panthers", away: "Eels", visible: true, start: now.Add(2 * time.Hour)},
+	{id: 3, sportID: 2, name: "Swans v Giants", venue: "SCG", home: "Swans", away: "Giants", visible: false, start: now.Add(time.Hour)},
+	{id: 4, sportID: 2, name: "Cats v Hawks", venue: "GMHBA Stadium", home: "Cats", away: "Hawks", visible: true, start: now.Add(30 * time.Minute)},
+}
+
+// writeRacingFixtures creates the racing database at path, in the schema
+// racing creates, holding races and runners.
+func writeRacingFixtures(path string) error {
+	return writeDB(path, func(db *sql.DB) error {
+		if _, err := db.Exec(`CREATE TABLE races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME)`); err != nil {
+			return err
+		}
+		if _, err := db.Exec(`CREATE TABLE runners (race_id INTEGER, number INTEGER, name TEXT, scratched INTEGER, finish_position INTEGER, PRIMARY KEY (race_id, number))`); err != nil {
+			return err
+		}
+
+		for _, r := range races {
+			if _, err := db.Exec(`INSERT INTO races(id, meeting_id, name, number, visible, advertised_start_time) VALUES (?,?,?,?,?,?)`,
+				r.id, r.meetingID, r.name, r.number, r.visible, r.start.UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+		}
+		for _, r := range runners {
+			if _, err := db.Exec(`INSERT INTO runners(race_id, number, name, scratched, finish_position) VALUES (?,?,?,?,0)`,
+				1002, r.number, r.name, r.scratched); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeSportsFixtures creates the sports database at path, in the schema
+// sports creates, holding events.
+func writeSportsFixtures(path string) error {
+	return writeDB(path, func(db *sql.DB) error {
+		if _, err := db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, sport_id INTEGER, name TEXT, venue TEXT, visible INTEGER, advertised_start_time DATETIME, home_team TEXT, away_team TEXT)`); err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if _, err := db.Exec(`INSERT INTO events(id, sport_id, name, venue, visible, advertised_start_time, home_team, away_team) VALUES (?,?,?,?,?,?,?,?)`,
+				e.id, e.sportID, e.name, e.venue, e.visible, e.start.UTC().Format(time.RFC3339), e.home, e.away); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func writeDB(path string, fill func(*sql.DB) error) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fill(db)
+}
@@ -0,0 +1,16 @@
+module git.neds.sh/matty/entain/e2e
+
+go 1.23.0
+
+toolchain go1.24.6
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// graphQL posts query to the gateway's GraphQL API, and returns its data.
+func graphQL(t *testing.T, query string) json.RawMessage {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	code, resp := stack.do(t, http.MethodPost, "/graphql", string(body), false)
+	require.Equal(t, http.StatusOK, code, string(resp))
+
+	var result struct {
+		Data   json.RawMessage   `json:"data"`
+		Errors []json.RawMessage `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(resp, &result))
+	require.Empty(t, result.Errors, string(resp))
+	return result.Data
+}
+
+func TestGraphQL(t *testing.T) {
+	data := graphQL(t, `{
+		races(meetingIds: [501], orderBy: "number") {
+			id name status
+			meeting { id races { id } }
+			runners { number name scratched }
+		}
+		race(id: 1004) { name meetingId }
+		missing: race(id: 1003) { id }
+		event(id: 4) { homeTeam awayTeam venue status }
+		hidden: event(id: 3) { id }
+	}`)
+
+	require.JSONEq(t, `{
+		"races": [
+			{"id": "1001", "name": "Flemington R1", "status": "CLOSED",
+				"meeting": {"id": "501", "races": [{"id": "1001"}, {"id": "1002"}]}, "runners": []},
+			{"id": "1002", "name": "Flemington R2", "status": "OPEN",
+				"meeting": {"id": "501", "races": [{"id": "1001"}, {"id": "1002"}]},
+				"runners": [
+					{"number": 1, "name": "Fast Lane", "scratched": false},
+					{"number": 2, "name": "Slow Coach", "scratched": true},
+					{"number": 3, "name": "Steady Eddie", "scratched": false}
+				]}
+		],
+		"race": {"name": "Randwick R1", "meetingId": "502"},
+		"missing": null,
+		"event": {"homeTeam": "Cats", "awayTeam": "Hawks", "venue": "GMHBA Stadium", "status": "OPEN"},
+		"hidden": null
+	}`, string(data), "hidden races and events stay hidden from anonymous callers")
+}
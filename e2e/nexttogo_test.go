@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// item is an item of the next to go feed as the gateway returns it.
+type item struct {
+	Type                string    `json:"type"`
+	AdvertisedStartTime time.Time `json:"advertisedStartTime"`
+	Race                *race     `json:"race"`
+	Event               *event    `json:"event"`
+}
+
+func nextToGo(t *testing.T, query string) []item {
+	t.Helper()
+
+	code, body := stack.do(t, http.MethodGet, "/v1/next-to-go"+query, "", false)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var resp struct {
+		Items       []item   `json:"items"`
+		Unavailable []string `json:"unavailable"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Empty(t, resp.Unavailable)
+	return resp.Items
+}
+
+func TestNextToGo(t *testing.T) {
+	items := nextToGo(t, "?limit=20")
+	require.NotEmpty(t, items)
+	require.LessOrEqual(t, len(items), 20)
+
+	for i, it := range items {
+		require.True(t, it.AdvertisedStartTime.After(now), "item %d has started", i)
+		if i > 0 {
+			require.False(t, it.AdvertisedStartTime.Before(items[i-1].AdvertisedStartTime), "item %d is out of order", i)
+		}
+		switch it.Type {
+		case "TYPE_RACE":
+			require.NotNil(t, it.Race)
+			require.Equal(t, "STATUS_OPEN", it.Race.Status)
+		case "TYPE_EVENT":
+			require.NotNil(t, it.Event)
+			require.Equal(t, "STATUS_OPEN", it.Event.Status)
+		default:
+			t.Fatalf("item %d has type %q", i, it.Type)
+		}
+	}
+}
+
+func TestNextToGoEvents(t *testing.T) {
+	items := nextToGo(t, "?types=TYPE_EVENT")
+
+	var ids []string
+	for _, it := range items {
+		require.Equal(t, "TYPE_EVENT", it.Type)
+		ids = append(ids, it.Event.ID)
+	}
+	require.Equal(t, []string{"4", "2"}, ids, "open, visible events, soonest first")
+}
@@ -0,0 +1,245 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// race is a race as the gateway returns it.
+type race struct {
+	ID                  string `json:"id"`
+	MeetingID           string `json:"meetingId"`
+	Name                string `json:"name"`
+	Number              string `json:"number"`
+	Visible             bool   `json:"visible"`
+	AdvertisedStartTime string `json:"advertisedStartTime"`
+	Status              string `json:"status"`
+}
+
+func TestListRaces(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		authenticated bool
+		want          []string
+	}{
+		{
+			name: "ordered by start time",
+			body: `{"filter":{"meetingIds":[501,502]}}`,
+			want: []string{"1001", "1004", "1002"},
+		},
+		{
+			name: "filtered by meeting",
+			body: `{"filter":{"meetingIds":[502]}}`,
+			want: []string{"1004"},
+		},
+		{
+			name: "ordered by name descending",
+			body: `{"filter":{"meetingIds":[501,502],"orderBy":"name desc"}}`,
+			want: []string{"1004", "1002", "1001"},
+		},
+		{
+			name: "ordered by number then ID",
+			body: `{"filter":{"meetingIds":[501],"orderBy":"number DESC"}}`,
+			want: []string{"1002", "1001"},
+		},
+		{
+			name: "hidden races refused to the public",
+			body: `{"filter":{"meetingIds":[501,502],"showHidden":true}}`,
+			want: []string{"1001", "1004", "1002"},
+		},
+		{
+			name:          "hidden races shown to trading",
+			body:          `{"filter":{"meetingIds":[501,502],"showHidden":true}}`,
+			authenticated: true,
+			want:          []string{"1001", "1004", "1002", "1003"},
+		},
+		{
+			name:          "visible races only unless asked",
+			body:          `{"filter":{"meetingIds":[501,502]}}`,
+			authenticated: true,
+			want:          []string{"1001", "1004", "1002"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := stack.do(t, http.MethodPost, "/v1/list-races", tt.body, tt.authenticated)
+			require.Equal(t, http.StatusOK, code, string(body))
+
+			var resp struct {
+				Races []race `json:"races"`
+			}
+			require.NoError(t, json.Unmarshal(body, &resp))
+
+			var ids []string
+			for _, r := range resp.Races {
+				ids = append(ids, r.ID)
+			}
+			require.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestListRacesStatus(t *testing.T) {
+	code, body := stack.do(t, http.MethodPost, "/v1/list-races", `{"filter":{"meetingIds":[501,502],"showHidden":true}}`, true)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var resp struct {
+		Races []race `json:"races"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+
+	statuses := make(map[string]string)
+	for _, r := range resp.Races {
+		statuses[r.ID] = r.Status
+	}
+	require.Equal(t, map[string]string{
+		"1001": "STATUS_CLOSED",
+		"1002": "STATUS_OPEN",
+		"1003": "STATUS_OPEN",
+		"1004": "STATUS_OPEN",
+	}, statuses)
+}
+
+func TestGetRace(t *testing.T) {
+	code, body := stack.do(t, http.MethodGet, "/v1/races/1002", "", false)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.JSONEq(t, fmt.Sprintf(`{
+		"race": {
+			"id": "1002",
+			"meetingId": "501",
+			"name": "Flemington R2",
+			"number": "2",
+			"visible": true,
+			"advertisedStartTime": %q,
+			"status": "STATUS_OPEN",
+			"runners": [
+				{"number": "1", "name": "Fast Lane", "scratched": false, "finishPosition": "0"},
+				{"number": "2", "name": "Slow Coach", "scratched": true, "finishPosition": "0"},
+				{"number": "3", "name": "Steady Eddie", "scratched": false, "finishPosition": "0"}
+			]
+		}
+	}`, now.Add(time.Hour).UTC().Format(time.RFC3339)), string(body))
+}
+
+func TestGetRaceJSONFormat(t *testing.T) {
+	code, body := stack.do(t, http.MethodGet, "/v1/races/1002?$format=snake,enum-numbers,omit-unpopulated", "", false)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.JSONEq(t, fmt.Sprintf(`{
+		"race": {
+			"id": "1002",
+			"meeting_id": "501",
+			"name": "Flemington R2",
+			"number": "2",
+			"visible": true,
+			"advertised_start_time": %q,
+			"runners": [
+				{"number": "1", "name": "Fast Lane"},
+				{"number": "2", "name": "Slow Coach", "scratched": true},
+				{"number": "3", "name": "Steady Eddie"}
+			]
+		}
+	}`, now.Add(time.Hour).UTC().Format(time.RFC3339)), string(body))
+
+	code, _ = stack.do(t, http.MethodGet, "/v1/races/1002?$format=xml", "", false)
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetRaceErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		authenticated bool
+		wantCode      int
+		wantMessage   string
+	}{
+		{
+			name:        "unknown race",
+			path:        "/v1/races/999999",
+			wantCode:    http.StatusNotFound,
+			wantMessage: "race not found",
+		},
+		{
+			name:        "hidden race to the public",
+			path:        "/v1/races/1003",
+			wantCode:    http.StatusNotFound,
+			wantMessage: "race not found",
+		},
+		{
+			name:          "hidden race to trading",
+			path:          "/v1/races/1003",
+			authenticated: true,
+			wantCode:      http.StatusOK,
+		},
+		{
+			name:     "malformed ID",
+			path:     "/v1/races/abc",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := stack.do(t, http.MethodGet, tt.path, "", tt.authenticated)
+			require.Equal(t, tt.wantCode, code, string(body))
+
+			if tt.wantMessage != "" {
+				var status struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				}
+				require.NoError(t, json.Unmarshal(body, &status))
+				require.Equal(t, tt.wantMessage, status.Message)
+			}
+		})
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "malformed JSON",
+			method:   http.MethodPost,
+			path:     "/v1/list-races",
+			body:     `{"filter":`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong field type",
+			method:   http.MethodPost,
+			path:     "/v1/list-events",
+			body:     `{"filter":{"sportIds":"one"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown route",
+			method:   http.MethodGet,
+			path:     "/v1/horses",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "betting without credentials",
+			method:   http.MethodGet,
+			path:     "/v1/bets/1",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := stack.do(t, tt.method, tt.path, tt.body, false)
+			require.Equal(t, tt.wantCode, code, string(body))
+		})
+	}
+}
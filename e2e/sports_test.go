@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// event is a sports event as the gateway returns it.
+type event struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestListEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		authenticated bool
+		want          []string
+	}{
+		{
+			name: "visible events ordered by start time",
+			body: `{}`,
+			want: []string{"1", "4", "2"},
+		},
+		{
+			name: "filtered by sport",
+			body: `{"filter":{"sportIds":[1]}}`,
+			want: []string{"1", "2"},
+		},
+		{
+			name: "ordered by venue",
+			body: `{"filter":{"orderBy":"venue"}}`,
+			want: []string{"1", "2", "4"},
+		},
+		{
+			name: "hidden events refused to the public",
+			body: `{"filter":{"showHidden":true}}`,
+			want: []string{"1", "4", "2"},
+		},
+		{
+			name:          "hidden events shown to trading",
+			body:          `{"filter":{"showHidden":true}}`,
+			authenticated: true,
+			want:          []string{"1", "4", "3", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := stack.do(t, http.MethodPost, "/v1/list-events", tt.body, tt.authenticated)
+			require.Equal(t, http.StatusOK, code, string(body))
+
+			var resp struct {
+				Events []event `json:"events"`
+			}
+			require.NoError(t, json.Unmarshal(body, &resp))
+
+			var ids []string
+			for _, e := range resp.Events {
+				ids = append(ids, e.ID)
+			}
+			require.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestListEventsResponse(t *testing.T) {
+	code, body := stack.do(t, http.MethodPost, "/v1/list-events", `{"filter":{"sportIds":[1]}}`, false)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.JSONEq(t, fmt.Sprintf(`{
+		"events": [
+			{
+				"id": "1",
+				"sportId": "1",
+				"name": "Storm v Broncos",
+				"venue": "AAMI Park",
+				"visible": true,
+				"advertisedStartTime": %q,
+				"status": "STATUS_CLOSED",
+				"homeTeam": "Storm",
+				"awayTeam": "Broncos"
+			},
+			{
+				"id": "2",
+				"sportId": "1",
+				"name": "Panthers v Eels",
+				"venue": "BlueBet Stadium",
+				"visible": true,
+				"advertisedStartTime": %q,
+				"status": "STATUS_OPEN",
+				"homeTeam": "Panthers",
+				"awayTeam": "Eels"
+			}
+		]
+	}`, now.Add(-time.Hour).UTC().Format(time.RFC3339), now.Add(2*time.Hour).UTC().Format(time.RFC3339)), string(body))
+}
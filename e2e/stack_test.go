@@ -0,0 +1,307 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// apiKey is a trading API key the gateway accepts.
+const apiKey = "e2e-trading-key"
+
+// stack is the running services the tests send requests to.
+var stack *services
+
+// services are the processes of a running stack.
+type services struct {
+	url    string
+	client *http.Client
+	procs  []*process
+}
+
+// process is a running service and everything it has logged.
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	out  *syncBuffer
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if testing.Short() {
+		fmt.Println("skipping end-to-end tests in short mode")
+		os.Exit(0)
+	}
+
+	dir, err := os.MkdirTemp("", "entain-e2e-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	stack, err = start(dir)
+	code := 1
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "starting stack:", err)
+	} else {
+		code = m.Run()
+	}
+
+	if stack != nil {
+		stack.stop()
+		if code != 0 {
+			stack.dumpLogs(os.Stderr)
+		}
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// start builds the services into dir and runs them, each with a database of
+// fixture data, returning once the gateway answers.
+func start(dir string) (*services, error) {
+	root, err := filepath.Abs("..")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"racing", "sports", "api"} {
+		cmd := exec.Command("go", "build", "-o", filepath.Join(dir, "bin", name), ".")
+		cmd.Dir = filepath.Join(root, name)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("building %s: %w\n%s", name, err, out)
+		}
+	}
+
+	racingDB, sportsDB := filepath.Join(dir, "racing.db"), filepath.Join(dir, "sports.db")
+	if err := writeRacingFixtures(racingDB); err != nil {
+		return nil, err
+	}
+	if err := writeSportsFixtures(sportsDB); err != nil {
+		return nil, err
+	}
+
+	apiKeys := filepath.Join(dir, "api-keys.json")
+	sum := sha256.Sum256([]byte(apiKey))
+	keys := fmt.Sprintf(`{"keys":[{"name":"e2e","sha256":%q,"roles":["trading"]}]}`, hex.EncodeToString(sum[:]))
+	if err := os.WriteFile(apiKeys, []byte(keys), 0o600); err != nil {
+		return nil, err
+	}
+
+	ports, err := freePorts(8)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tlsDir       = filepath.Join(dir, "tls")
+		racingAddr   = ports[0]
+		sportsAddr   = ports[1]
+		apiAddr      = ports[2]
+		unusedAddr   = "127.0.0.1:1"
+		sharedFlags  = []string{"--tls-dev", "--tls-dev-dir", tlsDir, "--log-level", "warn"}
+		racingFlags  = []string{"--grpc-endpoint", racingAddr, "--admin-endpoint", ports[3], "--admin-grpc-endpoint", ports[6], "--db-dsn", racingDB}
+		sportsFlags  = []string{"--grpc-endpoint", sportsAddr, "--admin-endpoint", ports[4], "--admin-grpc-endpoint", ports[7], "--db-dsn", sportsDB}
+		gatewayFlags = []string{
+			"--api-endpoint", apiAddr,
+			"--admin-endpoint", ports[5],
+			"--racing-grpc-endpoint", racingAddr,
+			"--sports-grpc-endpoint", sportsAddr,
+			"--betting-grpc-endpoint", unusedAddr,
+			"--accounts-grpc-endpoint", unusedAddr,
+			"--grpc-tls-dev", "--grpc-tls-dev-dir", tlsDir,
+			"--api-keys-file", apiKeys,
+		}
+	)
+
+	s := &services{url: "https://" + apiAddr}
+	for name, args := range map[string][]string{"racing": racingFlags, "sports": sportsFlags, "api": gatewayFlags} {
+		p, err := run(name, filepath.Join(dir, "bin", name), dir, slices.Concat(sharedFlags, args))
+		if err != nil {
+			s.stop()
+			return nil, err
+		}
+		s.procs = append(s.procs, p)
+	}
+
+	if err := s.waitReady(tlsDir, 30*time.Second); err != nil {
+		s.stop()
+		s.dumpLogs(os.Stderr)
+		return nil, err
+	}
+	return s, nil
+}
+
+// run starts the service name from bin, in dir.
+func run(name, bin, dir string, args []string) (*process, error) {
+	p := &process{name: name, cmd: exec.Command(bin, args...), out: &syncBuffer{}}
+	p.cmd.Dir = dir
+	p.cmd.Stdout, p.cmd.Stderr = p.out, p.out
+	if err := p.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// waitReady waits for the development CA, then for the gateway to answer
+// for both racing and sports.
+func (s *services) waitReady(tlsDir string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	caFile := filepath.Join(tlsDir, "ca", "ca.pem")
+	for {
+		ca, err := os.ReadFile(caFile)
+		if err == nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return errors.New("no certificates in " + caFile)
+			}
+			s.client = &http.Client{
+				Timeout:   5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no development CA: %w", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	for _, path := range []string{"/v1/list-races", "/v1/list-events"} {
+		for {
+			resp, err := s.client.Post(s.url+path, "application/json", strings.NewReader("{}"))
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode == http.StatusOK {
+					break
+				}
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("gateway not answering %s: %v", path, err)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// stop stops every service gracefully, killing any that takes too long.
+func (s *services) stop() {
+	var wg sync.WaitGroup
+	for _, p := range s.procs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_ = p.cmd.Process.Signal(syscall.SIGTERM)
+			done := make(chan struct{})
+			go func() {
+				_ = p.cmd.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				_ = p.cmd.Process.Kill()
+				<-done
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *services) dumpLogs(w io.Writer) {
+	for _, p := range s.procs {
+		fmt.Fprintf(w, "--- %s logs ---\n%s\n", p.name, p.out.String())
+	}
+}
+
+// do sends a request to the gateway, with the trading API key if
+// authenticated, and returns the status code and body.
+func (s *services) do(t *testing.T, method, path, body string, authenticated bool) (int, []byte) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authenticated {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// freePorts returns n addresses on the loopback interface that nothing is
+// listening on.
+func freePorts(n int) ([]string, error) {
+	var (
+		addrs     []string
+		listeners []net.Listener
+	)
+	defer func() {
+		for _, lis := range listeners {
+			lis.Close()
+		}
+	}()
+
+	for range n {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, lis)
+		addrs = append(addrs, lis.Addr().String())
+	}
+	return addrs, nil
+}
+
+// syncBuffer is a bytes.Buffer safe to write from a process's stdout and
+// stderr while it's read.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
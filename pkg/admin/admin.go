@@ -0,0 +1,63 @@
+// Package admin serves a service's operational endpoints on ports separate
+// from its public API: /metrics over HTTP, and reflection, channelz and the
+// Admin service over gRPC.
+package admin
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server is an admin HTTP server.
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+	addr   net.Addr
+}
+
+// New returns an admin server for addr, serving /metrics.
+func New(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &Server{
+		mux: mux,
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Handle registers handler for pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start listens on the admin address and serves in the background.
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.addr = lis.Addr()
+
+	go func() {
+		if err := s.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			slog.Error("admin server failed", "error", err)
+		}
+	}()
+	slog.Info("admin server listening", "endpoint", s.addr.String())
+
+	return nil
+}
+
+// Addr returns the address the server is listening on, once started.
+func (s *Server) Addr() string {
+	return s.addr.String()
+}
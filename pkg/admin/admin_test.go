@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ServesMetrics(t *testing.T) {
+	s := New("127.0.0.1:0")
+	require.NoError(t, s.Start())
+	t.Cleanup(func() { s.server.Close() })
+
+	resp, err := http.Get("http://" + s.Addr() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/proto/admin"
+)
+
+// GRPCServer is an admin gRPC server. It serves the Admin service, channelz
+// and reflection, so tools such as grpcurl can describe and call them without
+// the proto files. It's unauthenticated: keep its address private, and don't
+// register a service's public servers on it, which would let callers skip
+// the mTLS and roles those are served behind.
+type GRPCServer struct {
+	*grpc.Server
+	endpoint string
+	addr     net.Addr
+}
+
+// NewGRPC returns an admin gRPC server for addr, serving svc, with opts.
+func NewGRPC(addr string, svc *Service, opts ...grpc.ServerOption) *GRPCServer {
+	server := grpc.NewServer(opts...)
+	admin.RegisterAdminServer(server, svc)
+	channelz.RegisterChannelzServiceToServer(server)
+	reflection.Register(server)
+
+	return &GRPCServer{Server: server, endpoint: addr}
+}
+
+// Start listens on the admin address and serves in the background, until
+// Stop.
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.addr = lis.Addr()
+
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			slog.Error("admin gRPC server failed", "error", err)
+		}
+	}()
+	slog.Info("admin gRPC server listening", "endpoint", s.addr.String())
+
+	return nil
+}
+
+// Addr returns the address the server is listening on, once started.
+func (s *GRPCServer) Addr() string {
+	return s.addr.String()
+}
+
+// Service implements the Admin service for a service's process.
+type Service struct {
+	name   string
+	db     *sql.DB
+	reseed func(context.Context) error
+	start  time.Time
+}
+
+// NewService returns the Admin service of the service called name, reporting
+// on db. Reseed calls reseed, or fails if it's nil, as it should be in
+// production.
+func NewService(name string, db *sql.DB, reseed func(context.Context) error) *Service {
+	return &Service{name: name, db: db, reseed: reseed, start: time.Now()}
+}
+
+// GetBuildInfo returns the build of the running binary, as the Go toolchain
+// stamped it.
+func (s *Service) GetBuildInfo(context.Context, *admin.GetBuildInfoRequest) (*admin.GetBuildInfoResponse, error) {
+	resp := &admin.GetBuildInfoResponse{Service: s.name, StartTime: timestamppb.New(s.start)}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return resp, nil
+	}
+	resp.GoVersion = info.GoVersion
+	resp.Path = info.Path
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			resp.Revision = setting.Value
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, setting.Value); err == nil {
+				resp.RevisionTime = timestamppb.New(t)
+			}
+		case "vcs.modified":
+			resp.Modified = setting.Value == "true"
+		}
+	}
+	return resp, nil
+}
+
+// GetDBStats returns the connection pool stats, and counts the rows of every
+// table.
+func (s *Service) GetDBStats(ctx context.Context, _ *admin.GetDBStatsRequest) (*admin.GetDBStatsResponse, error) {
+	stats := s.db.Stats()
+	resp := &admin.GetDBStatsResponse{
+		MaxOpenConnections: int64(stats.MaxOpenConnections),
+		OpenConnections:    int64(stats.OpenConnections),
+		InUse:              int64(stats.InUse),
+		Idle:               int64(stats.Idle),
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       durationpb.New(stats.WaitDuration),
+	}
+
+	var err error
+	if resp.TableRows, err = tableRows(ctx, s.db); err != nil {
+		return nil, status.Errorf(codes.Internal, "counting rows: %s", err)
+	}
+	return resp, nil
+}
+
+// tableRows counts the rows of each table in the SQLite database db.
+func tableRows(ctx context.Context, db *sql.DB) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %q`, table)).Scan(&count); err != nil {
+			return nil, err
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// Reseed replaces the database's data with freshly seeded data, if the
+// service allows it.
+func (s *Service) Reseed(ctx context.Context, _ *admin.ReseedRequest) (*admin.ReseedResponse, error) {
+	if s.reseed == nil {
+		return nil, status.Error(codes.FailedPrecondition, "reseeding is disabled: start the service with -admin-allow-reseed outside production")
+	}
+	if err := s.reseed(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "reseeding: %s", err)
+	}
+	slog.WarnContext(ctx, "database reseeded")
+	return &admin.ReseedResponse{}, nil
+}
+
+// GetLogLevel returns the levels logged.
+func (s *Service) GetLogLevel(context.Context, *admin.GetLogLevelRequest) (*admin.GetLogLevelResponse, error) {
+	return &admin.GetLogLevelResponse{Level: logging.Level()}, nil
+}
+
+// SetLogLevel changes the levels logged, until the service restarts.
+func (s *Service) SetLogLevel(ctx context.Context, in *admin.SetLogLevelRequest) (*admin.SetLogLevelResponse, error) {
+	previous, err := logging.SetLevel(in.Level)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	slog.WarnContext(ctx, "log level changed", "level", in.Level, "previous", previous)
+	return &admin.SetLogLevelResponse{Level: in.Level, Previous: previous}, nil
+}
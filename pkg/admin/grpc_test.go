@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/proto/admin"
+)
+
+// startGRPC starts an admin gRPC server of svc, and returns a connection to it.
+func startGRPC(t *testing.T, svc *Service) *grpc.ClientConn {
+	t.Helper()
+
+	s := NewGRPC("127.0.0.1:0", svc)
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(s.Addr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE races (id INTEGER PRIMARY KEY); INSERT INTO races VALUES (1), (2)`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestService(t *testing.T) {
+	client := admin.NewAdminClient(startGRPC(t, NewService("racing", testDB(t), nil)))
+	ctx := context.Background()
+
+	build, err := client.GetBuildInfo(ctx, &admin.GetBuildInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "racing", build.Service)
+	require.NotEmpty(t, build.GoVersion)
+	require.NotNil(t, build.StartTime)
+
+	stats, err := client.GetDBStats(ctx, &admin.GetDBStatsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"races": 2}, stats.TableRows)
+	require.Positive(t, stats.OpenConnections)
+
+	_, err = client.Reseed(ctx, &admin.ReseedRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err), "reseeding is off unless allowed")
+}
+
+func TestServiceReseed(t *testing.T) {
+	var reseeds int
+	reseed := func(context.Context) error {
+		reseeds++
+		if reseeds > 1 {
+			return errors.New("database is locked")
+		}
+		return nil
+	}
+	client := admin.NewAdminClient(startGRPC(t, NewService("racing", testDB(t), reseed)))
+
+	_, err := client.Reseed(context.Background(), &admin.ReseedRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, reseeds)
+
+	_, err = client.Reseed(context.Background(), &admin.ReseedRequest{})
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestServiceLogLevel(t *testing.T) {
+	require.NoError(t, (&logging.Flags{Level: "info", Format: logging.FormatText}).Setup("test"))
+	client := admin.NewAdminClient(startGRPC(t, NewService("racing", testDB(t), nil)))
+	ctx := context.Background()
+
+	resp, err := client.SetLogLevel(ctx, &admin.SetLogLevelRequest{Level: "debug,tlsutil=warn"})
+	require.NoError(t, err)
+	require.Equal(t, "info", resp.Previous)
+
+	got, err := client.GetLogLevel(ctx, &admin.GetLogLevelRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "debug,tlsutil=warn", got.Level)
+
+	_, err = client.SetLogLevel(ctx, &admin.SetLogLevelRequest{Level: "loud"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCServerReflectionAndChannelz(t *testing.T) {
+	conn := startGRPC(t, NewService("racing", testDB(t), nil))
+	ctx := context.Background()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, svc := range resp.GetListServicesResponse().Service {
+		services = append(services, svc.Name)
+	}
+	require.Subset(t, services, []string{"admin.Admin", "grpc.channelz.v1.Channelz", "grpc.reflection.v1.ServerReflection"})
+
+	servers, err := channelzpb.NewChannelzClient(conn).GetServers(ctx, &channelzpb.GetServersRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, servers.Server)
+}
@@ -0,0 +1,109 @@
+// Package cache is a read-through cache for repository results, kept either
+// in process, in a size-bounded LRU, or in Redis, where every replica of a
+// service shares it.
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/metrics"
+)
+
+// Cache stores values by key for up to a time to live.
+type Cache interface {
+	// Get returns the value stored under key, and whether there was one.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key for ttl, or until evicted when ttl is 0.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Namespace caches the results of one repository's queries, such as the
+// races listed for each filter, and invalidates all of them at once when
+// the repository is written to.
+//
+// Keys are prefixed with the namespace's generation, a value stored in the
+// cache itself. Invalidating stores a new generation, so every replica
+// sharing the cache stops reading the old entries, which then expire.
+type Namespace struct {
+	cache Cache
+	name  string
+	ttl   time.Duration
+}
+
+// NewNamespace returns the namespace name in c, whose entries live for ttl.
+func NewNamespace(c Cache, name string, ttl time.Duration) *Namespace {
+	return &Namespace{cache: c, name: name, ttl: ttl}
+}
+
+// Get returns the value cached under key, or loads, caches and returns it
+// on a miss. When the cache fails, Get logs why and falls back to load, so
+// the cache going down slows the service but doesn't break it.
+func (n *Namespace) Get(ctx context.Context, key string, load func(context.Context) ([]byte, error)) ([]byte, error) {
+	generation, err := n.generation(ctx)
+	if err != nil {
+		n.fail(ctx, "reading the cache generation", err)
+		return load(ctx)
+	}
+	key = n.name + ":" + generation + ":" + key
+
+	value, ok, err := n.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		n.fail(ctx, "reading the cache", err)
+	case ok:
+		metrics.ObserveCache(n.name, metrics.CacheHit)
+		return value, nil
+	default:
+		metrics.ObserveCache(n.name, metrics.CacheMiss)
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.cache.Set(ctx, key, value, n.ttl); err != nil {
+		n.fail(ctx, "writing the cache", err)
+	}
+	return value, nil
+}
+
+// Invalidate drops every entry in the namespace, for every replica sharing
+// the cache.
+func (n *Namespace) Invalidate(ctx context.Context) error {
+	_, err := n.newGeneration(ctx)
+	return err
+}
+
+func (n *Namespace) generation(ctx context.Context) (string, error) {
+	generation, ok, err := n.cache.Get(ctx, n.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		// Never set, or evicted: entries under an older generation may be
+		// stale, so start a new one rather than guess.
+		return n.newGeneration(ctx)
+	}
+	return string(generation), nil
+}
+
+func (n *Namespace) newGeneration(ctx context.Context) (string, error) {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := n.cache.Set(ctx, n.generationKey(), []byte(generation), 0); err != nil {
+		return "", err
+	}
+	return generation, nil
+}
+
+func (n *Namespace) generationKey() string {
+	return n.name + ":generation"
+}
+
+func (n *Namespace) fail(ctx context.Context, doing string, err error) {
+	metrics.ObserveCache(n.name, metrics.CacheError)
+	slog.WarnContext(ctx, "cache failed "+doing+"; querying the repository", "cache", n.name, "error", err)
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Second))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+
+	value, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	// b is now the least recently used, so c evicts it.
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+	_, ok, _ = c.Get(ctx, "b")
+	require.False(t, ok)
+	require.Equal(t, 2, c.Len())
+
+	now = now.Add(time.Second)
+	_, ok, _ = c.Get(ctx, "a")
+	require.False(t, ok, "a has expired")
+	_, ok, _ = c.Get(ctx, "c")
+	require.True(t, ok, "c doesn't expire")
+	require.Equal(t, 1, c.Len())
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	c := NewRedis(server.Addr())
+	t.Cleanup(func() { c.Close() })
+
+	_, ok, err := c.Get(ctx, "races")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "races", []byte("1"), time.Second))
+	value, ok, err := c.Get(ctx, "races")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	server.FastForward(time.Second)
+	_, ok, err = c.Get(ctx, "races")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	server.Close()
+	_, _, err = c.Get(ctx, "races")
+	require.Error(t, err)
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	shared := NewRedis(server.Addr())
+	t.Cleanup(func() { shared.Close() })
+
+	for name, c := range map[string]Cache{"memory": NewLRU(10), "redis": shared} {
+		t.Run(name, func(t *testing.T) {
+			var loads int
+			load := func(context.Context) ([]byte, error) {
+				loads++
+				return []byte{byte(loads)}, nil
+			}
+			ns := NewNamespace(c, "races-"+name, time.Minute)
+
+			value, err := ns.Get(ctx, "meeting=1", load)
+			require.NoError(t, err)
+			require.Equal(t, []byte{1}, value)
+
+			value, err = ns.Get(ctx, "meeting=1", load)
+			require.NoError(t, err)
+			require.Equal(t, []byte{1}, value, "served from the cache")
+
+			_, err = ns.Get(ctx, "meeting=2", load)
+			require.NoError(t, err)
+			require.Equal(t, 2, loads)
+
+			require.NoError(t, ns.Invalidate(ctx))
+			value, err = ns.Get(ctx, "meeting=1", load)
+			require.NoError(t, err)
+			require.Equal(t, []byte{3}, value, "loaded again after invalidating")
+		})
+	}
+}
+
+func TestNamespaceSharedInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	replica1, replica2 := NewNamespace(c, "races", time.Minute), NewNamespace(c, "races", time.Minute)
+
+	_, err := replica1.Get(ctx, "all", func(context.Context) ([]byte, error) { return []byte("old"), nil })
+	require.NoError(t, err)
+
+	require.NoError(t, replica2.Invalidate(ctx))
+	value, err := replica1.Get(ctx, "all", func(context.Context) ([]byte, error) { return []byte("new"), nil })
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), value)
+}
+
+func TestNamespaceFallsBackWhenCacheFails(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	c := NewRedis(server.Addr())
+	t.Cleanup(func() { c.Close() })
+	server.Close()
+
+	ns := NewNamespace(c, "races", time.Minute)
+	value, err := ns.Get(ctx, "all", func(context.Context) ([]byte, error) { return []byte("races"), nil })
+	require.NoError(t, err)
+	require.Equal(t, []byte("races"), value)
+
+	_, err = ns.Get(ctx, "all", func(context.Context) ([]byte, error) { return nil, errors.New("database is locked") })
+	require.EqualError(t, err, "database is locked")
+}
+
+func TestFlagsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   Flags
+		wantErr string
+	}{
+		{name: "memory", flags: Flags{Backend: BackendMemory, TTL: time.Second, Size: 10}},
+		{name: "redis", flags: Flags{Backend: BackendRedis, TTL: time.Second, RedisAddr: "localhost:6379"}},
+		{name: "none ignores the rest", flags: Flags{Backend: BackendNone}},
+		{name: "unknown backend", flags: Flags{Backend: "memcached"}, wantErr: `unknown cache backend "memcached": want none, memory or redis`},
+		{name: "no TTL", flags: Flags{Backend: BackendMemory, Size: 10}, wantErr: "cache TTL must be positive"},
+		{name: "no size", flags: Flags{Backend: BackendMemory, TTL: time.Second}, wantErr: "cache size 0 must be positive"},
+		{name: "bad address", flags: Flags{Backend: BackendRedis, TTL: time.Second, RedisAddr: "redis"}, wantErr: `invalid cache Redis address "redis"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flags.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Backends the -cache flag accepts.
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Flags are the command line options selecting where query results are
+// cached, and for how long.
+type Flags struct {
+	Backend   string
+	TTL       time.Duration
+	Size      int
+	RedisAddr string
+}
+
+// RegisterFlags registers -cache, -cache-ttl, -cache-size and
+// -cache-redis-addr on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.Backend, "cache", BackendMemory, "Where to cache query results: none, memory or redis")
+	fs.DurationVar(&f.TTL, "cache-ttl", 5*time.Second, "How long a cached query result is served before the query runs again")
+	fs.IntVar(&f.Size, "cache-size", 1024, "Query results the memory cache holds before evicting the least recently used")
+	fs.StringVar(&f.RedisAddr, "cache-redis-addr", "localhost:6379", "Address of the Redis server the redis cache uses")
+
+	return &f
+}
+
+// Validate checks that the flags name a known backend, a positive TTL and,
+// for the backend chosen, a positive size or a Redis address.
+func (f *Flags) Validate() error {
+	switch f.Backend {
+	case BackendNone:
+		return nil
+	case BackendMemory:
+		if f.Size <= 0 {
+			return fmt.Errorf("cache size %d must be positive", f.Size)
+		}
+	case BackendRedis:
+		if _, _, err := net.SplitHostPort(f.RedisAddr); err != nil {
+			return fmt.Errorf("invalid cache Redis address %q: %w", f.RedisAddr, err)
+		}
+	default:
+		return fmt.Errorf("unknown cache backend %q: want none, memory or redis", f.Backend)
+	}
+	if f.TTL <= 0 {
+		return errors.New("cache TTL must be positive")
+	}
+	return nil
+}
+
+// New returns the cache the flags select, or nil when caching is off, and
+// a function releasing it.
+func (f *Flags) New() (Cache, func() error, error) {
+	if err := f.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	switch f.Backend {
+	case BackendMemory:
+		return NewLRU(f.Size), func() error { return nil }, nil
+	case BackendRedis:
+		c := NewRedis(f.RedisAddr)
+		return c, c.Close, nil
+	default:
+		return nil, func() error { return nil }, nil
+	}
+}
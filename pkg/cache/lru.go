@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Cache holding up to a fixed number of entries,
+// evicting the least recently used first.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first.
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // Zero when the entry doesn't expire.
+}
+
+// NewLRU returns an LRU holding up to size entries.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored under key, unless it has expired.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores value under key for ttl, evicting the least recently used
+// entry if the LRU is full.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Len returns how many entries the LRU holds, including any expired ones
+// not yet evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
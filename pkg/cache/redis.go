@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Cache kept in a Redis server, or anything that speaks its
+// protocol, shared by every replica pointed at it.
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis returns a cache in the Redis server at addr. It connects on
+// first use.
+func NewRedis(addr string) *Redis {
+	return &Redis{client: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+// Get returns the value stored under key.
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl.
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Close closes the connections to the server.
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,217 @@
+// Package config loads a binary's settings from command line flags,
+// environment variables and a YAML file, validates them, and can print the
+// result.
+//
+// Every setting is a flag. A flag not given on the command line takes its
+// value from the environment variable named after it, such as
+// RACING_GRPC_ENDPOINT for -grpc-endpoint with prefix RACING, then from the
+// config file, then its default.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stdout is where -print-config writes.
+var stdout io.Writer = os.Stdout
+
+// ErrPrinted is returned by Load when -print-config asked only for the
+// configuration to be printed.
+var ErrPrinted = errors.New("configuration printed")
+
+// Where a setting's value came from.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Validator checks settings once they are loaded.
+type Validator interface {
+	Validate() error
+}
+
+// Check adapts a function to a Validator.
+type Check func() error
+
+// Validate calls c.
+func (c Check) Validate() error {
+	return c()
+}
+
+// Load parses args into fs, then fills in each flag not given on the command
+// line from the environment or the file named by -config, and checks the
+// result with validators. With -print-config, it writes the configuration to
+// stdout and returns ErrPrinted, or the validation error if there is one.
+func Load(fs *flag.FlagSet, envPrefix string, args []string, validators ...Validator) error {
+	var (
+		path  = fs.String("config", "", "YAML file of settings, keyed by flag name (env "+EnvName(envPrefix, "config")+")")
+		print = fs.Bool("print-config", false, "Print the configuration and where each setting came from, then exit")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sources := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) { sources[f.Name] = SourceDefault })
+	fs.Visit(func(f *flag.Flag) { sources[f.Name] = SourceFlag })
+
+	if *path == "" {
+		*path = os.Getenv(EnvName(envPrefix, "config"))
+	}
+	file, err := readFile(*path)
+	if err != nil {
+		return err
+	}
+	for name := range file {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown setting %q", *path, name)
+		}
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if sources[f.Name] == SourceFlag {
+			return
+		}
+
+		source, value, ok := SourceEnv, "", false
+		if value, ok = os.LookupEnv(EnvName(envPrefix, f.Name)); !ok {
+			source, value, ok = SourceFile, file[f.Name], hasKey(file, f.Name)
+		}
+		if !ok {
+			return
+		}
+
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s from %s: %w", f.Name, describe(source, envPrefix, f.Name, *path), err))
+			return
+		}
+		sources[f.Name] = source
+	})
+
+	for _, v := range validators {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err = errors.Join(errs...)
+
+	if *print {
+		if perr := Print(stdout, fs, sources, envPrefix); perr != nil {
+			return perr
+		}
+		if err == nil {
+			return ErrPrinted
+		}
+	}
+
+	return err
+}
+
+// Parse loads the configuration of the command line into flag.CommandLine,
+// as Load does. Like flag.Parse, it exits: with status 2 if the
+// configuration is invalid, and status 0 once -print-config has printed it.
+func Parse(envPrefix string, validators ...Validator) {
+	err := Load(flag.CommandLine, envPrefix, os.Args[1:], validators...)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrPrinted):
+		os.Exit(0)
+	default:
+		fmt.Fprintf(flag.CommandLine.Output(), "invalid configuration:\n%s\n", err)
+		os.Exit(2)
+	}
+}
+
+// EnvName returns the environment variable setting flag name, such as
+// RACING_GRPC_ENDPOINT for grpc-endpoint with prefix RACING.
+func EnvName(prefix, name string) string {
+	return strings.ToUpper(prefix + "_" + strings.ReplaceAll(name, "-", "_"))
+}
+
+// readFile reads the settings in the YAML file at path, if path isn't empty.
+// Nested keys are joined with "-", so
+//
+//	tls:
+//	  dev: true
+//
+// sets -tls-dev.
+func readFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+func flatten(settings map[string]string, prefix string, doc map[string]any) error {
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "-" + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(settings, name, value); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("setting %q: lists aren't supported", name)
+		case nil:
+			settings[name] = ""
+		default:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+func hasKey(m map[string]string, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// describe names where a value came from, for messages.
+func describe(source, envPrefix, name, path string) string {
+	switch source {
+	case SourceEnv:
+		return "$" + EnvName(envPrefix, name)
+	case SourceFile:
+		return path
+	default:
+		return source
+	}
+}
+
+// names returns the names of fs's flags in order.
+func names(fs *flag.FlagSet) []string {
+	var out []string
+	fs.VisitAll(func(f *flag.Flag) { out = append(out, f.Name) })
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// settings is a flag set like a service's.
+type settings struct {
+	fs       *flag.FlagSet
+	endpoint *string
+	dsn      *string
+	timeout  *time.Duration
+	tlsDev   *bool
+}
+
+func newSettings() *settings {
+	s := &settings{fs: flag.NewFlagSet("test", flag.ContinueOnError)}
+	s.endpoint = s.fs.String("grpc-endpoint", "localhost:9000", "")
+	s.dsn = s.fs.String("db-dsn", "./db/racing.db", "")
+	s.timeout = s.fs.Duration("shutdown-timeout", 15*time.Second, "")
+	s.tlsDev = s.fs.Bool("tls-dev", false, "")
+	return s
+}
+
+// captureStdout returns what -print-config writes for the rest of the test.
+func captureStdout(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := stdout
+	stdout = &buf
+	t.Cleanup(func() { stdout = previous })
+
+	return &buf
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+grpc-endpoint: localhost:7000
+db-dsn: ./file.db
+shutdown-timeout: 1s
+tls:
+  dev: true
+`)
+	t.Setenv("RACING_DB_DSN", "./env.db")
+	t.Setenv("RACING_SHUTDOWN_TIMEOUT", "2s")
+
+	s := newSettings()
+	require.NoError(t, Load(s.fs, "racing", []string{"-config", path, "-shutdown-timeout", "3s"}))
+
+	require.Equal(t, "localhost:7000", *s.endpoint)
+	require.Equal(t, "./env.db", *s.dsn)
+	require.Equal(t, 3*time.Second, *s.timeout)
+	require.True(t, *s.tlsDev)
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("RACING_CONFIG", writeFile(t, "grpc-endpoint: localhost:7000\n"))
+
+	s := newSettings()
+	require.NoError(t, Load(s.fs, "racing", nil))
+	require.Equal(t, "localhost:7000", *s.endpoint)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown setting",
+			file:    "grpc-endpiont: localhost:7000\n",
+			wantErr: `unknown setting "grpc-endpiont"`,
+		},
+		{
+			name:    "invalid env",
+			env:     map[string]string{"RACING_SHUTDOWN_TIMEOUT": "soon"},
+			wantErr: "invalid shutdown-timeout from $RACING_SHUTDOWN_TIMEOUT",
+		},
+		{
+			name:    "invalid file",
+			file:    "tls-dev: maybe\n",
+			wantErr: "invalid tls-dev from",
+		},
+		{
+			name:    "lists",
+			file:    "grpc-endpoint: [a, b]\n",
+			wantErr: "lists aren't supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var args []string
+			if tt.file != "" {
+				args = []string{"-config", writeFile(t, tt.file)}
+			}
+
+			err := Load(newSettings().fs, "racing", args)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadValidates(t *testing.T) {
+	s := newSettings()
+	err := Load(s.fs, "racing", []string{"-grpc-endpoint", "localhost", "-shutdown-timeout", "0s"},
+		Endpoint("grpc-endpoint", s.endpoint),
+		Positive("shutdown-timeout", s.timeout),
+		Required("db-dsn", s.dsn),
+	)
+
+	require.ErrorContains(t, err, "-grpc-endpoint")
+	require.ErrorContains(t, err, "-shutdown-timeout must be positive")
+	require.NotContains(t, err.Error(), "db-dsn")
+}
+
+func TestLoadPrintsConfig(t *testing.T) {
+	t.Setenv("RACING_DB_DSN", "./env.db")
+	out := captureStdout(t)
+
+	s := newSettings()
+	err := Load(s.fs, "racing", []string{"-print-config", "-tls-dev"})
+	require.True(t, errors.Is(err, ErrPrinted))
+	require.Equal(t, `db-dsn: ./env.db # env $RACING_DB_DSN
+grpc-endpoint: localhost:9000 # default
+shutdown-timeout: 15s # default
+tls-dev: true # flag
+`, out.String())
+
+	// What's printed loads back to the same settings.
+	reloaded := newSettings()
+	require.NoError(t, Load(reloaded.fs, "other", []string{"-config", writeFile(t, out.String())}))
+	require.Equal(t, "./env.db", *reloaded.dsn)
+	require.True(t, *reloaded.tlsDev)
+}
+
+func TestLoadPrintsInvalidConfig(t *testing.T) {
+	out := captureStdout(t)
+
+	s := newSettings()
+	err := Load(s.fs, "racing", []string{"-print-config", "-grpc-endpoint", "nope"}, Endpoint("grpc-endpoint", s.endpoint))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrPrinted))
+	require.Contains(t, out.String(), "grpc-endpoint: nope # flag")
+}
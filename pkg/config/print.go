@@ -0,0 +1,40 @@
+package config
+
+import (
+	"flag"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes fs's settings to w as a config file that Load can read back,
+// noting after each where its value came from: sources maps flag names to
+// SourceFlag, SourceEnv, SourceFile or SourceDefault.
+func Print(w io.Writer, fs *flag.FlagSet, sources map[string]string, envPrefix string) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range names(fs) {
+		if name == "config" || name == "print-config" {
+			continue
+		}
+
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: fs.Lookup(name).Value.String()}
+		if value.Value == "" {
+			value.Style = yaml.DoubleQuotedStyle
+		}
+
+		source := sources[name]
+		if source == SourceEnv {
+			source += " $" + EnvName(envPrefix, name)
+		}
+		value.LineComment = source
+
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Endpoint checks that the flag name holds a host:port address to listen on
+// or dial.
+func Endpoint(name string, addr *string) Validator {
+	return Check(func() error {
+		_, port, err := net.SplitHostPort(*addr)
+		if err != nil {
+			return fmt.Errorf("-%s: %w", name, err)
+		}
+		if _, err := net.LookupPort("tcp", port); err != nil {
+			return fmt.Errorf("-%s: invalid port %q", name, port)
+		}
+		return nil
+	})
+}
+
+// Positive checks that the flag name holds a duration greater than zero.
+func Positive(name string, d *time.Duration) Validator {
+	return Check(func() error {
+		if *d <= 0 {
+			return fmt.Errorf("-%s must be positive, not %s", name, *d)
+		}
+		return nil
+	})
+}
+
+// Required checks that the flag name isn't empty.
+func Required(name string, value *string) Validator {
+	return Check(func() error {
+		if *value == "" {
+			return fmt.Errorf("-%s is required", name)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+// Package health reports whether services are ready for traffic, through the
+// standard grpc.health.v1 service and the gateway's /healthz and /readyz, and
+// drains them gracefully on shutdown.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+)
+
+// DefaultInterval is how often Watch checks that a service is still healthy.
+const DefaultInterval = 5 * time.Second
+
+// KeepaliveMinTime is the shortest interval between keepalive pings a
+// server accepts from its clients.
+const KeepaliveMinTime = 10 * time.Second
+
+// KeepalivePolicy lets clients such as the gateway ping idle connections,
+// at most every KeepaliveMinTime, to find dead ones. The default policy
+// would take those pings as abuse and close the connection.
+func KeepalivePolicy() grpc.ServerOption {
+	return grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: KeepaliveMinTime, PermitWithoutStream: true})
+}
+
+// NewServer registers a grpc.health.v1 service on server, reporting the
+// server and each of services NOT_SERVING until Watch finds them healthy.
+func NewServer(server *grpc.Server, services ...string) *grpchealth.Server {
+	hs := grpchealth.NewServer()
+	for _, service := range append([]string{""}, services...) {
+		hs.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(server, hs)
+	return hs
+}
+
+// Watch runs check now and then every interval until ctx is done, reporting
+// the server and each of services SERVING while it passes and NOT_SERVING
+// while it fails. Run it once the service has finished setting up, such as
+// seeding or migrating its database.
+func Watch(ctx context.Context, hs *grpchealth.Server, check func(context.Context) error, interval time.Duration, services ...string) {
+	services = append([]string{""}, services...)
+	current := healthpb.HealthCheckResponse_UNKNOWN
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		err := check(checkCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if status != current {
+			if err != nil {
+				slog.Error("health check failed", "error", err)
+			} else {
+				slog.Info("health check passed")
+			}
+			for _, service := range services {
+				hs.SetServingStatus(service, status)
+			}
+			current = status
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Serve serves server on lis until ctx is done, then reports every service
+// NOT_SERVING and stops gracefully, letting RPCs in flight finish. RPCs still
+// running after timeout are cancelled.
+func Serve(ctx context.Context, server *grpc.Server, lis net.Listener, hs *grpchealth.Server, timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(lis)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "timeout", timeout.String())
+	hs.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		slog.Warn("RPCs still running at shutdown timeout; cancelling them")
+		server.Stop()
+		<-stopped
+	}
+
+	return <-errs
+}
@@ -0,0 +1,222 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial returns a client connection to a server listening on lis.
+func dial(t *testing.T, lis *bufconn.Listener) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// backend starts a gRPC server with a health service, reporting status.
+func backend(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	hs := NewServer(server)
+	hs.SetServingStatus("", status)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	return dial(t, lis)
+}
+
+func statusOf(t *testing.T, hs *grpchealth.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestWatch(t *testing.T) {
+	hs := NewServer(grpc.NewServer(), "racing.Racing")
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, hs, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, hs, "racing.Racing"))
+
+	var failing atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, hs, func(context.Context) error {
+			if failing.Load() {
+				return errors.New("database is locked")
+			}
+			return nil
+		}, time.Millisecond, "racing.Racing")
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return statusOf(t, hs, "racing.Racing") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, hs, ""))
+
+	failing.Store(true)
+	require.Eventually(t, func() bool {
+		return statusOf(t, hs, "") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestServeStopsGracefully(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	hs := NewServer(server)
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- Serve(ctx, server, lis, hs, time.Second) }()
+
+	client := healthpb.NewHealthClient(dial(t, lis))
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve didn't return")
+	}
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, hs, ""))
+}
+
+func TestServeCancelsRPCsAfterTimeout(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	hs := NewServer(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- Serve(ctx, server, lis, hs, 50*time.Millisecond) }()
+
+	// A Watch stream runs until the server stops it.
+	stream, err := healthpb.NewHealthClient(dial(t, lis)).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve didn't stop the running RPC")
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	serving := backend(t, healthpb.HealthCheckResponse_SERVING)
+	notServing := backend(t, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	tests := []struct {
+		name     string
+		conns    map[string]grpc.ClientConnInterface
+		optional []string
+		drain    bool
+		wantCode int
+		want     report
+	}{
+		{
+			name:     "all serving",
+			conns:    map[string]grpc.ClientConnInterface{"racing": serving, "sports": serving},
+			wantCode: http.StatusOK,
+			want:     report{Status: "ready", Backends: map[string]string{"racing": "SERVING", "sports": "SERVING"}},
+		},
+		{
+			name:     "one not serving",
+			conns:    map[string]grpc.ClientConnInterface{"racing": serving, "sports": notServing},
+			wantCode: http.StatusServiceUnavailable,
+			want:     report{Status: "not ready", Backends: map[string]string{"racing": "SERVING", "sports": "NOT_SERVING"}},
+		},
+		{
+			name:     "optional not serving",
+			conns:    map[string]grpc.ClientConnInterface{"racing": serving, "betting": notServing},
+			optional: []string{"betting"},
+			wantCode: http.StatusOK,
+			want:     report{Status: "ready", Backends: map[string]string{"racing": "SERVING", "betting": "NOT_SERVING"}},
+		},
+		{
+			name:     "draining",
+			conns:    map[string]grpc.ClientConnInterface{"racing": serving},
+			drain:    true,
+			wantCode: http.StatusServiceUnavailable,
+			want:     report{Status: "draining"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backends := NewBackends(tt.conns, time.Second, tt.optional...)
+			if tt.drain {
+				backends.Drain()
+			}
+
+			rec := httptest.NewRecorder()
+			backends.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			var got report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadyHandlerBackendDown(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	conn := dial(t, lis)
+	require.NoError(t, lis.Close())
+
+	statuses, ready := NewBackends(map[string]grpc.ClientConnInterface{"betting": conn}, 100*time.Millisecond).Check(context.Background())
+	require.False(t, ready)
+	require.Equal(t, "Unavailable", statuses["betting"])
+}
+
+func TestLiveHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LiveHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestHandlerRoutesProbes(t *testing.T) {
+	backends := NewBackends(map[string]grpc.ClientConnInterface{"racing": backend(t, healthpb.HealthCheckResponse_SERVING)}, time.Second)
+	handler := backends.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for path, want := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusOK, "/v1/list-races": http.StatusTeapot} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, want, rec.Code, path)
+	}
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Backends reports whether the gRPC services behind the gateway are ready.
+type Backends struct {
+	clients  map[string]healthpb.HealthClient
+	optional map[string]bool
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewBackends checks the backends reached through conns, keyed by name,
+// waiting up to timeout for each to answer. The optional backends are
+// reported but don't affect readiness.
+func NewBackends(conns map[string]grpc.ClientConnInterface, timeout time.Duration, optional ...string) *Backends {
+	clients := make(map[string]healthpb.HealthClient, len(conns))
+	for name, conn := range conns {
+		clients[name] = healthpb.NewHealthClient(conn)
+	}
+	skip := make(map[string]bool, len(optional))
+	for _, name := range optional {
+		skip[name] = true
+	}
+	return &Backends{clients: clients, optional: skip, timeout: timeout}
+}
+
+// Drain makes the gateway report not ready from now on, so load balancers
+// stop sending it traffic while it shuts down.
+func (b *Backends) Drain() {
+	b.draining.Store(true)
+}
+
+// report is the body of /healthz and /readyz.
+type report struct {
+	Status   string            `json:"status"`
+	Backends map[string]string `json:"backends,omitempty"`
+}
+
+// Check asks each backend for its health, and returns the status of each and
+// whether all but the optional ones are serving.
+func (b *Backends) Check(ctx context.Context) (map[string]string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]string, len(b.clients))
+		ready    = true
+	)
+	for name, client := range b.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			serving := err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+
+			result := resp.GetStatus().String()
+			if err != nil {
+				result = status.Code(err).String()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[name] = result
+			ready = ready && (serving || b.optional[name])
+		}()
+	}
+	wg.Wait()
+
+	return statuses, ready
+}
+
+// ReadyHandler serves /readyz: 200 when every backend but the optional ones
+// is serving, and 503 when any isn't or the gateway is draining.
+func (b *Backends) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b.draining.Load() {
+			writeReport(w, http.StatusServiceUnavailable, report{Status: "draining"})
+			return
+		}
+
+		statuses, ready := b.Check(r.Context())
+		if !ready {
+			writeReport(w, http.StatusServiceUnavailable, report{Status: "not ready", Backends: statuses})
+			return
+		}
+		writeReport(w, http.StatusOK, report{Status: "ready", Backends: statuses})
+	})
+}
+
+// Handler serves /healthz and /readyz, and passes every other request to
+// next. Probes are answered before next, so they stay out of its logs,
+// metrics and traces.
+func (b *Backends) Handler(next http.Handler) http.Handler {
+	live, ready := LiveHandler(), b.ReadyHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			live.ServeHTTP(w, r)
+		case "/readyz":
+			ready.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// LiveHandler serves /healthz, which answers 200 for as long as the process
+// can serve HTTP at all.
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, report{Status: "ok"})
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, body report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
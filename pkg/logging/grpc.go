@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor logs each RPC a server handles with its method,
+// status code, duration and, if the request has one, its filter. The request
+// ID is taken from the caller's metadata, or made if the caller sent none.
+// With this package at debug level, whole requests are logged too.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = WithRequestID(ctx, incomingRequestID(ctx))
+		if msg, ok := req.(proto.Message); ok {
+			slog.DebugContext(ctx, "rpc request", "method", info.FullMethod, "request", Proto(msg))
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if filter, ok := requestFilter(req); ok {
+			attrs = append(attrs, slog.Any("filter", Proto(filter)))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+		slog.LogAttrs(ctx, rpcLevel(code), "rpc", attrs...)
+
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor sends the request ID of ctx to the server.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := RequestID(ctx); id != "" {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			md = md.Copy()
+			md.Set(RequestIDMetadata, id)
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// incomingRequestID returns the request ID the caller sent, or a new one if
+// it sent none or one that isn't valid.
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(RequestIDMetadata); len(ids) > 0 && validRequestID(ids[0]) {
+		return ids[0]
+	}
+	return NewRequestID()
+}
+
+// rpcLevel is the level to log an RPC that ended with code at: errors for
+// failures of the server or what it depends on, info for everything else.
+func rpcLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
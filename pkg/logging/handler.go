@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// handler drops records below the level of the package that logged them,
+// and adds the request and trace IDs of the record's context.
+type handler struct {
+	next   slog.Handler
+	levels *levelVar
+}
+
+// levelVar holds the levels a handler logs at, which can change while it's
+// in use.
+type levelVar struct {
+	levels atomic.Pointer[Levels]
+	// min is the lowest level any package logs at, below which the next
+	// handler drops records before they're built.
+	min slog.LevelVar
+}
+
+func newLevelVar(levels *Levels) *levelVar {
+	v := &levelVar{}
+	v.set(levels)
+	return v
+}
+
+func (v *levelVar) set(levels *Levels) {
+	v.levels.Store(levels)
+	v.min.Set(levels.min())
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.levels.Load().For(packageOf(r.PC)) {
+		return nil
+	}
+
+	requestID := RequestID(ctx)
+	span := trace.SpanContextFromContext(ctx)
+	if requestID != "" || span.IsValid() {
+		r = r.Clone()
+		if requestID != "" {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+		if span.IsValid() {
+			r.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+		}
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), levels: h.levels}
+}
+
+// packages caches the package of each program counter that has logged.
+var packages sync.Map
+
+// packageOf returns the import path of the package containing pc.
+func packageOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if pkg, ok := packages.Load(pc); ok {
+		return pkg.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	// Function is the package path, then a dot, then the function, as in
+	// "git.neds.sh/matty/entain/racing/db.(*racesRepo).List".
+	pkg := frame.Function
+	slash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[slash+1:], "."); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+
+	packages.Store(pc, pkg)
+	return pkg
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Levels holds the minimum level to log for each package, and for any
+// package not named.
+type Levels struct {
+	spec     string
+	def      slog.Level
+	packages []packageLevel
+}
+
+type packageLevel struct {
+	pkg   string
+	level slog.Level
+}
+
+// ParseLevels parses a comma separated list of levels, such as
+// "info,racing/db=debug,tlsutil=warn". A bare level is the default; pkg=level
+// sets the level of packages whose import path is pkg or ends in /pkg.
+func ParseLevels(spec string) (*Levels, error) {
+	levels := &Levels{spec: spec, def: slog.LevelInfo}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pkg, name, named := strings.Cut(part, "=")
+		if !named {
+			name = pkg
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", part, err)
+		}
+
+		if !named {
+			levels.def = level
+			continue
+		}
+		pkg = strings.Trim(pkg, "/")
+		if pkg == "" {
+			return nil, fmt.Errorf("invalid log level %q: missing package", part)
+		}
+		levels.packages = append(levels.packages, packageLevel{pkg: pkg, level: level})
+	}
+
+	// The most specific package wins.
+	sort.SliceStable(levels.packages, func(i, j int) bool {
+		return len(levels.packages[i].pkg) > len(levels.packages[j].pkg)
+	})
+
+	return levels, nil
+}
+
+// For returns the minimum level to log for the package with import path pkg.
+func (l *Levels) For(pkg string) slog.Level {
+	for _, p := range l.packages {
+		if pkg == p.pkg || strings.HasSuffix(pkg, "/"+p.pkg) {
+			return p.level
+		}
+	}
+	return l.def
+}
+
+// min returns the lowest level any package logs at.
+func (l *Levels) min() slog.Level {
+	level := l.def
+	for _, p := range l.packages {
+		level = min(level, p.level)
+	}
+	return level
+}
+
+// String returns the levels as they were parsed.
+func (l *Levels) String() string {
+	return l.spec
+}
@@ -0,0 +1,320 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// captureLogs makes a logger writing JSON at the levels in spec the default
+// for the rest of the test, and returns what it writes.
+func captureLogs(t *testing.T, spec string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	handler, err := (&Flags{Level: spec, Format: FormatJSON}).handler(&buf)
+	require.NoError(t, err)
+
+	previous, previousLevels := slog.Default(), current.Load()
+	slog.SetDefault(slog.New(handler))
+	current.Store(handler.levels)
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		current.Store(previousLevels)
+	})
+
+	return &buf
+}
+
+// records decodes each line of JSON logs.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		out = append(out, record)
+	}
+	return out
+}
+
+// testMessages builds a request message with a filter and a field marked
+// debug_redact, like those in the service protos.
+func testMessages(t *testing.T) (request, filter protoreflect.MessageDescriptor) {
+	t.Helper()
+
+	redact := &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("logging_test.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Filter"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("meeting_ids"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+					{Name: proto.String("show_hidden"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+			{
+				Name: proto.String("Request"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("filter"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".test.Filter"), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("account_id"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("reason"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Options: redact},
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	return file.Messages().ByName("Request"), file.Messages().ByName("Filter")
+}
+
+func testRequest(t *testing.T) proto.Message {
+	t.Helper()
+
+	requestDesc, filterDesc := testMessages(t)
+	filter := dynamicpb.NewMessage(filterDesc)
+	ids := filter.NewField(filterDesc.Fields().ByName("meeting_ids")).List()
+	ids.Append(protoreflect.ValueOfInt64(5))
+	ids.Append(protoreflect.ValueOfInt64(8))
+	filter.Set(filterDesc.Fields().ByName("meeting_ids"), protoreflect.ValueOfList(ids))
+	filter.Set(filterDesc.Fields().ByName("show_hidden"), protoreflect.ValueOfBool(true))
+
+	request := dynamicpb.NewMessage(requestDesc)
+	request.Set(requestDesc.Fields().ByName("filter"), protoreflect.ValueOfMessage(filter))
+	request.Set(requestDesc.Fields().ByName("account_id"), protoreflect.ValueOfInt64(42))
+	request.Set(requestDesc.Fields().ByName("reason"), protoreflect.ValueOfString("gambling too much"))
+	return request
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, racing/db=debug ,db=error,tlsutil=info")
+	require.NoError(t, err)
+
+	tests := []struct {
+		pkg  string
+		want slog.Level
+	}{
+		{"git.neds.sh/matty/entain/racing/db", slog.LevelDebug},
+		{"git.neds.sh/matty/entain/sports/db", slog.LevelError},
+		{"git.neds.sh/matty/entain/pkg/tlsutil", slog.LevelInfo},
+		{"git.neds.sh/matty/entain/pkg/metrics", slog.LevelWarn},
+		{"main", slog.LevelWarn},
+		// A package name must match whole path segments.
+		{"git.neds.sh/matty/entain/pkg/notdb", slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			require.Equal(t, tt.want, levels.For(tt.pkg))
+		})
+	}
+	require.Equal(t, slog.LevelDebug, levels.min())
+
+	for _, spec := range []string{"loud", "racing=loud", "=debug"} {
+		_, err := ParseLevels(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestHandlerLevelsPerPackage(t *testing.T) {
+	buf := captureLogs(t, "warn,pkg/logging=debug")
+	slog.Debug("from logging")
+	require.Len(t, records(t, buf), 1)
+
+	buf = captureLogs(t, "debug,logging=warn")
+	slog.Info("from logging")
+	require.Empty(t, records(t, buf))
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := captureLogs(t, "info")
+	slog.Debug("hidden")
+	require.Empty(t, records(t, buf))
+
+	previous, err := SetLevel("warn,logging=debug")
+	require.NoError(t, err)
+	require.Equal(t, "info", previous)
+	require.Equal(t, "warn,logging=debug", Level())
+	slog.Debug("shown")
+	require.Len(t, records(t, buf), 1)
+
+	_, err = SetLevel("loud")
+	require.Error(t, err)
+	require.Equal(t, "warn,logging=debug", Level(), "a bad level changes nothing")
+}
+
+func TestHandlerAddsRequestAndTraceIDs(t *testing.T) {
+	buf := captureLogs(t, "info")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+	slog.InfoContext(ctx, "hello", "authorization", "Bearer abc", "token", "t")
+
+	got := records(t, buf)
+	require.Len(t, got, 1)
+	require.Equal(t, "req-1", got[0]["request_id"])
+	require.Equal(t, "01000000000000000000000000000000", got[0]["trace_id"])
+	require.Equal(t, Redacted, got[0]["authorization"])
+	require.Equal(t, Redacted, got[0]["token"])
+}
+
+func TestProtoRedactsFields(t *testing.T) {
+	buf := captureLogs(t, "info")
+	slog.Info("request", "request", Proto(testRequest(t)))
+
+	got := records(t, buf)
+	require.Len(t, got, 1)
+	require.Equal(t, map[string]any{
+		"filter":     map[string]any{"meeting_ids": []any{5.0, 8.0}, "show_hidden": true},
+		"account_id": 42.0,
+		"reason":     Redacted,
+	}, got[0]["request"])
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/racing.Racing/ListRaces"}
+	request := testRequest(t)
+
+	tests := []struct {
+		name      string
+		requestID string
+		err       error
+		wantLevel string
+		wantKept  bool
+	}{
+		{name: "keeps the caller's request ID", requestID: "abc-123", wantLevel: "INFO", wantKept: true},
+		{name: "replaces an invalid request ID", requestID: "bad id\n", wantLevel: "INFO"},
+		{name: "makes a request ID", wantLevel: "INFO"},
+		{name: "logs server errors as errors", err: status.Error(codes.Internal, "db is down"), wantLevel: "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t, "info")
+
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RequestIDMetadata, tt.requestID))
+			}
+
+			var handled string
+			_, err := interceptor(ctx, request, info, func(ctx context.Context, _ any) (any, error) {
+				handled = RequestID(ctx)
+				return nil, tt.err
+			})
+			require.Equal(t, tt.err, err)
+
+			got := records(t, buf)
+			require.Len(t, got, 1)
+			require.Equal(t, tt.wantLevel, got[0]["level"])
+			require.Equal(t, "/racing.Racing/ListRaces", got[0]["method"])
+			require.Equal(t, status.Code(tt.err).String(), got[0]["code"])
+			require.Equal(t, map[string]any{"meeting_ids": []any{5.0, 8.0}, "show_hidden": true}, got[0]["filter"])
+			require.Equal(t, handled, got[0]["request_id"])
+			require.True(t, validRequestID(handled))
+			if tt.wantKept {
+				require.Equal(t, tt.requestID, handled)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorLogsRequestsAtDebug(t *testing.T) {
+	buf := captureLogs(t, "info,logging=debug")
+
+	_, err := UnaryServerInterceptor()(context.Background(), testRequest(t), &grpc.UnaryServerInfo{FullMethod: "/accounts.Accounts/SelfExclude"}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	got := records(t, buf)
+	require.Len(t, got, 2)
+	require.Equal(t, "rpc request", got[0]["msg"])
+	require.Equal(t, Redacted, got[0]["request"].(map[string]any)["reason"])
+	require.NotContains(t, buf.String(), "gambling too much")
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-auth-subject", "alice")
+	ctx = WithRequestID(ctx, "req-7")
+
+	err := interceptor(ctx, "/racing.Racing/GetRace", nil, nil, nil, func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		require.Equal(t, []string{"req-7"}, md.Get(RequestIDMetadata))
+		require.Equal(t, []string{"alice"}, md.Get("x-auth-subject"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		wantKept  bool
+	}{
+		{name: "keeps the caller's request ID", requestID: "abc-123", wantKept: true},
+		{name: "replaces an invalid request ID", requestID: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "makes a request ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t, "info")
+
+			var handled string
+			handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handled = RequestID(r.Context())
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/v1/races/7", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.True(t, validRequestID(handled))
+			require.Equal(t, handled, rec.Header().Get(RequestIDHeader))
+			if tt.wantKept {
+				require.Equal(t, tt.requestID, handled)
+			}
+
+			got := records(t, buf)
+			require.Len(t, got, 1)
+			require.Equal(t, "/v1/races/7", got[0]["path"])
+			require.Equal(t, 404.0, got[0]["status"])
+			require.Equal(t, handled, got[0]["request_id"])
+		})
+	}
+}
+
+func TestSetupRejectsUnknownFormat(t *testing.T) {
+	require.ErrorContains(t, (&Flags{Level: "info", Format: "xml"}).Setup("racing"), `unknown log format "xml"`)
+}
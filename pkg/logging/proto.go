@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"log/slog"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Proto returns a value that logs the fields set in msg. Fields marked
+// [debug_redact = true] in the proto are logged as Redacted.
+func Proto(msg proto.Message) slog.LogValuer {
+	return protoValuer{msg: msg}
+}
+
+type protoValuer struct {
+	msg proto.Message
+}
+
+func (v protoValuer) LogValue() slog.Value {
+	if v.msg == nil {
+		return slog.Value{}
+	}
+
+	var attrs []slog.Attr
+	v.msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		attrs = append(attrs, slog.Any(string(fd.Name()), fieldValue(fd, value)))
+		return true
+	})
+	return slog.GroupValue(attrs...)
+}
+
+// fieldValue converts the value of the field fd to plain Go values that any
+// slog handler can write.
+func fieldValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	if redacted(fd) {
+		return Redacted
+	}
+
+	switch {
+	case fd.IsList():
+		list := value.List()
+		values := make([]any, list.Len())
+		for i := range values {
+			values[i] = singularValue(fd, list.Get(i))
+		}
+		return values
+
+	case fd.IsMap():
+		values := make(map[string]any)
+		value.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			values[k.String()] = singularValue(fd.MapValue(), v)
+			return true
+		})
+		return values
+
+	default:
+		return singularValue(fd, value)
+	}
+}
+
+func singularValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		values := make(map[string]any)
+		value.Message().Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			values[string(fd.Name())] = fieldValue(fd, value)
+			return true
+		})
+		return values
+
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(value.Enum())
+
+	case protoreflect.BytesKind:
+		// Log the size of binary data, not the data.
+		return len(value.Bytes())
+
+	default:
+		return value.Interface()
+	}
+}
+
+// redacted reports whether fd is marked [debug_redact = true].
+func redacted(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && opts.GetDebugRedact()
+}
+
+// requestFilter returns the filter field of req, if it has one set.
+func requestFilter(req any) (proto.Message, bool) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+
+	m := msg.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("filter")
+	if fd == nil || fd.Kind() != protoreflect.MessageKind || fd.IsList() || !m.Has(fd) {
+		return nil, false
+	}
+	return m.Get(fd).Message().Interface(), true
+}
@@ -0,0 +1,11 @@
+package metrics
+
+// SetBreakerOpen records whether the circuit breaker in front of backend is
+// open, refusing calls.
+func SetBreakerOpen(backend string, open bool) {
+	v := 0.0
+	if open {
+		v = 1
+	}
+	breakerOpen.WithLabelValues(backend).Set(v)
+}
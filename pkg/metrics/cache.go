@@ -0,0 +1,13 @@
+package metrics
+
+// Results of a cache lookup.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// ObserveCache records a lookup in the cache named name, and its result.
+func ObserveCache(name, result string) {
+	cacheRequests.WithLabelValues(name, result).Inc()
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// ObserveQuery records a repository query that started at start and returned
+// rows rows, or failed with err.
+func ObserveQuery(repo, query string, start time.Time, rows int, err error) {
+	dbQueryDuration.WithLabelValues(repo, query).Observe(time.Since(start).Seconds())
+	if err != nil {
+		dbQueryErrors.WithLabelValues(repo, query).Inc()
+		return
+	}
+	dbQueryRows.WithLabelValues(repo, query).Observe(float64(rows))
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with name.
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
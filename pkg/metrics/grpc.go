@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records the count, latency and status code of each RPC a server handles.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		service, method := splitMethod(info.FullMethod)
+		grpcServerHandling.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+		grpcServerHandled.WithLabelValues(service, method, status.Code(err).String()).Inc()
+
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor records the count, latency and status code of each RPC a client makes.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, fullMethod string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, fullMethod, req, reply, cc, opts...)
+
+		service, method := splitMethod(fullMethod)
+		grpcClientHandling.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+		grpcClientHandled.WithLabelValues(service, method, status.Code(err).String()).Inc()
+
+		return err
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into its service and method.
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", "unknown"
+	}
+
+	return service, method
+}
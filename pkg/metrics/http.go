@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that no route claimed, keeping arbitrary
+// paths out of the label values.
+const unmatchedRoute = "unmatched"
+
+type routeKey struct{}
+
+// Handler records the count, latency and status code of each request served
+// by next, labelled by the route set with SetRoute.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := unmatchedRoute
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.code)).Inc()
+	})
+}
+
+// SetRoute names the route serving a request, such as "/v1/races/{id}". It
+// must be called beneath Handler, once a router has matched the request.
+func SetRoute(ctx context.Context, route string) {
+	if r, ok := ctx.Value(routeKey{}).(*string); ok {
+		*r = route
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,67 @@
+// Package metrics records Prometheus metrics for gRPC servers and clients,
+// HTTP handlers, repository queries, caches and circuit breakers. Everything
+// registers with the default Prometheus registry, which the admin server
+// exposes on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	grpcServerHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "RPCs completed on the server, by method and status code.",
+	}, []string{"grpc_service", "grpc_method", "grpc_code"})
+	grpcServerHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time taken to handle RPCs on the server.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"grpc_service", "grpc_method"})
+
+	grpcClientHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_handled_total",
+		Help: "RPCs completed by the client, by method and status code.",
+	}, []string{"grpc_service", "grpc_method", "grpc_code"})
+	grpcClientHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Time taken for RPCs to complete, as seen by the client.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"grpc_service", "grpc_method"})
+
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by route and status code.",
+	}, []string{"method", "route", "code"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by repository queries.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"repo", "query"})
+	dbQueryRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_rows",
+		Help:    "Rows returned by repository queries.",
+		Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"repo", "query"})
+	dbQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Repository queries that failed.",
+	}, []string{"repo", "query"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "Cache lookups, by cache and whether they hit, missed or failed.",
+	}, []string{"cache", "result"})
+
+	breakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_open",
+		Help: "Whether the circuit breaker in front of a backend is open, refusing calls.",
+	}, []string{"backend"})
+)
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/racing.Racing/GetRace"}
+
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "race not found")
+	})
+	require.Error(t, err)
+	_, err = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(grpcServerHandled.WithLabelValues("racing.Racing", "GetRace", "NotFound")))
+	require.Equal(t, 1.0, testutil.ToFloat64(grpcServerHandled.WithLabelValues("racing.Racing", "GetRace", "OK")))
+	require.Equal(t, 1, testutil.CollectAndCount(grpcServerHandling, "grpc_server_handling_seconds"))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+
+	err := interceptor(context.Background(), "/sports.Sports/ListEvents", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	})
+	require.Error(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(grpcClientHandled.WithLabelValues("sports.Sports", "ListEvents", "Unavailable")))
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/races/7" {
+			SetRoute(r.Context(), "/v1/races/{id}")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/races/7", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/path", nil))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/v1/races/{id}", "404")))
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "200")))
+}
+
+func TestObserveQuery(t *testing.T) {
+	ObserveQuery("races", "list", time.Now(), 12, nil)
+	ObserveQuery("races", "list", time.Now(), 0, errors.New("boom"))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(dbQueryErrors.WithLabelValues("races", "list")))
+	require.Equal(t, 1, testutil.CollectAndCount(dbQueryRows, "db_query_rows"))
+}
@@ -0,0 +1,195 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	devCAValidity   = 365 * 24 * time.Hour
+	devCertValidity = 30 * 24 * time.Hour
+)
+
+// DefaultDevDir is where dev mode keeps its CA and certificates, shared by
+// every service on the machine whatever directory it runs from.
+func DefaultDevDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "entain", "dev-tls")
+}
+
+// DevFiles issues a fresh certificate for name from the development CA in
+// dir, creating the CA on first use. The certificate is valid for localhost
+// and name, as both a server and a client, so services can use it for mutual
+// TLS with each other. Never use dev mode outside a developer machine.
+func DevFiles(dir, name string) (Files, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Files{}, err
+	}
+
+	ca, caKey, err := devCA(dir)
+	if err != nil {
+		return Files{}, fmt.Errorf("loading dev CA: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Files{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost", name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(devCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return Files{}, err
+	}
+
+	files := Files{
+		Cert: filepath.Join(dir, name+".pem"),
+		Key:  filepath.Join(dir, name+"-key.pem"),
+		CA:   filepath.Join(dir, "ca", "ca.pem"),
+	}
+	if err := writeKey(files.Key, key); err != nil {
+		return Files{}, err
+	}
+	if err := writePEM(files.Cert, "CERTIFICATE", der, 0o644); err != nil {
+		return Files{}, err
+	}
+
+	return files, nil
+}
+
+// devCA loads the CA from dir, creating it if there isn't one. Services
+// started together may race to create it; the CA is built in a scratch
+// directory and renamed into place, so exactly one wins and the rest load it.
+func devCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	caDir := filepath.Join(dir, "ca")
+
+	if _, err := os.Stat(caDir); errors.Is(err, os.ErrNotExist) {
+		if err := createDevCA(dir, caDir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pair, err := tls.LoadX509KeyPair(filepath.Join(caDir, "ca.pem"), filepath.Join(caDir, "ca-key.pem"))
+	if err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("dev CA key is not ECDSA")
+	}
+
+	return ca, key, nil
+}
+
+func createDevCA(dir, caDir string) error {
+	scratch, err := os.MkdirTemp(dir, ".ca-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "Entain development CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(devCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	if err := writeKey(filepath.Join(scratch, "ca-key.pem"), key); err != nil {
+		return err
+	}
+	if err := writePEM(filepath.Join(scratch, "ca.pem"), "CERTIFICATE", der, 0o644); err != nil {
+		return err
+	}
+
+	// Losing the race leaves the winner's CA in place, which is what we want.
+	if err := os.Rename(scratch, caDir); err != nil {
+		if _, statErr := os.Stat(filepath.Join(caDir, "ca.pem")); statErr == nil {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func writeKey(path string, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	return writePEM(path, "PRIVATE KEY", der, 0o600)
+}
+
+// writePEM replaces path atomically, so a reloader never reads half a file.
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func serialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+
+	return serial
+}
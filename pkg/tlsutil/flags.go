@@ -0,0 +1,70 @@
+package tlsutil
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Flags are the command line options selecting a TLS identity.
+type Flags struct {
+	Cert   string
+	Key    string
+	CA     string
+	Dev    bool
+	DevDir string
+
+	// ReloadInterval is how often the files are checked for changes; zero
+	// checks on every handshake.
+	ReloadInterval time.Duration
+
+	prefix string
+}
+
+// RegisterFlags registers prefix-tls-cert, -tls-key, -tls-ca, -tls-dev,
+// -tls-dev-dir and -tls-reload-interval on fs. Prefix may be empty.
+func RegisterFlags(fs *flag.FlagSet, prefix string) *Flags {
+	f := Flags{prefix: prefix}
+
+	fs.StringVar(&f.Cert, prefix+"tls-cert", "", "TLS certificate chain file (PEM)")
+	fs.StringVar(&f.Key, prefix+"tls-key", "", "TLS private key file (PEM)")
+	fs.StringVar(&f.CA, prefix+"tls-ca", "", "CA bundle that peer certificates must chain to (PEM)")
+	fs.BoolVar(&f.Dev, prefix+"tls-dev", false, "Issue a certificate from a local development CA instead of using files")
+	fs.StringVar(&f.DevDir, prefix+"tls-dev-dir", DefaultDevDir(), "Directory holding the development CA")
+	fs.DurationVar(&f.ReloadInterval, prefix+"tls-reload-interval", DefaultReloadInterval, "How often to check the certificate files for changes")
+
+	return &f
+}
+
+// Reloader loads the identity the flags select. In dev mode, name is the
+// service the certificate is issued to.
+func (f *Flags) Reloader(name string) (*Reloader, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	files := Files{Cert: f.Cert, Key: f.Key, CA: f.CA}
+	if f.Dev {
+		var err error
+		if files, err = DevFiles(f.DevDir, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewReloader(files, f.ReloadInterval)
+}
+
+// Validate checks that the flags select exactly one identity: certificate
+// files, or dev TLS.
+func (f *Flags) Validate() error {
+	switch {
+	case f.Dev && (f.Cert != "" || f.Key != "" || f.CA != ""):
+		return fmt.Errorf("-%stls-dev issues its own certificates; don't also give certificate files", f.prefix)
+	case !f.Dev && (f.Cert == "" || f.Key == ""):
+		return fmt.Errorf("TLS is required: give -%stls-cert and -%stls-key, or use -%stls-dev", f.prefix, f.prefix, f.prefix)
+	case f.ReloadInterval < 0:
+		return errors.New("-" + f.prefix + "tls-reload-interval can't be negative")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package tlsutil loads the TLS identities services use for the public HTTP
+// listener and for mutual TLS between the gateway and backend services.
+//
+// Certificates, keys and CA bundles are read from PEM files and re-read when
+// the files change, so rotating a certificate doesn't need a restart. In dev
+// mode the files are issued by a local CA created on first use.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often the files are checked for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// Files names the PEM files a TLS identity is loaded from.
+type Files struct {
+	// Cert and Key are the certificate chain and private key presented to peers.
+	Cert string
+	Key  string
+	// CA is the bundle peer certificates must chain to. It may be empty when
+	// peers aren't verified, as for the public HTTP listener.
+	CA string
+}
+
+// Reloader holds the current TLS identity from a set of files, reloading it
+// when any of the files change. A reload that fails keeps the previous
+// identity in use.
+type Reloader struct {
+	files    Files
+	interval time.Duration
+
+	mu      sync.Mutex
+	checked time.Time
+	stamps  []stamp
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// stamp identifies a version of a file.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads files, checking them for changes at most once per interval.
+func NewReloader(files Files, interval time.Duration) (*Reloader, error) {
+	if files.Cert == "" || files.Key == "" {
+		return nil, errors.New("a certificate and key are required")
+	}
+
+	r := &Reloader{files: files, interval: interval}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = time.Now()
+
+	return r, nil
+}
+
+// current returns the identity in force, reloading it first if it's due a check.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) >= r.interval {
+		r.checked = time.Now()
+		if stamps, err := r.stat(); err == nil && !equalStamps(stamps, r.stamps) {
+			if err := r.load(); err != nil {
+				slog.Warn("keeping previous TLS certificate, reload failed", "cert", r.files.Cert, "error", err)
+			} else {
+				slog.Info("reloaded TLS certificate", "cert", r.files.Cert)
+			}
+		}
+	}
+
+	return r.cert, r.pool
+}
+
+// load reads the files. Callers must hold mu, except during construction.
+func (r *Reloader) load() error {
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.files.Cert, r.files.Key)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+	if r.files.CA != "" {
+		data, err := os.ReadFile(r.files.CA)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s: no certificates found", r.files.CA)
+		}
+	}
+
+	r.cert, r.pool, r.stamps = &cert, pool, stamps
+
+	return nil
+}
+
+func (r *Reloader) stat() ([]stamp, error) {
+	var stamps []stamp
+	for _, path := range []string{r.files.Cert, r.files.Key, r.files.CA} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, stamp{modTime: info.ModTime(), size: info.Size()})
+	}
+
+	return stamps, nil
+}
+
+func equalStamps(a, b []stamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ServerConfig returns a server configuration that presents the current
+// certificate on every handshake. clientAuth other than tls.NoClientCert
+// verifies client certificates against the CA bundle. It offers HTTP/2 and
+// HTTP/1.1 over ALPN.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		// GetConfigForClient takes precedence; GetCertificate tells
+		// http.Server.ServeTLS it needn't load certificate files itself.
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+	// The per-handshake configuration replaces base entirely, so it carries
+	// base's protocols over; without them ALPN falls back to HTTP/1.1.
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		config := r.serverConfig(clientAuth)
+		config.NextProtos = base.NextProtos
+
+		return config, nil
+	}
+
+	return base
+}
+
+func (r *Reloader) serverConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	cert, pool := r.current()
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		ClientCAs:    pool,
+		ClientAuth:   clientAuth,
+	}
+}
+
+// clientConfig returns a client configuration from the current identity,
+// presenting the certificate to servers that ask for one.
+func (r *Reloader) clientConfig() *tls.Config {
+	cert, pool := r.current()
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		RootCAs:      pool,
+	}
+}
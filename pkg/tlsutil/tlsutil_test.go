@@ -0,0 +1,217 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func serial(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	cert, _ := r.current()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.SerialNumber.String()
+}
+
+func TestDevFiles_SharesCA(t *testing.T) {
+	dir := t.TempDir()
+
+	// Services starting together race to create the CA; they must all end up on the same one.
+	var wg sync.WaitGroup
+	files := make([]Files, 8)
+	errs := make([]error, 8)
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			files[i], errs[i] = DevFiles(dir, "svc")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	ca, err := os.ReadFile(files[0].CA)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(ca))
+
+	racing, err := DevFiles(dir, "racing")
+	require.NoError(t, err)
+	pair, err := tls.LoadX509KeyPair(racing.Cert, racing.Key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	require.NoError(t, err)
+
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: "racing", KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: "localhost"})
+	require.NoError(t, err)
+
+	info, err := os.Stat(racing.Key)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestReloader_ReloadsRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	files, err := DevFiles(dir, "svc")
+	require.NoError(t, err)
+
+	r, err := NewReloader(files, 0)
+	require.NoError(t, err)
+	before := serial(t, r)
+
+	_, err = DevFiles(dir, "svc")
+	require.NoError(t, err)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(files.Cert, later, later))
+
+	require.NotEqual(t, before, serial(t, r))
+}
+
+func TestReloader_KeepsCertificateWhenReloadFails(t *testing.T) {
+	dir := t.TempDir()
+	files, err := DevFiles(dir, "svc")
+	require.NoError(t, err)
+
+	r, err := NewReloader(files, 0)
+	require.NoError(t, err)
+	before := serial(t, r)
+
+	require.NoError(t, os.WriteFile(files.Cert, []byte("not a certificate"), 0o644))
+
+	require.Equal(t, before, serial(t, r))
+}
+
+func TestCredentials_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	serverFiles, err := DevFiles(dir, "server")
+	require.NoError(t, err)
+	serverReloader, err := NewReloader(serverFiles, time.Hour)
+	require.NoError(t, err)
+	serverCreds, err := ServerCredentials(serverReloader)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer(grpc.Creds(serverCreds))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	check := func(creds credentials.TransportCredentials) error {
+		conn, err := grpc.NewClient("passthrough:///localhost",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(creds),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	t.Run("client with a certificate from the CA", func(t *testing.T) {
+		clientFiles, err := DevFiles(dir, "client")
+		require.NoError(t, err)
+		clientReloader, err := NewReloader(clientFiles, time.Hour)
+		require.NoError(t, err)
+		creds, err := ClientCredentials(clientReloader)
+		require.NoError(t, err)
+
+		require.NoError(t, check(creds))
+	})
+
+	t.Run("client without a certificate", func(t *testing.T) {
+		ca, err := os.ReadFile(serverFiles.CA)
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca)
+
+		require.Error(t, check(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+	})
+
+	t.Run("client from another CA", func(t *testing.T) {
+		otherFiles, err := DevFiles(filepath.Join(t.TempDir(), "other"), "client")
+		require.NoError(t, err)
+		otherReloader, err := NewReloader(otherFiles, time.Hour)
+		require.NoError(t, err)
+		creds, err := ClientCredentials(otherReloader)
+		require.NoError(t, err)
+
+		require.Error(t, check(creds))
+	})
+}
+
+func TestFlags_Reloader(t *testing.T) {
+	f := &Flags{}
+	_, err := f.Reloader("svc")
+	require.Error(t, err)
+
+	f = &Flags{Dev: true, DevDir: t.TempDir()}
+	r, err := f.Reloader("svc")
+	require.NoError(t, err)
+	_, err = ServerCredentials(r)
+	require.NoError(t, err)
+
+	f = &Flags{Dev: true, DevDir: t.TempDir(), Cert: "cert.pem"}
+	_, err = f.Reloader("svc")
+	require.Error(t, err)
+}
+
+func TestReloader_ServerConfigNegotiatesHTTP2(t *testing.T) {
+	files, err := DevFiles(t.TempDir(), "server")
+	require.NoError(t, err)
+	r, err := NewReloader(files, time.Hour)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}),
+		TLSConfig: r.ServerConfig(tls.NoClientCert),
+	}
+	go srv.ServeTLS(lis, "", "")
+	t.Cleanup(func() { srv.Close() })
+
+	ca, err := os.ReadFile(files.CA)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+
+	for _, protos := range [][]string{{"h2", "http/1.1"}, {"http/1.1"}} {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost", NextProtos: protos})
+		require.NoError(t, err)
+		require.Equal(t, protos[0], conn.ConnectionState().NegotiatedProtocol)
+		conn.Close()
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}, ForceAttemptHTTP2: true}}
+	resp, err := client.Get("https://localhost:" + strconv.Itoa(lis.Addr().(*net.TCPAddr).Port))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, 2, resp.ProtoMajor)
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartQuery starts a span for the repository query named query on repo,
+// which runs statement against SQLite. End it with EndQuery.
+func StartQuery(ctx context.Context, repo, query, statement string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, repo+"."+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameSQLite,
+			semconv.DBCollectionName(repo),
+			semconv.DBOperationName(query),
+			semconv.DBQueryText(statement),
+		),
+	)
+}
+
+// EndQuery ends a span StartQuery started, recording the rows the query
+// returned, or err if it failed.
+func EndQuery(span trace.Span, rows int, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(semconv.DBResponseReturnedRows(rows))
+	}
+	span.End()
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// Exporters the -trace-exporter flag accepts.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Flags are the command line options selecting where spans are exported.
+type Flags struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	File         string
+	SampleRatio  float64
+}
+
+// RegisterFlags registers -trace-exporter, -trace-otlp-endpoint,
+// -trace-otlp-insecure, -trace-file and -trace-sample-ratio on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+
+	fs.StringVar(&f.Exporter, "trace-exporter", ExporterNone, "Where to export trace spans: none, otlp, stdout or file")
+	fs.StringVar(&f.OTLPEndpoint, "trace-otlp-endpoint", "", "OTLP/gRPC collector endpoint (defaults to OTEL_EXPORTER_OTLP_ENDPOINT, then localhost:4317)")
+	fs.BoolVar(&f.OTLPInsecure, "trace-otlp-insecure", false, "Export to the OTLP collector without TLS")
+	fs.StringVar(&f.File, "trace-file", "traces.jsonl", "File the file exporter appends spans to")
+	fs.Float64Var(&f.SampleRatio, "trace-sample-ratio", 1, "Fraction of new traces to sample; requests that arrive sampled stay sampled")
+
+	return &f
+}
+
+// Validate checks that the flags name a known exporter and a sample ratio
+// between 0 and 1.
+func (f *Flags) Validate() error {
+	switch f.Exporter {
+	case ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile:
+	default:
+		return fmt.Errorf("unknown trace exporter %q: want none, otlp, stdout or file", f.Exporter)
+	}
+	if f.SampleRatio < 0 || f.SampleRatio > 1 {
+		return fmt.Errorf("trace sample ratio %v must be between 0 and 1", f.SampleRatio)
+	}
+	if f.Exporter == ExporterFile && f.File == "" {
+		return errors.New("the file trace exporter needs -trace-file")
+	}
+	return nil
+}
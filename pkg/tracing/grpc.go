@@ -0,0 +1,18 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc/stats"
+)
+
+// ServerHandler starts a server span for each RPC, continuing the trace
+// context the caller sent in its metadata.
+func ServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler()
+}
+
+// ClientHandler starts a client span for each RPC and sends its trace context
+// to the server in the request metadata.
+func ClientHandler() stats.Handler {
+	return otelgrpc.NewClientHandler()
+}
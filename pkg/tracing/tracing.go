@@ -0,0 +1,111 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, W3C trace
+// context propagation, and spans for gRPC, HTTP and repository queries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation names the tracer the spans started by this package belong to.
+const instrumentation = "git.neds.sh/matty/entain/pkg/tracing"
+
+// ShutdownFunc flushes buffered spans and releases the exporter.
+type ShutdownFunc func(context.Context) error
+
+// Setup installs the global tracer provider for service and the W3C trace
+// context and baggage propagators. Trace context is propagated even when the
+// exporter is none, so a service that doesn't export still links the spans
+// of the services either side of it.
+func (f *Flags) Setup(ctx context.Context, service string) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closer, err := f.exporter(ctx)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(service),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(f.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// exporter builds the exporter the flags select, and anything to close once
+// it has shut down. It returns a nil exporter when tracing is off.
+func (f *Flags) exporter(ctx context.Context) (sdktrace.SpanExporter, io.Closer, error) {
+	switch f.Exporter {
+	case "", ExporterNone:
+		return nil, nil, nil
+
+	case ExporterOTLP:
+		var opts []otlptracegrpc.Option
+		if f.OTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(f.OTLPEndpoint))
+		}
+		if f.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		return exporter, nil, err
+
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+
+	case ExporterFile:
+		file, err := os.OpenFile(f.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q: want none, otlp, stdout or file", f.Exporter)
+	}
+}
+
+// tracer returns this package's tracer from the global provider, so spans
+// follow whichever provider Setup installed.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
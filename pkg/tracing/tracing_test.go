@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recordSpans installs a tracer provider that records every span, and the
+// propagators Setup installs.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	_, err := (&Flags{Exporter: ExporterNone}).Setup(context.Background(), "test")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestQuerySpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := StartQuery(context.Background(), "races", "list", "SELECT id FROM races")
+	EndQuery(span, 3, nil)
+	_, span = StartQuery(context.Background(), "races", "get", "SELECT id FROM races WHERE id = ?")
+	EndQuery(span, 0, errors.New("disk I/O error"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "races.list", spans[0].Name())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	attrs := attributes(spans[0])
+	require.Equal(t, "sqlite", attrs["db.system.name"].AsString())
+	require.Equal(t, "list", attrs["db.operation.name"].AsString())
+	require.Equal(t, "SELECT id FROM races", attrs["db.query.text"].AsString())
+	require.Equal(t, int64(3), attrs["db.response.returned_rows"].AsInt64())
+
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Len(t, spans[1].Events(), 1)
+}
+
+func TestHandlerContinuesTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), r.Method, "/v1/races/{id}")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/races/7", nil)
+	req.Header.Set("traceparent", traceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET /v1/races/{id}", spans[0].Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	require.Equal(t, "/v1/races/{id}", attributes(spans[0])["http.route"].AsString())
+}
+
+func TestTracePropagatesFromHTTPToGRPC(t *testing.T) {
+	recorder := recordSpans(t)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.StatsHandler(ServerHandler()))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(ClientHandler()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := healthpb.NewHealthClient(conn).Check(r.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/list-races", nil)
+	req.Header.Set("traceparent", traceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	server.GracefulStop()
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), span.Name())
+		byName[span.Name()+"/"+span.SpanKind().String()] = span
+	}
+	require.Len(t, byName, 3)
+
+	gateway := byName["GET/server"]
+	client := byName["grpc.health.v1.Health/Check/client"]
+	service := byName["grpc.health.v1.Health/Check/server"]
+	require.Equal(t, gateway.SpanContext().SpanID(), client.Parent().SpanID())
+	require.Equal(t, client.SpanContext().SpanID(), service.Parent().SpanID())
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	shutdown, err := (&Flags{Exporter: ExporterFile, File: path, SampleRatio: 1}).Setup(context.Background(), "racing")
+	require.NoError(t, err)
+
+	_, span := StartQuery(context.Background(), "races", "list", "SELECT 1")
+	EndQuery(span, 1, nil)
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Name":"races.list"`)
+	require.Contains(t, string(data), `"Value":"racing"`)
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := (&Flags{Exporter: "zipkin"}).Setup(context.Background(), "racing")
+	require.ErrorContains(t, err, `unknown trace exporter "zipkin"`)
+}
@@ -0,0 +1,47 @@
+// Package visibility decides which callers of racing and sports may see
+// hidden races and events, from the roles the API gateway verified and
+// forwards in their metadata.
+package visibility
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataRoles carries the caller's roles, as verified and forwarded by the API gateway.
+const MetadataRoles = "x-auth-roles"
+
+// PrivilegedRoles may see hidden races and events.
+var PrivilegedRoles = []string{"trading", "admin"}
+
+// Privileged reports whether the caller holds a role that may see hidden
+// races and events. Callers without roles are public.
+func Privileged(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get(MetadataRoles) {
+		for _, role := range strings.Split(value, ",") {
+			if slices.Contains(PrivilegedRoles, strings.TrimSpace(role)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ShowHidden resolves a list's show_hidden for the caller: hidden items are
+// only listed when a privileged caller asks for them.
+func ShowHidden(ctx context.Context, requested bool) *bool {
+	showHidden := requested && Privileged(ctx)
+	return &showHidden
+}
+
+// CanSee reports whether the caller may see an item looked up by ID. Hidden
+// items don't exist as far as public callers can tell.
+func CanSee(ctx context.Context, visible bool) bool {
+	return visible || Privileged(ctx)
+}
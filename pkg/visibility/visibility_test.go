@@ -0,0 +1,46 @@
+package visibility
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// callerContext returns an incoming context for a caller holding roles, as the gateway forwards them.
+func callerContext(roles string) context.Context {
+	if roles == "" {
+		return context.Background()
+	}
+
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataRoles, roles))
+}
+
+func TestVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      string
+		privileged bool
+	}{
+		{name: "public caller"},
+		{name: "customer", roles: "customer"},
+		{name: "partner", roles: "partner"},
+		{name: "trader", roles: "trading", privileged: true},
+		{name: "customer and trader", roles: "customer, trading", privileged: true},
+		{name: "admin", roles: "admin", privileged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := callerContext(tt.roles)
+			require.Equal(t, tt.privileged, Privileged(ctx))
+
+			require.False(t, *ShowHidden(ctx, false), "hidden items are only listed when asked for")
+			require.Equal(t, tt.privileged, *ShowHidden(ctx, true))
+
+			require.True(t, CanSee(ctx, true))
+			require.Equal(t, tt.privileged, CanSee(ctx, false), "hidden items are found by privileged callers only")
+		})
+	}
+}
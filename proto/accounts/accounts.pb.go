@@ -0,0 +1,2065 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: accounts/accounts.proto
+
+package accounts
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type is the reason funds moved.
+type Transaction_Type int32
+
+const (
+	Transaction_TYPE_UNSPECIFIED Transaction_Type = 0
+	Transaction_TYPE_DEPOSIT     Transaction_Type = 1
+	Transaction_TYPE_WITHDRAWAL  Transaction_Type = 2
+	Transaction_TYPE_BET_STAKE   Transaction_Type = 3
+	Transaction_TYPE_PAYOUT      Transaction_Type = 4
+	Transaction_TYPE_REFUND      Transaction_Type = 5
+)
+
+// Enum value maps for Transaction_Type.
+var (
+	Transaction_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_DEPOSIT",
+		2: "TYPE_WITHDRAWAL",
+		3: "TYPE_BET_STAKE",
+		4: "TYPE_PAYOUT",
+		5: "TYPE_REFUND",
+	}
+	Transaction_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_DEPOSIT":     1,
+		"TYPE_WITHDRAWAL":  2,
+		"TYPE_BET_STAKE":   3,
+		"TYPE_PAYOUT":      4,
+		"TYPE_REFUND":      5,
+	}
+)
+
+func (x Transaction_Type) Enum() *Transaction_Type {
+	p := new(Transaction_Type)
+	*p = x
+	return p
+}
+
+func (x Transaction_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Transaction_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_accounts_accounts_proto_enumTypes[0].Descriptor()
+}
+
+func (Transaction_Type) Type() protoreflect.EnumType {
+	return &file_accounts_accounts_proto_enumTypes[0]
+}
+
+func (x Transaction_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Transaction_Type.Descriptor instead.
+func (Transaction_Type) EnumDescriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{21, 0}
+}
+
+// Type is what the limit caps.
+type Limit_Type int32
+
+const (
+	Limit_TYPE_UNSPECIFIED Limit_Type = 0
+	// Total deposited.
+	Limit_TYPE_DEPOSIT Limit_Type = 1
+	// Stakes less payouts and refunds.
+	Limit_TYPE_LOSS Limit_Type = 2
+	// Total staked.
+	Limit_TYPE_STAKE Limit_Type = 3
+)
+
+// Enum value maps for Limit_Type.
+var (
+	Limit_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_DEPOSIT",
+		2: "TYPE_LOSS",
+		3: "TYPE_STAKE",
+	}
+	Limit_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_DEPOSIT":     1,
+		"TYPE_LOSS":        2,
+		"TYPE_STAKE":       3,
+	}
+)
+
+func (x Limit_Type) Enum() *Limit_Type {
+	p := new(Limit_Type)
+	*p = x
+	return p
+}
+
+func (x Limit_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Limit_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_accounts_accounts_proto_enumTypes[1].Descriptor()
+}
+
+func (Limit_Type) Type() protoreflect.EnumType {
+	return &file_accounts_accounts_proto_enumTypes[1]
+}
+
+func (x Limit_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Limit_Type.Descriptor instead.
+func (Limit_Type) EnumDescriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{22, 0}
+}
+
+// Window is the rolling period the limit applies over.
+type Limit_Window int32
+
+const (
+	Limit_WINDOW_UNSPECIFIED Limit_Window = 0
+	// The last 24 hours.
+	Limit_WINDOW_DAILY Limit_Window = 1
+	// The last 7 days.
+	Limit_WINDOW_WEEKLY Limit_Window = 2
+	// The last 30 days.
+	Limit_WINDOW_MONTHLY Limit_Window = 3
+)
+
+// Enum value maps for Limit_Window.
+var (
+	Limit_Window_name = map[int32]string{
+		0: "WINDOW_UNSPECIFIED",
+		1: "WINDOW_DAILY",
+		2: "WINDOW_WEEKLY",
+		3: "WINDOW_MONTHLY",
+	}
+	Limit_Window_value = map[string]int32{
+		"WINDOW_UNSPECIFIED": 0,
+		"WINDOW_DAILY":       1,
+		"WINDOW_WEEKLY":      2,
+		"WINDOW_MONTHLY":     3,
+	}
+)
+
+func (x Limit_Window) Enum() *Limit_Window {
+	p := new(Limit_Window)
+	*p = x
+	return p
+}
+
+func (x Limit_Window) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Limit_Window) Descriptor() protoreflect.EnumDescriptor {
+	return file_accounts_accounts_proto_enumTypes[2].Descriptor()
+}
+
+func (Limit_Window) Type() protoreflect.EnumType {
+	return &file_accounts_accounts_proto_enumTypes[2]
+}
+
+func (x Limit_Window) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Limit_Window.Descriptor instead.
+func (Limit_Window) EnumDescriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{22, 1}
+}
+
+// Kind is the control that changed.
+type ControlChange_Kind int32
+
+const (
+	ControlChange_KIND_UNSPECIFIED    ControlChange_Kind = 0
+	ControlChange_KIND_LIMIT          ControlChange_Kind = 1
+	ControlChange_KIND_COOL_OFF       ControlChange_Kind = 2
+	ControlChange_KIND_SELF_EXCLUSION ControlChange_Kind = 3
+)
+
+// Enum value maps for ControlChange_Kind.
+var (
+	ControlChange_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_LIMIT",
+		2: "KIND_COOL_OFF",
+		3: "KIND_SELF_EXCLUSION",
+	}
+	ControlChange_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED":    0,
+		"KIND_LIMIT":          1,
+		"KIND_COOL_OFF":       2,
+		"KIND_SELF_EXCLUSION": 3,
+	}
+)
+
+func (x ControlChange_Kind) Enum() *ControlChange_Kind {
+	p := new(ControlChange_Kind)
+	*p = x
+	return p
+}
+
+func (x ControlChange_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ControlChange_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_accounts_accounts_proto_enumTypes[3].Descriptor()
+}
+
+func (ControlChange_Kind) Type() protoreflect.EnumType {
+	return &file_accounts_accounts_proto_enumTypes[3]
+}
+
+func (x ControlChange_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ControlChange_Kind.Descriptor instead.
+func (ControlChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{24, 0}
+}
+
+// Request for CreateAccount call.
+type CreateAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountRequest) Reset() {
+	*x = CreateAccountRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountRequest) ProtoMessage() {}
+
+func (x *CreateAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateAccountRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response to CreateAccount call.
+type CreateAccountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountResponse) Reset() {
+	*x = CreateAccountResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountResponse) ProtoMessage() {}
+
+func (x *CreateAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccountResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateAccountResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+// Request for GetBalance call.
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBalanceRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+// Response to GetBalance call.
+type GetBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceResponse) Reset() {
+	*x = GetBalanceResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceResponse) ProtoMessage() {}
+
+func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBalanceResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+// Request for ListTransactions call.
+type ListTransactionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// PageSize caps the number of transactions returned, default 50 and at most 500.
+	PageSize      int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTransactionsRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// Response to ListTransactions call.
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+// Request for Debit call.
+type DebitRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Type must be TYPE_WITHDRAWAL or TYPE_BET_STAKE.
+	Type        Transaction_Type `protobuf:"varint,2,opt,name=type,proto3,enum=accounts.Transaction_Type" json:"type,omitempty"`
+	AmountCents int64            `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// IdempotencyKey identifies the posting; replaying a key returns the original transaction.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Reference is a free-form note, e.g. the bet or payment it relates to.
+	Reference     string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DebitRequest) Reset() {
+	*x = DebitRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebitRequest) ProtoMessage() {}
+
+func (x *DebitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebitRequest.ProtoReflect.Descriptor instead.
+func (*DebitRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DebitRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *DebitRequest) GetType() Transaction_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Transaction_TYPE_UNSPECIFIED
+}
+
+func (x *DebitRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *DebitRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *DebitRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+// Response to Debit call.
+type DebitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DebitResponse) Reset() {
+	*x = DebitResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebitResponse) ProtoMessage() {}
+
+func (x *DebitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebitResponse.ProtoReflect.Descriptor instead.
+func (*DebitResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DebitResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+// Request for Credit call.
+type CreditRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Type must be TYPE_DEPOSIT, TYPE_PAYOUT or TYPE_REFUND.
+	Type        Transaction_Type `protobuf:"varint,2,opt,name=type,proto3,enum=accounts.Transaction_Type" json:"type,omitempty"`
+	AmountCents int64            `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// IdempotencyKey identifies the posting; replaying a key returns the original transaction.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Reference is a free-form note, e.g. the bet or payment it relates to.
+	Reference     string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreditRequest) Reset() {
+	*x = CreditRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditRequest) ProtoMessage() {}
+
+func (x *CreditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditRequest.ProtoReflect.Descriptor instead.
+func (*CreditRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreditRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *CreditRequest) GetType() Transaction_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Transaction_TYPE_UNSPECIFIED
+}
+
+func (x *CreditRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *CreditRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreditRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+// Response to Credit call.
+type CreditResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreditResponse) Reset() {
+	*x = CreditResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditResponse) ProtoMessage() {}
+
+func (x *CreditResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditResponse.ProtoReflect.Descriptor instead.
+func (*CreditResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreditResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+// Request for SetLimit call.
+type SetLimitRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Type      Limit_Type             `protobuf:"varint,2,opt,name=type,proto3,enum=accounts.Limit_Type" json:"type,omitempty"`
+	Window    Limit_Window           `protobuf:"varint,3,opt,name=window,proto3,enum=accounts.Limit_Window" json:"window,omitempty"`
+	// AmountCents is the new limit; leave unset to remove the limit.
+	AmountCents *int64 `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3,oneof" json:"amount_cents,omitempty"`
+	// Reason is recorded in the audit trail.
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLimitRequest) Reset() {
+	*x = SetLimitRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLimitRequest) ProtoMessage() {}
+
+func (x *SetLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLimitRequest.ProtoReflect.Descriptor instead.
+func (*SetLimitRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetLimitRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *SetLimitRequest) GetType() Limit_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Limit_TYPE_UNSPECIFIED
+}
+
+func (x *SetLimitRequest) GetWindow() Limit_Window {
+	if x != nil {
+		return x.Window
+	}
+	return Limit_WINDOW_UNSPECIFIED
+}
+
+func (x *SetLimitRequest) GetAmountCents() int64 {
+	if x != nil && x.AmountCents != nil {
+		return *x.AmountCents
+	}
+	return 0
+}
+
+func (x *SetLimitRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Response to SetLimit call.
+type SetLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         *Limit                 `protobuf:"bytes,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLimitResponse) Reset() {
+	*x = SetLimitResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLimitResponse) ProtoMessage() {}
+
+func (x *SetLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLimitResponse.ProtoReflect.Descriptor instead.
+func (*SetLimitResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetLimitResponse) GetLimit() *Limit {
+	if x != nil {
+		return x.Limit
+	}
+	return nil
+}
+
+// Request for GetControls call.
+type GetControlsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetControlsRequest) Reset() {
+	*x = GetControlsRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetControlsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetControlsRequest) ProtoMessage() {}
+
+func (x *GetControlsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetControlsRequest.ProtoReflect.Descriptor instead.
+func (*GetControlsRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetControlsRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+// Response to GetControls call.
+type GetControlsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Controls      *Controls              `protobuf:"bytes,1,opt,name=controls,proto3" json:"controls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetControlsResponse) Reset() {
+	*x = GetControlsResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetControlsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetControlsResponse) ProtoMessage() {}
+
+func (x *GetControlsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetControlsResponse.ProtoReflect.Descriptor instead.
+func (*GetControlsResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetControlsResponse) GetControls() *Controls {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+// Request for StartCoolOff call.
+type StartCoolOffRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Duration is between 24 hours and six weeks.
+	Duration      *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason        string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartCoolOffRequest) Reset() {
+	*x = StartCoolOffRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartCoolOffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartCoolOffRequest) ProtoMessage() {}
+
+func (x *StartCoolOffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartCoolOffRequest.ProtoReflect.Descriptor instead.
+func (*StartCoolOffRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StartCoolOffRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *StartCoolOffRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *StartCoolOffRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Response to StartCoolOff call.
+type StartCoolOffResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Controls      *Controls              `protobuf:"bytes,1,opt,name=controls,proto3" json:"controls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartCoolOffResponse) Reset() {
+	*x = StartCoolOffResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartCoolOffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartCoolOffResponse) ProtoMessage() {}
+
+func (x *StartCoolOffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartCoolOffResponse.ProtoReflect.Descriptor instead.
+func (*StartCoolOffResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StartCoolOffResponse) GetControls() *Controls {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+// Request for SelfExclude call.
+type SelfExcludeRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Duration is at least six months; leave unset to exclude permanently.
+	Duration      *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason        string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfExcludeRequest) Reset() {
+	*x = SelfExcludeRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfExcludeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfExcludeRequest) ProtoMessage() {}
+
+func (x *SelfExcludeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfExcludeRequest.ProtoReflect.Descriptor instead.
+func (*SelfExcludeRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SelfExcludeRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *SelfExcludeRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *SelfExcludeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Response to SelfExclude call.
+type SelfExcludeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Controls      *Controls              `protobuf:"bytes,1,opt,name=controls,proto3" json:"controls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfExcludeResponse) Reset() {
+	*x = SelfExcludeResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfExcludeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfExcludeResponse) ProtoMessage() {}
+
+func (x *SelfExcludeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfExcludeResponse.ProtoReflect.Descriptor instead.
+func (*SelfExcludeResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SelfExcludeResponse) GetControls() *Controls {
+	if x != nil {
+		return x.Controls
+	}
+	return nil
+}
+
+// Request for ListControlChanges call.
+type ListControlChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListControlChangesRequest) Reset() {
+	*x = ListControlChangesRequest{}
+	mi := &file_accounts_accounts_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListControlChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListControlChangesRequest) ProtoMessage() {}
+
+func (x *ListControlChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListControlChangesRequest.ProtoReflect.Descriptor instead.
+func (*ListControlChangesRequest) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListControlChangesRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+// Response to ListControlChanges call.
+type ListControlChangesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*ControlChange       `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListControlChangesResponse) Reset() {
+	*x = ListControlChangesResponse{}
+	mi := &file_accounts_accounts_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListControlChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListControlChangesResponse) ProtoMessage() {}
+
+func (x *ListControlChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListControlChangesResponse.ProtoReflect.Descriptor instead.
+func (*ListControlChangesResponse) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListControlChangesResponse) GetChanges() []*ControlChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// A customer account resource.
+type Account struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID represents a unique identifier for the account.
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Name is the account holder's name.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// BalanceCents is the funds available, never negative.
+	BalanceCents int64 `protobuf:"varint,3,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// CreatedTime is when the account was opened.
+	CreatedTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_time,json=createdTime,proto3" json:"created_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_accounts_accounts_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Account) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Account) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Account) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+func (x *Account) GetCreatedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTime
+	}
+	return nil
+}
+
+// A transaction posted to a customer account. Every transaction is balanced by
+// an opposite entry against a house ledger account.
+type Transaction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID represents a unique identifier for the transaction.
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// AccountID is the customer account the transaction was posted to.
+	AccountId int64            `protobuf:"varint,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Type      Transaction_Type `protobuf:"varint,3,opt,name=type,proto3,enum=accounts.Transaction_Type" json:"type,omitempty"`
+	// AmountCents is signed from the customer's view: credits positive, debits negative.
+	AmountCents int64 `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// BalanceCents is the account balance immediately after the transaction.
+	BalanceCents   int64  `protobuf:"varint,5,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	Reference      string `protobuf:"bytes,6,opt,name=reference,proto3" json:"reference,omitempty"`
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// CreatedTime is when the transaction was posted.
+	CreatedTime   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_time,json=createdTime,proto3" json:"created_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_accounts_accounts_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Transaction) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Transaction) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *Transaction) GetType() Transaction_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Transaction_TYPE_UNSPECIFIED
+}
+
+func (x *Transaction) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *Transaction) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+func (x *Transaction) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *Transaction) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTime
+	}
+	return nil
+}
+
+// A responsible gambling limit over a rolling window.
+type Limit struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Type   Limit_Type             `protobuf:"varint,1,opt,name=type,proto3,enum=accounts.Limit_Type" json:"type,omitempty"`
+	Window Limit_Window           `protobuf:"varint,2,opt,name=window,proto3,enum=accounts.Limit_Window" json:"window,omitempty"`
+	// AmountCents is the limit in force; unset when there is none.
+	AmountCents *int64 `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3,oneof" json:"amount_cents,omitempty"`
+	// Pending is set while a loosening change waits out its delay.
+	Pending bool `protobuf:"varint,4,opt,name=pending,proto3" json:"pending,omitempty"`
+	// PendingAmountCents is the limit that applies once the delay passes; unset for removal.
+	PendingAmountCents *int64 `protobuf:"varint,5,opt,name=pending_amount_cents,json=pendingAmountCents,proto3,oneof" json:"pending_amount_cents,omitempty"`
+	// PendingEffectiveTime is when the pending change takes effect.
+	PendingEffectiveTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=pending_effective_time,json=pendingEffectiveTime,proto3" json:"pending_effective_time,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Limit) Reset() {
+	*x = Limit{}
+	mi := &file_accounts_accounts_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limit) ProtoMessage() {}
+
+func (x *Limit) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limit.ProtoReflect.Descriptor instead.
+func (*Limit) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Limit) GetType() Limit_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Limit_TYPE_UNSPECIFIED
+}
+
+func (x *Limit) GetWindow() Limit_Window {
+	if x != nil {
+		return x.Window
+	}
+	return Limit_WINDOW_UNSPECIFIED
+}
+
+func (x *Limit) GetAmountCents() int64 {
+	if x != nil && x.AmountCents != nil {
+		return *x.AmountCents
+	}
+	return 0
+}
+
+func (x *Limit) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+func (x *Limit) GetPendingAmountCents() int64 {
+	if x != nil && x.PendingAmountCents != nil {
+		return *x.PendingAmountCents
+	}
+	return 0
+}
+
+func (x *Limit) GetPendingEffectiveTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PendingEffectiveTime
+	}
+	return nil
+}
+
+// The responsible gambling controls on an account.
+type Controls struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Limits    []*Limit               `protobuf:"bytes,2,rep,name=limits,proto3" json:"limits,omitempty"`
+	// CoolOffUntil is set while betting and deposits are paused.
+	CoolOffUntil *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=cool_off_until,json=coolOffUntil,proto3" json:"cool_off_until,omitempty"`
+	// ExcludedUntil is set while the customer is self-excluded for a fixed period.
+	ExcludedUntil *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=excluded_until,json=excludedUntil,proto3" json:"excluded_until,omitempty"`
+	// ExcludedPermanently is set once the customer has permanently self-excluded.
+	ExcludedPermanently bool `protobuf:"varint,5,opt,name=excluded_permanently,json=excludedPermanently,proto3" json:"excluded_permanently,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Controls) Reset() {
+	*x = Controls{}
+	mi := &file_accounts_accounts_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Controls) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Controls) ProtoMessage() {}
+
+func (x *Controls) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Controls.ProtoReflect.Descriptor instead.
+func (*Controls) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Controls) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *Controls) GetLimits() []*Limit {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *Controls) GetCoolOffUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CoolOffUntil
+	}
+	return nil
+}
+
+func (x *Controls) GetExcludedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExcludedUntil
+	}
+	return nil
+}
+
+func (x *Controls) GetExcludedPermanently() bool {
+	if x != nil {
+		return x.ExcludedPermanently
+	}
+	return false
+}
+
+// An audit record of a change to an account's controls.
+type ControlChange struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId int64                  `protobuf:"varint,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Kind      ControlChange_Kind     `protobuf:"varint,3,opt,name=kind,proto3,enum=accounts.ControlChange_Kind" json:"kind,omitempty"`
+	// LimitType and LimitWindow identify the limit for KIND_LIMIT changes.
+	LimitType   Limit_Type   `protobuf:"varint,4,opt,name=limit_type,json=limitType,proto3,enum=accounts.Limit_Type" json:"limit_type,omitempty"`
+	LimitWindow Limit_Window `protobuf:"varint,5,opt,name=limit_window,json=limitWindow,proto3,enum=accounts.Limit_Window" json:"limit_window,omitempty"`
+	// OldAmountCents and NewAmountCents are the limit before and after; unset means no limit.
+	OldAmountCents *int64 `protobuf:"varint,6,opt,name=old_amount_cents,json=oldAmountCents,proto3,oneof" json:"old_amount_cents,omitempty"`
+	NewAmountCents *int64 `protobuf:"varint,7,opt,name=new_amount_cents,json=newAmountCents,proto3,oneof" json:"new_amount_cents,omitempty"`
+	// Until is the end of a cool-off or self-exclusion; unset for a permanent exclusion.
+	Until *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=until,proto3" json:"until,omitempty"`
+	// EffectiveTime is when the change takes effect, later than ChangedTime for delayed increases.
+	EffectiveTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=effective_time,json=effectiveTime,proto3" json:"effective_time,omitempty"`
+	ChangedTime   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=changed_time,json=changedTime,proto3" json:"changed_time,omitempty"`
+	Reason        string                 `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlChange) Reset() {
+	*x = ControlChange{}
+	mi := &file_accounts_accounts_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlChange) ProtoMessage() {}
+
+func (x *ControlChange) ProtoReflect() protoreflect.Message {
+	mi := &file_accounts_accounts_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlChange.ProtoReflect.Descriptor instead.
+func (*ControlChange) Descriptor() ([]byte, []int) {
+	return file_accounts_accounts_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ControlChange) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ControlChange) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ControlChange) GetKind() ControlChange_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return ControlChange_KIND_UNSPECIFIED
+}
+
+func (x *ControlChange) GetLimitType() Limit_Type {
+	if x != nil {
+		return x.LimitType
+	}
+	return Limit_TYPE_UNSPECIFIED
+}
+
+func (x *ControlChange) GetLimitWindow() Limit_Window {
+	if x != nil {
+		return x.LimitWindow
+	}
+	return Limit_WINDOW_UNSPECIFIED
+}
+
+func (x *ControlChange) GetOldAmountCents() int64 {
+	if x != nil && x.OldAmountCents != nil {
+		return *x.OldAmountCents
+	}
+	return 0
+}
+
+func (x *ControlChange) GetNewAmountCents() int64 {
+	if x != nil && x.NewAmountCents != nil {
+		return *x.NewAmountCents
+	}
+	return 0
+}
+
+func (x *ControlChange) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *ControlChange) GetEffectiveTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveTime
+	}
+	return nil
+}
+
+func (x *ControlChange) GetChangedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedTime
+	}
+	return nil
+}
+
+func (x *ControlChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_accounts_accounts_proto protoreflect.FileDescriptor
+
+const file_accounts_accounts_proto_rawDesc = "" +
+	"\n" +
+	"\x17accounts/accounts.proto\x12\baccounts\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\"/\n" +
+	"\x14CreateAccountRequest\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tB\x03\x80\x01\x01R\x04name\"D\n" +
+	"\x15CreateAccountResponse\x12+\n" +
+	"\aaccount\x18\x01 \x01(\v2\x11.accounts.AccountR\aaccount\"2\n" +
+	"\x11GetBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\"A\n" +
+	"\x12GetBalanceResponse\x12+\n" +
+	"\aaccount\x18\x01 \x01(\v2\x11.accounts.AccountR\aaccount\"U\n" +
+	"\x17ListTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"U\n" +
+	"\x18ListTransactionsResponse\x129\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x15.accounts.TransactionR\ftransactions\"\xcc\x01\n" +
+	"\fDebitRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12.\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1a.accounts.Transaction.TypeR\x04type\x12!\n" +
+	"\famount_cents\x18\x03 \x01(\x03R\vamountCents\x12,\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tB\x03\x80\x01\x01R\x0eidempotencyKey\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\"H\n" +
+	"\rDebitResponse\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.accounts.TransactionR\vtransaction\"\xcd\x01\n" +
+	"\rCreditRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12.\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1a.accounts.Transaction.TypeR\x04type\x12!\n" +
+	"\famount_cents\x18\x03 \x01(\x03R\vamountCents\x12,\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tB\x03\x80\x01\x01R\x0eidempotencyKey\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\"I\n" +
+	"\x0eCreditResponse\x127\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x15.accounts.TransactionR\vtransaction\"\xe0\x01\n" +
+	"\x0fSetLimitRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12(\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x14.accounts.Limit.TypeR\x04type\x12.\n" +
+	"\x06window\x18\x03 \x01(\x0e2\x16.accounts.Limit.WindowR\x06window\x12&\n" +
+	"\famount_cents\x18\x04 \x01(\x03H\x00R\vamountCents\x88\x01\x01\x12\x1b\n" +
+	"\x06reason\x18\x05 \x01(\tB\x03\x80\x01\x01R\x06reasonB\x0f\n" +
+	"\r_amount_cents\"9\n" +
+	"\x10SetLimitResponse\x12%\n" +
+	"\x05limit\x18\x01 \x01(\v2\x0f.accounts.LimitR\x05limit\"3\n" +
+	"\x12GetControlsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\"E\n" +
+	"\x13GetControlsResponse\x12.\n" +
+	"\bcontrols\x18\x01 \x01(\v2\x12.accounts.ControlsR\bcontrols\"\x88\x01\n" +
+	"\x13StartCoolOffRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x1b\n" +
+	"\x06reason\x18\x03 \x01(\tB\x03\x80\x01\x01R\x06reason\"F\n" +
+	"\x14StartCoolOffResponse\x12.\n" +
+	"\bcontrols\x18\x01 \x01(\v2\x12.accounts.ControlsR\bcontrols\"\x87\x01\n" +
+	"\x12SelfExcludeRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x1b\n" +
+	"\x06reason\x18\x03 \x01(\tB\x03\x80\x01\x01R\x06reason\"E\n" +
+	"\x13SelfExcludeResponse\x12.\n" +
+	"\bcontrols\x18\x01 \x01(\v2\x12.accounts.ControlsR\bcontrols\":\n" +
+	"\x19ListControlChangesRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\"O\n" +
+	"\x1aListControlChangesResponse\x121\n" +
+	"\achanges\x18\x01 \x03(\v2\x17.accounts.ControlChangeR\achanges\"\x96\x01\n" +
+	"\aAccount\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tB\x03\x80\x01\x01R\x04name\x12#\n" +
+	"\rbalance_cents\x18\x03 \x01(\x03R\fbalanceCents\x12=\n" +
+	"\fcreated_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedTime\"\xba\x03\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\x03R\taccountId\x12.\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1a.accounts.Transaction.TypeR\x04type\x12!\n" +
+	"\famount_cents\x18\x04 \x01(\x03R\vamountCents\x12#\n" +
+	"\rbalance_cents\x18\x05 \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\treference\x18\x06 \x01(\tR\treference\x12,\n" +
+	"\x0fidempotency_key\x18\a \x01(\tB\x03\x80\x01\x01R\x0eidempotencyKey\x12=\n" +
+	"\fcreated_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedTime\"y\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_DEPOSIT\x10\x01\x12\x13\n" +
+	"\x0fTYPE_WITHDRAWAL\x10\x02\x12\x12\n" +
+	"\x0eTYPE_BET_STAKE\x10\x03\x12\x0f\n" +
+	"\vTYPE_PAYOUT\x10\x04\x12\x0f\n" +
+	"\vTYPE_REFUND\x10\x05\"\x80\x04\n" +
+	"\x05Limit\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.accounts.Limit.TypeR\x04type\x12.\n" +
+	"\x06window\x18\x02 \x01(\x0e2\x16.accounts.Limit.WindowR\x06window\x12&\n" +
+	"\famount_cents\x18\x03 \x01(\x03H\x00R\vamountCents\x88\x01\x01\x12\x18\n" +
+	"\apending\x18\x04 \x01(\bR\apending\x125\n" +
+	"\x14pending_amount_cents\x18\x05 \x01(\x03H\x01R\x12pendingAmountCents\x88\x01\x01\x12P\n" +
+	"\x16pending_effective_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x14pendingEffectiveTime\"M\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_DEPOSIT\x10\x01\x12\r\n" +
+	"\tTYPE_LOSS\x10\x02\x12\x0e\n" +
+	"\n" +
+	"TYPE_STAKE\x10\x03\"Y\n" +
+	"\x06Window\x12\x16\n" +
+	"\x12WINDOW_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fWINDOW_DAILY\x10\x01\x12\x11\n" +
+	"\rWINDOW_WEEKLY\x10\x02\x12\x12\n" +
+	"\x0eWINDOW_MONTHLY\x10\x03B\x0f\n" +
+	"\r_amount_centsB\x17\n" +
+	"\x15_pending_amount_cents\"\x8a\x02\n" +
+	"\bControls\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12'\n" +
+	"\x06limits\x18\x02 \x03(\v2\x0f.accounts.LimitR\x06limits\x12@\n" +
+	"\x0ecool_off_until\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcoolOffUntil\x12A\n" +
+	"\x0eexcluded_until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rexcludedUntil\x121\n" +
+	"\x14excluded_permanently\x18\x05 \x01(\bR\x13excludedPermanently\"\x93\x05\n" +
+	"\rControlChange\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\x03R\taccountId\x120\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x1c.accounts.ControlChange.KindR\x04kind\x123\n" +
+	"\n" +
+	"limit_type\x18\x04 \x01(\x0e2\x14.accounts.Limit.TypeR\tlimitType\x129\n" +
+	"\flimit_window\x18\x05 \x01(\x0e2\x16.accounts.Limit.WindowR\vlimitWindow\x12-\n" +
+	"\x10old_amount_cents\x18\x06 \x01(\x03H\x00R\x0eoldAmountCents\x88\x01\x01\x12-\n" +
+	"\x10new_amount_cents\x18\a \x01(\x03H\x01R\x0enewAmountCents\x88\x01\x01\x120\n" +
+	"\x05until\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12A\n" +
+	"\x0eeffective_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\reffectiveTime\x12=\n" +
+	"\fchanged_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vchangedTime\x12\x1b\n" +
+	"\x06reason\x18\v \x01(\tB\x03\x80\x01\x01R\x06reason\"X\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"KIND_LIMIT\x10\x01\x12\x11\n" +
+	"\rKIND_COOL_OFF\x10\x02\x12\x17\n" +
+	"\x13KIND_SELF_EXCLUSION\x10\x03B\x13\n" +
+	"\x11_old_amount_centsB\x13\n" +
+	"\x11_new_amount_cents2\xec\b\n" +
+	"\bAccounts\x12i\n" +
+	"\rCreateAccount\x12\x1e.accounts.CreateAccountRequest\x1a\x1f.accounts.CreateAccountResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/accounts\x12r\n" +
+	"\n" +
+	"GetBalance\x12\x1b.accounts.GetBalanceRequest\x1a\x1c.accounts.GetBalanceResponse\")\x82\xd3\xe4\x93\x02#\x12!/v1/accounts/{account_id}/balance\x12\x89\x01\n" +
+	"\x10ListTransactions\x12!.accounts.ListTransactionsRequest\x1a\".accounts.ListTransactionsResponse\".\x82\xd3\xe4\x93\x02(\x12&/v1/accounts/{account_id}/transactions\x12n\n" +
+	"\bSetLimit\x12\x19.accounts.SetLimitRequest\x1a\x1a.accounts.SetLimitResponse\"+\x82\xd3\xe4\x93\x02%:\x01*\" /v1/accounts/{account_id}/limits\x12v\n" +
+	"\vGetControls\x12\x1c.accounts.GetControlsRequest\x1a\x1d.accounts.GetControlsResponse\"*\x82\xd3\xe4\x93\x02$\x12\"/v1/accounts/{account_id}/controls\x12|\n" +
+	"\fStartCoolOff\x12\x1d.accounts.StartCoolOffRequest\x1a\x1e.accounts.StartCoolOffResponse\"-\x82\xd3\xe4\x93\x02':\x01*\"\"/v1/accounts/{account_id}/cool-off\x12\x7f\n" +
+	"\vSelfExclude\x12\x1c.accounts.SelfExcludeRequest\x1a\x1d.accounts.SelfExcludeResponse\"3\x82\xd3\xe4\x93\x02-:\x01*\"(/v1/accounts/{account_id}/self-exclusion\x12\x92\x01\n" +
+	"\x12ListControlChanges\x12#.accounts.ListControlChangesRequest\x1a$.accounts.ListControlChangesResponse\"1\x82\xd3\xe4\x93\x02+\x12)/v1/accounts/{account_id}/control-changes\x12:\n" +
+	"\x05Debit\x12\x16.accounts.DebitRequest\x1a\x17.accounts.DebitResponse\"\x00\x12=\n" +
+	"\x06Credit\x12\x17.accounts.CreditRequest\x1a\x18.accounts.CreditResponse\"\x00B2Z0git.neds.sh/matty/entain/proto/accounts;accountsb\x06proto3"
+
+var (
+	file_accounts_accounts_proto_rawDescOnce sync.Once
+	file_accounts_accounts_proto_rawDescData []byte
+)
+
+func file_accounts_accounts_proto_rawDescGZIP() []byte {
+	file_accounts_accounts_proto_rawDescOnce.Do(func() {
+		file_accounts_accounts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_accounts_accounts_proto_rawDesc), len(file_accounts_accounts_proto_rawDesc)))
+	})
+	return file_accounts_accounts_proto_rawDescData
+}
+
+var file_accounts_accounts_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_accounts_accounts_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_accounts_accounts_proto_goTypes = []any{
+	(Transaction_Type)(0),              // 0: accounts.Transaction.Type
+	(Limit_Type)(0),                    // 1: accounts.Limit.Type
+	(Limit_Window)(0),                  // 2: accounts.Limit.Window
+	(ControlChange_Kind)(0),            // 3: accounts.ControlChange.Kind
+	(*CreateAccountRequest)(nil),       // 4: accounts.CreateAccountRequest
+	(*CreateAccountResponse)(nil),      // 5: accounts.CreateAccountResponse
+	(*GetBalanceRequest)(nil),          // 6: accounts.GetBalanceRequest
+	(*GetBalanceResponse)(nil),         // 7: accounts.GetBalanceResponse
+	(*ListTransactionsRequest)(nil),    // 8: accounts.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),   // 9: accounts.ListTransactionsResponse
+	(*DebitRequest)(nil),               // 10: accounts.DebitRequest
+	(*DebitResponse)(nil),              // 11: accounts.DebitResponse
+	(*CreditRequest)(nil),              // 12: accounts.CreditRequest
+	(*CreditResponse)(nil),             // 13: accounts.CreditResponse
+	(*SetLimitRequest)(nil),            // 14: accounts.SetLimitRequest
+	(*SetLimitResponse)(nil),           // 15: accounts.SetLimitResponse
+	(*GetControlsRequest)(nil),         // 16: accounts.GetControlsRequest
+	(*GetControlsResponse)(nil),        // 17: accounts.GetControlsResponse
+	(*StartCoolOffRequest)(nil),        // 18: accounts.StartCoolOffRequest
+	(*StartCoolOffResponse)(nil),       // 19: accounts.StartCoolOffResponse
+	(*SelfExcludeRequest)(nil),         // 20: accounts.SelfExcludeRequest
+	(*SelfExcludeResponse)(nil),        // 21: accounts.SelfExcludeResponse
+	(*ListControlChangesRequest)(nil),  // 22: accounts.ListControlChangesRequest
+	(*ListControlChangesResponse)(nil), // 23: accounts.ListControlChangesResponse
+	(*Account)(nil),                    // 24: accounts.Account
+	(*Transaction)(nil),                // 25: accounts.Transaction
+	(*Limit)(nil),                      // 26: accounts.Limit
+	(*Controls)(nil),                   // 27: accounts.Controls
+	(*ControlChange)(nil),              // 28: accounts.ControlChange
+	(*durationpb.Duration)(nil),        // 29: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),      // 30: google.protobuf.Timestamp
+}
+var file_accounts_accounts_proto_depIdxs = []int32{
+	24, // 0: accounts.CreateAccountResponse.account:type_name -> accounts.Account
+	24, // 1: accounts.GetBalanceResponse.account:type_name -> accounts.Account
+	25, // 2: accounts.ListTransactionsResponse.transactions:type_name -> accounts.Transaction
+	0,  // 3: accounts.DebitRequest.type:type_name -> accounts.Transaction.Type
+	25, // 4: accounts.DebitResponse.transaction:type_name -> accounts.Transaction
+	0,  // 5: accounts.CreditRequest.type:type_name -> accounts.Transaction.Type
+	25, // 6: accounts.CreditResponse.transaction:type_name -> accounts.Transaction
+	1,  // 7: accounts.SetLimitRequest.type:type_name -> accounts.Limit.Type
+	2,  // 8: accounts.SetLimitRequest.window:type_name -> accounts.Limit.Window
+	26, // 9: accounts.SetLimitResponse.limit:type_name -> accounts.Limit
+	27, // 10: accounts.GetControlsResponse.controls:type_name -> accounts.Controls
+	29, // 11: accounts.StartCoolOffRequest.duration:type_name -> google.protobuf.Duration
+	27, // 12: accounts.StartCoolOffResponse.controls:type_name -> accounts.Controls
+	29, // 13: accounts.SelfExcludeRequest.duration:type_name -> google.protobuf.Duration
+	27, // 14: accounts.SelfExcludeResponse.controls:type_name -> accounts.Controls
+	28, // 15: accounts.ListControlChangesResponse.changes:type_name -> accounts.ControlChange
+	30, // 16: accounts.Account.created_time:type_name -> google.protobuf.Timestamp
+	0,  // 17: accounts.Transaction.type:type_name -> accounts.Transaction.Type
+	30, // 18: accounts.Transaction.created_time:type_name -> google.protobuf.Timestamp
+	1,  // 19: accounts.Limit.type:type_name -> accounts.Limit.Type
+	2,  // 20: accounts.Limit.window:type_name -> accounts.Limit.Window
+	30, // 21: accounts.Limit.pending_effective_time:type_name -> google.protobuf.Timestamp
+	26, // 22: accounts.Controls.limits:type_name -> accounts.Limit
+	30, // 23: accounts.Controls.cool_off_until:type_name -> google.protobuf.Timestamp
+	30, // 24: accounts.Controls.excluded_until:type_name -> google.protobuf.Timestamp
+	3,  // 25: accounts.ControlChange.kind:type_name -> accounts.ControlChange.Kind
+	1,  // 26: accounts.ControlChange.limit_type:type_name -> accounts.Limit.Type
+	2,  // 27: accounts.ControlChange.limit_window:type_name -> accounts.Limit.Window
+	30, // 28: accounts.ControlChange.until:type_name -> google.protobuf.Timestamp
+	30, // 29: accounts.ControlChange.effective_time:type_name -> google.protobuf.Timestamp
+	30, // 30: accounts.ControlChange.changed_time:type_name -> google.protobuf.Timestamp
+	4,  // 31: accounts.Accounts.CreateAccount:input_type -> accounts.CreateAccountRequest
+	6,  // 32: accounts.Accounts.GetBalance:input_type -> accounts.GetBalanceRequest
+	8,  // 33: accounts.Accounts.ListTransactions:input_type -> accounts.ListTransactionsRequest
+	14, // 34: accounts.Accounts.SetLimit:input_type -> accounts.SetLimitRequest
+	16, // 35: accounts.Accounts.GetControls:input_type -> accounts.GetControlsRequest
+	18, // 36: accounts.Accounts.StartCoolOff:input_type -> accounts.StartCoolOffRequest
+	20, // 37: accounts.Accounts.SelfExclude:input_type -> accounts.SelfExcludeRequest
+	22, // 38: accounts.Accounts.ListControlChanges:input_type -> accounts.ListControlChangesRequest
+	10, // 39: accounts.Accounts.Debit:input_type -> accounts.DebitRequest
+	12, // 40: accounts.Accounts.Credit:input_type -> accounts.CreditRequest
+	5,  // 41: accounts.Accounts.CreateAccount:output_type -> accounts.CreateAccountResponse
+	7,  // 42: accounts.Accounts.GetBalance:output_type -> accounts.GetBalanceResponse
+	9,  // 43: accounts.Accounts.ListTransactions:output_type -> accounts.ListTransactionsResponse
+	15, // 44: accounts.Accounts.SetLimit:output_type -> accounts.SetLimitResponse
+	17, // 45: accounts.Accounts.GetControls:output_type -> accounts.GetControlsResponse
+	19, // 46: accounts.Accounts.StartCoolOff:output_type -> accounts.StartCoolOffResponse
+	21, // 47: accounts.Accounts.SelfExclude:output_type -> accounts.SelfExcludeResponse
+	23, // 48: accounts.Accounts.ListControlChanges:output_type -> accounts.ListControlChangesResponse
+	11, // 49: accounts.Accounts.Debit:output_type -> accounts.DebitResponse
+	13, // 50: accounts.Accounts.Credit:output_type -> accounts.CreditResponse
+	41, // [41:51] is the sub-list for method output_type
+	31, // [31:41] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_accounts_accounts_proto_init() }
+func file_accounts_accounts_proto_init() {
+	if File_accounts_accounts_proto != nil {
+		return
+	}
+	file_accounts_accounts_proto_msgTypes[10].OneofWrappers = []any{}
+	file_accounts_accounts_proto_msgTypes[22].OneofWrappers = []any{}
+	file_accounts_accounts_proto_msgTypes[24].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_accounts_accounts_proto_rawDesc), len(file_accounts_accounts_proto_rawDesc)),
+			NumEnums:      4,
+			NumMessages:   25,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_accounts_accounts_proto_goTypes,
+		DependencyIndexes: file_accounts_accounts_proto_depIdxs,
+		EnumInfos:         file_accounts_accounts_proto_enumTypes,
+		MessageInfos:      file_accounts_accounts_proto_msgTypes,
+	}.Build()
+	File_accounts_accounts_proto = out.File
+	file_accounts_accounts_proto_goTypes = nil
+	file_accounts_accounts_proto_depIdxs = nil
+}
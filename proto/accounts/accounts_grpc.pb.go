@@ -0,0 +1,493 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: accounts/accounts.proto
+
+package accounts
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Accounts_CreateAccount_FullMethodName      = "/accounts.Accounts/CreateAccount"
+	Accounts_GetBalance_FullMethodName         = "/accounts.Accounts/GetBalance"
+	Accounts_ListTransactions_FullMethodName   = "/accounts.Accounts/ListTransactions"
+	Accounts_SetLimit_FullMethodName           = "/accounts.Accounts/SetLimit"
+	Accounts_GetControls_FullMethodName        = "/accounts.Accounts/GetControls"
+	Accounts_StartCoolOff_FullMethodName       = "/accounts.Accounts/StartCoolOff"
+	Accounts_SelfExclude_FullMethodName        = "/accounts.Accounts/SelfExclude"
+	Accounts_ListControlChanges_FullMethodName = "/accounts.Accounts/ListControlChanges"
+	Accounts_Debit_FullMethodName              = "/accounts.Accounts/Debit"
+	Accounts_Credit_FullMethodName             = "/accounts.Accounts/Credit"
+)
+
+// AccountsClient is the client API for Accounts service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AccountsClient interface {
+	// CreateAccount opens a new customer account with a zero balance.
+	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error)
+	// GetBalance returns the current balance of an account.
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// ListTransactions returns the transactions posted to an account, newest first.
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	// SetLimit sets or removes a deposit, loss or stake limit. Tightening takes
+	// effect immediately; loosening or removing waits out a mandatory delay.
+	SetLimit(ctx context.Context, in *SetLimitRequest, opts ...grpc.CallOption) (*SetLimitResponse, error)
+	// GetControls returns an account's limits, cool-off and self-exclusion.
+	GetControls(ctx context.Context, in *GetControlsRequest, opts ...grpc.CallOption) (*GetControlsResponse, error)
+	// StartCoolOff blocks betting and deposits for a period. It can be extended but not shortened.
+	StartCoolOff(ctx context.Context, in *StartCoolOffRequest, opts ...grpc.CallOption) (*StartCoolOffResponse, error)
+	// SelfExclude blocks betting and deposits for at least six months, or permanently.
+	SelfExclude(ctx context.Context, in *SelfExcludeRequest, opts ...grpc.CallOption) (*SelfExcludeResponse, error)
+	// ListControlChanges returns the audit trail of every limit, cool-off and exclusion change.
+	ListControlChanges(ctx context.Context, in *ListControlChangesRequest, opts ...grpc.CallOption) (*ListControlChangesResponse, error)
+	// Debit and Credit are internal to the betting and payments services and
+	// deliberately have no HTTP binding.
+	// Debit takes funds from an account for a withdrawal or bet stake. It fails
+	// rather than overdraw the account or breach its gambling controls, and
+	// replays are deduplicated by idempotency key.
+	Debit(ctx context.Context, in *DebitRequest, opts ...grpc.CallOption) (*DebitResponse, error)
+	// Credit pays funds into an account for a deposit, payout or refund.
+	// Replays are deduplicated by idempotency key.
+	Credit(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*CreditResponse, error)
+}
+
+type accountsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountsClient(cc grpc.ClientConnInterface) AccountsClient {
+	return &accountsClient{cc}
+}
+
+func (c *accountsClient) CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAccountResponse)
+	err := c.cc.Invoke(ctx, Accounts_CreateAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, Accounts_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, Accounts_ListTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) SetLimit(ctx context.Context, in *SetLimitRequest, opts ...grpc.CallOption) (*SetLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLimitResponse)
+	err := c.cc.Invoke(ctx, Accounts_SetLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) GetControls(ctx context.Context, in *GetControlsRequest, opts ...grpc.CallOption) (*GetControlsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetControlsResponse)
+	err := c.cc.Invoke(ctx, Accounts_GetControls_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) StartCoolOff(ctx context.Context, in *StartCoolOffRequest, opts ...grpc.CallOption) (*StartCoolOffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartCoolOffResponse)
+	err := c.cc.Invoke(ctx, Accounts_StartCoolOff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) SelfExclude(ctx context.Context, in *SelfExcludeRequest, opts ...grpc.CallOption) (*SelfExcludeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SelfExcludeResponse)
+	err := c.cc.Invoke(ctx, Accounts_SelfExclude_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) ListControlChanges(ctx context.Context, in *ListControlChangesRequest, opts ...grpc.CallOption) (*ListControlChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListControlChangesResponse)
+	err := c.cc.Invoke(ctx, Accounts_ListControlChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) Debit(ctx context.Context, in *DebitRequest, opts ...grpc.CallOption) (*DebitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DebitResponse)
+	err := c.cc.Invoke(ctx, Accounts_Debit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) Credit(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*CreditResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreditResponse)
+	err := c.cc.Invoke(ctx, Accounts_Credit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountsServer is the server API for Accounts service.
+// All implementations should embed UnimplementedAccountsServer
+// for forward compatibility.
+type AccountsServer interface {
+	// CreateAccount opens a new customer account with a zero balance.
+	CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error)
+	// GetBalance returns the current balance of an account.
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// ListTransactions returns the transactions posted to an account, newest first.
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	// SetLimit sets or removes a deposit, loss or stake limit. Tightening takes
+	// effect immediately; loosening or removing waits out a mandatory delay.
+	SetLimit(context.Context, *SetLimitRequest) (*SetLimitResponse, error)
+	// GetControls returns an account's limits, cool-off and self-exclusion.
+	GetControls(context.Context, *GetControlsRequest) (*GetControlsResponse, error)
+	// StartCoolOff blocks betting and deposits for a period. It can be extended but not shortened.
+	StartCoolOff(context.Context, *StartCoolOffRequest) (*StartCoolOffResponse, error)
+	// SelfExclude blocks betting and deposits for at least six months, or permanently.
+	SelfExclude(context.Context, *SelfExcludeRequest) (*SelfExcludeResponse, error)
+	// ListControlChanges returns the audit trail of every limit, cool-off and exclusion change.
+	ListControlChanges(context.Context, *ListControlChangesRequest) (*ListControlChangesResponse, error)
+	// Debit and Credit are internal to the betting and payments services and
+	// deliberately have no HTTP binding.
+	// Debit takes funds from an account for a withdrawal or bet stake. It fails
+	// rather than overdraw the account or breach its gambling controls, and
+	// replays are deduplicated by idempotency key.
+	Debit(context.Context, *DebitRequest) (*DebitResponse, error)
+	// Credit pays funds into an account for a deposit, payout or refund.
+	// Replays are deduplicated by idempotency key.
+	Credit(context.Context, *CreditRequest) (*CreditResponse, error)
+}
+
+// UnimplementedAccountsServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccountsServer struct{}
+
+func (UnimplementedAccountsServer) CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAccount not implemented")
+}
+func (UnimplementedAccountsServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedAccountsServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedAccountsServer) SetLimit(context.Context, *SetLimitRequest) (*SetLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLimit not implemented")
+}
+func (UnimplementedAccountsServer) GetControls(context.Context, *GetControlsRequest) (*GetControlsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetControls not implemented")
+}
+func (UnimplementedAccountsServer) StartCoolOff(context.Context, *StartCoolOffRequest) (*StartCoolOffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartCoolOff not implemented")
+}
+func (UnimplementedAccountsServer) SelfExclude(context.Context, *SelfExcludeRequest) (*SelfExcludeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelfExclude not implemented")
+}
+func (UnimplementedAccountsServer) ListControlChanges(context.Context, *ListControlChangesRequest) (*ListControlChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListControlChanges not implemented")
+}
+func (UnimplementedAccountsServer) Debit(context.Context, *DebitRequest) (*DebitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Debit not implemented")
+}
+func (UnimplementedAccountsServer) Credit(context.Context, *CreditRequest) (*CreditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Credit not implemented")
+}
+func (UnimplementedAccountsServer) testEmbeddedByValue() {}
+
+// UnsafeAccountsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountsServer will
+// result in compilation errors.
+type UnsafeAccountsServer interface {
+	mustEmbedUnimplementedAccountsServer()
+}
+
+func RegisterAccountsServer(s grpc.ServiceRegistrar, srv AccountsServer) {
+	// If the following call pancis, it indicates UnimplementedAccountsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Accounts_ServiceDesc, srv)
+}
+
+func _Accounts_CreateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).CreateAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_CreateAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).CreateAccount(ctx, req.(*CreateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_ListTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_SetLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).SetLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_SetLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).SetLimit(ctx, req.(*SetLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_GetControls_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetControlsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).GetControls(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_GetControls_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).GetControls(ctx, req.(*GetControlsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_StartCoolOff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartCoolOffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).StartCoolOff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_StartCoolOff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).StartCoolOff(ctx, req.(*StartCoolOffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_SelfExclude_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelfExcludeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).SelfExclude(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_SelfExclude_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).SelfExclude(ctx, req.(*SelfExcludeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_ListControlChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListControlChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).ListControlChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_ListControlChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).ListControlChanges(ctx, req.(*ListControlChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_Debit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).Debit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_Debit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).Debit(ctx, req.(*DebitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_Credit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).Credit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accounts_Credit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).Credit(ctx, req.(*CreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Accounts_ServiceDesc is the grpc.ServiceDesc for Accounts service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Accounts_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "accounts.Accounts",
+	HandlerType: (*AccountsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAccount",
+			Handler:    _Accounts_CreateAccount_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Accounts_GetBalance_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _Accounts_ListTransactions_Handler,
+		},
+		{
+			MethodName: "SetLimit",
+			Handler:    _Accounts_SetLimit_Handler,
+		},
+		{
+			MethodName: "GetControls",
+			Handler:    _Accounts_GetControls_Handler,
+		},
+		{
+			MethodName: "StartCoolOff",
+			Handler:    _Accounts_StartCoolOff_Handler,
+		},
+		{
+			MethodName: "SelfExclude",
+			Handler:    _Accounts_SelfExclude_Handler,
+		},
+		{
+			MethodName: "ListControlChanges",
+			Handler:    _Accounts_ListControlChanges_Handler,
+		},
+		{
+			MethodName: "Debit",
+			Handler:    _Accounts_Debit_Handler,
+		},
+		{
+			MethodName: "Credit",
+			Handler:    _Accounts_Credit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "accounts/accounts.proto",
+}
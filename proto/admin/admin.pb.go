@@ -0,0 +1,680 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: admin/admin.proto
+
+package admin
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Request for GetBuildInfo call.
+type GetBuildInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBuildInfoRequest) Reset() {
+	*x = GetBuildInfoRequest{}
+	mi := &file_admin_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBuildInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBuildInfoRequest) ProtoMessage() {}
+
+func (x *GetBuildInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBuildInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetBuildInfoRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{0}
+}
+
+// Response to GetBuildInfo call.
+type GetBuildInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Service is the name of the service, such as "racing".
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// GoVersion is the Go toolchain the binary was built with.
+	GoVersion string `protobuf:"bytes,2,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	// Path is the binary's main package path.
+	Path string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	// Revision is the VCS revision the binary was built from, if known.
+	Revision string `protobuf:"bytes,4,opt,name=revision,proto3" json:"revision,omitempty"`
+	// RevisionTime is when the revision was committed, if known.
+	RevisionTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=revision_time,json=revisionTime,proto3" json:"revision_time,omitempty"`
+	// Modified is set when the binary was built with uncommitted changes.
+	Modified bool `protobuf:"varint,6,opt,name=modified,proto3" json:"modified,omitempty"`
+	// StartTime is when the process started.
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBuildInfoResponse) Reset() {
+	*x = GetBuildInfoResponse{}
+	mi := &file_admin_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBuildInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBuildInfoResponse) ProtoMessage() {}
+
+func (x *GetBuildInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBuildInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetBuildInfoResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBuildInfoResponse) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *GetBuildInfoResponse) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *GetBuildInfoResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GetBuildInfoResponse) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *GetBuildInfoResponse) GetRevisionTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevisionTime
+	}
+	return nil
+}
+
+func (x *GetBuildInfoResponse) GetModified() bool {
+	if x != nil {
+		return x.Modified
+	}
+	return false
+}
+
+func (x *GetBuildInfoResponse) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+// Request for GetDBStats call.
+type GetDBStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDBStatsRequest) Reset() {
+	*x = GetDBStatsRequest{}
+	mi := &file_admin_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDBStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDBStatsRequest) ProtoMessage() {}
+
+func (x *GetDBStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDBStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetDBStatsRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{2}
+}
+
+// Response to GetDBStats call.
+type GetDBStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// MaxOpenConnections is the most connections the pool opens, 0 for no limit.
+	MaxOpenConnections int64 `protobuf:"varint,1,opt,name=max_open_connections,json=maxOpenConnections,proto3" json:"max_open_connections,omitempty"`
+	// OpenConnections are the connections open, in use or idle.
+	OpenConnections int64 `protobuf:"varint,2,opt,name=open_connections,json=openConnections,proto3" json:"open_connections,omitempty"`
+	// InUse are the connections in use.
+	InUse int64 `protobuf:"varint,3,opt,name=in_use,json=inUse,proto3" json:"in_use,omitempty"`
+	// Idle are the connections open but not in use.
+	Idle int64 `protobuf:"varint,4,opt,name=idle,proto3" json:"idle,omitempty"`
+	// WaitCount is how many times a query has waited for a connection.
+	WaitCount int64 `protobuf:"varint,5,opt,name=wait_count,json=waitCount,proto3" json:"wait_count,omitempty"`
+	// WaitDuration is how long queries have waited for connections in all.
+	WaitDuration *durationpb.Duration `protobuf:"bytes,6,opt,name=wait_duration,json=waitDuration,proto3" json:"wait_duration,omitempty"`
+	// TableRows are the rows in each table, by table name.
+	TableRows     map[string]int64 `protobuf:"bytes,7,rep,name=table_rows,json=tableRows,proto3" json:"table_rows,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDBStatsResponse) Reset() {
+	*x = GetDBStatsResponse{}
+	mi := &file_admin_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDBStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDBStatsResponse) ProtoMessage() {}
+
+func (x *GetDBStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDBStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetDBStatsResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetDBStatsResponse) GetMaxOpenConnections() int64 {
+	if x != nil {
+		return x.MaxOpenConnections
+	}
+	return 0
+}
+
+func (x *GetDBStatsResponse) GetOpenConnections() int64 {
+	if x != nil {
+		return x.OpenConnections
+	}
+	return 0
+}
+
+func (x *GetDBStatsResponse) GetInUse() int64 {
+	if x != nil {
+		return x.InUse
+	}
+	return 0
+}
+
+func (x *GetDBStatsResponse) GetIdle() int64 {
+	if x != nil {
+		return x.Idle
+	}
+	return 0
+}
+
+func (x *GetDBStatsResponse) GetWaitCount() int64 {
+	if x != nil {
+		return x.WaitCount
+	}
+	return 0
+}
+
+func (x *GetDBStatsResponse) GetWaitDuration() *durationpb.Duration {
+	if x != nil {
+		return x.WaitDuration
+	}
+	return nil
+}
+
+func (x *GetDBStatsResponse) GetTableRows() map[string]int64 {
+	if x != nil {
+		return x.TableRows
+	}
+	return nil
+}
+
+// Request for Reseed call.
+type ReseedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReseedRequest) Reset() {
+	*x = ReseedRequest{}
+	mi := &file_admin_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReseedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReseedRequest) ProtoMessage() {}
+
+func (x *ReseedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReseedRequest.ProtoReflect.Descriptor instead.
+func (*ReseedRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{4}
+}
+
+// Response to Reseed call.
+type ReseedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReseedResponse) Reset() {
+	*x = ReseedResponse{}
+	mi := &file_admin_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReseedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReseedResponse) ProtoMessage() {}
+
+func (x *ReseedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReseedResponse.ProtoReflect.Descriptor instead.
+func (*ReseedResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{5}
+}
+
+// Request for GetLogLevel call.
+type GetLogLevelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLogLevelRequest) Reset() {
+	*x = GetLogLevelRequest{}
+	mi := &file_admin_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLogLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogLevelRequest) ProtoMessage() {}
+
+func (x *GetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*GetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{6}
+}
+
+// Response to GetLogLevel call.
+type GetLogLevelResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Level is the minimum level logged, with any per package levels, such as
+	// "info,racing/db=debug".
+	Level         string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLogLevelResponse) Reset() {
+	*x = GetLogLevelResponse{}
+	mi := &file_admin_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLogLevelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogLevelResponse) ProtoMessage() {}
+
+func (x *GetLogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogLevelResponse.ProtoReflect.Descriptor instead.
+func (*GetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetLogLevelResponse) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+// Request for SetLogLevel call.
+type SetLogLevelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Level is the minimum level to log, optionally per package, as the
+	// -log-level flag takes it, such as "info,racing/db=debug".
+	Level         string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLogLevelRequest) Reset() {
+	*x = SetLogLevelRequest{}
+	mi := &file_admin_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLogLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelRequest) ProtoMessage() {}
+
+func (x *SetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetLogLevelRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+// Response to SetLogLevel call.
+type SetLogLevelResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Level is the minimum level now logged.
+	Level string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	// Previous is the minimum level logged before.
+	Previous      string `protobuf:"bytes,2,opt,name=previous,proto3" json:"previous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLogLevelResponse) Reset() {
+	*x = SetLogLevelResponse{}
+	mi := &file_admin_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLogLevelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelResponse) ProtoMessage() {}
+
+func (x *SetLogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelResponse.ProtoReflect.Descriptor instead.
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_admin_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetLogLevelResponse) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *SetLogLevelResponse) GetPrevious() string {
+	if x != nil {
+		return x.Previous
+	}
+	return ""
+}
+
+var File_admin_admin_proto protoreflect.FileDescriptor
+
+const file_admin_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x11admin/admin.proto\x12\x05admin\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x15\n" +
+	"\x13GetBuildInfoRequest\"\x97\x02\n" +
+	"\x14GetBuildInfoResponse\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x02 \x01(\tR\tgoVersion\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x1a\n" +
+	"\brevision\x18\x04 \x01(\tR\brevision\x12?\n" +
+	"\rrevision_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\frevisionTime\x12\x1a\n" +
+	"\bmodified\x18\x06 \x01(\bR\bmodified\x129\n" +
+	"\n" +
+	"start_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\"\x13\n" +
+	"\x11GetDBStatsRequest\"\x82\x03\n" +
+	"\x12GetDBStatsResponse\x120\n" +
+	"\x14max_open_connections\x18\x01 \x01(\x03R\x12maxOpenConnections\x12)\n" +
+	"\x10open_connections\x18\x02 \x01(\x03R\x0fopenConnections\x12\x15\n" +
+	"\x06in_use\x18\x03 \x01(\x03R\x05inUse\x12\x12\n" +
+	"\x04idle\x18\x04 \x01(\x03R\x04idle\x12\x1d\n" +
+	"\n" +
+	"wait_count\x18\x05 \x01(\x03R\twaitCount\x12>\n" +
+	"\rwait_duration\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\fwaitDuration\x12G\n" +
+	"\n" +
+	"table_rows\x18\a \x03(\v2(.admin.GetDBStatsResponse.TableRowsEntryR\ttableRows\x1a<\n" +
+	"\x0eTableRowsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x0f\n" +
+	"\rReseedRequest\"\x10\n" +
+	"\x0eReseedResponse\"\x14\n" +
+	"\x12GetLogLevelRequest\"+\n" +
+	"\x13GetLogLevelResponse\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\"*\n" +
+	"\x12SetLogLevelRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\"G\n" +
+	"\x13SetLogLevelResponse\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x1a\n" +
+	"\bprevious\x18\x02 \x01(\tR\bprevious2\xd6\x02\n" +
+	"\x05Admin\x12G\n" +
+	"\fGetBuildInfo\x12\x1a.admin.GetBuildInfoRequest\x1a\x1b.admin.GetBuildInfoResponse\x12A\n" +
+	"\n" +
+	"GetDBStats\x12\x18.admin.GetDBStatsRequest\x1a\x19.admin.GetDBStatsResponse\x125\n" +
+	"\x06Reseed\x12\x14.admin.ReseedRequest\x1a\x15.admin.ReseedResponse\x12D\n" +
+	"\vGetLogLevel\x12\x19.admin.GetLogLevelRequest\x1a\x1a.admin.GetLogLevelResponse\x12D\n" +
+	"\vSetLogLevel\x12\x19.admin.SetLogLevelRequest\x1a\x1a.admin.SetLogLevelResponseB,Z*git.neds.sh/matty/entain/proto/admin;adminb\x06proto3"
+
+var (
+	file_admin_admin_proto_rawDescOnce sync.Once
+	file_admin_admin_proto_rawDescData []byte
+)
+
+func file_admin_admin_proto_rawDescGZIP() []byte {
+	file_admin_admin_proto_rawDescOnce.Do(func() {
+		file_admin_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_admin_admin_proto_rawDesc), len(file_admin_admin_proto_rawDesc)))
+	})
+	return file_admin_admin_proto_rawDescData
+}
+
+var file_admin_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_admin_admin_proto_goTypes = []any{
+	(*GetBuildInfoRequest)(nil),   // 0: admin.GetBuildInfoRequest
+	(*GetBuildInfoResponse)(nil),  // 1: admin.GetBuildInfoResponse
+	(*GetDBStatsRequest)(nil),     // 2: admin.GetDBStatsRequest
+	(*GetDBStatsResponse)(nil),    // 3: admin.GetDBStatsResponse
+	(*ReseedRequest)(nil),         // 4: admin.ReseedRequest
+	(*ReseedResponse)(nil),        // 5: admin.ReseedResponse
+	(*GetLogLevelRequest)(nil),    // 6: admin.GetLogLevelRequest
+	(*GetLogLevelResponse)(nil),   // 7: admin.GetLogLevelResponse
+	(*SetLogLevelRequest)(nil),    // 8: admin.SetLogLevelRequest
+	(*SetLogLevelResponse)(nil),   // 9: admin.SetLogLevelResponse
+	nil,                           // 10: admin.GetDBStatsResponse.TableRowsEntry
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 12: google.protobuf.Duration
+}
+var file_admin_admin_proto_depIdxs = []int32{
+	11, // 0: admin.GetBuildInfoResponse.revision_time:type_name -> google.protobuf.Timestamp
+	11, // 1: admin.GetBuildInfoResponse.start_time:type_name -> google.protobuf.Timestamp
+	12, // 2: admin.GetDBStatsResponse.wait_duration:type_name -> google.protobuf.Duration
+	10, // 3: admin.GetDBStatsResponse.table_rows:type_name -> admin.GetDBStatsResponse.TableRowsEntry
+	0,  // 4: admin.Admin.GetBuildInfo:input_type -> admin.GetBuildInfoRequest
+	2,  // 5: admin.Admin.GetDBStats:input_type -> admin.GetDBStatsRequest
+	4,  // 6: admin.Admin.Reseed:input_type -> admin.ReseedRequest
+	6,  // 7: admin.Admin.GetLogLevel:input_type -> admin.GetLogLevelRequest
+	8,  // 8: admin.Admin.SetLogLevel:input_type -> admin.SetLogLevelRequest
+	1,  // 9: admin.Admin.GetBuildInfo:output_type -> admin.GetBuildInfoResponse
+	3,  // 10: admin.Admin.GetDBStats:output_type -> admin.GetDBStatsResponse
+	5,  // 11: admin.Admin.Reseed:output_type -> admin.ReseedResponse
+	7,  // 12: admin.Admin.GetLogLevel:output_type -> admin.GetLogLevelResponse
+	9,  // 13: admin.Admin.SetLogLevel:output_type -> admin.SetLogLevelResponse
+	9,  // [9:14] is the sub-list for method output_type
+	4,  // [4:9] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_admin_admin_proto_init() }
+func file_admin_admin_proto_init() {
+	if File_admin_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_admin_admin_proto_rawDesc), len(file_admin_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_admin_admin_proto_goTypes,
+		DependencyIndexes: file_admin_admin_proto_depIdxs,
+		MessageInfos:      file_admin_admin_proto_msgTypes,
+	}.Build()
+	File_admin_admin_proto = out.File
+	file_admin_admin_proto_goTypes = nil
+	file_admin_admin_proto_depIdxs = nil
+}
@@ -0,0 +1,854 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: betting/betting.proto
+
+package betting
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type is the exotic pool the bet is placed into.
+type Bet_Type int32
+
+const (
+	Bet_TYPE_UNSPECIFIED Bet_Type = 0
+	// First two in any order.
+	Bet_TYPE_QUINELLA Bet_Type = 1
+	// First and second in correct order.
+	Bet_TYPE_EXACTA Bet_Type = 2
+	// First, second and third in correct order.
+	Bet_TYPE_TRIFECTA Bet_Type = 3
+	// First four in correct order.
+	Bet_TYPE_FIRST_FOUR Bet_Type = 4
+)
+
+// Enum value maps for Bet_Type.
+var (
+	Bet_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_QUINELLA",
+		2: "TYPE_EXACTA",
+		3: "TYPE_TRIFECTA",
+		4: "TYPE_FIRST_FOUR",
+	}
+	Bet_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_QUINELLA":    1,
+		"TYPE_EXACTA":      2,
+		"TYPE_TRIFECTA":    3,
+		"TYPE_FIRST_FOUR":  4,
+	}
+)
+
+func (x Bet_Type) Enum() *Bet_Type {
+	p := new(Bet_Type)
+	*p = x
+	return p
+}
+
+func (x Bet_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Bet_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_betting_betting_proto_enumTypes[0].Descriptor()
+}
+
+func (Bet_Type) Type() protoreflect.EnumType {
+	return &file_betting_betting_proto_enumTypes[0]
+}
+
+func (x Bet_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Bet_Type.Descriptor instead.
+func (Bet_Type) EnumDescriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{8, 0}
+}
+
+// Status tracks the bet through settlement.
+type Bet_Status int32
+
+const (
+	Bet_STATUS_PENDING Bet_Status = 0
+	Bet_STATUS_WON     Bet_Status = 1
+	Bet_STATUS_LOST    Bet_Status = 2
+	// Every combination was scratched, so the stake is returned.
+	Bet_STATUS_REFUNDED Bet_Status = 3
+)
+
+// Enum value maps for Bet_Status.
+var (
+	Bet_Status_name = map[int32]string{
+		0: "STATUS_PENDING",
+		1: "STATUS_WON",
+		2: "STATUS_LOST",
+		3: "STATUS_REFUNDED",
+	}
+	Bet_Status_value = map[string]int32{
+		"STATUS_PENDING":  0,
+		"STATUS_WON":      1,
+		"STATUS_LOST":     2,
+		"STATUS_REFUNDED": 3,
+	}
+)
+
+func (x Bet_Status) Enum() *Bet_Status {
+	p := new(Bet_Status)
+	*p = x
+	return p
+}
+
+func (x Bet_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Bet_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_betting_betting_proto_enumTypes[1].Descriptor()
+}
+
+func (Bet_Status) Type() protoreflect.EnumType {
+	return &file_betting_betting_proto_enumTypes[1]
+}
+
+func (x Bet_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Bet_Status.Descriptor instead.
+func (Bet_Status) EnumDescriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{8, 1}
+}
+
+// Request for PlaceBet call.
+type PlaceBetRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	RaceId int64                  `protobuf:"varint,1,opt,name=race_id,json=raceId,proto3" json:"race_id,omitempty"`
+	Type   Bet_Type               `protobuf:"varint,2,opt,name=type,proto3,enum=betting.Bet_Type" json:"type,omitempty"`
+	// Boxed bets take a single leg whose runners may fill any placing.
+	Boxed bool `protobuf:"varint,3,opt,name=boxed,proto3" json:"boxed,omitempty"`
+	// Legs hold the runner numbers selected for each placing, in finishing order.
+	Legs []*Leg `protobuf:"bytes,4,rep,name=legs,proto3" json:"legs,omitempty"`
+	// StakeCents is the total outlay, spread flexi across every combination.
+	StakeCents int64 `protobuf:"varint,5,opt,name=stake_cents,json=stakeCents,proto3" json:"stake_cents,omitempty"`
+	// AccountID is the customer account the stake is debited from.
+	AccountId int64 `protobuf:"varint,6,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// IdempotencyKey identifies the placement; retrying a key returns the original bet.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PlaceBetRequest) Reset() {
+	*x = PlaceBetRequest{}
+	mi := &file_betting_betting_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceBetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceBetRequest) ProtoMessage() {}
+
+func (x *PlaceBetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceBetRequest.ProtoReflect.Descriptor instead.
+func (*PlaceBetRequest) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PlaceBetRequest) GetRaceId() int64 {
+	if x != nil {
+		return x.RaceId
+	}
+	return 0
+}
+
+func (x *PlaceBetRequest) GetType() Bet_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Bet_TYPE_UNSPECIFIED
+}
+
+func (x *PlaceBetRequest) GetBoxed() bool {
+	if x != nil {
+		return x.Boxed
+	}
+	return false
+}
+
+func (x *PlaceBetRequest) GetLegs() []*Leg {
+	if x != nil {
+		return x.Legs
+	}
+	return nil
+}
+
+func (x *PlaceBetRequest) GetStakeCents() int64 {
+	if x != nil {
+		return x.StakeCents
+	}
+	return 0
+}
+
+func (x *PlaceBetRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *PlaceBetRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// Response to PlaceBet call.
+type PlaceBetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bet           *Bet                   `protobuf:"bytes,1,opt,name=bet,proto3" json:"bet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceBetResponse) Reset() {
+	*x = PlaceBetResponse{}
+	mi := &file_betting_betting_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceBetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceBetResponse) ProtoMessage() {}
+
+func (x *PlaceBetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceBetResponse.ProtoReflect.Descriptor instead.
+func (*PlaceBetResponse) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlaceBetResponse) GetBet() *Bet {
+	if x != nil {
+		return x.Bet
+	}
+	return nil
+}
+
+// Request for GetBet call.
+type GetBetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBetRequest) Reset() {
+	*x = GetBetRequest{}
+	mi := &file_betting_betting_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBetRequest) ProtoMessage() {}
+
+func (x *GetBetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBetRequest.ProtoReflect.Descriptor instead.
+func (*GetBetRequest) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBetRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Response to GetBet call.
+type GetBetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bet           *Bet                   `protobuf:"bytes,1,opt,name=bet,proto3" json:"bet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBetResponse) Reset() {
+	*x = GetBetResponse{}
+	mi := &file_betting_betting_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBetResponse) ProtoMessage() {}
+
+func (x *GetBetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBetResponse.ProtoReflect.Descriptor instead.
+func (*GetBetResponse) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBetResponse) GetBet() *Bet {
+	if x != nil {
+		return x.Bet
+	}
+	return nil
+}
+
+// Request for SettleRace call.
+type SettleRaceRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	RaceId int64                  `protobuf:"varint,1,opt,name=race_id,json=raceId,proto3" json:"race_id,omitempty"`
+	// Dividends declared for each exotic pool, per $1 unit.
+	Dividends     []*Dividend `protobuf:"bytes,2,rep,name=dividends,proto3" json:"dividends,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SettleRaceRequest) Reset() {
+	*x = SettleRaceRequest{}
+	mi := &file_betting_betting_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettleRaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettleRaceRequest) ProtoMessage() {}
+
+func (x *SettleRaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettleRaceRequest.ProtoReflect.Descriptor instead.
+func (*SettleRaceRequest) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SettleRaceRequest) GetRaceId() int64 {
+	if x != nil {
+		return x.RaceId
+	}
+	return 0
+}
+
+func (x *SettleRaceRequest) GetDividends() []*Dividend {
+	if x != nil {
+		return x.Dividends
+	}
+	return nil
+}
+
+// Response to SettleRace call.
+type SettleRaceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bets settled by this call.
+	Bets          []*Bet `protobuf:"bytes,1,rep,name=bets,proto3" json:"bets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SettleRaceResponse) Reset() {
+	*x = SettleRaceResponse{}
+	mi := &file_betting_betting_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettleRaceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettleRaceResponse) ProtoMessage() {}
+
+func (x *SettleRaceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettleRaceResponse.ProtoReflect.Descriptor instead.
+func (*SettleRaceResponse) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SettleRaceResponse) GetBets() []*Bet {
+	if x != nil {
+		return x.Bets
+	}
+	return nil
+}
+
+// A leg of an exotic bet: the runners selected for one placing.
+type Leg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunnerNumbers []int64                `protobuf:"varint,1,rep,packed,name=runner_numbers,json=runnerNumbers,proto3" json:"runner_numbers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Leg) Reset() {
+	*x = Leg{}
+	mi := &file_betting_betting_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Leg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Leg) ProtoMessage() {}
+
+func (x *Leg) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Leg.ProtoReflect.Descriptor instead.
+func (*Leg) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Leg) GetRunnerNumbers() []int64 {
+	if x != nil {
+		return x.RunnerNumbers
+	}
+	return nil
+}
+
+// A dividend declared for an exotic pool.
+type Dividend struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  Bet_Type               `protobuf:"varint,1,opt,name=type,proto3,enum=betting.Bet_Type" json:"type,omitempty"`
+	// AmountCents is the return for a $1 unit, including the stake.
+	AmountCents   int64 `protobuf:"varint,2,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dividend) Reset() {
+	*x = Dividend{}
+	mi := &file_betting_betting_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dividend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dividend) ProtoMessage() {}
+
+func (x *Dividend) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dividend.ProtoReflect.Descriptor instead.
+func (*Dividend) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Dividend) GetType() Bet_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Bet_TYPE_UNSPECIFIED
+}
+
+func (x *Dividend) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// An exotic bet resource.
+type Bet struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID represents a unique identifier for the bet.
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// RaceID is the race the bet is placed on.
+	RaceId     int64    `protobuf:"varint,2,opt,name=race_id,json=raceId,proto3" json:"race_id,omitempty"`
+	Type       Bet_Type `protobuf:"varint,3,opt,name=type,proto3,enum=betting.Bet_Type" json:"type,omitempty"`
+	Boxed      bool     `protobuf:"varint,4,opt,name=boxed,proto3" json:"boxed,omitempty"`
+	Legs       []*Leg   `protobuf:"bytes,5,rep,name=legs,proto3" json:"legs,omitempty"`
+	StakeCents int64    `protobuf:"varint,6,opt,name=stake_cents,json=stakeCents,proto3" json:"stake_cents,omitempty"`
+	// Combinations is the number of distinct finishing orders covered.
+	Combinations int64 `protobuf:"varint,7,opt,name=combinations,proto3" json:"combinations,omitempty"`
+	// FlexiPercent is the share of a $1 unit held in each combination.
+	FlexiPercent float64    `protobuf:"fixed64,8,opt,name=flexi_percent,json=flexiPercent,proto3" json:"flexi_percent,omitempty"`
+	Status       Bet_Status `protobuf:"varint,9,opt,name=status,proto3,enum=betting.Bet_Status" json:"status,omitempty"`
+	// PayoutCents is the return paid on settlement.
+	PayoutCents int64 `protobuf:"varint,10,opt,name=payout_cents,json=payoutCents,proto3" json:"payout_cents,omitempty"`
+	// PlacedTime is when the bet was accepted.
+	PlacedTime *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=placed_time,json=placedTime,proto3" json:"placed_time,omitempty"`
+	// AccountID is the customer account that placed the bet and receives any payout.
+	AccountId     int64 `protobuf:"varint,12,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bet) Reset() {
+	*x = Bet{}
+	mi := &file_betting_betting_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bet) ProtoMessage() {}
+
+func (x *Bet) ProtoReflect() protoreflect.Message {
+	mi := &file_betting_betting_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bet.ProtoReflect.Descriptor instead.
+func (*Bet) Descriptor() ([]byte, []int) {
+	return file_betting_betting_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Bet) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Bet) GetRaceId() int64 {
+	if x != nil {
+		return x.RaceId
+	}
+	return 0
+}
+
+func (x *Bet) GetType() Bet_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Bet_TYPE_UNSPECIFIED
+}
+
+func (x *Bet) GetBoxed() bool {
+	if x != nil {
+		return x.Boxed
+	}
+	return false
+}
+
+func (x *Bet) GetLegs() []*Leg {
+	if x != nil {
+		return x.Legs
+	}
+	return nil
+}
+
+func (x *Bet) GetStakeCents() int64 {
+	if x != nil {
+		return x.StakeCents
+	}
+	return 0
+}
+
+func (x *Bet) GetCombinations() int64 {
+	if x != nil {
+		return x.Combinations
+	}
+	return 0
+}
+
+func (x *Bet) GetFlexiPercent() float64 {
+	if x != nil {
+		return x.FlexiPercent
+	}
+	return 0
+}
+
+func (x *Bet) GetStatus() Bet_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Bet_STATUS_PENDING
+}
+
+func (x *Bet) GetPayoutCents() int64 {
+	if x != nil {
+		return x.PayoutCents
+	}
+	return 0
+}
+
+func (x *Bet) GetPlacedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PlacedTime
+	}
+	return nil
+}
+
+func (x *Bet) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+var File_betting_betting_proto protoreflect.FileDescriptor
+
+const file_betting_betting_proto_rawDesc = "" +
+	"\n" +
+	"\x15betting/betting.proto\x12\abetting\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\"\xf7\x01\n" +
+	"\x0fPlaceBetRequest\x12\x17\n" +
+	"\arace_id\x18\x01 \x01(\x03R\x06raceId\x12%\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x11.betting.Bet.TypeR\x04type\x12\x14\n" +
+	"\x05boxed\x18\x03 \x01(\bR\x05boxed\x12 \n" +
+	"\x04legs\x18\x04 \x03(\v2\f.betting.LegR\x04legs\x12\x1f\n" +
+	"\vstake_cents\x18\x05 \x01(\x03R\n" +
+	"stakeCents\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x06 \x01(\x03R\taccountId\x12,\n" +
+	"\x0fidempotency_key\x18\a \x01(\tB\x03\x80\x01\x01R\x0eidempotencyKey\"2\n" +
+	"\x10PlaceBetResponse\x12\x1e\n" +
+	"\x03bet\x18\x01 \x01(\v2\f.betting.BetR\x03bet\"\x1f\n" +
+	"\rGetBetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"0\n" +
+	"\x0eGetBetResponse\x12\x1e\n" +
+	"\x03bet\x18\x01 \x01(\v2\f.betting.BetR\x03bet\"]\n" +
+	"\x11SettleRaceRequest\x12\x17\n" +
+	"\arace_id\x18\x01 \x01(\x03R\x06raceId\x12/\n" +
+	"\tdividends\x18\x02 \x03(\v2\x11.betting.DividendR\tdividends\"6\n" +
+	"\x12SettleRaceResponse\x12 \n" +
+	"\x04bets\x18\x01 \x03(\v2\f.betting.BetR\x04bets\",\n" +
+	"\x03Leg\x12%\n" +
+	"\x0erunner_numbers\x18\x01 \x03(\x03R\rrunnerNumbers\"T\n" +
+	"\bDividend\x12%\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x11.betting.Bet.TypeR\x04type\x12!\n" +
+	"\famount_cents\x18\x02 \x01(\x03R\vamountCents\"\xe1\x04\n" +
+	"\x03Bet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\arace_id\x18\x02 \x01(\x03R\x06raceId\x12%\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x11.betting.Bet.TypeR\x04type\x12\x14\n" +
+	"\x05boxed\x18\x04 \x01(\bR\x05boxed\x12 \n" +
+	"\x04legs\x18\x05 \x03(\v2\f.betting.LegR\x04legs\x12\x1f\n" +
+	"\vstake_cents\x18\x06 \x01(\x03R\n" +
+	"stakeCents\x12\"\n" +
+	"\fcombinations\x18\a \x01(\x03R\fcombinations\x12#\n" +
+	"\rflexi_percent\x18\b \x01(\x01R\fflexiPercent\x12+\n" +
+	"\x06status\x18\t \x01(\x0e2\x13.betting.Bet.StatusR\x06status\x12!\n" +
+	"\fpayout_cents\x18\n" +
+	" \x01(\x03R\vpayoutCents\x12;\n" +
+	"\vplaced_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"placedTime\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\f \x01(\x03R\taccountId\"h\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rTYPE_QUINELLA\x10\x01\x12\x0f\n" +
+	"\vTYPE_EXACTA\x10\x02\x12\x11\n" +
+	"\rTYPE_TRIFECTA\x10\x03\x12\x13\n" +
+	"\x0fTYPE_FIRST_FOUR\x10\x04\"R\n" +
+	"\x06Status\x12\x12\n" +
+	"\x0eSTATUS_PENDING\x10\x00\x12\x0e\n" +
+	"\n" +
+	"STATUS_WON\x10\x01\x12\x0f\n" +
+	"\vSTATUS_LOST\x10\x02\x12\x13\n" +
+	"\x0fSTATUS_REFUNDED\x10\x032\x9f\x02\n" +
+	"\aBetting\x12T\n" +
+	"\bPlaceBet\x12\x18.betting.PlaceBetRequest\x1a\x19.betting.PlaceBetResponse\"\x13\x82\xd3\xe4\x93\x02\r:\x01*\"\b/v1/bets\x12P\n" +
+	"\x06GetBet\x12\x16.betting.GetBetRequest\x1a\x17.betting.GetBetResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/bets/{id}\x12l\n" +
+	"\n" +
+	"SettleRace\x12\x1a.betting.SettleRaceRequest\x1a\x1b.betting.SettleRaceResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/races/{race_id}/settleB0Z.git.neds.sh/matty/entain/proto/betting;bettingb\x06proto3"
+
+var (
+	file_betting_betting_proto_rawDescOnce sync.Once
+	file_betting_betting_proto_rawDescData []byte
+)
+
+func file_betting_betting_proto_rawDescGZIP() []byte {
+	file_betting_betting_proto_rawDescOnce.Do(func() {
+		file_betting_betting_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_betting_betting_proto_rawDesc), len(file_betting_betting_proto_rawDesc)))
+	})
+	return file_betting_betting_proto_rawDescData
+}
+
+var file_betting_betting_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_betting_betting_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_betting_betting_proto_goTypes = []any{
+	(Bet_Type)(0),                 // 0: betting.Bet.Type
+	(Bet_Status)(0),               // 1: betting.Bet.Status
+	(*PlaceBetRequest)(nil),       // 2: betting.PlaceBetRequest
+	(*PlaceBetResponse)(nil),      // 3: betting.PlaceBetResponse
+	(*GetBetRequest)(nil),         // 4: betting.GetBetRequest
+	(*GetBetResponse)(nil),        // 5: betting.GetBetResponse
+	(*SettleRaceRequest)(nil),     // 6: betting.SettleRaceRequest
+	(*SettleRaceResponse)(nil),    // 7: betting.SettleRaceResponse
+	(*Leg)(nil),                   // 8: betting.Leg
+	(*Dividend)(nil),              // 9: betting.Dividend
+	(*Bet)(nil),                   // 10: betting.Bet
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_betting_betting_proto_depIdxs = []int32{
+	0,  // 0: betting.PlaceBetRequest.type:type_name -> betting.Bet.Type
+	8,  // 1: betting.PlaceBetRequest.legs:type_name -> betting.Leg
+	10, // 2: betting.PlaceBetResponse.bet:type_name -> betting.Bet
+	10, // 3: betting.GetBetResponse.bet:type_name -> betting.Bet
+	9,  // 4: betting.SettleRaceRequest.dividends:type_name -> betting.Dividend
+	10, // 5: betting.SettleRaceResponse.bets:type_name -> betting.Bet
+	0,  // 6: betting.Dividend.type:type_name -> betting.Bet.Type
+	0,  // 7: betting.Bet.type:type_name -> betting.Bet.Type
+	8,  // 8: betting.Bet.legs:type_name -> betting.Leg
+	1,  // 9: betting.Bet.status:type_name -> betting.Bet.Status
+	11, // 10: betting.Bet.placed_time:type_name -> google.protobuf.Timestamp
+	2,  // 11: betting.Betting.PlaceBet:input_type -> betting.PlaceBetRequest
+	4,  // 12: betting.Betting.GetBet:input_type -> betting.GetBetRequest
+	6,  // 13: betting.Betting.SettleRace:input_type -> betting.SettleRaceRequest
+	3,  // 14: betting.Betting.PlaceBet:output_type -> betting.PlaceBetResponse
+	5,  // 15: betting.Betting.GetBet:output_type -> betting.GetBetResponse
+	7,  // 16: betting.Betting.SettleRace:output_type -> betting.SettleRaceResponse
+	14, // [14:17] is the sub-list for method output_type
+	11, // [11:14] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_betting_betting_proto_init() }
+func file_betting_betting_proto_init() {
+	if File_betting_betting_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_betting_betting_proto_rawDesc), len(file_betting_betting_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_betting_betting_proto_goTypes,
+		DependencyIndexes: file_betting_betting_proto_depIdxs,
+		EnumInfos:         file_betting_betting_proto_enumTypes,
+		MessageInfos:      file_betting_betting_proto_msgTypes,
+	}.Build()
+	File_betting_betting_proto = out.File
+	file_betting_betting_proto_goTypes = nil
+	file_betting_betting_proto_depIdxs = nil
+}
@@ -0,0 +1,201 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: betting/betting.proto
+
+package betting
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Betting_PlaceBet_FullMethodName   = "/betting.Betting/PlaceBet"
+	Betting_GetBet_FullMethodName     = "/betting.Betting/GetBet"
+	Betting_SettleRace_FullMethodName = "/betting.Betting/SettleRace"
+)
+
+// BettingClient is the client API for Betting service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BettingClient interface {
+	// PlaceBet places an exotic bet against the runners of an open race.
+	PlaceBet(ctx context.Context, in *PlaceBetRequest, opts ...grpc.CallOption) (*PlaceBetResponse, error)
+	// GetBet returns a single bet by ID.
+	GetBet(ctx context.Context, in *GetBetRequest, opts ...grpc.CallOption) (*GetBetResponse, error)
+	// SettleRace settles all pending bets on a race from its official result placings.
+	SettleRace(ctx context.Context, in *SettleRaceRequest, opts ...grpc.CallOption) (*SettleRaceResponse, error)
+}
+
+type bettingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBettingClient(cc grpc.ClientConnInterface) BettingClient {
+	return &bettingClient{cc}
+}
+
+func (c *bettingClient) PlaceBet(ctx context.Context, in *PlaceBetRequest, opts ...grpc.CallOption) (*PlaceBetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaceBetResponse)
+	err := c.cc.Invoke(ctx, Betting_PlaceBet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bettingClient) GetBet(ctx context.Context, in *GetBetRequest, opts ...grpc.CallOption) (*GetBetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBetResponse)
+	err := c.cc.Invoke(ctx, Betting_GetBet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bettingClient) SettleRace(ctx context.Context, in *SettleRaceRequest, opts ...grpc.CallOption) (*SettleRaceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SettleRaceResponse)
+	err := c.cc.Invoke(ctx, Betting_SettleRace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BettingServer is the server API for Betting service.
+// All implementations should embed UnimplementedBettingServer
+// for forward compatibility.
+type BettingServer interface {
+	// PlaceBet places an exotic bet against the runners of an open race.
+	PlaceBet(context.Context, *PlaceBetRequest) (*PlaceBetResponse, error)
+	// GetBet returns a single bet by ID.
+	GetBet(context.Context, *GetBetRequest) (*GetBetResponse, error)
+	// SettleRace settles all pending bets on a race from its official result placings.
+	SettleRace(context.Context, *SettleRaceRequest) (*SettleRaceResponse, error)
+}
+
+// UnimplementedBettingServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBettingServer struct{}
+
+func (UnimplementedBettingServer) PlaceBet(context.Context, *PlaceBetRequest) (*PlaceBetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceBet not implemented")
+}
+func (UnimplementedBettingServer) GetBet(context.Context, *GetBetRequest) (*GetBetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBet not implemented")
+}
+func (UnimplementedBettingServer) SettleRace(context.Context, *SettleRaceRequest) (*SettleRaceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SettleRace not implemented")
+}
+func (UnimplementedBettingServer) testEmbeddedByValue() {}
+
+// UnsafeBettingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BettingServer will
+// result in compilation errors.
+type UnsafeBettingServer interface {
+	mustEmbedUnimplementedBettingServer()
+}
+
+func RegisterBettingServer(s grpc.ServiceRegistrar, srv BettingServer) {
+	// If the following call pancis, it indicates UnimplementedBettingServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Betting_ServiceDesc, srv)
+}
+
+func _Betting_PlaceBet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceBetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BettingServer).PlaceBet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Betting_PlaceBet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BettingServer).PlaceBet(ctx, req.(*PlaceBetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Betting_GetBet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BettingServer).GetBet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Betting_GetBet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BettingServer).GetBet(ctx, req.(*GetBetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Betting_SettleRace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SettleRaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BettingServer).SettleRace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Betting_SettleRace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BettingServer).SettleRace(ctx, req.(*SettleRaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Betting_ServiceDesc is the grpc.ServiceDesc for Betting service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Betting_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "betting.Betting",
+	HandlerType: (*BettingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PlaceBet",
+			Handler:    _Betting_PlaceBet_Handler,
+		},
+		{
+			MethodName: "GetBet",
+			Handler:    _Betting_GetBet_Handler,
+		},
+		{
+			MethodName: "SettleRace",
+			Handler:    _Betting_SettleRace_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "betting/betting.proto",
+}
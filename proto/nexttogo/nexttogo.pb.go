@@ -0,0 +1,387 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: nexttogo/nexttogo.proto
+
+package nexttogo
+
+import (
+	racing "git.neds.sh/matty/entain/proto/racing"
+	sports "git.neds.sh/matty/entain/proto/sports"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type says which of race and event is set.
+type Item_Type int32
+
+const (
+	Item_TYPE_UNSPECIFIED Item_Type = 0
+	Item_TYPE_RACE        Item_Type = 1
+	Item_TYPE_EVENT       Item_Type = 2
+)
+
+// Enum value maps for Item_Type.
+var (
+	Item_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_RACE",
+		2: "TYPE_EVENT",
+	}
+	Item_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_RACE":        1,
+		"TYPE_EVENT":       2,
+	}
+)
+
+func (x Item_Type) Enum() *Item_Type {
+	p := new(Item_Type)
+	*p = x
+	return p
+}
+
+func (x Item_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Item_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_nexttogo_nexttogo_proto_enumTypes[0].Descriptor()
+}
+
+func (Item_Type) Type() protoreflect.EnumType {
+	return &file_nexttogo_nexttogo_proto_enumTypes[0]
+}
+
+func (x Item_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Item_Type.Descriptor instead.
+func (Item_Type) EnumDescriptor() ([]byte, []int) {
+	return file_nexttogo_nexttogo_proto_rawDescGZIP(), []int{2, 0}
+}
+
+// Request for ListNextToGo call.
+type ListNextToGoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Limit is how many items to list: 10 by default, at most 100.
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Types limits the feed to these types of item. Every type is listed by
+	// default.
+	Types         []Item_Type `protobuf:"varint,2,rep,packed,name=types,proto3,enum=nexttogo.Item_Type" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNextToGoRequest) Reset() {
+	*x = ListNextToGoRequest{}
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNextToGoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNextToGoRequest) ProtoMessage() {}
+
+func (x *ListNextToGoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNextToGoRequest.ProtoReflect.Descriptor instead.
+func (*ListNextToGoRequest) Descriptor() ([]byte, []int) {
+	return file_nexttogo_nexttogo_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListNextToGoRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNextToGoRequest) GetTypes() []Item_Type {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// Response to ListNextToGo call.
+type ListNextToGoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Items are the open races and events yet to start, soonest first.
+	Items []*Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Unavailable names the services that failed to answer, such as "racing",
+	// whose items are missing from the feed.
+	Unavailable   []string `protobuf:"bytes,2,rep,name=unavailable,proto3" json:"unavailable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNextToGoResponse) Reset() {
+	*x = ListNextToGoResponse{}
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNextToGoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNextToGoResponse) ProtoMessage() {}
+
+func (x *ListNextToGoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNextToGoResponse.ProtoReflect.Descriptor instead.
+func (*ListNextToGoResponse) Descriptor() ([]byte, []int) {
+	return file_nexttogo_nexttogo_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListNextToGoResponse) GetItems() []*Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListNextToGoResponse) GetUnavailable() []string {
+	if x != nil {
+		return x.Unavailable
+	}
+	return nil
+}
+
+// An item in the feed: a race or a sports event.
+type Item struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Type is the type of item.
+	Type Item_Type `protobuf:"varint,1,opt,name=type,proto3,enum=nexttogo.Item_Type" json:"type,omitempty"`
+	// AdvertisedStartTime is when the race or event is advertised to start,
+	// which the feed is ordered by.
+	AdvertisedStartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=advertised_start_time,json=advertisedStartTime,proto3" json:"advertised_start_time,omitempty"`
+	// Item is the race or event itself.
+	//
+	// Types that are valid to be assigned to Item:
+	//
+	//	*Item_Race
+	//	*Item_Event
+	Item          isItem_Item `protobuf_oneof:"item"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_nexttogo_nexttogo_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_nexttogo_nexttogo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Item) GetType() Item_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Item_TYPE_UNSPECIFIED
+}
+
+func (x *Item) GetAdvertisedStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AdvertisedStartTime
+	}
+	return nil
+}
+
+func (x *Item) GetItem() isItem_Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *Item) GetRace() *racing.Race {
+	if x != nil {
+		if x, ok := x.Item.(*Item_Race); ok {
+			return x.Race
+		}
+	}
+	return nil
+}
+
+func (x *Item) GetEvent() *sports.Event {
+	if x != nil {
+		if x, ok := x.Item.(*Item_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+type isItem_Item interface {
+	isItem_Item()
+}
+
+type Item_Race struct {
+	// Race is the race, for items of TYPE_RACE.
+	Race *racing.Race `protobuf:"bytes,3,opt,name=race,proto3,oneof"`
+}
+
+type Item_Event struct {
+	// Event is the sports event, for items of TYPE_EVENT.
+	Event *sports.Event `protobuf:"bytes,4,opt,name=event,proto3,oneof"`
+}
+
+func (*Item_Race) isItem_Item() {}
+
+func (*Item_Event) isItem_Item() {}
+
+var File_nexttogo_nexttogo_proto protoreflect.FileDescriptor
+
+const file_nexttogo_nexttogo_proto_rawDesc = "" +
+	"\n" +
+	"\x17nexttogo/nexttogo.proto\x12\bnexttogo\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x13racing/racing.proto\x1a\x13sports/sports.proto\"V\n" +
+	"\x13ListNextToGoRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12)\n" +
+	"\x05types\x18\x02 \x03(\x0e2\x13.nexttogo.Item.TypeR\x05types\"^\n" +
+	"\x14ListNextToGoResponse\x12$\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.nexttogo.ItemR\x05items\x12 \n" +
+	"\vunavailable\x18\x02 \x03(\tR\vunavailable\"\x8f\x02\n" +
+	"\x04Item\x12'\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x13.nexttogo.Item.TypeR\x04type\x12N\n" +
+	"\x15advertised_start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x13advertisedStartTime\x12\"\n" +
+	"\x04race\x18\x03 \x01(\v2\f.racing.RaceH\x00R\x04race\x12%\n" +
+	"\x05event\x18\x04 \x01(\v2\r.sports.EventH\x00R\x05event\";\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tTYPE_RACE\x10\x01\x12\x0e\n" +
+	"\n" +
+	"TYPE_EVENT\x10\x02B\x06\n" +
+	"\x04item2q\n" +
+	"\bNextToGo\x12e\n" +
+	"\fListNextToGo\x12\x1d.nexttogo.ListNextToGoRequest\x1a\x1e.nexttogo.ListNextToGoResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/next-to-goB2Z0git.neds.sh/matty/entain/proto/nexttogo;nexttogob\x06proto3"
+
+var (
+	file_nexttogo_nexttogo_proto_rawDescOnce sync.Once
+	file_nexttogo_nexttogo_proto_rawDescData []byte
+)
+
+func file_nexttogo_nexttogo_proto_rawDescGZIP() []byte {
+	file_nexttogo_nexttogo_proto_rawDescOnce.Do(func() {
+		file_nexttogo_nexttogo_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_nexttogo_nexttogo_proto_rawDesc), len(file_nexttogo_nexttogo_proto_rawDesc)))
+	})
+	return file_nexttogo_nexttogo_proto_rawDescData
+}
+
+var file_nexttogo_nexttogo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_nexttogo_nexttogo_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_nexttogo_nexttogo_proto_goTypes = []any{
+	(Item_Type)(0),                // 0: nexttogo.Item.Type
+	(*ListNextToGoRequest)(nil),   // 1: nexttogo.ListNextToGoRequest
+	(*ListNextToGoResponse)(nil),  // 2: nexttogo.ListNextToGoResponse
+	(*Item)(nil),                  // 3: nexttogo.Item
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+	(*racing.Race)(nil),           // 5: racing.Race
+	(*sports.Event)(nil),          // 6: sports.Event
+}
+var file_nexttogo_nexttogo_proto_depIdxs = []int32{
+	0, // 0: nexttogo.ListNextToGoRequest.types:type_name -> nexttogo.Item.Type
+	3, // 1: nexttogo.ListNextToGoResponse.items:type_name -> nexttogo.Item
+	0, // 2: nexttogo.Item.type:type_name -> nexttogo.Item.Type
+	4, // 3: nexttogo.Item.advertised_start_time:type_name -> google.protobuf.Timestamp
+	5, // 4: nexttogo.Item.race:type_name -> racing.Race
+	6, // 5: nexttogo.Item.event:type_name -> sports.Event
+	1, // 6: nexttogo.NextToGo.ListNextToGo:input_type -> nexttogo.ListNextToGoRequest
+	2, // 7: nexttogo.NextToGo.ListNextToGo:output_type -> nexttogo.ListNextToGoResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_nexttogo_nexttogo_proto_init() }
+func file_nexttogo_nexttogo_proto_init() {
+	if File_nexttogo_nexttogo_proto != nil {
+		return
+	}
+	file_nexttogo_nexttogo_proto_msgTypes[2].OneofWrappers = []any{
+		(*Item_Race)(nil),
+		(*Item_Event)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_nexttogo_nexttogo_proto_rawDesc), len(file_nexttogo_nexttogo_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_nexttogo_nexttogo_proto_goTypes,
+		DependencyIndexes: file_nexttogo_nexttogo_proto_depIdxs,
+		EnumInfos:         file_nexttogo_nexttogo_proto_enumTypes,
+		MessageInfos:      file_nexttogo_nexttogo_proto_msgTypes,
+	}.Build()
+	File_nexttogo_nexttogo_proto = out.File
+	file_nexttogo_nexttogo_proto_goTypes = nil
+	file_nexttogo_nexttogo_proto_depIdxs = nil
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: nexttogo/nexttogo.proto
+
+package nexttogo
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NextToGo_ListNextToGo_FullMethodName = "/nexttogo.NextToGo/ListNextToGo"
+)
+
+// NextToGoClient is the client API for NextToGo service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NextToGo is served by the gateway itself, from racing and sports.
+type NextToGoClient interface {
+	// ListNextToGo returns the races and sports events starting soonest, in
+	// one feed.
+	ListNextToGo(ctx context.Context, in *ListNextToGoRequest, opts ...grpc.CallOption) (*ListNextToGoResponse, error)
+}
+
+type nextToGoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNextToGoClient(cc grpc.ClientConnInterface) NextToGoClient {
+	return &nextToGoClient{cc}
+}
+
+func (c *nextToGoClient) ListNextToGo(ctx context.Context, in *ListNextToGoRequest, opts ...grpc.CallOption) (*ListNextToGoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNextToGoResponse)
+	err := c.cc.Invoke(ctx, NextToGo_ListNextToGo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NextToGoServer is the server API for NextToGo service.
+// All implementations should embed UnimplementedNextToGoServer
+// for forward compatibility.
+//
+// NextToGo is served by the gateway itself, from racing and sports.
+type NextToGoServer interface {
+	// ListNextToGo returns the races and sports events starting soonest, in
+	// one feed.
+	ListNextToGo(context.Context, *ListNextToGoRequest) (*ListNextToGoResponse, error)
+}
+
+// UnimplementedNextToGoServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNextToGoServer struct{}
+
+func (UnimplementedNextToGoServer) ListNextToGo(context.Context, *ListNextToGoRequest) (*ListNextToGoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNextToGo not implemented")
+}
+func (UnimplementedNextToGoServer) testEmbeddedByValue() {}
+
+// UnsafeNextToGoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NextToGoServer will
+// result in compilation errors.
+type UnsafeNextToGoServer interface {
+	mustEmbedUnimplementedNextToGoServer()
+}
+
+func RegisterNextToGoServer(s grpc.ServiceRegistrar, srv NextToGoServer) {
+	// If the following call pancis, it indicates UnimplementedNextToGoServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NextToGo_ServiceDesc, srv)
+}
+
+func _NextToGo_ListNextToGo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNextToGoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NextToGoServer).ListNextToGo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NextToGo_ListNextToGo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NextToGoServer).ListNextToGo(ctx, req.(*ListNextToGoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NextToGo_ServiceDesc is the grpc.ServiceDesc for NextToGo service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NextToGo_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nexttogo.NextToGo",
+	HandlerType: (*NextToGoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListNextToGo",
+			Handler:    _NextToGo_ListNextToGo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "nexttogo/nexttogo.proto",
+}
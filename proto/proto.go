@@ -0,0 +1,19 @@
+// Package proto holds the protos of every service, the single source of the
+// code generated from them: messages, gRPC clients and servers, the gateway's
+// HTTP and Connect handlers, and the OpenAPI spec of the public routes.
+//
+// Run go generate after changing a proto, and check it with
+// "buf breaking --against" a previous version before merging.
+package proto
+
+import _ "embed"
+
+//go:generate buf generate
+//go:generate buf generate --template buf.gen.openapi.yaml
+//go:generate buf generate --template buf.gen.connect.yaml
+
+// OpenAPI is the OpenAPI v2 spec of the racing, sports and next to go
+// gateway routes, generated from their protos.
+//
+//go:embed api.swagger.json
+var OpenAPI []byte
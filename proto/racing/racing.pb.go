@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v5.29.3
+// 	protoc        (unknown)
 // source: racing/racing.proto
 
 package racing
@@ -72,7 +72,8 @@ func (Race_Status) EnumDescriptor() ([]byte, []int) {
 
 // Request for ListRaces call.
 type ListRacesRequest struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filter narrows and orders the races listed.
 	Filter        *ListRacesRequestFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -117,8 +118,9 @@ func (x *ListRacesRequest) GetFilter() *ListRacesRequestFilter {
 
 // Response to ListRaces call.
 type ListRacesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Races         []*Race                `protobuf:"bytes,1,rep,name=races,proto3" json:"races,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Races are the races matching the filter.
+	Races         []*Race `protobuf:"bytes,1,rep,name=races,proto3" json:"races,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -162,8 +164,9 @@ func (x *ListRacesResponse) GetRaces() []*Race {
 
 // Request for GetRace call.
 type GetRaceRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID is the race to fetch.
+	Id            int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -207,8 +210,9 @@ func (x *GetRaceRequest) GetId() int64 {
 
 // Response to GetRace call.
 type GetRaceResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Race          *Race                  `protobuf:"bytes,1,opt,name=race,proto3" json:"race,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Race is the race, with its runners.
+	Race          *Race `protobuf:"bytes,1,opt,name=race,proto3" json:"race,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -252,14 +256,24 @@ func (x *GetRaceResponse) GetRace() *Race {
 
 // Filter for listing races.
 type ListRacesRequestFilter struct {
-	state      protoimpl.MessageState `protogen:"open.v1"`
-	MeetingIds []int64                `protobuf:"varint,1,rep,packed,name=meeting_ids,json=meetingIds,proto3" json:"meeting_ids,omitempty"`
-	// When unset, include hidden (default). Set false to only visible; true to include hidden.
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// MeetingIDs limits the list to races at these meetings.
+	MeetingIds []int64 `protobuf:"varint,1,rep,packed,name=meeting_ids,json=meetingIds,proto3" json:"meeting_ids,omitempty"`
+	// Set true to include hidden races. Only honoured for trading callers;
+	// everyone else sees visible races only.
 	ShowHidden *bool `protobuf:"varint,2,opt,name=show_hidden,json=showHidden,proto3,oneof" json:"show_hidden,omitempty"`
 	// Order by, e.g. "advertised_start_time" or "advertised_start_time desc" (default asc)
-	OrderBy       string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	OrderBy string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// StartsAfter limits the list to races advertised to start after this time.
+	StartsAfter *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=starts_after,json=startsAfter,proto3" json:"starts_after,omitempty"`
+	// Limit caps how many races are listed, after ordering; 0 lists them all.
+	Limit int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// IDs limits the list to these races.
+	Ids []int64 `protobuf:"varint,6,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// Set true to list each race with its runners.
+	IncludeRunners bool `protobuf:"varint,7,opt,name=include_runners,json=includeRunners,proto3" json:"include_runners,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ListRacesRequestFilter) Reset() {
@@ -313,6 +327,34 @@ func (x *ListRacesRequestFilter) GetOrderBy() string {
 	return ""
 }
 
+func (x *ListRacesRequestFilter) GetStartsAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAfter
+	}
+	return nil
+}
+
+func (x *ListRacesRequestFilter) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRacesRequestFilter) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ListRacesRequestFilter) GetIncludeRunners() bool {
+	if x != nil {
+		return x.IncludeRunners
+	}
+	return false
+}
+
 // A race resource.
 type Race struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -328,9 +370,14 @@ type Race struct {
 	Visible bool `protobuf:"varint,5,opt,name=visible,proto3" json:"visible,omitempty"`
 	// AdvertisedStartTime is the time the race is advertised to run.
 	AdvertisedStartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=advertised_start_time,json=advertisedStartTime,proto3" json:"advertised_start_time,omitempty"`
-	Status              Race_Status            `protobuf:"varint,7,opt,name=status,proto3,enum=racing.Race_Status" json:"status,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	// Status is whether the race is open for betting.
+	Status Race_Status `protobuf:"varint,7,opt,name=status,proto3,enum=racing.Race_Status" json:"status,omitempty"`
+	// Runners are the starters entered in the race, ordered by runner number.
+	// Only populated when fetching a single race, or listing with
+	// include_runners.
+	Runners       []*Runner `protobuf:"bytes,8,rep,name=runners,proto3" json:"runners,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Race) Reset() {
@@ -412,6 +459,86 @@ func (x *Race) GetStatus() Race_Status {
 	return Race_STATUS_OPEN
 }
 
+func (x *Race) GetRunners() []*Runner {
+	if x != nil {
+		return x.Runners
+	}
+	return nil
+}
+
+// A runner entered in a race.
+type Runner struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number is the saddlecloth number exotic bets are placed against.
+	Number int64 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// Name is the runner's name.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Scratched is set when the runner has been withdrawn from the race.
+	Scratched bool `protobuf:"varint,3,opt,name=scratched,proto3" json:"scratched,omitempty"`
+	// FinishPosition is the official result placing, 0 until the race is resulted.
+	FinishPosition int64 `protobuf:"varint,4,opt,name=finish_position,json=finishPosition,proto3" json:"finish_position,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Runner) Reset() {
+	*x = Runner{}
+	mi := &file_racing_racing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Runner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Runner) ProtoMessage() {}
+
+func (x *Runner) ProtoReflect() protoreflect.Message {
+	mi := &file_racing_racing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Runner.ProtoReflect.Descriptor instead.
+func (*Runner) Descriptor() ([]byte, []int) {
+	return file_racing_racing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Runner) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Runner) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Runner) GetScratched() bool {
+	if x != nil {
+		return x.Scratched
+	}
+	return false
+}
+
+func (x *Runner) GetFinishPosition() int64 {
+	if x != nil {
+		return x.FinishPosition
+	}
+	return 0
+}
+
 var File_racing_racing_proto protoreflect.FileDescriptor
 
 const file_racing_racing_proto_rawDesc = "" +
@@ -424,14 +551,18 @@ const file_racing_racing_proto_rawDesc = "" +
 	"\x0eGetRaceRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"3\n" +
 	"\x0fGetRaceResponse\x12 \n" +
-	"\x04race\x18\x01 \x01(\v2\f.racing.RaceR\x04race\"\x8a\x01\n" +
+	"\x04race\x18\x01 \x01(\v2\f.racing.RaceR\x04race\"\x9a\x02\n" +
 	"\x16ListRacesRequestFilter\x12\x1f\n" +
 	"\vmeeting_ids\x18\x01 \x03(\x03R\n" +
 	"meetingIds\x12$\n" +
 	"\vshow_hidden\x18\x02 \x01(\bH\x00R\n" +
 	"showHidden\x88\x01\x01\x12\x19\n" +
-	"\border_by\x18\x03 \x01(\tR\aorderByB\x0e\n" +
-	"\f_show_hidden\"\xa6\x02\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12=\n" +
+	"\fstarts_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vstartsAfter\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x10\n" +
+	"\x03ids\x18\x06 \x03(\x03R\x03ids\x12'\n" +
+	"\x0finclude_runners\x18\a \x01(\bR\x0eincludeRunnersB\x0e\n" +
+	"\f_show_hidden\"\xd0\x02\n" +
 	"\x04Race\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
 	"\n" +
@@ -440,13 +571,19 @@ const file_racing_racing_proto_rawDesc = "" +
 	"\x06number\x18\x04 \x01(\x03R\x06number\x12\x18\n" +
 	"\avisible\x18\x05 \x01(\bR\avisible\x12N\n" +
 	"\x15advertised_start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x13advertisedStartTime\x12+\n" +
-	"\x06status\x18\a \x01(\x0e2\x13.racing.Race.StatusR\x06status\",\n" +
+	"\x06status\x18\a \x01(\x0e2\x13.racing.Race.StatusR\x06status\x12(\n" +
+	"\arunners\x18\b \x03(\v2\x0e.racing.RunnerR\arunners\",\n" +
 	"\x06Status\x12\x0f\n" +
 	"\vSTATUS_OPEN\x10\x00\x12\x11\n" +
-	"\rSTATUS_CLOSED\x10\x012\xb9\x01\n" +
+	"\rSTATUS_CLOSED\x10\x01\"{\n" +
+	"\x06Runner\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tscratched\x18\x03 \x01(\bR\tscratched\x12'\n" +
+	"\x0ffinish_position\x18\x04 \x01(\x03R\x0efinishPosition2\xb9\x01\n" +
 	"\x06Racing\x12[\n" +
 	"\tListRaces\x12\x18.racing.ListRacesRequest\x1a\x19.racing.ListRacesResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/list-races\x12R\n" +
-	"\aGetRace\x12\x16.racing.GetRaceRequest\x1a\x17.racing.GetRaceResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/races/{id}B\tZ\a/racingb\x06proto3"
+	"\aGetRace\x12\x16.racing.GetRaceRequest\x1a\x17.racing.GetRaceResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/races/{id}B.Z,git.neds.sh/matty/entain/proto/racing;racingb\x06proto3"
 
 var (
 	file_racing_racing_proto_rawDescOnce sync.Once
@@ -461,7 +598,7 @@ func file_racing_racing_proto_rawDescGZIP() []byte {
 }
 
 var file_racing_racing_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_racing_racing_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_racing_racing_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_racing_racing_proto_goTypes = []any{
 	(Race_Status)(0),               // 0: racing.Race.Status
 	(*ListRacesRequest)(nil),       // 1: racing.ListRacesRequest
@@ -470,23 +607,26 @@ var file_racing_racing_proto_goTypes = []any{
 	(*GetRaceResponse)(nil),        // 4: racing.GetRaceResponse
 	(*ListRacesRequestFilter)(nil), // 5: racing.ListRacesRequestFilter
 	(*Race)(nil),                   // 6: racing.Race
-	(*timestamppb.Timestamp)(nil),  // 7: google.protobuf.Timestamp
+	(*Runner)(nil),                 // 7: racing.Runner
+	(*timestamppb.Timestamp)(nil),  // 8: google.protobuf.Timestamp
 }
 var file_racing_racing_proto_depIdxs = []int32{
 	5, // 0: racing.ListRacesRequest.filter:type_name -> racing.ListRacesRequestFilter
 	6, // 1: racing.ListRacesResponse.races:type_name -> racing.Race
 	6, // 2: racing.GetRaceResponse.race:type_name -> racing.Race
-	7, // 3: racing.Race.advertised_start_time:type_name -> google.protobuf.Timestamp
-	0, // 4: racing.Race.status:type_name -> racing.Race.Status
-	1, // 5: racing.Racing.ListRaces:input_type -> racing.ListRacesRequest
-	3, // 6: racing.Racing.GetRace:input_type -> racing.GetRaceRequest
-	2, // 7: racing.Racing.ListRaces:output_type -> racing.ListRacesResponse
-	4, // 8: racing.Racing.GetRace:output_type -> racing.GetRaceResponse
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	8, // 3: racing.ListRacesRequestFilter.starts_after:type_name -> google.protobuf.Timestamp
+	8, // 4: racing.Race.advertised_start_time:type_name -> google.protobuf.Timestamp
+	0, // 5: racing.Race.status:type_name -> racing.Race.Status
+	7, // 6: racing.Race.runners:type_name -> racing.Runner
+	1, // 7: racing.Racing.ListRaces:input_type -> racing.ListRacesRequest
+	3, // 8: racing.Racing.GetRace:input_type -> racing.GetRaceRequest
+	2, // 9: racing.Racing.ListRaces:output_type -> racing.ListRacesResponse
+	4, // 10: racing.Racing.GetRace:output_type -> racing.GetRaceResponse
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_racing_racing_proto_init() }
@@ -501,7 +641,7 @@ func file_racing_racing_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_racing_racing_proto_rawDesc), len(file_racing_racing_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
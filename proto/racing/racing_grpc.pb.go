@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v5.29.3
+// - protoc             (unknown)
 // source: racing/racing.proto
 
 package racing
@@ -27,7 +27,7 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RacingClient interface {
-	// ListRaces will return a collection of all races.
+	// ListRaces returns a list of all races.
 	ListRaces(ctx context.Context, in *ListRacesRequest, opts ...grpc.CallOption) (*ListRacesResponse, error)
 	// GetRace returns a single race by ID.
 	GetRace(ctx context.Context, in *GetRaceRequest, opts ...grpc.CallOption) (*GetRaceResponse, error)
@@ -65,7 +65,7 @@ func (c *racingClient) GetRace(ctx context.Context, in *GetRaceRequest, opts ...
 // All implementations should embed UnimplementedRacingServer
 // for forward compatibility.
 type RacingServer interface {
-	// ListRaces will return a collection of all races.
+	// ListRaces returns a list of all races.
 	ListRaces(context.Context, *ListRacesRequest) (*ListRacesResponse, error)
 	// GetRace returns a single race by ID.
 	GetRace(context.Context, *GetRaceRequest) (*GetRaceResponse, error)
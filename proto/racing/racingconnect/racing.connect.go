@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: racing/racing.proto
+
+package racingconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	racing "git.neds.sh/matty/entain/proto/racing"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// RacingName is the fully-qualified name of the Racing service.
+	RacingName = "racing.Racing"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// RacingListRacesProcedure is the fully-qualified name of the Racing's ListRaces RPC.
+	RacingListRacesProcedure = "/racing.Racing/ListRaces"
+	// RacingGetRaceProcedure is the fully-qualified name of the Racing's GetRace RPC.
+	RacingGetRaceProcedure = "/racing.Racing/GetRace"
+)
+
+// RacingClient is a client for the racing.Racing service.
+type RacingClient interface {
+	// ListRaces returns a list of all races.
+	ListRaces(context.Context, *connect.Request[racing.ListRacesRequest]) (*connect.Response[racing.ListRacesResponse], error)
+	// GetRace returns a single race by ID.
+	GetRace(context.Context, *connect.Request[racing.GetRaceRequest]) (*connect.Response[racing.GetRaceResponse], error)
+}
+
+// NewRacingClient constructs a client for the racing.Racing service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewRacingClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) RacingClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	racingMethods := racing.File_racing_racing_proto.Services().ByName("Racing").Methods()
+	return &racingClient{
+		listRaces: connect.NewClient[racing.ListRacesRequest, racing.ListRacesResponse](
+			httpClient,
+			baseURL+RacingListRacesProcedure,
+			connect.WithSchema(racingMethods.ByName("ListRaces")),
+			connect.WithClientOptions(opts...),
+		),
+		getRace: connect.NewClient[racing.GetRaceRequest, racing.GetRaceResponse](
+			httpClient,
+			baseURL+RacingGetRaceProcedure,
+			connect.WithSchema(racingMethods.ByName("GetRace")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// racingClient implements RacingClient.
+type racingClient struct {
+	listRaces *connect.Client[racing.ListRacesRequest, racing.ListRacesResponse]
+	getRace   *connect.Client[racing.GetRaceRequest, racing.GetRaceResponse]
+}
+
+// ListRaces calls racing.Racing.ListRaces.
+func (c *racingClient) ListRaces(ctx context.Context, req *connect.Request[racing.ListRacesRequest]) (*connect.Response[racing.ListRacesResponse], error) {
+	return c.listRaces.CallUnary(ctx, req)
+}
+
+// GetRace calls racing.Racing.GetRace.
+func (c *racingClient) GetRace(ctx context.Context, req *connect.Request[racing.GetRaceRequest]) (*connect.Response[racing.GetRaceResponse], error) {
+	return c.getRace.CallUnary(ctx, req)
+}
+
+// RacingHandler is an implementation of the racing.Racing service.
+type RacingHandler interface {
+	// ListRaces returns a list of all races.
+	ListRaces(context.Context, *connect.Request[racing.ListRacesRequest]) (*connect.Response[racing.ListRacesResponse], error)
+	// GetRace returns a single race by ID.
+	GetRace(context.Context, *connect.Request[racing.GetRaceRequest]) (*connect.Response[racing.GetRaceResponse], error)
+}
+
+// NewRacingHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewRacingHandler(svc RacingHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	racingMethods := racing.File_racing_racing_proto.Services().ByName("Racing").Methods()
+	racingListRacesHandler := connect.NewUnaryHandler(
+		RacingListRacesProcedure,
+		svc.ListRaces,
+		connect.WithSchema(racingMethods.ByName("ListRaces")),
+		connect.WithHandlerOptions(opts...),
+	)
+	racingGetRaceHandler := connect.NewUnaryHandler(
+		RacingGetRaceProcedure,
+		svc.GetRace,
+		connect.WithSchema(racingMethods.ByName("GetRace")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/racing.Racing/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RacingListRacesProcedure:
+			racingListRacesHandler.ServeHTTP(w, r)
+		case RacingGetRaceProcedure:
+			racingGetRaceHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedRacingHandler returns CodeUnimplemented from all methods.
+type UnimplementedRacingHandler struct{}
+
+func (UnimplementedRacingHandler) ListRaces(context.Context, *connect.Request[racing.ListRacesRequest]) (*connect.Response[racing.ListRacesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("racing.Racing.ListRaces is not implemented"))
+}
+
+func (UnimplementedRacingHandler) GetRace(context.Context, *connect.Request[racing.GetRaceRequest]) (*connect.Response[racing.GetRaceResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("racing.Racing.GetRace is not implemented"))
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v5.29.3
+// 	protoc        (unknown)
 // source: sports/sports.proto
 
 package sports
@@ -72,7 +72,8 @@ func (Event_Status) EnumDescriptor() ([]byte, []int) {
 
 // Request for ListEvents call.
 type ListEventsRequest struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filter narrows and orders the events listed.
 	Filter        *ListEventsRequestFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -117,8 +118,9 @@ func (x *ListEventsRequest) GetFilter() *ListEventsRequestFilter {
 
 // Response to ListEvents call.
 type ListEventsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Events are the sports events matching the filter.
+	Events        []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -162,12 +164,20 @@ func (x *ListEventsResponse) GetEvents() []*Event {
 
 // Filter for listing sports events.
 type ListEventsRequestFilter struct {
-	state    protoimpl.MessageState `protogen:"open.v1"`
-	SportIds []int64                `protobuf:"varint,1,rep,packed,name=sport_ids,json=sportIds,proto3" json:"sport_ids,omitempty"`
-	// When unset, include hidden (default). Set false to only visible; true to include hidden.
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// SportIDs limits the list to events of these sports.
+	SportIds []int64 `protobuf:"varint,1,rep,packed,name=sport_ids,json=sportIds,proto3" json:"sport_ids,omitempty"`
+	// Set true to include hidden events. Only honoured for trading callers;
+	// everyone else sees visible events only.
 	ShowHidden *bool `protobuf:"varint,2,opt,name=show_hidden,json=showHidden,proto3,oneof" json:"show_hidden,omitempty"`
 	// Order by, e.g. "advertised_start_time" or "advertised_start_time desc" (default asc)
-	OrderBy       string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	OrderBy string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// StartsAfter limits the list to events advertised to start after this time.
+	StartsAfter *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=starts_after,json=startsAfter,proto3" json:"starts_after,omitempty"`
+	// Limit caps how many events are listed, after ordering; 0 lists them all.
+	Limit int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// IDs limits the list to these events.
+	Ids           []int64 `protobuf:"varint,6,rep,packed,name=ids,proto3" json:"ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -223,6 +233,27 @@ func (x *ListEventsRequestFilter) GetOrderBy() string {
 	return ""
 }
 
+func (x *ListEventsRequestFilter) GetStartsAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAfter
+	}
+	return nil
+}
+
+func (x *ListEventsRequestFilter) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListEventsRequestFilter) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
 // A sports event resource.
 type Event struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -238,7 +269,8 @@ type Event struct {
 	Visible bool `protobuf:"varint,5,opt,name=visible,proto3" json:"visible,omitempty"`
 	// AdvertisedStartTime is the time the event is advertised to start.
 	AdvertisedStartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=advertised_start_time,json=advertisedStartTime,proto3" json:"advertised_start_time,omitempty"`
-	Status              Event_Status           `protobuf:"varint,7,opt,name=status,proto3,enum=sports.Event_Status" json:"status,omitempty"`
+	// Status is whether the event is open for betting.
+	Status Event_Status `protobuf:"varint,7,opt,name=status,proto3,enum=sports.Event_Status" json:"status,omitempty"`
 	// HomeTeam represents the home team or participant.
 	HomeTeam string `protobuf:"bytes,8,opt,name=home_team,json=homeTeam,proto3" json:"home_team,omitempty"`
 	// AwayTeam represents the away team or participant.
@@ -348,12 +380,15 @@ const file_sports_sports_proto_rawDesc = "" +
 	"\x11ListEventsRequest\x127\n" +
 	"\x06filter\x18\x01 \x01(\v2\x1f.sports.ListEventsRequestFilterR\x06filter\";\n" +
 	"\x12ListEventsResponse\x12%\n" +
-	"\x06events\x18\x01 \x03(\v2\r.sports.EventR\x06events\"\x87\x01\n" +
+	"\x06events\x18\x01 \x03(\v2\r.sports.EventR\x06events\"\xee\x01\n" +
 	"\x17ListEventsRequestFilter\x12\x1b\n" +
 	"\tsport_ids\x18\x01 \x03(\x03R\bsportIds\x12$\n" +
 	"\vshow_hidden\x18\x02 \x01(\bH\x00R\n" +
 	"showHidden\x88\x01\x01\x12\x19\n" +
-	"\border_by\x18\x03 \x01(\tR\aorderByB\x0e\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12=\n" +
+	"\fstarts_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vstartsAfter\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x10\n" +
+	"\x03ids\x18\x06 \x03(\x03R\x03idsB\x0e\n" +
 	"\f_show_hidden\"\xdc\x02\n" +
 	"\x05Event\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
@@ -370,7 +405,7 @@ const file_sports_sports_proto_rawDesc = "" +
 	"\rSTATUS_CLOSED\x10\x012i\n" +
 	"\x06Sports\x12_\n" +
 	"\n" +
-	"ListEvents\x12\x19.sports.ListEventsRequest\x1a\x1a.sports.ListEventsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/list-eventsB\tZ\a/sportsb\x06proto3"
+	"ListEvents\x12\x19.sports.ListEventsRequest\x1a\x1a.sports.ListEventsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/list-eventsB.Z,git.neds.sh/matty/entain/proto/sports;sportsb\x06proto3"
 
 var (
 	file_sports_sports_proto_rawDescOnce sync.Once
@@ -397,15 +432,16 @@ var file_sports_sports_proto_goTypes = []any{
 var file_sports_sports_proto_depIdxs = []int32{
 	3, // 0: sports.ListEventsRequest.filter:type_name -> sports.ListEventsRequestFilter
 	4, // 1: sports.ListEventsResponse.events:type_name -> sports.Event
-	5, // 2: sports.Event.advertised_start_time:type_name -> google.protobuf.Timestamp
-	0, // 3: sports.Event.status:type_name -> sports.Event.Status
-	1, // 4: sports.Sports.ListEvents:input_type -> sports.ListEventsRequest
-	2, // 5: sports.Sports.ListEvents:output_type -> sports.ListEventsResponse
-	5, // [5:6] is the sub-list for method output_type
-	4, // [4:5] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	5, // 2: sports.ListEventsRequestFilter.starts_after:type_name -> google.protobuf.Timestamp
+	5, // 3: sports.Event.advertised_start_time:type_name -> google.protobuf.Timestamp
+	0, // 4: sports.Event.status:type_name -> sports.Event.Status
+	1, // 5: sports.Sports.ListEvents:input_type -> sports.ListEventsRequest
+	2, // 6: sports.Sports.ListEvents:output_type -> sports.ListEventsResponse
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_sports_sports_proto_init() }
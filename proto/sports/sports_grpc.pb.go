@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v5.29.3
+// - protoc             (unknown)
 // source: sports/sports.proto
 
 package sports
@@ -26,7 +26,7 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SportsClient interface {
-	// ListEvents will return a collection of all sports events.
+	// ListEvents returns a list of all sports events.
 	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
 }
 
@@ -52,7 +52,7 @@ func (c *sportsClient) ListEvents(ctx context.Context, in *ListEventsRequest, op
 // All implementations should embed UnimplementedSportsServer
 // for forward compatibility.
 type SportsServer interface {
-	// ListEvents will return a collection of all sports events.
+	// ListEvents returns a list of all sports events.
 	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
 }
 
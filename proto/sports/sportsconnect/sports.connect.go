@@ -0,0 +1,110 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: sports/sports.proto
+
+package sportsconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	sports "git.neds.sh/matty/entain/proto/sports"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// SportsName is the fully-qualified name of the Sports service.
+	SportsName = "sports.Sports"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// SportsListEventsProcedure is the fully-qualified name of the Sports's ListEvents RPC.
+	SportsListEventsProcedure = "/sports.Sports/ListEvents"
+)
+
+// SportsClient is a client for the sports.Sports service.
+type SportsClient interface {
+	// ListEvents returns a list of all sports events.
+	ListEvents(context.Context, *connect.Request[sports.ListEventsRequest]) (*connect.Response[sports.ListEventsResponse], error)
+}
+
+// NewSportsClient constructs a client for the sports.Sports service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewSportsClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) SportsClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	sportsMethods := sports.File_sports_sports_proto.Services().ByName("Sports").Methods()
+	return &sportsClient{
+		listEvents: connect.NewClient[sports.ListEventsRequest, sports.ListEventsResponse](
+			httpClient,
+			baseURL+SportsListEventsProcedure,
+			connect.WithSchema(sportsMethods.ByName("ListEvents")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// sportsClient implements SportsClient.
+type sportsClient struct {
+	listEvents *connect.Client[sports.ListEventsRequest, sports.ListEventsResponse]
+}
+
+// ListEvents calls sports.Sports.ListEvents.
+func (c *sportsClient) ListEvents(ctx context.Context, req *connect.Request[sports.ListEventsRequest]) (*connect.Response[sports.ListEventsResponse], error) {
+	return c.listEvents.CallUnary(ctx, req)
+}
+
+// SportsHandler is an implementation of the sports.Sports service.
+type SportsHandler interface {
+	// ListEvents returns a list of all sports events.
+	ListEvents(context.Context, *connect.Request[sports.ListEventsRequest]) (*connect.Response[sports.ListEventsResponse], error)
+}
+
+// NewSportsHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewSportsHandler(svc SportsHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	sportsMethods := sports.File_sports_sports_proto.Services().ByName("Sports").Methods()
+	sportsListEventsHandler := connect.NewUnaryHandler(
+		SportsListEventsProcedure,
+		svc.ListEvents,
+		connect.WithSchema(sportsMethods.ByName("ListEvents")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/sports.Sports/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case SportsListEventsProcedure:
+			sportsListEventsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedSportsHandler returns CodeUnimplemented from all methods.
+type UnimplementedSportsHandler struct{}
+
+func (UnimplementedSportsHandler) ListEvents(context.Context, *connect.Request[sports.ListEventsRequest]) (*connect.Response[sports.ListEventsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sports.Sports.ListEvents is not implemented"))
+}
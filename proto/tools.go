@@ -1,13 +1,12 @@
-// +build tools
+//go:build tools
 
-package tools
-
-// What is this file? https://github.com/golang/go/wiki/Modules#how-can-i-track-tool-dependencies-for-a-module
+package proto
 
+// The plugins buf generates code with.
 import (
+	_ "connectrpc.com/connect/cmd/protoc-gen-connect-go"
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-grpc-gateway"
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2"
-	_ "google.golang.org/genproto/googleapis/api"
 	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
 )
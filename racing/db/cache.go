@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+// cachedRacesRepo serves race lists from a cache, querying the repository
+// it wraps only on a miss.
+type cachedRacesRepo struct {
+	RacesRepo
+	lists *cache.Namespace
+}
+
+// NewCachedRacesRepo caches the races repo lists in c for ttl, keyed by the
+// normalised filter. Races are cached without a status, which callers derive
+// from the advertised start time on every read. A nil c leaves repo uncached.
+func NewCachedRacesRepo(repo RacesRepo, c cache.Cache, ttl time.Duration) RacesRepo {
+	if c == nil {
+		return repo
+	}
+	return &cachedRacesRepo{RacesRepo: repo, lists: cache.NewNamespace(c, repoName, ttl)}
+}
+
+// Init seeds the repository, then invalidates the lists cached before. A
+// cache that can't be reached doesn't stop the service starting: lists
+// cached before expire within their TTL all the same.
+func (r *cachedRacesRepo) Init() error {
+	if err := r.RacesRepo.Init(); err != nil {
+		return err
+	}
+	r.invalidate(context.Background())
+	return nil
+}
+
+// Reseed reseeds the repository, then invalidates the lists cached before.
+func (r *cachedRacesRepo) Reseed(ctx context.Context) error {
+	if err := r.RacesRepo.Reseed(ctx); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *cachedRacesRepo) invalidate(ctx context.Context) {
+	if err := r.lists.Invalidate(ctx); err != nil {
+		slog.WarnContext(ctx, "failed invalidating the cache", "cache", repoName, "error", err)
+	}
+}
+
+func (r *cachedRacesRepo) List(ctx context.Context, filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
+	// Lists of what starts after a time change as it passes, so the whole
+	// list is cached and cut off at the time on each read.
+	if startsAfter := filter.GetStartsAfter(); startsAfter != nil {
+		unbounded := proto.Clone(filter).(*racing.ListRacesRequestFilter)
+		unbounded.StartsAfter, unbounded.Limit = nil, 0
+
+		races, err := r.list(ctx, unbounded)
+		if err != nil {
+			return nil, err
+		}
+		return startingAfter(races, startsAfter.AsTime(), filter.Limit), nil
+	}
+
+	return r.list(ctx, filter)
+}
+
+func (r *cachedRacesRepo) list(ctx context.Context, filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
+	value, err := r.lists.Get(ctx, listKey(filter), func(ctx context.Context) ([]byte, error) {
+		races, err := r.RacesRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(&racing.ListRacesResponse{Races: races})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp racing.ListRacesResponse
+	if err := proto.Unmarshal(value, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Races, nil
+}
+
+// startingAfter keeps the races, in order, advertised to start after t, up
+// to limit of them when it's positive, as the database would list them.
+func startingAfter(races []*racing.Race, t time.Time, limit int32) []*racing.Race {
+	kept := races[:0]
+	for _, race := range races {
+		if limit > 0 && len(kept) == int(limit) {
+			break
+		}
+		if race.GetAdvertisedStartTime().AsTime().After(t) {
+			kept = append(kept, race)
+		}
+	}
+	return kept
+}
+
+// listKey identifies the races filter lists, so filters listing the same
+// races in the same order share a key: meeting and race IDs in any order, and
+// order_by in any case or spacing.
+func listKey(filter *racing.ListRacesRequestFilter) string {
+	if filter == nil {
+		return "all"
+	}
+
+	meetingIDs := slices.Compact(slices.Sorted(slices.Values(filter.MeetingIds)))
+	ids := slices.Compact(slices.Sorted(slices.Values(filter.Ids)))
+
+	hidden := "any"
+	if filter.ShowHidden != nil && !*filter.ShowHidden {
+		hidden = "visible"
+	}
+
+	return fmt.Sprintf("meetings=%v;ids=%v;hidden=%s;order=%s;limit=%d;runners=%t", meetingIDs, ids, hidden, orderBy(filter.OrderBy), max(filter.Limit, 0), filter.IncludeRunners)
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/proto/racing"
+)
+
+func Test_listKey(t *testing.T) {
+	same := [][]*racing.ListRacesRequestFilter{
+		{
+			{MeetingIds: []int64{1, 2}},
+			{MeetingIds: []int64{2, 1, 2}},
+		},
+		{
+			{Ids: []int64{3, 4}},
+			{Ids: []int64{4, 3, 4}},
+		},
+		{
+			{OrderBy: "name desc"},
+			{OrderBy: "  Name   DESC "},
+		},
+		{
+			{},
+			{ShowHidden: boolPtr(true)},
+			{OrderBy: "advertised_start_time"},
+		},
+		{
+			{OrderBy: "nonexistent"},
+			{OrderBy: "   "},
+		},
+	}
+	seen := make(map[string]int)
+	for group, filters := range same {
+		for _, filter := range filters {
+			key := listKey(filter)
+			require.Equal(t, listKey(filters[0]), key, "%v", filter)
+			seen[key] = group
+		}
+	}
+	require.Len(t, seen, len(same), "groups listing different races share a key")
+
+	require.NotEqual(t, listKey(&racing.ListRacesRequestFilter{}), listKey(&racing.ListRacesRequestFilter{ShowHidden: boolPtr(false)}))
+	require.NotEqual(t, listKey(nil), listKey(&racing.ListRacesRequestFilter{}))
+	require.NotEqual(t, listKey(&racing.ListRacesRequestFilter{}), listKey(&racing.ListRacesRequestFilter{Limit: 5}))
+	require.Equal(t, listKey(&racing.ListRacesRequestFilter{}), listKey(&racing.ListRacesRequestFilter{Limit: -1}))
+	require.NotEqual(t, listKey(&racing.ListRacesRequestFilter{}), listKey(&racing.ListRacesRequestFilter{IncludeRunners: true}))
+}
+
+func TestCachedRacesRepo(t *testing.T) {
+	ctx := context.Background()
+	races := []*racing.Race{
+		{Id: 1, MeetingId: 5, Name: "Race 1", AdvertisedStartTime: timestamppb.New(time.Now())},
+	}
+
+	m := NewRacesRepoMock(t)
+	m.On("Init").Return(nil).Twice()
+	m.On("Reseed", mock.Anything).Return(nil).Once()
+	m.On("List", mock.Anything, mock.Anything).Return(races, nil).Times(3)
+	m.On("Get", mock.Anything, int64(1)).Return(races[0], nil).Once()
+
+	repo := NewCachedRacesRepo(m, cache.NewLRU(10), time.Minute)
+	require.NoError(t, repo.Init())
+
+	for _, filter := range []*racing.ListRacesRequestFilter{
+		{MeetingIds: []int64{5, 6}},
+		{MeetingIds: []int64{6, 5}},
+	} {
+		got, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.True(t, proto.Equal(races[0], got[0]))
+	}
+	m.AssertNumberOfCalls(t, "List", 1)
+
+	// Races handed out are copies, so callers setting a status can't change
+	// what's cached.
+	got, err := repo.List(ctx, &racing.ListRacesRequestFilter{MeetingIds: []int64{5, 6}})
+	require.NoError(t, err)
+	got[0].Status = racing.Race_STATUS_CLOSED
+	got, err = repo.List(ctx, &racing.ListRacesRequestFilter{MeetingIds: []int64{5, 6}})
+	require.NoError(t, err)
+	require.Equal(t, racing.Race_STATUS_OPEN, got[0].Status)
+
+	// Writing to the repository drops what it listed before.
+	require.NoError(t, repo.Init())
+	_, err = repo.List(ctx, &racing.ListRacesRequestFilter{MeetingIds: []int64{5, 6}})
+	require.NoError(t, err)
+	m.AssertNumberOfCalls(t, "List", 2)
+	require.NoError(t, repo.Reseed(ctx))
+	_, err = repo.List(ctx, &racing.ListRacesRequestFilter{MeetingIds: []int64{5, 6}})
+	require.NoError(t, err)
+	m.AssertNumberOfCalls(t, "List", 3)
+
+	// Single races aren't cached.
+	race, err := repo.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, races[0], race)
+}
+
+func TestCachedRacesRepo_StartsAfter(t *testing.T) {
+	now := time.Now()
+	races := []*racing.Race{
+		{Id: 1, AdvertisedStartTime: timestamppb.New(now.Add(-time.Minute))},
+		{Id: 2, AdvertisedStartTime: timestamppb.New(now.Add(time.Minute))},
+		{Id: 3, AdvertisedStartTime: timestamppb.New(now.Add(2 * time.Minute))},
+	}
+
+	// The whole list is cached without the time or limit, and each read
+	// cuts it off.
+	m := NewRacesRepoMock(t)
+	unbounded := mock.MatchedBy(func(f *racing.ListRacesRequestFilter) bool { return f.StartsAfter == nil && f.Limit == 0 })
+	m.On("List", mock.Anything, unbounded).Return(races, nil).Once()
+
+	repo := NewCachedRacesRepo(m, cache.NewLRU(10), time.Minute)
+	for _, tt := range []struct {
+		startsAfter time.Time
+		limit       int32
+		want        []int64
+	}{
+		{startsAfter: now, want: []int64{2, 3}},
+		{startsAfter: now, limit: 1, want: []int64{2}},
+		{startsAfter: now.Add(90 * time.Second), want: []int64{3}},
+		{startsAfter: now.Add(time.Hour), want: nil},
+	} {
+		got, err := repo.List(context.Background(), &racing.ListRacesRequestFilter{StartsAfter: timestamppb.New(tt.startsAfter), Limit: tt.limit})
+		require.NoError(t, err)
+		var ids []int64
+		for _, race := range got {
+			ids = append(ids, race.Id)
+		}
+		require.Equal(t, tt.want, ids)
+	}
+}
+
+func TestCachedRacesRepo_ErrorsAreNotCached(t *testing.T) {
+	m := NewRacesRepoMock(t)
+	m.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("database is locked")).Once()
+	m.On("List", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	repo := NewCachedRacesRepo(m, cache.NewLRU(10), time.Minute)
+	_, err := repo.List(context.Background(), nil)
+	require.EqualError(t, err, "database is locked")
+	_, err = repo.List(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+func TestNewCachedRacesRepo_NoCache(t *testing.T) {
+	m := NewRacesRepoMock(t)
+	require.Same(t, RacesRepo(m), NewCachedRacesRepo(m, nil, time.Minute))
+}
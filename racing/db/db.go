@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"math/rand"
 	"time"
 
 	"syreclabs.com/go/faker"
@@ -26,5 +28,92 @@ func (r *racesRepo) seed() error {
 		}
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return r.seedRunners()
+}
+
+// seedRunners fields 8-14 runners per race, scratching roughly one in ten.
+// Races that have already jumped are resulted with a random finishing order.
+func (r *racesRepo) seedRunners() error {
+	if _, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS runners (race_id INTEGER, number INTEGER, name TEXT, scratched INTEGER, finish_position INTEGER, PRIMARY KEY (race_id, number))`); err != nil {
+		return err
+	}
+
+	// Runners are only seeded once, alongside the races they belong to.
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM runners`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`SELECT id, advertised_start_time FROM races`)
+	if err != nil {
+		return err
+	}
+
+	starts := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			id    int64
+			start time.Time
+		)
+		if err := rows.Scan(&id, &start); err != nil {
+			rows.Close()
+			return err
+		}
+		starts[id] = start
+	}
+	rows.Close()
+
+	for raceID, start := range starts {
+		field := faker.RandomInt(8, 14)
+		scratched := make(map[int]bool)
+		var starters []int
+		for number := 1; number <= field; number++ {
+			if faker.RandomInt(1, 10) == 1 {
+				scratched[number] = true
+				continue
+			}
+			starters = append(starters, number)
+		}
+
+		positions := make(map[int]int)
+		if start.Before(time.Now()) {
+			rand.Shuffle(len(starters), func(i, j int) { starters[i], starters[j] = starters[j], starters[i] })
+			for i, number := range starters {
+				positions[number] = i + 1
+			}
+		}
+
+		for number := 1; number <= field; number++ {
+			if _, err := r.db.Exec(
+				`INSERT OR IGNORE INTO runners(race_id, number, name, scratched, finish_position) VALUES (?,?,?,?,?)`,
+				raceID,
+				number,
+				faker.Name().FirstName()+" "+faker.Team().Creature(),
+				scratched[number],
+				positions[number],
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reseed deletes the races and their runners, then seeds new ones.
+func (r *racesRepo) Reseed(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM runners`); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM races`); err != nil {
+		return err
+	}
+	return r.seed()
 }
@@ -1,8 +1,10 @@
 package db
 
 const (
-	racesList = "list"
-	racesGet  = "get"
+	racesList        = "list"
+	racesGet         = "get"
+	racesRunners     = "runners"
+	racesListRunners = "list_runners"
 )
 
 func getRaceQueries() map[string]string {
@@ -28,5 +30,24 @@ func getRaceQueries() map[string]string {
             FROM races
             WHERE id = ?
         `,
+		racesRunners: `
+			SELECT
+				number,
+				name,
+				scratched,
+				finish_position
+			FROM runners
+			WHERE race_id = ?
+			ORDER BY number ASC
+		`,
+		racesListRunners: `
+			SELECT
+				race_id,
+				number,
+				name,
+				scratched,
+				finish_position
+			FROM runners
+		`,
 	}
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"sync"
@@ -9,9 +10,14 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
-	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/racing"
 )
 
+// repoName labels the races repository's query metrics and spans.
+const repoName = "races"
+
 // RacesRepo provides repository access to races.
 //
 //go:generate mockery --name RacesRepo --structname RacesRepoMock --dir . --output . --outpkg db --filename races_repo_mock.go
@@ -20,10 +26,13 @@ type RacesRepo interface {
 	Init() error
 
 	// List will return a list of races.
-	List(filter *racing.ListRacesRequestFilter) ([]*racing.Race, error)
+	List(ctx context.Context, filter *racing.ListRacesRequestFilter) ([]*racing.Race, error)
+
+	// Get returns a single race by id, including its runners.
+	Get(ctx context.Context, id int64) (*racing.Race, error)
 
-	// Get returns a single race by id.
-	Get(id int64) (*racing.Race, error)
+	// Reseed replaces every race and runner with freshly seeded ones.
+	Reseed(ctx context.Context) error
 }
 
 type racesRepo struct {
@@ -48,9 +57,8 @@ func (r *racesRepo) Init() error {
 	return err
 }
 
-func (r *racesRepo) List(filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
+func (r *racesRepo) List(ctx context.Context, filter *racing.ListRacesRequestFilter) (races []*racing.Race, err error) {
 	var (
-		err   error
 		query string
 		args  []any
 	)
@@ -59,17 +67,39 @@ func (r *racesRepo) List(filter *racing.ListRacesRequestFilter) ([]*racing.Race,
 
 	query, args = r.applyFilter(query, filter)
 
-	rows, err := r.db.Query(query, args...)
+	ctx, span := tracing.StartQuery(ctx, repoName, racesList, query)
+	defer func(start time.Time) {
+		metrics.ObserveQuery(repoName, racesList, start, len(races), err)
+		tracing.EndQuery(span, len(races), err)
+	}(time.Now())
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	if races, err = r.scanRaces(rows); err != nil {
+		return nil, err
+	}
 
-	return r.scanRaces(rows)
+	if filter.GetIncludeRunners() && len(races) > 0 {
+		if err := r.listRunners(ctx, races); err != nil {
+			return nil, err
+		}
+	}
+	return races, nil
 }
 
-func (r *racesRepo) Get(id int64) (*racing.Race, error) {
+func (r *racesRepo) Get(ctx context.Context, id int64) (_ *racing.Race, err error) {
 	query := getRaceQueries()[racesGet]
-	row := r.db.QueryRow(query, id)
+
+	start, found := time.Now(), 0
+	ctx, span := tracing.StartQuery(ctx, repoName, racesGet, query)
+	defer func() {
+		metrics.ObserveQuery(repoName, racesGet, start, found, err)
+		tracing.EndQuery(span, found, err)
+	}()
+
+	row := r.db.QueryRowContext(ctx, query, id)
 	var race racing.Race
 	var advertisedStart time.Time
 	if err := row.Scan(&race.Id, &race.MeetingId, &race.Name, &race.Number, &race.Visible, &advertisedStart); err != nil {
@@ -79,9 +109,85 @@ func (r *racesRepo) Get(id int64) (*racing.Race, error) {
 		return nil, err
 	}
 	race.AdvertisedStartTime = timestamppb.New(advertisedStart)
+	found = 1
+
+	runners, err := r.getRunners(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	race.Runners = runners
+
 	return &race, nil
 }
 
+// getRunners returns the field for a race, ordered by runner number.
+func (r *racesRepo) getRunners(ctx context.Context, raceID int64) (runners []*racing.Runner, err error) {
+	query := getRaceQueries()[racesRunners]
+
+	ctx, span := tracing.StartQuery(ctx, repoName, racesRunners, query)
+	defer func(start time.Time) {
+		metrics.ObserveQuery(repoName, racesRunners, start, len(runners), err)
+		tracing.EndQuery(span, len(runners), err)
+	}(time.Now())
+
+	rows, err := r.db.QueryContext(ctx, query, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var runner racing.Runner
+		if err := rows.Scan(&runner.Number, &runner.Name, &runner.Scratched, &runner.FinishPosition); err != nil {
+			return nil, err
+		}
+		runners = append(runners, &runner)
+	}
+
+	return runners, rows.Err()
+}
+
+// listRunners fills in the runners of races, ordered by runner number, from
+// one query.
+func (r *racesRepo) listRunners(ctx context.Context, races []*racing.Race) (err error) {
+	byID := make(map[int64]*racing.Race, len(races))
+	args := make([]any, 0, len(races))
+	for _, race := range races {
+		byID[race.Id] = race
+		args = append(args, race.Id)
+	}
+	query := getRaceQueries()[racesListRunners] + " WHERE race_id IN (" + strings.Repeat("?,", len(races)-1) + "?) ORDER BY race_id ASC, number ASC"
+
+	found := 0
+	ctx, span := tracing.StartQuery(ctx, repoName, racesListRunners, query)
+	defer func(start time.Time) {
+		metrics.ObserveQuery(repoName, racesListRunners, start, found, err)
+		tracing.EndQuery(span, found, err)
+	}(time.Now())
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			raceID int64
+			runner racing.Runner
+		)
+		if err := rows.Scan(&raceID, &runner.Number, &runner.Name, &runner.Scratched, &runner.FinishPosition); err != nil {
+			return err
+		}
+		if race := byID[raceID]; race != nil {
+			race.Runners = append(race.Runners, &runner)
+			found++
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *racesRepo) applyFilter(query string, filter *racing.ListRacesRequestFilter) (string, []any) {
 	var (
 		clauses []string
@@ -100,43 +206,66 @@ func (r *racesRepo) applyFilter(query string, filter *racing.ListRacesRequestFil
 		}
 	}
 
+	if len(filter.Ids) > 0 {
+		clauses = append(clauses, "id IN ("+strings.Repeat("?,", len(filter.Ids)-1)+"?)")
+
+		for _, id := range filter.Ids {
+			args = append(args, id)
+		}
+	}
+
 	// show_hidden semantics: unset or true => include hidden; false => only visible
 	if filter.ShowHidden != nil && !*filter.ShowHidden {
 		clauses = append(clauses, "visible = 1")
 	}
 
+	// Start times are stored as RFC 3339 text, whose offsets may differ, so
+	// they're compared as Julian days rather than as text.
+	if filter.StartsAfter != nil {
+		clauses = append(clauses, "julianday(advertised_start_time) > julianday(?)")
+		args = append(args, filter.StartsAfter.AsTime().Format(time.RFC3339Nano))
+	}
+
 	if len(clauses) != 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
 	}
 
-	if filter.OrderBy != "" {
-		ob := strings.ToLower(strings.TrimSpace(filter.OrderBy))
-		tokens := strings.Fields(ob)
-		if len(tokens) >= 1 {
-			switch tokens[0] {
-			case "id", "meeting_id", "name", "number", "visible", "advertised_start_time":
-				orderField := tokens[0]
-				orderDir := "ASC"
-				if len(tokens) >= 2 {
-					switch tokens[1] {
-					case "desc":
-						orderDir = "DESC"
-					case "asc":
-						orderDir = "ASC"
-					}
-				}
-				query += " ORDER BY " + orderField + " " + orderDir
-			default:
-				// unknown column ignored to avoid injection; keep defaults
-			}
-		}
-	} else {
-		query += " ORDER BY advertised_start_time ASC"
+	query += orderBy(filter.OrderBy)
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
 	}
 
 	return query, args
 }
 
+// orderBy returns the ORDER BY clause for order_by, such as "name desc": by
+// advertised start time when it's empty, and none when it names an unknown
+// column.
+func orderBy(orderBy string) string {
+	if orderBy == "" {
+		return " ORDER BY advertised_start_time ASC"
+	}
+
+	tokens := strings.Fields(strings.ToLower(orderBy))
+	if len(tokens) == 0 {
+		return ""
+	}
+	switch tokens[0] {
+	case "id", "meeting_id", "name", "number", "visible", "advertised_start_time":
+	default:
+		// unknown column ignored to avoid injection; keep defaults
+		return ""
+	}
+
+	orderDir := "ASC"
+	if len(tokens) >= 2 && tokens[1] == "desc" {
+		orderDir = "DESC"
+	}
+	return " ORDER BY " + tokens[0] + " " + orderDir
+}
+
 func (m *racesRepo) scanRaces(
 	rows *sql.Rows,
 ) ([]*racing.Race, error) {
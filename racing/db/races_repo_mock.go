@@ -3,8 +3,11 @@
 package db
 
 import (
-	racing "git.neds.sh/matty/entain/racing/proto/racing"
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
+
+	racing "git.neds.sh/matty/entain/proto/racing"
 )
 
 // RacesRepoMock is an autogenerated mock type for the RacesRepo type
@@ -12,6 +15,36 @@ type RacesRepoMock struct {
 	mock.Mock
 }
 
+// Get provides a mock function with given fields: ctx, id
+func (_m *RacesRepoMock) Get(ctx context.Context, id int64) (*racing.Race, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *racing.Race
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*racing.Race, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *racing.Race); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*racing.Race)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with no fields
 func (_m *RacesRepoMock) Init() error {
 	ret := _m.Called()
@@ -30,9 +63,9 @@ func (_m *RacesRepoMock) Init() error {
 	return r0
 }
 
-// List provides a mock function with given fields: filter
-func (_m *RacesRepoMock) List(filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
-	ret := _m.Called(filter)
+// List provides a mock function with given fields: ctx, filter
+func (_m *RacesRepoMock) List(ctx context.Context, filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -40,19 +73,19 @@ func (_m *RacesRepoMock) List(filter *racing.ListRacesRequestFilter) ([]*racing.
 
 	var r0 []*racing.Race
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*racing.ListRacesRequestFilter) ([]*racing.Race, error)); ok {
-		return rf(filter)
+	if rf, ok := ret.Get(0).(func(context.Context, *racing.ListRacesRequestFilter) ([]*racing.Race, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(*racing.ListRacesRequestFilter) []*racing.Race); ok {
-		r0 = rf(filter)
+	if rf, ok := ret.Get(0).(func(context.Context, *racing.ListRacesRequestFilter) []*racing.Race); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*racing.Race)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*racing.ListRacesRequestFilter) error); ok {
-		r1 = rf(filter)
+	if rf, ok := ret.Get(1).(func(context.Context, *racing.ListRacesRequestFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,34 +93,22 @@ func (_m *RacesRepoMock) List(filter *racing.ListRacesRequestFilter) ([]*racing.
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: id
-func (_m *RacesRepoMock) Get(id int64) (*racing.Race, error) {
-	ret := _m.Called(id)
+// Reseed provides a mock function with given fields: ctx
+func (_m *RacesRepoMock) Reseed(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for Reseed")
 	}
 
-	var r0 *racing.Race
-	var r1 error
-	if rf, ok := ret.Get(0).(func(int64) (*racing.Race, error)); ok {
-		return rf(id)
-	}
-	if rf, ok := ret.Get(0).(func(int64) *racing.Race); ok {
-		r0 = rf(id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*racing.Race)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
 // NewRacesRepoMock creates a new instance of RacesRepoMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -1,15 +1,18 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"database/sql/driver"
 	"regexp"
 	"testing"
 	"time"
 
-	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/proto/racing"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func boolPtr(b bool) *bool { return &b }
@@ -62,6 +65,16 @@ func Test_applyFilter_QueryOnly(t *testing.T) {
 			filter:    &racing.ListRacesRequestFilter{},
 			expectSQL: base + " ORDER BY advertised_start_time ASC",
 		},
+		{
+			name:      "ids + meeting_ids",
+			filter:    &racing.ListRacesRequestFilter{MeetingIds: []int64{1}, Ids: []int64{4, 5}},
+			expectSQL: base + " WHERE meeting_id IN (?) AND id IN (?,?) ORDER BY advertised_start_time ASC",
+		},
+		{
+			name:      "starts_after + limit",
+			filter:    &racing.ListRacesRequestFilter{ShowHidden: boolPtr(false), StartsAfter: timestamppb.Now(), Limit: 5},
+			expectSQL: base + " WHERE visible = 1 AND julianday(advertised_start_time) > julianday(?) ORDER BY advertised_start_time ASC LIMIT ?",
+		},
 	}
 
 	r := &racesRepo{}
@@ -122,6 +135,14 @@ func TestRacesRepo_List_WithSQLMock(t *testing.T) {
 			rows:      [][]any{},
 			wantCount: 0,
 		},
+		{
+			name:      "starts_after + limit",
+			filter:    &racing.ListRacesRequestFilter{MeetingIds: []int64{1}, StartsAfter: timestamppb.New(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)), Limit: 2},
+			expectSQL: base + " WHERE meeting_id IN (?) AND julianday(advertised_start_time) > julianday(?) ORDER BY advertised_start_time ASC LIMIT ?",
+			args:      []any{int64(1), "2026-10-19T12:00:00Z", int32(2)},
+			rows:      [][]any{},
+			wantCount: 0,
+		},
 		{
 			name:      "order by number desc",
 			filter:    &racing.ListRacesRequestFilter{OrderBy: "number desc"},
@@ -159,7 +180,7 @@ func TestRacesRepo_List_WithSQLMock(t *testing.T) {
 			}
 			exp.WillReturnRows(r)
 
-			got, err := repo.List(tt.filter)
+			got, err := repo.List(context.Background(), tt.filter)
 			require.NoError(t, err)
 			require.Len(t, got, tt.wantCount)
 			require.NoError(t, mock.ExpectationsWereMet())
@@ -169,13 +190,16 @@ func TestRacesRepo_List_WithSQLMock(t *testing.T) {
 
 func TestRacesRepo_Get_WithSQLMock(t *testing.T) {
 	baseGet := getRaceQueries()[racesGet]
+	baseRunners := getRaceQueries()[racesRunners]
 	cols := []string{"id", "meeting_id", "name", "number", "visible", "advertised_start_time"}
+	runnerCols := []string{"number", "name", "scratched", "finish_position"}
 
 	tests := []struct {
 		name      string
 		id        int64
 		expectSQL string
 		row       []any
+		runners   [][]any
 		willErr   error
 		wantNil   bool
 	}{
@@ -184,8 +208,12 @@ func TestRacesRepo_Get_WithSQLMock(t *testing.T) {
 			id:        42,
 			expectSQL: baseGet,
 			row:       []any{int64(42), int64(5), "Found Race", int64(3), true, time.Now()},
-			willErr:   nil,
-			wantNil:   false,
+			runners: [][]any{
+				{int64(1), "Runner One", false, int64(2)},
+				{int64(2), "Runner Two", true, int64(0)},
+			},
+			willErr: nil,
+			wantNil: false,
 		},
 		{
 			name:      "not found (no rows)",
@@ -225,10 +253,20 @@ func TestRacesRepo_Get_WithSQLMock(t *testing.T) {
 						drvVals = append(drvVals, v)
 					}
 					exp.WillReturnRows(sqlmock.NewRows(cols).AddRow(drvVals...))
+
+					runnerRows := sqlmock.NewRows(runnerCols)
+					for _, runner := range tt.runners {
+						var runnerVals []driver.Value
+						for _, v := range runner {
+							runnerVals = append(runnerVals, v)
+						}
+						runnerRows.AddRow(runnerVals...)
+					}
+					mock.ExpectQuery(regexp.QuoteMeta(baseRunners)).WithArgs(tt.id).WillReturnRows(runnerRows)
 				}
 			}
 
-			got, err := repo.Get(tt.id)
+			got, err := repo.Get(context.Background(), tt.id)
 			if tt.willErr != nil {
 				require.Error(t, err)
 			} else {
@@ -239,8 +277,74 @@ func TestRacesRepo_Get_WithSQLMock(t *testing.T) {
 			} else {
 				require.NotNil(t, got)
 				require.Equal(t, tt.id, got.Id)
+				require.Len(t, got.Runners, len(tt.runners))
 			}
 			require.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestRacesRepo_List_IncludeRunners(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	start := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(getRaceQueries()[racesList]+" WHERE id IN (?,?) ORDER BY advertised_start_time ASC")).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "meeting_id", "name", "number", "visible", "advertised_start_time"}).
+			AddRow(int64(1), int64(5), "Race A", int64(1), true, start).
+			AddRow(int64(2), int64(5), "Race B", int64(2), true, start))
+	mock.ExpectQuery(regexp.QuoteMeta(getRaceQueries()[racesListRunners]+" WHERE race_id IN (?,?) ORDER BY race_id ASC, number ASC")).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"race_id", "number", "name", "scratched", "finish_position"}).
+			AddRow(int64(1), int64(1), "Alpha", false, int64(0)).
+			AddRow(int64(1), int64(2), "Bravo", true, int64(0)).
+			AddRow(int64(2), int64(1), "Charlie", false, int64(0)))
+
+	repo := &racesRepo{db: sqlDB}
+	races, err := repo.List(context.Background(), &racing.ListRacesRequestFilter{Ids: []int64{1, 2}, IncludeRunners: true})
+	require.NoError(t, err)
+	require.Len(t, races, 2)
+	require.Len(t, races[0].Runners, 2)
+	require.Equal(t, "Bravo", races[0].Runners[1].Name)
+	require.True(t, races[0].Runners[1].Scratched)
+	require.Len(t, races[1].Runners, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRacesRepo_List_StartsAfter(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	_, err = sqlDB.Exec(`CREATE TABLE races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME)`)
+	require.NoError(t, err)
+
+	// Start times written in other offsets still compare by the instant.
+	for id, start := range map[int]string{
+		1: "2026-10-19T11:59:00Z",
+		2: "2026-10-19T23:04:00+11:00",
+		3: "2026-10-19T12:02:00Z",
+		4: "2026-10-19T12:03:00Z",
+		5: "2026-10-19T07:00:00-05:00",
+	} {
+		_, err := sqlDB.Exec(`INSERT INTO races(id, meeting_id, name, number, visible, advertised_start_time) VALUES (?,1,'',1,1,?)`, id, start)
+		require.NoError(t, err)
+	}
+
+	repo := &racesRepo{db: sqlDB}
+	list := func(limit int32) []int64 {
+		races, err := repo.List(context.Background(), &racing.ListRacesRequestFilter{
+			StartsAfter: timestamppb.New(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)),
+			Limit:       limit,
+		})
+		require.NoError(t, err)
+		var ids []int64
+		for _, race := range races {
+			ids = append(ids, race.Id)
+		}
+		return ids
+	}
+	require.Equal(t, []int64{3, 4, 2}, list(0))
+	require.Equal(t, []int64{3, 4}, list(2))
+}
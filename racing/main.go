@@ -1,59 +1,153 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"git.neds.sh/matty/entain/pkg/admin"
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/pkg/config"
+	"git.neds.sh/matty/entain/pkg/health"
+	"git.neds.sh/matty/entain/pkg/logging"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tlsutil"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/racing"
 	"git.neds.sh/matty/entain/racing/db"
-	"git.neds.sh/matty/entain/racing/proto/racing"
 	"git.neds.sh/matty/entain/racing/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 var (
-	grpcEndpoint = flag.String("grpc-endpoint", "localhost:9000", "gRPC server endpoint")
+	grpcEndpoint      = flag.String("grpc-endpoint", "localhost:9000", "gRPC server endpoint")
+	adminEndpoint     = flag.String("admin-endpoint", "localhost:9100", "Admin HTTP endpoint serving /metrics")
+	adminGRPCEndpoint = flag.String("admin-grpc-endpoint", "localhost:9200", "Admin gRPC endpoint serving the admin service and channelz, with reflection of them, unauthenticated")
+	adminAllowReseed  = flag.Bool("admin-allow-reseed", false, "Let the admin service reseed the database; never set in production")
+	shutdownTimeout   = flag.Duration("shutdown-timeout", 15*time.Second, "How long to let RPCs in flight finish when shutting down")
+	dbDSN             = flag.String("db-dsn", "./db/racing.db", "SQLite database DSN")
+	healthInterval    = flag.Duration("health-interval", health.DefaultInterval, "How often to check that the database is still healthy")
+	tlsFlags          = tlsutil.RegisterFlags(flag.CommandLine, "")
+	cacheFlags        = cache.RegisterFlags(flag.CommandLine)
+	traceFlags        = tracing.RegisterFlags(flag.CommandLine)
+	logFlags          = logging.RegisterFlags(flag.CommandLine)
 )
 
 func main() {
-	flag.Parse()
+	config.Parse("RACING",
+		config.Endpoint("grpc-endpoint", grpcEndpoint),
+		config.Endpoint("admin-endpoint", adminEndpoint),
+		config.Endpoint("admin-grpc-endpoint", adminGRPCEndpoint),
+		config.Required("db-dsn", dbDSN),
+		config.Positive("shutdown-timeout", shutdownTimeout),
+		config.Positive("health-interval", healthInterval),
+		tlsFlags,
+		cacheFlags,
+		traceFlags,
+		logFlags,
+	)
+
+	if err := logFlags.Setup("racing"); err != nil {
+		log.Fatalf("invalid logging flags: %s\n", err)
+	}
 
 	if err := run(); err != nil {
-		log.Fatalf("failed running grpc server: %s\n", err)
+		slog.Error("failed running grpc server", "error", err)
+		os.Exit(1)
 	}
 }
 
 func run() error {
-	conn, err := net.Listen("tcp", ":9000")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := traceFlags.Setup(context.Background(), "racing")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	conn, err := net.Listen("tcp", *grpcEndpoint)
 	if err != nil {
 		return err
 	}
 
-	racingDB, err := sql.Open("sqlite3", "./db/racing.db")
+	racingDB, err := sql.Open("sqlite3", *dbDSN)
 	if err != nil {
 		return err
 	}
+	defer racingDB.Close()
 
-	racesRepo := db.NewRacesRepo(racingDB)
+	listCache, closeCache, err := cacheFlags.New()
+	if err != nil {
+		return err
+	}
+	defer closeCache()
+
+	racesRepo := db.NewCachedRacesRepo(db.NewRacesRepo(racingDB), listCache, cacheFlags.TTL)
 	if err := racesRepo.Init(); err != nil {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	reloader, err := tlsFlags.Reloader("racing")
+	if err != nil {
+		return err
+	}
+	creds, err := tlsutil.ServerCredentials(reloader)
+	if err != nil {
+		return err
+	}
 
-	racing.RegisterRacingServer(
-		grpcServer,
-		service.NewRacingService(
-			racesRepo,
-		),
+	if err := metrics.RegisterDB(racingDB, "racing"); err != nil {
+		return err
+	}
+	if err := admin.New(*adminEndpoint).Start(); err != nil {
+		return err
+	}
+
+	interceptors := grpc.ChainUnaryInterceptor(
+		metrics.UnaryServerInterceptor(),
+		logging.UnaryServerInterceptor(),
+	)
+	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
+		health.KeepalivePolicy(),
+		interceptors,
+		grpc.StatsHandler(tracing.ServerHandler()),
 	)
 
-	log.Printf("gRPC server listening on: %s\n", *grpcEndpoint)
+	racingService := service.NewRacingService(racesRepo)
+	racing.RegisterRacingServer(grpcServer, racingService)
+	// Reflection lets grpcurl describe and call the racing API without the
+	// proto files, behind the same mTLS.
+	reflection.Register(grpcServer)
 
-	if err := grpcServer.Serve(conn); err != nil {
+	// The admin listener is plaintext and unauthenticated, so it doesn't serve
+	// the racing API: callers there could claim any role.
+	var reseed func(context.Context) error
+	if *adminAllowReseed {
+		reseed = racesRepo.Reseed
+	}
+	adminGRPC := admin.NewGRPC(*adminGRPCEndpoint, admin.NewService("racing", racingDB, reseed), interceptors)
+	if err := adminGRPC.Start(); err != nil {
 		return err
 	}
+	defer adminGRPC.Stop()
+
+	// The service is ready once its database is set up, and for as long as
+	// it answers.
+	healthServer := health.NewServer(grpcServer, racing.Racing_ServiceDesc.ServiceName)
+	go health.Watch(ctx, healthServer, racingDB.PingContext, *healthInterval, racing.Racing_ServiceDesc.ServiceName)
+
+	slog.Info("gRPC server listening", "endpoint", *grpcEndpoint)
 
-	return nil
+	return health.Serve(ctx, grpcServer, conn, healthServer, *shutdownTimeout)
 }
@@ -1,514 +0,0 @@
-// Code generated by protoc-gen-go. DO NOT EDIT.
-// versions:
-// 	protoc-gen-go v1.36.9
-// 	protoc        v5.29.3
-// source: racing/racing.proto
-
-package racing
-
-import (
-	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
-	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
-	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
-	reflect "reflect"
-	sync "sync"
-	unsafe "unsafe"
-)
-
-const (
-	// Verify that this generated code is sufficiently up-to-date.
-	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
-	// Verify that runtime/protoimpl is sufficiently up-to-date.
-	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
-)
-
-// Status is derived from advertised_start_time: OPEN (future) or CLOSED (past)
-type Race_Status int32
-
-const (
-	Race_STATUS_OPEN   Race_Status = 0
-	Race_STATUS_CLOSED Race_Status = 1
-)
-
-// Enum value maps for Race_Status.
-var (
-	Race_Status_name = map[int32]string{
-		0: "STATUS_OPEN",
-		1: "STATUS_CLOSED",
-	}
-	Race_Status_value = map[string]int32{
-		"STATUS_OPEN":   0,
-		"STATUS_CLOSED": 1,
-	}
-)
-
-func (x Race_Status) Enum() *Race_Status {
-	p := new(Race_Status)
-	*p = x
-	return p
-}
-
-func (x Race_Status) String() string {
-	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
-}
-
-func (Race_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_racing_racing_proto_enumTypes[0].Descriptor()
-}
-
-func (Race_Status) Type() protoreflect.EnumType {
-	return &file_racing_racing_proto_enumTypes[0]
-}
-
-func (x Race_Status) Number() protoreflect.EnumNumber {
-	return protoreflect.EnumNumber(x)
-}
-
-// Deprecated: Use Race_Status.Descriptor instead.
-func (Race_Status) EnumDescriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{5, 0}
-}
-
-type ListRacesRequest struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
-	Filter        *ListRacesRequestFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListRacesRequest) Reset() {
-	*x = ListRacesRequest{}
-	mi := &file_racing_racing_proto_msgTypes[0]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ListRacesRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*ListRacesRequest) ProtoMessage() {}
-
-func (x *ListRacesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[0]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ListRacesRequest.ProtoReflect.Descriptor instead.
-func (*ListRacesRequest) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{0}
-}
-
-func (x *ListRacesRequest) GetFilter() *ListRacesRequestFilter {
-	if x != nil {
-		return x.Filter
-	}
-	return nil
-}
-
-// Response to ListRaces call.
-type ListRacesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Races         []*Race                `protobuf:"bytes,1,rep,name=races,proto3" json:"races,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListRacesResponse) Reset() {
-	*x = ListRacesResponse{}
-	mi := &file_racing_racing_proto_msgTypes[1]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ListRacesResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*ListRacesResponse) ProtoMessage() {}
-
-func (x *ListRacesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[1]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ListRacesResponse.ProtoReflect.Descriptor instead.
-func (*ListRacesResponse) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{1}
-}
-
-func (x *ListRacesResponse) GetRaces() []*Race {
-	if x != nil {
-		return x.Races
-	}
-	return nil
-}
-
-// Request for GetRace call.
-type GetRaceRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *GetRaceRequest) Reset() {
-	*x = GetRaceRequest{}
-	mi := &file_racing_racing_proto_msgTypes[2]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *GetRaceRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*GetRaceRequest) ProtoMessage() {}
-
-func (x *GetRaceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[2]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use GetRaceRequest.ProtoReflect.Descriptor instead.
-func (*GetRaceRequest) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{2}
-}
-
-func (x *GetRaceRequest) GetId() int64 {
-	if x != nil {
-		return x.Id
-	}
-	return 0
-}
-
-// Response to GetRace call.
-type GetRaceResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Race          *Race                  `protobuf:"bytes,1,opt,name=race,proto3" json:"race,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *GetRaceResponse) Reset() {
-	*x = GetRaceResponse{}
-	mi := &file_racing_racing_proto_msgTypes[3]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *GetRaceResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*GetRaceResponse) ProtoMessage() {}
-
-func (x *GetRaceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[3]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use GetRaceResponse.ProtoReflect.Descriptor instead.
-func (*GetRaceResponse) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *GetRaceResponse) GetRace() *Race {
-	if x != nil {
-		return x.Race
-	}
-	return nil
-}
-
-// Filter for listing races.
-type ListRacesRequestFilter struct {
-	state      protoimpl.MessageState `protogen:"open.v1"`
-	MeetingIds []int64                `protobuf:"varint,1,rep,packed,name=meeting_ids,json=meetingIds,proto3" json:"meeting_ids,omitempty"`
-	// When unset, include hidden (default). Set false to only visible; true to include hidden.
-	ShowHidden *bool `protobuf:"varint,2,opt,name=show_hidden,json=showHidden,proto3,oneof" json:"show_hidden,omitempty"`
-	// Order by, e.g. "advertised_start_time" or "advertised_start_time desc" (default asc)
-	OrderBy       string `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListRacesRequestFilter) Reset() {
-	*x = ListRacesRequestFilter{}
-	mi := &file_racing_racing_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ListRacesRequestFilter) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*ListRacesRequestFilter) ProtoMessage() {}
-
-func (x *ListRacesRequestFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[4]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ListRacesRequestFilter.ProtoReflect.Descriptor instead.
-func (*ListRacesRequestFilter) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *ListRacesRequestFilter) GetMeetingIds() []int64 {
-	if x != nil {
-		return x.MeetingIds
-	}
-	return nil
-}
-
-func (x *ListRacesRequestFilter) GetShowHidden() bool {
-	if x != nil && x.ShowHidden != nil {
-		return *x.ShowHidden
-	}
-	return false
-}
-
-func (x *ListRacesRequestFilter) GetOrderBy() string {
-	if x != nil {
-		return x.OrderBy
-	}
-	return ""
-}
-
-// A race resource.
-type Race struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// ID represents a unique identifier for the race.
-	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	// MeetingID represents a unique identifier for the races meeting.
-	MeetingId int64 `protobuf:"varint,2,opt,name=meeting_id,json=meetingId,proto3" json:"meeting_id,omitempty"`
-	// Name is the official name given to the race.
-	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	// Number represents the number of the race.
-	Number int64 `protobuf:"varint,4,opt,name=number,proto3" json:"number,omitempty"`
-	// Visible represents whether or not the race is visible.
-	Visible bool `protobuf:"varint,5,opt,name=visible,proto3" json:"visible,omitempty"`
-	// AdvertisedStartTime is the time the race is advertised to run.
-	AdvertisedStartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=advertised_start_time,json=advertisedStartTime,proto3" json:"advertised_start_time,omitempty"`
-	Status              Race_Status            `protobuf:"varint,7,opt,name=status,proto3,enum=racing.Race_Status" json:"status,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
-}
-
-func (x *Race) Reset() {
-	*x = Race{}
-	mi := &file_racing_racing_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *Race) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*Race) ProtoMessage() {}
-
-func (x *Race) ProtoReflect() protoreflect.Message {
-	mi := &file_racing_racing_proto_msgTypes[5]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use Race.ProtoReflect.Descriptor instead.
-func (*Race) Descriptor() ([]byte, []int) {
-	return file_racing_racing_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *Race) GetId() int64 {
-	if x != nil {
-		return x.Id
-	}
-	return 0
-}
-
-func (x *Race) GetMeetingId() int64 {
-	if x != nil {
-		return x.MeetingId
-	}
-	return 0
-}
-
-func (x *Race) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *Race) GetNumber() int64 {
-	if x != nil {
-		return x.Number
-	}
-	return 0
-}
-
-func (x *Race) GetVisible() bool {
-	if x != nil {
-		return x.Visible
-	}
-	return false
-}
-
-func (x *Race) GetAdvertisedStartTime() *timestamppb.Timestamp {
-	if x != nil {
-		return x.AdvertisedStartTime
-	}
-	return nil
-}
-
-func (x *Race) GetStatus() Race_Status {
-	if x != nil {
-		return x.Status
-	}
-	return Race_STATUS_OPEN
-}
-
-var File_racing_racing_proto protoreflect.FileDescriptor
-
-const file_racing_racing_proto_rawDesc = "" +
-	"\n" +
-	"\x13racing/racing.proto\x12\x06racing\x1a\x1fgoogle/protobuf/timestamp.proto\"J\n" +
-	"\x10ListRacesRequest\x126\n" +
-	"\x06filter\x18\x01 \x01(\v2\x1e.racing.ListRacesRequestFilterR\x06filter\"7\n" +
-	"\x11ListRacesResponse\x12\"\n" +
-	"\x05races\x18\x01 \x03(\v2\f.racing.RaceR\x05races\" \n" +
-	"\x0eGetRaceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"3\n" +
-	"\x0fGetRaceResponse\x12 \n" +
-	"\x04race\x18\x01 \x01(\v2\f.racing.RaceR\x04race\"\x8a\x01\n" +
-	"\x16ListRacesRequestFilter\x12\x1f\n" +
-	"\vmeeting_ids\x18\x01 \x03(\x03R\n" +
-	"meetingIds\x12$\n" +
-	"\vshow_hidden\x18\x02 \x01(\bH\x00R\n" +
-	"showHidden\x88\x01\x01\x12\x19\n" +
-	"\border_by\x18\x03 \x01(\tR\aorderByB\x0e\n" +
-	"\f_show_hidden\"\xa6\x02\n" +
-	"\x04Race\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
-	"\n" +
-	"meeting_id\x18\x02 \x01(\x03R\tmeetingId\x12\x12\n" +
-	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
-	"\x06number\x18\x04 \x01(\x03R\x06number\x12\x18\n" +
-	"\avisible\x18\x05 \x01(\bR\avisible\x12N\n" +
-	"\x15advertised_start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x13advertisedStartTime\x12+\n" +
-	"\x06status\x18\a \x01(\x0e2\x13.racing.Race.StatusR\x06status\",\n" +
-	"\x06Status\x12\x0f\n" +
-	"\vSTATUS_OPEN\x10\x00\x12\x11\n" +
-	"\rSTATUS_CLOSED\x10\x012\x8a\x01\n" +
-	"\x06Racing\x12B\n" +
-	"\tListRaces\x12\x18.racing.ListRacesRequest\x1a\x19.racing.ListRacesResponse\"\x00\x12<\n" +
-	"\aGetRace\x12\x16.racing.GetRaceRequest\x1a\x17.racing.GetRaceResponse\"\x00B\tZ\a/racingb\x06proto3"
-
-var (
-	file_racing_racing_proto_rawDescOnce sync.Once
-	file_racing_racing_proto_rawDescData []byte
-)
-
-func file_racing_racing_proto_rawDescGZIP() []byte {
-	file_racing_racing_proto_rawDescOnce.Do(func() {
-		file_racing_racing_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_racing_racing_proto_rawDesc), len(file_racing_racing_proto_rawDesc)))
-	})
-	return file_racing_racing_proto_rawDescData
-}
-
-var file_racing_racing_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_racing_racing_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_racing_racing_proto_goTypes = []any{
-	(Race_Status)(0),               // 0: racing.Race.Status
-	(*ListRacesRequest)(nil),       // 1: racing.ListRacesRequest
-	(*ListRacesResponse)(nil),      // 2: racing.ListRacesResponse
-	(*GetRaceRequest)(nil),         // 3: racing.GetRaceRequest
-	(*GetRaceResponse)(nil),        // 4: racing.GetRaceResponse
-	(*ListRacesRequestFilter)(nil), // 5: racing.ListRacesRequestFilter
-	(*Race)(nil),                   // 6: racing.Race
-	(*timestamppb.Timestamp)(nil),  // 7: google.protobuf.Timestamp
-}
-var file_racing_racing_proto_depIdxs = []int32{
-	5, // 0: racing.ListRacesRequest.filter:type_name -> racing.ListRacesRequestFilter
-	6, // 1: racing.ListRacesResponse.races:type_name -> racing.Race
-	6, // 2: racing.GetRaceResponse.race:type_name -> racing.Race
-	7, // 3: racing.Race.advertised_start_time:type_name -> google.protobuf.Timestamp
-	0, // 4: racing.Race.status:type_name -> racing.Race.Status
-	1, // 5: racing.Racing.ListRaces:input_type -> racing.ListRacesRequest
-	3, // 6: racing.Racing.GetRace:input_type -> racing.GetRaceRequest
-	2, // 7: racing.Racing.ListRaces:output_type -> racing.ListRacesResponse
-	4, // 8: racing.Racing.GetRace:output_type -> racing.GetRaceResponse
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
-}
-
-func init() { file_racing_racing_proto_init() }
-func file_racing_racing_proto_init() {
-	if File_racing_racing_proto != nil {
-		return
-	}
-	file_racing_racing_proto_msgTypes[4].OneofWrappers = []any{}
-	type x struct{}
-	out := protoimpl.TypeBuilder{
-		File: protoimpl.DescBuilder{
-			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_racing_racing_proto_rawDesc), len(file_racing_racing_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   6,
-			NumExtensions: 0,
-			NumServices:   1,
-		},
-		GoTypes:           file_racing_racing_proto_goTypes,
-		DependencyIndexes: file_racing_racing_proto_depIdxs,
-		EnumInfos:         file_racing_racing_proto_enumTypes,
-		MessageInfos:      file_racing_racing_proto_msgTypes,
-	}.Build()
-	File_racing_racing_proto = out.File
-	file_racing_racing_proto_goTypes = nil
-	file_racing_racing_proto_depIdxs = nil
-}
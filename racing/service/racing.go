@@ -3,8 +3,9 @@ package service
 import (
 	"time"
 
+	"git.neds.sh/matty/entain/pkg/visibility"
+	"git.neds.sh/matty/entain/proto/racing"
 	"git.neds.sh/matty/entain/racing/db"
-	"git.neds.sh/matty/entain/racing/proto/racing"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -28,7 +29,14 @@ func NewRacingService(racesRepo db.RacesRepo) Racing {
 }
 
 func (s *racingService) ListRaces(ctx context.Context, in *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
-	races, err := s.racesRepo.List(in.Filter)
+	filter := in.Filter
+	if filter == nil {
+		filter = &racing.ListRacesRequestFilter{}
+	}
+	// Hidden races are only listed when a privileged caller asks for them.
+	filter.ShowHidden = visibility.ShowHidden(ctx, filter.GetShowHidden())
+
+	races, err := s.racesRepo.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -46,11 +54,12 @@ func (s *racingService) ListRaces(ctx context.Context, in *racing.ListRacesReque
 }
 
 func (s *racingService) GetRace(ctx context.Context, in *racing.GetRaceRequest) (*racing.GetRaceResponse, error) {
-	race, err := s.racesRepo.Get(in.Id)
+	race, err := s.racesRepo.Get(ctx, in.Id)
 	if err != nil {
 		return nil, err
 	}
-	if race == nil {
+	// Hidden races don't exist as far as public callers can tell.
+	if race == nil || !visibility.CanSee(ctx, race.Visible) {
 		return nil, status.Error(codes.NotFound, "race not found")
 	}
 
@@ -4,14 +4,30 @@ import (
 	"testing"
 	"time"
 
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/pkg/visibility"
+	"git.neds.sh/matty/entain/proto/racing"
 	"git.neds.sh/matty/entain/racing/db"
-	"git.neds.sh/matty/entain/racing/proto/racing"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+func boolPtr(b bool) *bool { return &b }
+
+// callerContext returns an incoming context for a caller holding roles, as the gateway forwards them.
+func callerContext(roles string) context.Context {
+	if roles == "" {
+		return context.Background()
+	}
+
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(visibility.MetadataRoles, roles))
+}
+
 func TestRacingService_ListRaces_StatusDerivation(t *testing.T) {
 	now := time.Now()
 	past := now.Add(-time.Hour)
@@ -44,7 +60,7 @@ func TestRacingService_ListRaces_StatusDerivation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := db.NewRacesRepoMock(t)
-			m.On("List", mock.AnythingOfType("*racing.ListRacesRequestFilter")).Return(tt.repoRaces, tt.repoErr).Once()
+			m.On("List", mock.Anything, mock.AnythingOfType("*racing.ListRacesRequestFilter")).Return(tt.repoRaces, tt.repoErr).Once()
 
 			svc := NewRacingService(m)
 			got, err := svc.ListRaces(context.Background(), &racing.ListRacesRequest{})
@@ -79,7 +95,7 @@ func TestRacingService_GetRace_NotFoundAndOK(t *testing.T) {
 		},
 		{
 			name:       "found",
-			repoRace:   &racing.Race{Id: 99, AdvertisedStartTime: timestamppb.New(future)},
+			repoRace:   &racing.Race{Id: 99, Visible: true, AdvertisedStartTime: timestamppb.New(future)},
 			repoErr:    nil,
 			expectCode: "OK",
 		},
@@ -89,9 +105,9 @@ func TestRacingService_GetRace_NotFoundAndOK(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m := db.NewRacesRepoMock(t)
 			if tt.repoRace == nil {
-				m.On("Get", int64(99)).Return((*racing.Race)(nil), nil).Once()
+				m.On("Get", mock.Anything, int64(99)).Return((*racing.Race)(nil), nil).Once()
 			} else {
-				m.On("Get", int64(99)).Return(tt.repoRace, nil).Once()
+				m.On("Get", mock.Anything, int64(99)).Return(tt.repoRace, nil).Once()
 			}
 
 			svc := NewRacingService(m)
@@ -108,3 +124,68 @@ func TestRacingService_GetRace_NotFoundAndOK(t *testing.T) {
 		})
 	}
 }
+
+func TestRacingService_ListRaces_Visibility(t *testing.T) {
+	tests := []struct {
+		name           string
+		roles          string
+		filter         *racing.ListRacesRequestFilter
+		wantShowHidden bool
+	}{
+		{name: "public caller defaults to visible only"},
+		{name: "public caller can't opt into hidden", filter: &racing.ListRacesRequestFilter{ShowHidden: boolPtr(true)}},
+		{name: "customer can't opt into hidden", roles: "customer", filter: &racing.ListRacesRequestFilter{ShowHidden: boolPtr(true)}},
+		{name: "trader defaults to visible only", roles: "trading"},
+		{name: "trader opts into hidden", roles: "customer,trading", filter: &racing.ListRacesRequestFilter{ShowHidden: boolPtr(true)}, wantShowHidden: true},
+		{name: "admin opts into hidden", roles: "admin", filter: &racing.ListRacesRequestFilter{ShowHidden: boolPtr(true)}, wantShowHidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := db.NewRacesRepoMock(t)
+			m.On("List", mock.Anything, mock.MatchedBy(func(f *racing.ListRacesRequestFilter) bool {
+				return f.ShowHidden != nil && *f.ShowHidden == tt.wantShowHidden
+			})).Return([]*racing.Race{}, nil).Once()
+
+			svc := NewRacingService(m)
+			_, err := svc.ListRaces(callerContext(tt.roles), &racing.ListRacesRequest{Filter: tt.filter})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRacingService_GetRace_Hidden(t *testing.T) {
+	hidden := &racing.Race{Id: 99, Visible: false, AdvertisedStartTime: timestamppb.New(time.Now())}
+
+	m := db.NewRacesRepoMock(t)
+	m.On("Get", mock.Anything, int64(99)).Return(hidden, nil).Times(3)
+	svc := NewRacingService(m)
+
+	_, err := svc.GetRace(callerContext(""), &racing.GetRaceRequest{Id: 99})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = svc.GetRace(callerContext("customer"), &racing.GetRaceRequest{Id: 99})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := svc.GetRace(callerContext("trading"), &racing.GetRaceRequest{Id: 99})
+	require.NoError(t, err)
+	require.Equal(t, int64(99), resp.Race.Id)
+}
+
+func TestRacingService_ListRaces_CachedStatus(t *testing.T) {
+	start := time.Now().Add(50 * time.Millisecond)
+
+	m := db.NewRacesRepoMock(t)
+	m.On("List", mock.Anything, mock.Anything).Return([]*racing.Race{{Id: 1, AdvertisedStartTime: timestamppb.New(start)}}, nil).Once()
+	svc := NewRacingService(db.NewCachedRacesRepo(m, cache.NewLRU(10), time.Minute))
+
+	got, err := svc.ListRaces(context.Background(), &racing.ListRacesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, racing.Race_STATUS_OPEN, got.Races[0].Status)
+
+	// The race jumps while still cached, and closes all the same.
+	time.Sleep(time.Until(start))
+	got, err = svc.ListRaces(context.Background(), &racing.ListRacesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, racing.Race_STATUS_CLOSED, got.Races[0].Status)
+}
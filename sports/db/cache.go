@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+// cachedEventsRepo serves event lists from a cache, querying the repository
+// it wraps only on a miss.
+type cachedEventsRepo struct {
+	EventsRepo
+	lists *cache.Namespace
+}
+
+// NewCachedEventsRepo caches the events repo lists in c for ttl, keyed by the
+// normalised filter. Events are cached without a status, which callers derive
+// from the advertised start time on every read. A nil c leaves repo uncached.
+func NewCachedEventsRepo(repo EventsRepo, c cache.Cache, ttl time.Duration) EventsRepo {
+	if c == nil {
+		return repo
+	}
+	return &cachedEventsRepo{EventsRepo: repo, lists: cache.NewNamespace(c, repoName, ttl)}
+}
+
+// Init seeds the repository, then invalidates the lists cached before. A
+// cache that can't be reached doesn't stop the service starting: lists
+// cached before expire within their TTL all the same.
+func (r *cachedEventsRepo) Init() error {
+	if err := r.EventsRepo.Init(); err != nil {
+		return err
+	}
+	r.invalidate(context.Background())
+	return nil
+}
+
+// Reseed reseeds the repository, then invalidates the lists cached before.
+func (r *cachedEventsRepo) Reseed(ctx context.Context) error {
+	if err := r.EventsRepo.Reseed(ctx); err != nil {
+		return err
+	}
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *cachedEventsRepo) invalidate(ctx context.Context) {
+	if err := r.lists.Invalidate(ctx); err != nil {
+		slog.WarnContext(ctx, "failed invalidating the cache", "cache", repoName, "error", err)
+	}
+}
+
+func (r *cachedEventsRepo) List(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
+	// Lists of what starts after a time change as it passes, so the whole
+	// list is cached and cut off at the time on each read.
+	if startsAfter := filter.GetStartsAfter(); startsAfter != nil {
+		unbounded := proto.Clone(filter).(*sports.ListEventsRequestFilter)
+		unbounded.StartsAfter, unbounded.Limit = nil, 0
+
+		events, err := r.list(ctx, unbounded)
+		if err != nil {
+			return nil, err
+		}
+		return startingAfter(events, startsAfter.AsTime(), filter.Limit), nil
+	}
+
+	return r.list(ctx, filter)
+}
+
+func (r *cachedEventsRepo) list(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
+	value, err := r.lists.Get(ctx, listKey(filter), func(ctx context.Context) ([]byte, error) {
+		events, err := r.EventsRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(&sports.ListEventsResponse{Events: events})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp sports.ListEventsResponse
+	if err := proto.Unmarshal(value, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// startingAfter keeps the events, in order, advertised to start after t, up
+// to limit of them when it's positive, as the database would list them.
+func startingAfter(events []*sports.Event, t time.Time, limit int32) []*sports.Event {
+	kept := events[:0]
+	for _, event := range events {
+		if limit > 0 && len(kept) == int(limit) {
+			break
+		}
+		if event.GetAdvertisedStartTime().AsTime().After(t) {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
+
+// listKey identifies the events filter lists, so filters listing the same
+// events in the same order share a key: sport and event IDs in any order, and
+// order_by in any case or spacing.
+func listKey(filter *sports.ListEventsRequestFilter) string {
+	if filter == nil {
+		return "all"
+	}
+
+	sportIDs := slices.Compact(slices.Sorted(slices.Values(filter.SportIds)))
+	ids := slices.Compact(slices.Sorted(slices.Values(filter.Ids)))
+
+	hidden := "any"
+	if filter.ShowHidden != nil && !*filter.ShowHidden {
+		hidden = "visible"
+	}
+
+	return fmt.Sprintf("sports=%v;ids=%v;hidden=%s;order=%s;limit=%d", sportIDs, ids, hidden, orderBy(filter.OrderBy), max(filter.Limit, 0))
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/proto/sports"
+)
+
+func TestCachedEventsRepo(t *testing.T) {
+	ctx := context.Background()
+	events := []*sports.Event{{Id: 1, SportId: 3, Name: "Final"}}
+
+	m := NewEventsRepoMock(t)
+	m.On("Init").Return(nil).Once()
+	m.On("List", mock.Anything, mock.Anything).Return(events, nil).Twice()
+
+	repo := NewCachedEventsRepo(m, cache.NewLRU(10), time.Minute)
+
+	for _, filter := range []*sports.ListEventsRequestFilter{
+		{SportIds: []int64{3, 4}, OrderBy: "name"},
+		{SportIds: []int64{4, 3, 3}, OrderBy: " NAME asc"},
+	} {
+		got, err := repo.List(ctx, filter)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "Final", got[0].Name)
+	}
+	m.AssertNumberOfCalls(t, "List", 1)
+
+	// A different filter lists different events.
+	_, err := repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{3}})
+	assert.NoError(t, err)
+	m.AssertNumberOfCalls(t, "List", 2)
+
+	// Event IDs in any order share a key too.
+	m.On("List", mock.Anything, mock.Anything).Return(events, nil).Once()
+	for _, ids := range [][]int64{{1, 2}, {2, 1}} {
+		_, err = repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{3}, Ids: ids})
+		assert.NoError(t, err)
+	}
+	m.AssertNumberOfCalls(t, "List", 3)
+
+	// A limit lists different events.
+	m.On("List", mock.Anything, mock.Anything).Return(events, nil).Once()
+	_, err = repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{3}, Limit: 1})
+	assert.NoError(t, err)
+	m.AssertNumberOfCalls(t, "List", 4)
+
+	// Lists of events starting after a time are cut from the whole list,
+	// which is cached without the time or limit.
+	now := time.Now()
+	upcoming := []*sports.Event{
+		{Id: 5, SportId: 5, AdvertisedStartTime: timestamppb.New(now.Add(-time.Minute))},
+		{Id: 6, SportId: 5, AdvertisedStartTime: timestamppb.New(now.Add(time.Minute))},
+		{Id: 7, SportId: 5, AdvertisedStartTime: timestamppb.New(now.Add(2 * time.Minute))},
+	}
+	unbounded := mock.MatchedBy(func(f *sports.ListEventsRequestFilter) bool { return f.StartsAfter == nil && f.Limit == 0 })
+	m.On("List", mock.Anything, unbounded).Return(upcoming, nil).Once()
+	for _, tt := range []struct {
+		startsAfter time.Time
+		limit       int32
+		want        []int64
+	}{
+		{startsAfter: now, want: []int64{6, 7}},
+		{startsAfter: now, limit: 1, want: []int64{6}},
+		{startsAfter: now.Add(90 * time.Second), want: []int64{7}},
+	} {
+		got, err := repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{5}, StartsAfter: timestamppb.New(tt.startsAfter), Limit: tt.limit})
+		assert.NoError(t, err)
+		var ids []int64
+		for _, event := range got {
+			ids = append(ids, event.Id)
+		}
+		assert.Equal(t, tt.want, ids)
+	}
+	m.AssertNumberOfCalls(t, "List", 5)
+
+	// Writing to the repository drops what it listed before.
+	assert.NoError(t, repo.Init())
+	m.On("List", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	got, err := repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{3, 4}, OrderBy: "name"})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	// So does reseeding it.
+	m.On("Reseed", mock.Anything).Return(nil).Once()
+	assert.NoError(t, repo.Reseed(ctx))
+	m.On("List", mock.Anything, mock.Anything).Return(events, nil).Once()
+	got, err = repo.List(ctx, &sports.ListEventsRequestFilter{SportIds: []int64{3, 4}, OrderBy: "name"})
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"syreclabs.com/go/faker"
@@ -118,3 +119,11 @@ func (r *eventsRepo) seed() error {
 
 	return nil
 }
+
+// Reseed deletes the events, then seeds new ones.
+func (r *eventsRepo) Reseed(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM events`); err != nil {
+		return err
+	}
+	return r.seed()
+}
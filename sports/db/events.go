@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"sync"
@@ -9,9 +10,14 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
-	"git.neds.sh/matty/entain/sports/proto/sports"
+	"git.neds.sh/matty/entain/pkg/metrics"
+	"git.neds.sh/matty/entain/pkg/tracing"
+	"git.neds.sh/matty/entain/proto/sports"
 )
 
+// repoName labels the events repository's query metrics and spans.
+const repoName = "events"
+
 // EventsRepo provides repository access to sports events.
 //
 //go:generate mockery --name EventsRepo --structname EventsRepoMock --dir . --output . --outpkg db --filename events_repo_mock.go
@@ -20,7 +26,10 @@ type EventsRepo interface {
 	Init() error
 
 	// List will return a list of sports events.
-	List(filter *sports.ListEventsRequestFilter) ([]*sports.Event, error)
+	List(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error)
+
+	// Reseed replaces every event with freshly seeded ones.
+	Reseed(ctx context.Context) error
 }
 
 type eventsRepo struct {
@@ -45,9 +54,8 @@ func (r *eventsRepo) Init() error {
 	return err
 }
 
-func (r *eventsRepo) List(filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
+func (r *eventsRepo) List(ctx context.Context, filter *sports.ListEventsRequestFilter) (events []*sports.Event, err error) {
 	var (
-		err   error
 		query string
 		args  []any
 	)
@@ -56,7 +64,13 @@ func (r *eventsRepo) List(filter *sports.ListEventsRequestFilter) ([]*sports.Eve
 
 	query, args = r.applyFilter(query, filter)
 
-	rows, err := r.db.Query(query, args...)
+	ctx, span := tracing.StartQuery(ctx, repoName, eventsList, query)
+	defer func(start time.Time) {
+		metrics.ObserveQuery(repoName, eventsList, start, len(events), err)
+		tracing.EndQuery(span, len(events), err)
+	}(time.Now())
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -82,43 +96,66 @@ func (r *eventsRepo) applyFilter(query string, filter *sports.ListEventsRequestF
 		}
 	}
 
+	if len(filter.Ids) > 0 {
+		clauses = append(clauses, "id IN ("+strings.Repeat("?,", len(filter.Ids)-1)+"?)")
+
+		for _, id := range filter.Ids {
+			args = append(args, id)
+		}
+	}
+
 	// show_hidden semantics: unset or true => include hidden; false => only visible
 	if filter.ShowHidden != nil && !*filter.ShowHidden {
 		clauses = append(clauses, "visible = 1")
 	}
 
+	// Start times are stored as RFC 3339 text, whose offsets may differ, so
+	// they're compared as Julian days rather than as text.
+	if filter.StartsAfter != nil {
+		clauses = append(clauses, "julianday(advertised_start_time) > julianday(?)")
+		args = append(args, filter.StartsAfter.AsTime().Format(time.RFC3339Nano))
+	}
+
 	if len(clauses) != 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
 	}
 
-	if filter.OrderBy != "" {
-		ob := strings.ToLower(strings.TrimSpace(filter.OrderBy))
-		tokens := strings.Fields(ob)
-		if len(tokens) >= 1 {
-			switch tokens[0] {
-			case "id", "sport_id", "name", "venue", "visible", "advertised_start_time", "home_team", "away_team":
-				orderField := tokens[0]
-				orderDir := "ASC"
-				if len(tokens) >= 2 {
-					switch tokens[1] {
-					case "desc":
-						orderDir = "DESC"
-					case "asc":
-						orderDir = "ASC"
-					}
-				}
-				query += " ORDER BY " + orderField + " " + orderDir
-			default:
-				// unknown column ignored to avoid injection; keep defaults
-			}
-		}
-	} else {
-		query += " ORDER BY advertised_start_time ASC"
+	query += orderBy(filter.OrderBy)
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
 	}
 
 	return query, args
 }
 
+// orderBy returns the ORDER BY clause for order_by, such as "name desc": by
+// advertised start time when it's empty, and none when it names an unknown
+// column.
+func orderBy(orderBy string) string {
+	if orderBy == "" {
+		return " ORDER BY advertised_start_time ASC"
+	}
+
+	tokens := strings.Fields(strings.ToLower(orderBy))
+	if len(tokens) == 0 {
+		return ""
+	}
+	switch tokens[0] {
+	case "id", "sport_id", "name", "venue", "visible", "advertised_start_time", "home_team", "away_team":
+	default:
+		// unknown column ignored to avoid injection; keep defaults
+		return ""
+	}
+
+	orderDir := "ASC"
+	if len(tokens) >= 2 && tokens[1] == "desc" {
+		orderDir = "DESC"
+	}
+	return " ORDER BY " + tokens[0] + " " + orderDir
+}
+
 func (m *eventsRepo) scanEvents(
 	rows *sql.Rows,
 ) ([]*sports.Event, error) {
@@ -3,8 +3,11 @@
 package db
 
 import (
-	sports "git.neds.sh/matty/entain/sports/proto/sports"
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
+
+	sports "git.neds.sh/matty/entain/proto/sports"
 )
 
 // EventsRepoMock is an autogenerated mock type for the EventsRepo type
@@ -30,9 +33,9 @@ func (_m *EventsRepoMock) Init() error {
 	return r0
 }
 
-// List provides a mock function with given fields: filter
-func (_m *EventsRepoMock) List(filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
-	ret := _m.Called(filter)
+// List provides a mock function with given fields: ctx, filter
+func (_m *EventsRepoMock) List(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -40,19 +43,19 @@ func (_m *EventsRepoMock) List(filter *sports.ListEventsRequestFilter) ([]*sport
 
 	var r0 []*sports.Event
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*sports.ListEventsRequestFilter) ([]*sports.Event, error)); ok {
-		return rf(filter)
+	if rf, ok := ret.Get(0).(func(context.Context, *sports.ListEventsRequestFilter) ([]*sports.Event, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(*sports.ListEventsRequestFilter) []*sports.Event); ok {
-		r0 = rf(filter)
+	if rf, ok := ret.Get(0).(func(context.Context, *sports.ListEventsRequestFilter) []*sports.Event); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*sports.Event)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*sports.ListEventsRequestFilter) error); ok {
-		r1 = rf(filter)
+	if rf, ok := ret.Get(1).(func(context.Context, *sports.ListEventsRequestFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,6 +63,24 @@ func (_m *EventsRepoMock) List(filter *sports.ListEventsRequestFilter) ([]*sport
 	return r0, r1
 }
 
+// Reseed provides a mock function with given fields: ctx
+func (_m *EventsRepoMock) Reseed(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reseed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewEventsRepoMock creates a new instance of EventsRepoMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewEventsRepoMock(t interface {
@@ -2,9 +2,11 @@ package db
 
 import (
 	"testing"
+	"time"
 
-	"git.neds.sh/matty/entain/sports/proto/sports"
+	"git.neds.sh/matty/entain/proto/sports"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestEventsRepo_applyFilter(t *testing.T) {
@@ -117,6 +119,24 @@ func TestEventsRepo_applyFilter(t *testing.T) {
 			expectedQuery: baseQuery + " WHERE sport_id IN (?,?) AND visible = 1 ORDER BY name DESC",
 			expectedArgs:  []any{int64(1), int64(3)},
 		},
+		{
+			name: "ids",
+			filter: &sports.ListEventsRequestFilter{
+				Ids:        []int64{7, 8},
+				ShowHidden: boolPtr(false),
+			},
+			expectedQuery: baseQuery + " WHERE id IN (?,?) AND visible = 1 ORDER BY advertised_start_time ASC",
+			expectedArgs:  []any{int64(7), int64(8)},
+		},
+		{
+			name: "starts_after and limit",
+			filter: &sports.ListEventsRequestFilter{
+				StartsAfter: timestamppb.New(time.Date(2026, 10, 19, 12, 0, 0, 500, time.FixedZone("AEDT", 11*60*60))),
+				Limit:       10,
+			},
+			expectedQuery: baseQuery + " WHERE julianday(advertised_start_time) > julianday(?) ORDER BY advertised_start_time ASC LIMIT ?",
+			expectedArgs:  []any{"2026-10-19T01:00:00.0000005Z", int32(10)},
+		},
 	}
 
 	for _, tt := range tests {
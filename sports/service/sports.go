@@ -3,8 +3,9 @@ package service
 import (
 	"time"
 
+	"git.neds.sh/matty/entain/pkg/visibility"
+	"git.neds.sh/matty/entain/proto/sports"
 	"git.neds.sh/matty/entain/sports/db"
-	"git.neds.sh/matty/entain/sports/proto/sports"
 	"golang.org/x/net/context"
 )
 
@@ -24,7 +25,14 @@ func NewSportsService(eventsRepo db.EventsRepo) *sportsService {
 }
 
 func (s *sportsService) ListEvents(ctx context.Context, in *sports.ListEventsRequest) (*sports.ListEventsResponse, error) {
-	events, err := s.eventsRepo.List(in.Filter)
+	filter := in.Filter
+	if filter == nil {
+		filter = &sports.ListEventsRequestFilter{}
+	}
+	// Hidden events are only listed when a privileged caller asks for them.
+	filter.ShowHidden = visibility.ShowHidden(ctx, filter.GetShowHidden())
+
+	events, err := s.eventsRepo.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -5,12 +5,15 @@ import (
 	"testing"
 	"time"
 
+	"git.neds.sh/matty/entain/pkg/cache"
+	"git.neds.sh/matty/entain/pkg/visibility"
+	"git.neds.sh/matty/entain/proto/sports"
 	"git.neds.sh/matty/entain/sports/db"
-	"git.neds.sh/matty/entain/sports/proto/sports"
 	"git.neds.sh/matty/entain/sports/service"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -56,7 +59,7 @@ func TestSportsService_ListEvents(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := db.NewEventsRepoMock(t)
-			repo.On("List", mock.Anything).Return(tt.events, tt.mockErr)
+			repo.On("List", mock.Anything, mock.Anything).Return(tt.events, tt.mockErr)
 			svc := service.NewSportsService(repo)
 
 			resp, err := svc.ListEvents(context.Background(), &sports.ListEventsRequest{Filter: &sports.ListEventsRequestFilter{}})
@@ -73,3 +76,56 @@ func TestSportsService_ListEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestSportsService_ListEvents_Visibility(t *testing.T) {
+	showHidden := true
+
+	tests := []struct {
+		name           string
+		roles          string
+		filter         *sports.ListEventsRequestFilter
+		wantShowHidden bool
+	}{
+		{name: "public caller defaults to visible only"},
+		{name: "public caller can't opt into hidden", filter: &sports.ListEventsRequestFilter{ShowHidden: &showHidden}},
+		{name: "partner can't opt into hidden", roles: "partner", filter: &sports.ListEventsRequestFilter{ShowHidden: &showHidden}},
+		{name: "trader defaults to visible only", roles: "trading", filter: &sports.ListEventsRequestFilter{}},
+		{name: "trader opts into hidden", roles: "trading", filter: &sports.ListEventsRequestFilter{ShowHidden: &showHidden}, wantShowHidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.roles != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(visibility.MetadataRoles, tt.roles))
+			}
+
+			repo := db.NewEventsRepoMock(t)
+			repo.On("List", mock.Anything, mock.MatchedBy(func(f *sports.ListEventsRequestFilter) bool {
+				return f.ShowHidden != nil && *f.ShowHidden == tt.wantShowHidden
+			})).Return([]*sports.Event{}, nil).Once()
+			svc := service.NewSportsService(repo)
+
+			_, err := svc.ListEvents(ctx, &sports.ListEventsRequest{Filter: tt.filter})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSportsService_ListEvents_CachedStatus(t *testing.T) {
+	start := time.Now().Add(50 * time.Millisecond)
+
+	m := db.NewEventsRepoMock(t)
+	m.On("List", mock.Anything, mock.Anything).Return([]*sports.Event{{Id: 1, AdvertisedStartTime: timestamppb.New(start)}}, nil).Once()
+	svc := service.NewSportsService(db.NewCachedEventsRepo(m, cache.NewLRU(10), time.Minute))
+
+	resp, err := svc.ListEvents(context.Background(), &sports.ListEventsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, sports.Event_STATUS_OPEN, resp.Events[0].Status)
+
+	// The event starts while still cached, and closes all the same.
+	time.Sleep(time.Until(start))
+	resp, err = svc.ListEvents(context.Background(), &sports.ListEventsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, sports.Event_STATUS_CLOSED, resp.Events[0].Status)
+}